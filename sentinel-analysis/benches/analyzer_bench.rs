@@ -1,8 +1,31 @@
 use criterion::{BenchmarkId, Criterion, black_box, criterion_group, criterion_main};
+use std::alloc::{GlobalAlloc, Layout, System};
 use std::fs;
 use std::sync::Arc;
+use std::sync::atomic::{AtomicUsize, Ordering};
 use scoper::{RulesRegistry, analyzer, utilities::DebugLevel};
 
+/// Counts the allocations of the benchmarks, to compare buffer and allocator reuse
+struct CountingAllocator;
+
+static ALLOCATIONS: AtomicUsize = AtomicUsize::new(0);
+static ALLOCATED_BYTES: AtomicUsize = AtomicUsize::new(0);
+
+unsafe impl GlobalAlloc for CountingAllocator {
+    unsafe fn alloc(&self, layout: Layout) -> *mut u8 {
+        ALLOCATIONS.fetch_add(1, Ordering::Relaxed);
+        ALLOCATED_BYTES.fetch_add(layout.size(), Ordering::Relaxed);
+        unsafe { System.alloc(layout) }
+    }
+
+    unsafe fn dealloc(&self, ptr: *mut u8, layout: Layout) {
+        unsafe { System.dealloc(ptr, layout) }
+    }
+}
+
+#[global_allocator]
+static GLOBAL: CountingAllocator = CountingAllocator;
+
 const SMALL_FILE: &str = r#"
 function test() {
     console.log("Hello World");
@@ -22,10 +45,11 @@ export class AppComponent {
 "#;
 
 fn setup_test_files(content: &str, count: usize) -> Vec<String> {
-    let temp_dir = tempfile::tempdir().unwrap();
+    // The directory is kept, the files are read in every iteration
+    let temp_dir = tempfile::tempdir().unwrap().into_path();
     (0..count)
         .map(|i| {
-            let file_path = temp_dir.path().join(format!("test_{}.ts", i));
+            let file_path = temp_dir.join(format!("test_{}.ts", i));
             fs::write(&file_path, content).unwrap();
             file_path.to_str().unwrap().to_string()
         })
@@ -106,10 +130,51 @@ fn bench_allocator_reuse(c: &mut Criterion) {
     group.finish();
 }
 
+fn bench_allocations(c: &mut Criterion) {
+    let mut group = c.benchmark_group("allocations");
+    group.sample_size(10);
+
+    let rules_registry = Arc::new(RulesRegistry::new());
+    let debug_level = DebugLevel::None;
+
+    for (size_name, content, count) in [
+        ("medium", MEDIUM_FILE, 200),
+        ("large", include_str!("../test_files/large.ts"), 50),
+    ] {
+        let files = setup_test_files(content, count);
+
+        // Criterion measures time only, so the allocations of one run are printed
+        let allocations = ALLOCATIONS.load(Ordering::Relaxed);
+        let bytes = ALLOCATED_BYTES.load(Ordering::Relaxed);
+        black_box(analyzer::process_files(
+            &files,
+            &rules_registry,
+            debug_level,
+        ));
+        println!(
+            "allocations/{}: {} allocations, {} KiB per file",
+            size_name,
+            (ALLOCATIONS.load(Ordering::Relaxed) - allocations) / count,
+            (ALLOCATED_BYTES.load(Ordering::Relaxed) - bytes) / count / 1024
+        );
+
+        group.bench_with_input(
+            BenchmarkId::new("process_files", size_name),
+            &files,
+            |b, files| {
+                b.iter(|| analyzer::process_files(black_box(files), &rules_registry, debug_level))
+            },
+        );
+    }
+
+    group.finish();
+}
+
 criterion_group!(
     benches,
     bench_file_analysis,
     bench_batch_sizes,
-    bench_allocator_reuse
+    bench_allocator_reuse,
+    bench_allocations
 );
 criterion_main!(benches);
@@ -2,6 +2,8 @@ use std::env;
 use std::fs;
 use std::io::Write;
 use std::path::Path;
+use std::process::Command;
+use std::time::{SystemTime, UNIX_EPOCH};
 
 const CUSTOM_RULES_DIR: &str = "src/rules/custom";
 const GENERATED_FILE: &str = "generated_rules.rs";
@@ -21,6 +23,49 @@ fn main() {
 
     // Generate the rules registration file
     generate_rules_file();
+
+    // Embed the commit, build date and parser version reported by `scoper version`
+    emit_build_info();
+}
+
+fn emit_build_info() {
+    println!("cargo:rerun-if-changed=Cargo.toml");
+    println!("cargo:rerun-if-changed=../.git/HEAD");
+    println!("cargo:rerun-if-changed=../.git/refs");
+    println!("cargo:rerun-if-env-changed=SOURCE_DATE_EPOCH");
+
+    let commit = Command::new("git")
+        .args(["rev-parse", "--short=12", "HEAD"])
+        .output()
+        .ok()
+        .filter(|output| output.status.success())
+        .map(|output| String::from_utf8_lossy(&output.stdout).trim().to_string())
+        .unwrap_or_else(|| "unknown".to_string());
+    println!("cargo:rustc-env=SCOPER_GIT_COMMIT={}", commit);
+
+    // SOURCE_DATE_EPOCH keeps reproducible builds reproducible
+    let timestamp = env::var("SOURCE_DATE_EPOCH")
+        .ok()
+        .and_then(|epoch| epoch.parse::<u64>().ok())
+        .unwrap_or_else(|| {
+            SystemTime::now()
+                .duration_since(UNIX_EPOCH)
+                .map(|d| d.as_secs())
+                .unwrap_or(0)
+        });
+    println!("cargo:rustc-env=SCOPER_BUILD_TIMESTAMP={}", timestamp);
+
+    let parser_version = fs::read_to_string("Cargo.toml")
+        .ok()
+        .and_then(|manifest| {
+            manifest
+                .lines()
+                .find(|line| line.trim_start().starts_with("oxc_parser "))
+                .and_then(|line| line.split('"').nth(1))
+                .map(str::to_string)
+        })
+        .unwrap_or_else(|| "unknown".to_string());
+    println!("cargo:rustc-env=SCOPER_PARSER_VERSION={}", parser_version);
 }
 
 fn generate_rules_file() {
@@ -0,0 +1,267 @@
+//! LLM-assisted fix suggestions for findings
+//!
+//! For findings of the rules selected in the `ai_suggestions` configuration, the chunk
+//! containing the finding is sent together with the rule's message and help to an
+//! OpenAI-compatible chat completions endpoint. The returned patch is attached to the
+//! finding in `findings.json` and always marked as AI-generated, since it is not verified
+//! by the analyzer. The step only runs with `--ai-suggestions` and a configured API key.
+
+use crate::chunker::{Chunk, ChunkOptions, chunk_source};
+use crate::exporter::FindingEntry;
+use crate::rules_registry::PROJECT_FILE;
+use crate::utilities::config::AiSuggestionsConfig;
+use crate::utilities::paths::PathBase;
+use crate::utilities::source::decode_source;
+use crate::utilities::{DebugLevel, log};
+use oxc_span::SourceType;
+use reqwest::blocking::Client;
+use serde::{Deserialize, Serialize};
+use serde_json::{Value, json};
+use std::collections::HashMap;
+use std::fs;
+use std::path::Path;
+
+const DEFAULT_CHAT_URL: &str = "https://api.openai.com/v1/chat/completions";
+const DEFAULT_MODEL: &str = "gpt-4o-mini";
+/// Maximum number of suggestions requested per run, to bound cost and run time
+const DEFAULT_MAX_SUGGESTIONS: usize = 20;
+/// Lines around a finding sent as context when the file cannot be chunked
+const FALLBACK_CONTEXT_LINES: usize = 20;
+
+const SYSTEM_PROMPT: &str = "You fix issues reported by Sentinel, a static analyzer for TypeScript and Angular code. \
+Reply with a minimal unified diff against the given file that fixes the reported issue and nothing else. \
+Keep the surrounding code unchanged. Do not add explanations outside of the diff.";
+
+/// A fix suggested by an LLM, attached to a finding
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct AiSuggestion {
+    /// Always `true`, so consumers can tell generated patches from rule-provided fixes
+    pub ai_generated: bool,
+    pub model: String,
+    /// The suggested patch as a unified diff
+    pub patch: String,
+}
+
+/// The code sent to the LLM for a finding
+struct FindingContext {
+    start_line: usize,
+    code: String,
+}
+
+/// Prefix every line of the code with its line number
+fn numbered_code(context: &FindingContext) -> String {
+    context
+        .code
+        .lines()
+        .enumerate()
+        .map(|(i, line)| format!("{:>5} | {}", context.start_line + i, line))
+        .collect::<Vec<_>>()
+        .join("\n")
+}
+
+/// Remove a Markdown code fence around the reply, if the model added one
+fn strip_code_fence(reply: &str) -> String {
+    let trimmed = reply.trim();
+    match trimmed.strip_prefix("```") {
+        Some(rest) => {
+            let body = rest.split_once('\n').map_or("", |(_, body)| body);
+            body.trim_end()
+                .trim_end_matches("```")
+                .trim_end()
+                .to_string()
+        }
+        None => trimmed.to_string(),
+    }
+}
+
+/// Get the chunk containing a line, or the lines around it if the file cannot be chunked
+fn finding_context(source: &str, chunks: &[Chunk], line: usize) -> FindingContext {
+    if let Some(chunk) = chunks
+        .iter()
+        .find(|chunk| chunk.start_line <= line && line <= chunk.end_line)
+    {
+        return FindingContext {
+            start_line: chunk.start_line,
+            code: chunk.code.clone(),
+        };
+    }
+
+    let start_line = line.saturating_sub(FALLBACK_CONTEXT_LINES).max(1);
+    FindingContext {
+        start_line,
+        code: source
+            .lines()
+            .skip(start_line - 1)
+            .take(2 * FALLBACK_CONTEXT_LINES + 1)
+            .collect::<Vec<_>>()
+            .join("\n"),
+    }
+}
+
+struct SuggestionClient<'c> {
+    config: &'c AiSuggestionsConfig,
+    api_key: &'c str,
+    client: Client,
+}
+
+impl<'c> SuggestionClient<'c> {
+    fn model(&self) -> &str {
+        self.config.model.as_deref().unwrap_or(DEFAULT_MODEL)
+    }
+
+    fn request_suggestion(
+        &self,
+        finding: &FindingEntry,
+        context: &FindingContext,
+    ) -> Result<AiSuggestion, String> {
+        let url = self.config.url.as_deref().unwrap_or(DEFAULT_CHAT_URL);
+        let prompt = format!(
+            "File: {}\nRule: {}\nIssue at line {}: {}\nGuidance: {}\n\nCode:\n{}",
+            finding.file,
+            finding.rule,
+            finding.line,
+            finding.message,
+            finding.help.as_deref().unwrap_or("none"),
+            numbered_code(context)
+        );
+
+        let response = self
+            .client
+            .post(url)
+            .bearer_auth(self.api_key)
+            .json(&json!({
+                "model": self.model(),
+                "temperature": 0,
+                "messages": [
+                    { "role": "system", "content": SYSTEM_PROMPT },
+                    { "role": "user", "content": prompt }
+                ]
+            }))
+            .send()
+            .map_err(|e| format!("Suggestion request to {} failed: {}", url, e))?;
+
+        let status = response.status();
+        let body: Value = response
+            .json()
+            .map_err(|e| format!("Invalid suggestion response from {}: {}", url, e))?;
+        if !status.is_success() {
+            return Err(format!(
+                "Suggestion request to {} failed with status {}: {}",
+                url, status, body
+            ));
+        }
+
+        let reply = body
+            .pointer("/choices/0/message/content")
+            .and_then(Value::as_str)
+            .ok_or_else(|| format!("Suggestion response from {} has no content", url))?;
+
+        Ok(AiSuggestion {
+            ai_generated: true,
+            model: self.model().to_string(),
+            patch: strip_code_fence(reply),
+        })
+    }
+}
+
+/// Request fix suggestions for the findings of the selected rules
+///
+/// The files of the findings are read relative to `base`, see `PathBase`.
+pub fn attach_ai_suggestions(
+    findings: &mut [FindingEntry],
+    config: &AiSuggestionsConfig,
+    base: &PathBase,
+    debug_level: DebugLevel,
+) {
+    let Some(api_key) = config.api_key.as_deref().filter(|key| !key.is_empty()) else {
+        log(
+            DebugLevel::Warn,
+            debug_level,
+            "AI suggestions are enabled, but no api_key is configured in ai_suggestions",
+        );
+        return;
+    };
+    if config.rules.is_empty() {
+        log(
+            DebugLevel::Warn,
+            debug_level,
+            "AI suggestions are enabled, but no rules are selected in ai_suggestions.rules",
+        );
+        return;
+    }
+
+    let client = SuggestionClient {
+        config,
+        api_key,
+        client: Client::new(),
+    };
+    let max_suggestions = config.max_suggestions.unwrap_or(DEFAULT_MAX_SUGGESTIONS);
+
+    // Files are read and chunked once, even if they have several findings; `None` for files
+    // that cannot be read
+    let chunk_options = ChunkOptions::default();
+    let mut files: HashMap<String, Option<(String, Vec<Chunk>)>> = HashMap::new();
+    let mut suggested = 0;
+
+    for finding in findings
+        .iter_mut()
+        .filter(|finding| config.rules.contains(&finding.rule))
+        // Project-level findings have no code to fix
+        .filter(|finding| finding.file != PROJECT_FILE)
+    {
+        if suggested >= max_suggestions {
+            log(
+                DebugLevel::Info,
+                debug_level,
+                &format!("Reached the limit of {} AI suggestions", max_suggestions),
+            );
+            break;
+        }
+
+        let file = files.entry(finding.file.clone()).or_insert_with(|| {
+            let source = match fs::read(base.resolve(&finding.file))
+                .map_err(|e| e.to_string())
+                .and_then(decode_source)
+            {
+                Ok(source) => source.content,
+                Err(e) => {
+                    log(
+                        DebugLevel::Error,
+                        debug_level,
+                        &format!("Failed to read {} for AI suggestions: {}", finding.file, e),
+                    );
+                    return None;
+                }
+            };
+            let chunks = SourceType::from_path(Path::new(&finding.file))
+                .ok()
+                .and_then(|source_type| {
+                    chunk_source(&source, source_type, &finding.file, &chunk_options).ok()
+                })
+                .unwrap_or_default();
+            Some((source, chunks))
+        });
+        let Some((source, chunks)) = file else {
+            continue;
+        };
+
+        let context = finding_context(source, chunks, finding.line);
+        match client.request_suggestion(finding, &context) {
+            Ok(suggestion) => {
+                finding.ai_suggestion = Some(suggestion);
+                suggested += 1;
+            }
+            Err(err) => {
+                log(DebugLevel::Error, debug_level, &err);
+                // Stop on the first failure instead of repeating it for every finding
+                break;
+            }
+        }
+    }
+
+    log(
+        DebugLevel::Info,
+        debug_level,
+        &format!("Attached {} AI-generated fix suggestions", suggested),
+    );
+}
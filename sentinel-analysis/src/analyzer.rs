@@ -1,19 +1,31 @@
 use crate::FileAnalysisResult;
 use crate::RuleDiagnostic;
+use crate::angular_graph::extract_angular_symbols;
+use crate::cache::{RuleCache, SpilledResult, content_hash};
+use crate::rules::{PARSE_ERROR_RULE, RuleCategory};
 use crate::rules_registry::RulesRegistry;
+use crate::utilities::config::Config;
+use crate::utilities::memory::resident_bytes;
+use crate::utilities::source::{ColumnUnit, decode_source, diagnostic_span, position_of_offset};
 use crate::utilities::{DebugLevel, log};
 
+use memmap2::Mmap;
 use oxc_allocator::Allocator;
+use oxc_diagnostics::OxcDiagnostic;
 use oxc_parser::Parser;
 use oxc_semantic::SemanticBuilder;
 use oxc_span::SourceType;
 
 use rayon::prelude::*;
-use std::collections::HashMap;
+use std::collections::{HashMap, HashSet};
 use std::fs;
-use std::path::Path;
+use std::io::{BufRead, BufReader, Read, Write};
+use std::path::{Path, PathBuf};
 use std::sync::Arc;
-use std::time::{Duration, Instant};
+use std::time::{Duration, Instant, SystemTime, UNIX_EPOCH};
+
+/// Maximum size of the files preloaded together, unless `batch_bytes` is configured
+pub const DEFAULT_BATCH_BYTES: u64 = 16 * 1024 * 1024;
 
 // Calculate optimal batch size based on available CPU cores
 fn calculate_batch_size() -> usize {
@@ -23,56 +35,237 @@ fn calculate_batch_size() -> usize {
     num_cpus * 2
 }
 
+/// Limits of the files that are preloaded and analyzed together
+///
+/// A batch ends at `max_files` files or once its files exceed `max_bytes`, so a few large
+/// files do not have to be held in memory at once. A single file larger than `max_bytes`
+/// is a batch of its own.
+///
+/// With a `memory_limit`, the batches are analyzed in waves of one batch per thread and
+/// the resident memory is checked after each wave. Above the limit the analysis switches
+/// to streaming instead of the process being killed for running out of memory: the
+/// remaining files are split into batches half the size, down to a single file per batch,
+/// the AST memory of each file is released instead of kept for the next one, and the
+/// results of finished waves are spilled to a temporary file until all files are done.
+///
+/// The files of a batch are read in parallel, on the analysis threads unless
+/// `read_threads` gives reading a pool of its own, e.g. a small one for network file
+/// systems. Files of at least `mmap_threshold` bytes are memory-mapped instead of read.
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub struct BatchOptions {
+    pub max_files: usize,
+    pub max_bytes: u64,
+    /// Resident memory in bytes above which the analysis switches to streaming
+    pub memory_limit: Option<u64>,
+    /// Number of threads reading files
+    pub read_threads: Option<usize>,
+    /// Size in bytes from which files are memory-mapped
+    pub mmap_threshold: Option<u64>,
+}
+
+impl Default for BatchOptions {
+    fn default() -> Self {
+        Self {
+            max_files: calculate_batch_size(),
+            max_bytes: DEFAULT_BATCH_BYTES,
+            memory_limit: None,
+            read_threads: None,
+            mmap_threshold: None,
+        }
+    }
+}
+
+impl BatchOptions {
+    /// Get the limits from `batch_size`, `batch_bytes`, `memory_limit_mb`, `read_threads`
+    /// and `mmap_threshold`
+    pub fn from_config(config: &Config) -> Self {
+        let defaults = Self::default();
+        Self {
+            max_files: config.batch_size.unwrap_or(defaults.max_files).max(1),
+            max_bytes: config.batch_bytes.unwrap_or(defaults.max_bytes),
+            memory_limit: config.memory_limit_mb.map(|mb| mb * 1024 * 1024),
+            read_threads: config.read_threads.filter(|&threads| threads > 0),
+            mmap_threshold: config.mmap_threshold,
+        }
+    }
+
+    /// Get limits half the size, for when memory runs short
+    fn shrink(&self) -> Self {
+        Self {
+            max_files: (self.max_files / 2).max(1),
+            max_bytes: (self.max_bytes / 2).max(1),
+            ..*self
+        }
+    }
+
+    /// Check if the batches cannot shrink any further
+    fn is_minimal(&self) -> bool {
+        self.max_files == 1
+    }
+}
+
+/// Split files into batches by count and size on disk
+fn split_batches<'f>(files: &'f [String], options: &BatchOptions) -> Vec<&'f [String]> {
+    let mut batches = Vec::new();
+    let mut start = 0;
+    let mut bytes = 0;
+    for (index, file) in files.iter().enumerate() {
+        let size = fs::metadata(file).map_or(0, |metadata| metadata.len());
+        let full = index - start >= options.max_files || bytes + size > options.max_bytes;
+        if index > start && full {
+            batches.push(&files[start..index]);
+            start = index;
+            bytes = 0;
+        }
+        bytes += size;
+    }
+    if start < files.len() {
+        batches.push(&files[start..]);
+    }
+    batches
+}
+
+/// Largest read buffer kept for the next file, so one huge file does not pin its memory
+const MAX_POOLED_BUFFER: usize = 1024 * 1024;
+
+/// Decode the bytes of a file into the shared source text
+///
+/// Plain UTF-8 files, by far the most common, are copied straight into the shared source
+/// text; other encodings go through `decode_source`.
+fn decode_bytes(bytes: &[u8]) -> Result<(Arc<str>, &'static str), String> {
+    let has_bom = [&[0xEF, 0xBB, 0xBF][..], &[0xFF, 0xFE], &[0xFE, 0xFF]]
+        .iter()
+        .any(|bom| bytes.starts_with(bom));
+    match std::str::from_utf8(bytes) {
+        Ok(content) if !has_bom && !bytes.contains(&0) => Ok((Arc::from(content), "utf-8")),
+        _ => decode_source(bytes.to_vec())
+            .map(|decoded| (Arc::from(decoded.content), decoded.encoding)),
+    }
+}
+
+/// Read and decode a file, reusing the buffer of the previous file
+///
+/// Files of at least `mmap_threshold` bytes are decoded from a memory map instead, which
+/// saves copying them into the buffer first.
+pub(crate) fn read_source(
+    file_path: &str,
+    buffer: &mut Vec<u8>,
+    mmap_threshold: Option<u64>,
+) -> Result<(Arc<str>, &'static str), String> {
+    let mut file = fs::File::open(file_path).map_err(|err| err.to_string())?;
+    let size = file.metadata().map_or(0, |metadata| metadata.len());
+    if mmap_threshold.is_some_and(|threshold| size >= threshold) {
+        // SAFETY: the map only lives while the file is decoded; like any mapped file, it
+        // must not be truncated by another process meanwhile
+        let map = unsafe { Mmap::map(&file) }.map_err(|err| err.to_string())?;
+        return decode_bytes(&map);
+    }
+
+    buffer.clear();
+    file.read_to_end(buffer).map_err(|err| err.to_string())?;
+    let result = decode_bytes(buffer);
+    buffer.shrink_to(MAX_POOLED_BUFFER);
+    result
+}
+
+/// Reads the files of the batches
+struct FileReader {
+    /// Pool of the `read_threads`, the analysis threads read if not set
+    pool: Option<rayon::ThreadPool>,
+    mmap_threshold: Option<u64>,
+}
+
+impl FileReader {
+    fn new(options: &BatchOptions) -> Self {
+        let pool = options.read_threads.map(|threads| {
+            rayon::ThreadPoolBuilder::new()
+                .num_threads(threads)
+                .build()
+                .expect("Failed to create read thread pool")
+        });
+        Self {
+            pool,
+            mmap_threshold: options.mmap_threshold,
+        }
+    }
+
+    // Pre-load file contents in parallel, reusing a read buffer per worker
+    fn read_all(
+        &self,
+        files: &[String],
+        debug_level: DebugLevel,
+    ) -> Vec<(String, Result<FileContent, String>)> {
+        let read = || {
+            files
+                .par_iter()
+                .map_init(Vec::new, |buffer, file_path| {
+                    let content = read_source(file_path, buffer, self.mmap_threshold).map(
+                        |(content, encoding)| {
+                            if encoding != "utf-8" {
+                                log(
+                                    DebugLevel::Info,
+                                    debug_level,
+                                    &format!("Decoded {} as {}", file_path, encoding),
+                                );
+                            }
+                            let source_type = SourceType::from_path(Path::new(file_path)).ok();
+                            FileContent {
+                                content,
+                                source_type,
+                            }
+                        },
+                    );
+                    (file_path.clone(), content)
+                })
+                .collect()
+        };
+        match &self.pool {
+            Some(pool) => pool.install(read),
+            None => read(),
+        }
+    }
+}
+
 /// Holds shared resources for batch processing
-struct BatchProcessor {
+struct BatchProcessor<'c> {
     allocator: Allocator,
     rules_registry: Arc<RulesRegistry>,
+    cache: Option<&'c RuleCache>,
+    reader: &'c FileReader,
     debug_level: DebugLevel,
+    /// Free the AST memory after each file instead of keeping it for the next one
+    release_memory: bool,
 }
 
 #[derive(Default)]
 struct FileContent {
-    content: String,
+    content: Arc<str>,
     source_type: Option<SourceType>,
 }
 
-impl BatchProcessor {
-    fn new(rules_registry: Arc<RulesRegistry>, debug_level: DebugLevel) -> Self {
+impl<'c> BatchProcessor<'c> {
+    fn new(
+        rules_registry: Arc<RulesRegistry>,
+        cache: Option<&'c RuleCache>,
+        reader: &'c FileReader,
+        debug_level: DebugLevel,
+        release_memory: bool,
+    ) -> Self {
         // Initialize with a larger capacity for reuse
         let allocator = Allocator::with_capacity(1024 * 1024); // 1MB initial capacity
         Self {
             allocator,
             rules_registry,
+            cache,
+            reader,
             debug_level,
+            release_memory,
         }
     }
 
-    // Pre-load file contents in parallel
-    fn preload_files(files: &[String]) -> Vec<(String, Result<FileContent, String>)> {
-        files
-            .par_iter()
-            .map(|file_path| {
-                let content = match fs::read(file_path) {
-                    Ok(bytes) => match String::from_utf8(bytes) {
-                        Ok(content) => {
-                            let source_type = SourceType::from_path(Path::new(file_path)).ok();
-                            Ok(FileContent {
-                                content,
-                                source_type,
-                            })
-                        }
-                        Err(_) => Err("UTF-8 conversion failed".to_string()),
-                    },
-                    Err(err) => Err(err.to_string()),
-                };
-                (file_path.clone(), content)
-            })
-            .collect()
-    }
-
     fn process_batch(&mut self, files: &[String]) -> Vec<FileAnalysisResult> {
         // Pre-load all files in parallel
-        let preloaded_files = Self::preload_files(files);
+        let preloaded_files = self.reader.read_all(files, self.debug_level);
 
         // Process preloaded files sequentially to reuse allocator
         preloaded_files
@@ -82,8 +275,12 @@ impl BatchProcessor {
                     Ok(file_content) => self.analyze_preloaded_file(file_path, file_content),
                     Err(err) => self.create_error_result(file_path, err),
                 };
-                // Reset allocator for next file
-                self.allocator.reset();
+                // Reset allocator for next file, or give its memory back when memory is short
+                if self.release_memory {
+                    self.allocator = Allocator::default();
+                } else {
+                    self.allocator.reset();
+                }
                 result
             })
             .collect()
@@ -96,6 +293,30 @@ impl BatchProcessor {
     ) -> FileAnalysisResult {
         let file_start = Instant::now();
 
+        // Skip generated code, e.g. protobuf or GraphQL clients, unless configured otherwise
+        let generated = self
+            .rules_registry
+            .is_generated_file(file_path, &content.content);
+        if generated && self.rules_registry.skips_generated_files() {
+            log(
+                DebugLevel::Debug,
+                self.debug_level,
+                &format!("Skipping generated file {}", file_path),
+            );
+            return FileAnalysisResult {
+                file_path: file_path.to_string(),
+                parse_duration: Duration::from_secs(0),
+                semantic_duration: Duration::from_secs(0),
+                rule_durations: HashMap::new(),
+                total_duration: file_start.elapsed(),
+                diagnostics: Vec::new(),
+                angular_symbols: Vec::new(),
+                content_hash: None,
+                generated: true,
+                skipped: None,
+            };
+        }
+
         // Parse file
         let parse_start = Instant::now();
         let source_type = match content.source_type {
@@ -103,30 +324,74 @@ impl BatchProcessor {
             None => return self.create_error_result(file_path, "Invalid source type"),
         };
 
+        // Reuse the cached results of unchanged rules on unchanged files, and skip parsing
+        // if they cover every rule that needs the AST
+        let cached = self.cache.map_or_else(HashMap::new, |cache| {
+            cache.lookup(file_path, &content.content, &self.rules_registry)
+        });
+        if let Some(parse) = self
+            .cache
+            .filter(|_| self.rules_registry.is_fully_cached(file_path, &cached))
+            .and_then(|cache| cache.lookup_parse(file_path, &content.content))
+        {
+            log(
+                DebugLevel::Debug,
+                self.debug_level,
+                &format!("Using cached results of all rules for {}", file_path),
+            );
+            let column_unit = self.rules_registry.column_unit();
+            let mut diagnostics: Vec<RuleDiagnostic> = parse
+                .parse_errors
+                .into_iter()
+                .map(|err| parse_error_diagnostic(err, &content.content, column_unit))
+                .collect();
+            let (rule_diagnostics, rule_durations) =
+                self.rules_registry
+                    .run_cached_rules(file_path, &content.content, cached);
+            diagnostics.extend(rule_diagnostics);
+            return FileAnalysisResult {
+                file_path: file_path.to_string(),
+                parse_duration: Duration::from_secs(0),
+                semantic_duration: Duration::from_secs(0),
+                rule_durations,
+                total_duration: file_start.elapsed(),
+                diagnostics,
+                angular_symbols: parse.angular_symbols,
+                content_hash: Some(content_hash(content.content.as_bytes())),
+                generated,
+                skipped: None,
+            };
+        }
+
         let parse_result = Parser::new(&self.allocator, &content.content, source_type).parse();
-        if !parse_result.errors.is_empty() {
+
+        // The parser recovers from most syntax errors, rules run on the recovered program
+        // and the parse errors are reported along with their findings
+        let column_unit = self.rules_registry.column_unit();
+        let parser_diagnostics: Vec<RuleDiagnostic> = parse_result
+            .errors
+            .iter()
+            .map(|err| parse_error_diagnostic(err.clone(), &content.content, column_unit))
+            .collect();
+        if !parser_diagnostics.is_empty() {
             log(
                 DebugLevel::Error,
                 self.debug_level,
                 &format!(
-                    "Parse errors in {}: {}",
+                    "Parse errors in {}: {}{}",
                     file_path,
-                    parse_result.errors.len()
+                    parser_diagnostics.len(),
+                    if parse_result.panicked {
+                        ""
+                    } else {
+                        ", analyzing the recovered program"
+                    }
                 ),
             );
+        }
 
-            let parser_diagnostics = parse_result
-                .errors
-                .into_iter()
-                .map(|err| RuleDiagnostic {
-                    rule_id: "parser".to_string(),
-                    diagnostic: err,
-                    source_code: content.content.clone(),
-                    line_number: 0,
-                    column_number: 0,
-                })
-                .collect();
-
+        // Nothing to analyze if the parser gave up
+        if parse_result.panicked {
             return FileAnalysisResult {
                 file_path: file_path.to_string(),
                 parse_duration: parse_start.elapsed(),
@@ -134,6 +399,10 @@ impl BatchProcessor {
                 rule_durations: HashMap::new(),
                 total_duration: file_start.elapsed(),
                 diagnostics: parser_diagnostics,
+                angular_symbols: Vec::new(),
+                content_hash: None,
+                generated,
+                skipped: None,
             };
         }
 
@@ -144,12 +413,29 @@ impl BatchProcessor {
         let semantic_result = SemanticBuilder::new().build(&parse_result.program);
         let semantic_duration = semantic_start.elapsed();
 
-        // Run rules
-        let (diagnostics, rule_durations) = self.rules_registry.run_rules_with_metrics(
+        // Run the rules that missed the cache
+        if !cached.is_empty() {
+            log(
+                DebugLevel::Debug,
+                self.debug_level,
+                &format!(
+                    "Using cached results of {} rules for {}",
+                    cached.len(),
+                    file_path
+                ),
+            );
+        }
+        let (rule_diagnostics, rule_durations) = self.rules_registry.run_rules_with_cache(
             &semantic_result,
             file_path,
             &content.content,
+            cached,
         );
+        let mut diagnostics = parser_diagnostics;
+        diagnostics.extend(rule_diagnostics);
+
+        // Collect Angular classes for the component and injection graph
+        let angular_symbols = extract_angular_symbols(&parse_result.program, file_path);
 
         FileAnalysisResult {
             file_path: file_path.to_string(),
@@ -158,6 +444,10 @@ impl BatchProcessor {
             rule_durations,
             total_duration: file_start.elapsed(),
             diagnostics,
+            angular_symbols,
+            content_hash: Some(content_hash(content.content.as_bytes())),
+            generated,
+            skipped: None,
         }
     }
 
@@ -175,36 +465,430 @@ impl BatchProcessor {
             rule_durations: HashMap::new(),
             total_duration: Duration::from_secs(0),
             diagnostics: Vec::new(),
+            angular_symbols: Vec::new(),
+            content_hash: None,
+            generated: false,
+            skipped: Some(error_msg.to_string()),
         }
     }
 }
 
+/// Report a syntax error of the parser as a finding of the reserved parse error rule
+pub(crate) fn parse_error_diagnostic(
+    diagnostic: OxcDiagnostic,
+    source_code: &Arc<str>,
+    column_unit: ColumnUnit,
+) -> RuleDiagnostic {
+    let (line_number, column_number) = diagnostic_span(&diagnostic).map_or((0, 0), |span| {
+        position_of_offset(source_code, span.start as usize, column_unit)
+    });
+    RuleDiagnostic {
+        rule_id: PARSE_ERROR_RULE.to_string(),
+        category: RuleCategory::Correctness,
+        rule_version: "1",
+        docs_url: None,
+        metadata: HashMap::new(),
+        suggestion: None,
+        diagnostic,
+        source_code: Arc::clone(source_code),
+        line_number,
+        column_number,
+        blame: None,
+    }
+}
+
 /// Process files in parallel using rayon with optimized batch processing
 pub fn process_files(
     files: &[String],
     rules_registry_arc: &Arc<RulesRegistry>,
     debug_level: DebugLevel,
+) -> (Vec<FileAnalysisResult>, Duration) {
+    process_files_with_cache(
+        files,
+        rules_registry_arc,
+        None,
+        BatchOptions::default(),
+        debug_level,
+    )
+}
+
+/// Process files like `process_files`, reusing the results of a rule cache
+pub fn process_files_with_cache(
+    files: &[String],
+    rules_registry_arc: &Arc<RulesRegistry>,
+    cache: Option<&RuleCache>,
+    batch_options: BatchOptions,
+    debug_level: DebugLevel,
 ) -> (Vec<FileAnalysisResult>, Duration) {
     let analysis_start = Instant::now();
-    let batch_size = calculate_batch_size();
+    let batches = split_batches(files, &batch_options);
+    log(
+        DebugLevel::Debug,
+        debug_level,
+        &format!(
+            "Analyzing {} files in {} batches",
+            files.len(),
+            batches.len()
+        ),
+    );
 
     // Create processors up front, one per thread
     let thread_pool = rayon::ThreadPoolBuilder::new()
         .build()
         .expect("Failed to create thread pool");
 
-    let analysis_results: Vec<FileAnalysisResult> = thread_pool.install(|| {
-        files
-            .par_chunks(batch_size)
-            .map(|batch| {
-                let mut processor =
-                    BatchProcessor::new(Arc::clone(rules_registry_arc), debug_level);
-                processor.process_batch(batch)
-            })
-            .flatten()
-            .collect()
-    });
+    let reader = FileReader::new(&batch_options);
+    let analysis_results: Vec<FileAnalysisResult> =
+        thread_pool.install(|| match batch_options.memory_limit {
+            Some(limit) => process_batches_with_memory_guard(
+                files,
+                batches,
+                rules_registry_arc,
+                cache,
+                &reader,
+                batch_options,
+                limit,
+                debug_level,
+            ),
+            None => process_batches(
+                &batches,
+                rules_registry_arc,
+                cache,
+                &reader,
+                false,
+                debug_level,
+            ),
+        });
 
     let analysis_duration = analysis_start.elapsed();
     (analysis_results, analysis_duration)
 }
+
+/// Analyze source text held in memory instead of files on disk, e.g. the fixtures of tests
+///
+/// The sources are analyzed like files at their paths, which do not have to exist.
+pub fn process_sources(
+    sources: &[(String, String)],
+    rules_registry_arc: &Arc<RulesRegistry>,
+    debug_level: DebugLevel,
+) -> (Vec<FileAnalysisResult>, Duration) {
+    let analysis_start = Instant::now();
+    let reader = FileReader::new(&BatchOptions::default());
+    let analysis_results = sources
+        .par_iter()
+        .map_init(
+            || {
+                BatchProcessor::new(
+                    Arc::clone(rules_registry_arc),
+                    None,
+                    &reader,
+                    debug_level,
+                    false,
+                )
+            },
+            |processor, (file_path, source)| {
+                let content = FileContent {
+                    content: Arc::from(source.as_str()),
+                    source_type: SourceType::from_path(Path::new(file_path)).ok(),
+                };
+                let result = processor.analyze_preloaded_file(file_path, &content);
+                processor.allocator.reset();
+                result
+            },
+        )
+        .collect();
+    (analysis_results, analysis_start.elapsed())
+}
+
+/// Analyze batches in parallel, one processor per batch
+fn process_batches(
+    batches: &[&[String]],
+    rules_registry_arc: &Arc<RulesRegistry>,
+    cache: Option<&RuleCache>,
+    reader: &FileReader,
+    release_memory: bool,
+    debug_level: DebugLevel,
+) -> Vec<FileAnalysisResult> {
+    // Processors are reused for the following batches of a worker, with their allocator
+    batches
+        .par_iter()
+        .map_init(
+            || {
+                BatchProcessor::new(
+                    Arc::clone(rules_registry_arc),
+                    cache,
+                    reader,
+                    debug_level,
+                    release_memory,
+                )
+            },
+            |processor, batch| processor.process_batch(batch),
+        )
+        .flatten()
+        .collect()
+}
+
+/// Analysis results written to a temporary file while memory is short
+///
+/// The file is removed once the spill is dropped.
+struct Spill {
+    path: PathBuf,
+    file: fs::File,
+    /// Bytes of complete results in the file
+    written: u64,
+}
+
+impl Spill {
+    fn create() -> Result<Self, String> {
+        let started = SystemTime::now()
+            .duration_since(UNIX_EPOCH)
+            .map_or(0, |elapsed| elapsed.as_nanos());
+        let path = std::env::temp_dir().join(format!(
+            "sentinel-spill-{}-{}.jsonl",
+            std::process::id(),
+            started
+        ));
+        let file = fs::File::create(&path)
+            .map_err(|e| format!("Failed to create {}: {}", path.display(), e))?;
+        Ok(Self {
+            path,
+            file,
+            written: 0,
+        })
+    }
+
+    /// Append results as JSON lines
+    fn write(&mut self, results: &[FileAnalysisResult]) -> Result<(), String> {
+        let mut lines = String::new();
+        for result in results {
+            let line = serde_json::to_string(&SpilledResult::from_result(result))
+                .map_err(|e| format!("Failed to serialize {}: {}", result.file_path, e))?;
+            lines.push_str(&line);
+            lines.push('\n');
+        }
+        self.file
+            .write_all(lines.as_bytes())
+            .and_then(|_| self.file.flush())
+            .map_err(|e| format!("Failed to write {}: {}", self.path.display(), e))?;
+        self.written += lines.len() as u64;
+        Ok(())
+    }
+
+    /// Read back the results written so far
+    fn read(&self, rules_registry: &RulesRegistry) -> Result<Vec<FileAnalysisResult>, String> {
+        let read_error = |e: String| format!("Failed to read {}: {}", self.path.display(), e);
+        let file = fs::File::open(&self.path).map_err(|e| read_error(e.to_string()))?;
+        BufReader::new(file.take(self.written))
+            .lines()
+            .map(|line| {
+                let line = line.map_err(|e| read_error(e.to_string()))?;
+                serde_json::from_str::<SpilledResult>(&line)
+                    .map(|spilled| spilled.into_result(rules_registry))
+                    .map_err(|e| read_error(e.to_string()))
+            })
+            .collect()
+    }
+}
+
+impl Drop for Spill {
+    fn drop(&mut self) {
+        let _ = fs::remove_file(&self.path);
+    }
+}
+
+/// Analyze batches in waves, switching to streaming while the memory is above the limit
+fn process_batches_with_memory_guard(
+    files: &[String],
+    mut batches: Vec<&[String]>,
+    rules_registry_arc: &Arc<RulesRegistry>,
+    cache: Option<&RuleCache>,
+    reader: &FileReader,
+    mut batch_options: BatchOptions,
+    limit: u64,
+    debug_level: DebugLevel,
+) -> Vec<FileAnalysisResult> {
+    if resident_bytes().is_none() {
+        log(
+            DebugLevel::Info,
+            debug_level,
+            "Memory usage cannot be measured on this platform, ignoring memory_limit_mb",
+        );
+        return process_batches(
+            &batches,
+            rules_registry_arc,
+            cache,
+            reader,
+            false,
+            debug_level,
+        );
+    }
+
+    let wave_size = rayon::current_num_threads().max(1);
+    let mut results = Vec::with_capacity(files.len());
+    let mut streaming = false;
+    let mut spill: Option<Spill> = None;
+    let mut done_files = 0;
+    let mut next_batch = 0;
+    while next_batch < batches.len() {
+        let wave = &batches[next_batch..(next_batch + wave_size).min(batches.len())];
+        next_batch += wave.len();
+        done_files += wave.iter().map(|batch| batch.len()).sum::<usize>();
+        results.extend(process_batches(
+            wave,
+            rules_registry_arc,
+            cache,
+            reader,
+            streaming,
+            debug_level,
+        ));
+
+        // Finished results wait on disk instead of in memory for the remaining waves
+        if let Some(active) = &mut spill {
+            match active.write(&results) {
+                Ok(()) => results.clear(),
+                Err(e) => {
+                    log(
+                        DebugLevel::Warn,
+                        debug_level,
+                        &format!("{}, keeping the results in memory", e),
+                    );
+                    results = take_spilled(spill.take(), rules_registry_arc, results);
+                }
+            }
+        }
+
+        let resident = resident_bytes().unwrap_or(0);
+        if resident <= limit || done_files == files.len() {
+            continue;
+        }
+
+        if !streaming {
+            streaming = true;
+            spill = match Spill::create() {
+                Ok(mut active) => match active.write(&results) {
+                    Ok(()) => {
+                        results.clear();
+                        Some(active)
+                    }
+                    Err(e) => {
+                        log(DebugLevel::Warn, debug_level, &e);
+                        None
+                    }
+                },
+                Err(e) => {
+                    log(DebugLevel::Warn, debug_level, &e);
+                    None
+                }
+            };
+        } else if batch_options.is_minimal() {
+            continue;
+        }
+
+        // Fewer files in flight keep the memory of the remaining waves down
+        batch_options = batch_options.shrink();
+        batches = split_batches(&files[done_files..], &batch_options);
+        next_batch = 0;
+        log(
+            DebugLevel::Warn,
+            debug_level,
+            &format!(
+                "Memory usage of {} MiB is above the limit of {} MiB, analyzing the remaining {} files in batches of at most {} files{}",
+                resident / 1024 / 1024,
+                limit / 1024 / 1024,
+                files.len() - done_files,
+                batch_options.max_files,
+                if spill.is_some() {
+                    " and spilling the results to disk"
+                } else {
+                    ""
+                }
+            ),
+        );
+    }
+    take_spilled(spill, rules_registry_arc, results)
+}
+
+/// Get the spilled results followed by the results still in memory
+fn take_spilled(
+    spill: Option<Spill>,
+    rules_registry_arc: &Arc<RulesRegistry>,
+    results: Vec<FileAnalysisResult>,
+) -> Vec<FileAnalysisResult> {
+    let Some(spill) = spill else {
+        return results;
+    };
+    let mut spilled = spill
+        .read(rules_registry_arc)
+        .expect("Failed to read back spilled results");
+    spilled.extend(results);
+    spilled
+}
+
+/// Merge two sets of analysis results, e.g. of two shards or of a cached and a fresh run
+///
+/// Files only present in one set are kept as they are. For files present in both, `b`
+/// is the newer result: the diagnostics of every rule that ran in `b` replace those of `a`,
+/// diagnostics of rules that only ran in `a` are kept, and duplicates are removed. Results
+/// keep the order of `a`, followed by the files only found in `b`.
+pub fn merge_results(
+    a: Vec<FileAnalysisResult>,
+    b: Vec<FileAnalysisResult>,
+) -> Vec<FileAnalysisResult> {
+    let mut newer: HashMap<String, FileAnalysisResult> = HashMap::with_capacity(b.len());
+    let mut new_files = Vec::new();
+    for result in b {
+        if !newer.contains_key(&result.file_path) {
+            new_files.push(result.file_path.clone());
+        }
+        newer.insert(result.file_path.clone(), result);
+    }
+
+    let mut merged = Vec::with_capacity(a.len() + newer.len());
+    for older in a {
+        match newer.remove(&older.file_path) {
+            Some(newer) => merged.push(merge_file_result(older, newer)),
+            None => merged.push(older),
+        }
+    }
+    merged.extend(new_files.into_iter().filter_map(|path| newer.remove(&path)));
+    merged
+}
+
+/// Merge the results of the same file, `newer` takes precedence
+fn merge_file_result(
+    older: FileAnalysisResult,
+    mut newer: FileAnalysisResult,
+) -> FileAnalysisResult {
+    // Rules that ran in the newer result, or whose results it took from the cache
+    let newer_rules: HashSet<String> = newer
+        .rule_durations
+        .keys()
+        .cloned()
+        .chain(newer.diagnostics.iter().map(|d| d.rule_id.clone()))
+        .collect();
+
+    let mut seen: HashSet<(String, usize, usize, String)> =
+        newer.diagnostics.iter().map(diagnostic_key).collect();
+    for diagnostic in older.diagnostics {
+        if !newer_rules.contains(&diagnostic.rule_id) && seen.insert(diagnostic_key(&diagnostic)) {
+            newer.diagnostics.push(diagnostic);
+        }
+    }
+
+    for (rule_name, duration) in older.rule_durations {
+        newer.rule_durations.entry(rule_name).or_insert(duration);
+    }
+    if newer.angular_symbols.is_empty() {
+        newer.angular_symbols = older.angular_symbols;
+    }
+    newer
+}
+
+fn diagnostic_key(diagnostic: &RuleDiagnostic) -> (String, usize, usize, String) {
+    (
+        diagnostic.rule_id.clone(),
+        diagnostic.line_number,
+        diagnostic.column_number,
+        diagnostic.diagnostic.message.to_string(),
+    )
+}
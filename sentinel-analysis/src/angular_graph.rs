@@ -0,0 +1,460 @@
+//! Angular component hierarchy and dependency injection graph
+//!
+//! While a file is analyzed, `extract_angular_symbols` records every decorated Angular
+//! class with its selector, the elements and attributes used in its template and the
+//! tokens it injects. After the analysis, `build_angular_graph` links the symbols of all
+//! files: template usages are matched against selectors to build the component tree, and
+//! constructor parameters and `inject()` calls form the injection graph. The graph is
+//! written to `angular-graph.json` next to `findings.json`.
+
+use crate::FileAnalysisResult;
+use crate::rules::class_context::{
+    array_identifiers, decorator_name, decorator_property, property_key_name, type_reference_name,
+};
+use crate::schema::SCHEMA_VERSION;
+use crate::utilities::{DebugLevel, log};
+use oxc_ast::ast::{
+    Argument, CallExpression, Class, Decorator, Expression, MethodDefinition, Program,
+};
+use oxc_ast_visit::{Visit, walk};
+use oxc_span::Span;
+use regex::Regex;
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeSet, HashMap};
+use std::path::Path;
+use std::sync::LazyLock;
+
+/// Number of entries listed in each hotspot ranking
+const HOTSPOT_LIMIT: usize = 10;
+
+/// Opening tags in a template, with their attribute section
+static TEMPLATE_TAG: LazyLock<Regex> =
+    LazyLock::new(|| Regex::new(r"<([A-Za-z][\w-]*)([^>]*)>").expect("Invalid tag pattern"));
+
+/// Attribute names in an opening tag, including `[input]`, `(output)` and `*directive`
+static TEMPLATE_ATTRIBUTE: LazyLock<Regex> = LazyLock::new(|| {
+    Regex::new(r"(?:^|\s)[\[(*]*([A-Za-z][\w-]*)").expect("Invalid attribute pattern")
+});
+
+/// A token injected into an Angular class
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct Injection {
+    pub token: String,
+    /// Either `constructor` or `inject`
+    pub via: String,
+}
+
+/// A decorated Angular class
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct AngularSymbol {
+    pub name: String,
+    /// One of `component`, `directive`, `pipe`, `injectable` or `ng-module`
+    pub kind: String,
+    pub file: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub selector: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub provided_in: Option<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub injections: Vec<Injection>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub providers: Vec<String>,
+    /// Classes listed in the `imports` of a standalone component or NgModule
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub imports: Vec<String>,
+    /// Element names and `[attribute]` names used in the template
+    #[serde(skip)]
+    pub template_usages: BTreeSet<String>,
+    /// Span of the class in its file
+    #[serde(skip)]
+    pub span: Span,
+}
+
+/// A component or directive used in the template of a component
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct ComponentEdge {
+    pub parent: String,
+    pub child: String,
+    /// The selector through which the child is used
+    pub selector: String,
+}
+
+/// A token injected into a class
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct InjectionEdge {
+    pub consumer: String,
+    pub token: String,
+    pub via: String,
+}
+
+/// A class with the number of its incoming or outgoing edges
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct Hotspot {
+    pub name: String,
+    pub count: usize,
+}
+
+/// Rankings that point at structural problems
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+pub struct AngularGraphSummary {
+    pub component_count: usize,
+    pub service_count: usize,
+    /// Tokens injected into the most classes
+    pub most_injected: Vec<Hotspot>,
+    /// Classes with the most injected dependencies, a hint at god components
+    pub most_dependencies: Vec<Hotspot>,
+    /// Components with the most child components in their template
+    pub most_children: Vec<Hotspot>,
+}
+
+/// Component tree and injection graph of the whole project
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct AngularGraph {
+    /// Schema version of the reports, see `schema`
+    #[serde(default)]
+    pub schema_version: u32,
+    pub symbols: Vec<AngularSymbol>,
+    pub component_edges: Vec<ComponentEdge>,
+    pub injection_edges: Vec<InjectionEdge>,
+    pub summary: AngularGraphSummary,
+}
+
+/// Get the kind of an Angular class from its decorator name
+fn symbol_kind(decorator: &str) -> Option<&'static str> {
+    match decorator {
+        "Component" => Some("component"),
+        "Directive" => Some("directive"),
+        "Pipe" => Some("pipe"),
+        "Injectable" => Some("injectable"),
+        "NgModule" => Some("ng-module"),
+        _ => None,
+    }
+}
+
+/// Get the value of a string or template literal without substitutions
+pub fn static_string(expr: &Expression) -> Option<String> {
+    match expr {
+        Expression::StringLiteral(literal) => Some(literal.value.to_string()),
+        Expression::TemplateLiteral(template) if template.expressions.is_empty() => template
+            .quasis
+            .first()
+            .map(|quasi| quasi.value.raw.to_string()),
+        _ => None,
+    }
+}
+
+/// Collect the element names and `[attribute]` names used in a template
+pub fn template_usages(template: &str) -> BTreeSet<String> {
+    let mut usages = BTreeSet::new();
+
+    for tag in TEMPLATE_TAG.captures_iter(template) {
+        usages.insert(tag[1].to_lowercase());
+        for attribute in TEMPLATE_ATTRIBUTE.captures_iter(&tag[2]) {
+            usages.insert(format!("[{}]", &attribute[1]));
+        }
+    }
+
+    usages
+}
+
+/// Split a selector list like `app-card, [appCard]` into the element or attribute names
+/// it matches
+fn selector_keys(selector: &str) -> Vec<String> {
+    selector
+        .split(',')
+        .filter_map(|part| {
+            let part = part.trim();
+            if let Some(attribute) = part.strip_prefix('[') {
+                let name = attribute.split([']', '=']).next()?;
+                return Some(format!("[{}]", name));
+            }
+            let element = part.split(['[', '.', ':']).next()?;
+            (!element.is_empty()).then(|| element.to_lowercase())
+        })
+        .collect()
+}
+
+/// Collect the Angular classes of a file
+pub fn extract_angular_symbols(program: &Program, file_path: &str) -> Vec<AngularSymbol> {
+    let mut collector = SymbolCollector {
+        file_path,
+        symbols: Vec::new(),
+    };
+    collector.visit_program(program);
+    collector.symbols
+}
+
+struct SymbolCollector<'f> {
+    file_path: &'f str,
+    symbols: Vec<AngularSymbol>,
+}
+
+impl<'f> SymbolCollector<'f> {
+    fn symbol_from_class(&self, class: &Class, decorator: &Decorator, kind: &str) -> AngularSymbol {
+        let property_string = |name: &str| {
+            decorator_property(decorator, name).and_then(|prop| static_string(&prop.value))
+        };
+        let property_identifiers = |name: &str| {
+            decorator_property(decorator, name)
+                .map(|prop| {
+                    array_identifiers(&prop.value)
+                        .into_iter()
+                        .map(|(name, _)| name.to_string())
+                        .collect()
+                })
+                .unwrap_or_default()
+        };
+
+        let template = property_string("template")
+            .or_else(|| property_string("templateUrl").and_then(|url| self.read_template(&url)));
+
+        let mut injections = InjectionCollector::default();
+        injections.visit_class_body(&class.body);
+
+        AngularSymbol {
+            name: class
+                .id
+                .as_ref()
+                .map_or_else(|| "anonymous".to_string(), |id| id.name.to_string()),
+            kind: kind.to_string(),
+            file: self.file_path.to_string(),
+            selector: property_string("selector"),
+            provided_in: property_string("providedIn"),
+            injections: injections.injections,
+            providers: property_identifiers("providers"),
+            imports: property_identifiers("imports"),
+            template_usages: template.as_deref().map(template_usages).unwrap_or_default(),
+            span: class.span,
+        }
+    }
+
+    /// Read an external template relative to the component file
+    fn read_template(&self, template_url: &str) -> Option<String> {
+        let dir = Path::new(self.file_path).parent()?;
+        std::fs::read_to_string(dir.join(template_url)).ok()
+    }
+}
+
+impl<'a, 'f> Visit<'a> for SymbolCollector<'f> {
+    fn visit_class(&mut self, class: &Class<'a>) {
+        let symbol = class.decorators.iter().find_map(|decorator| {
+            let kind = symbol_kind(&decorator_name(decorator)?)?;
+            Some(self.symbol_from_class(class, decorator, kind))
+        });
+        self.symbols.extend(symbol);
+
+        walk::walk_class(self, class);
+    }
+}
+
+/// Collects the tokens injected through the constructor and `inject()` calls
+#[derive(Default)]
+struct InjectionCollector {
+    injections: Vec<Injection>,
+}
+
+impl InjectionCollector {
+    fn push(&mut self, token: String, via: &str) {
+        if !self.injections.iter().any(|i| i.token == token) {
+            self.injections.push(Injection {
+                token,
+                via: via.to_string(),
+            });
+        }
+    }
+}
+
+impl<'a> Visit<'a> for InjectionCollector {
+    fn visit_method_definition(&mut self, method: &MethodDefinition<'a>) {
+        if property_key_name(&method.key) == "constructor" {
+            for param in &method.value.params.items {
+                // `@Inject(TOKEN)` takes precedence over the declared type
+                let token = param
+                    .decorators
+                    .iter()
+                    .find(|decorator| decorator_name(decorator).as_deref() == Some("Inject"))
+                    .and_then(|decorator| match &decorator.expression {
+                        Expression::CallExpression(call) => first_identifier_argument(call),
+                        _ => None,
+                    })
+                    .or_else(|| {
+                        param
+                            .pattern
+                            .type_annotation
+                            .as_ref()
+                            .and_then(|t| type_reference_name(&t.type_annotation))
+                    });
+
+                if let Some(token) = token {
+                    self.push(token, "constructor");
+                }
+            }
+        }
+
+        walk::walk_method_definition(self, method);
+    }
+
+    fn visit_call_expression(&mut self, call: &CallExpression<'a>) {
+        if matches!(&call.callee, Expression::Identifier(ident) if ident.name == "inject") {
+            if let Some(token) = first_identifier_argument(call) {
+                self.push(token, "inject");
+            }
+        }
+
+        walk::walk_call_expression(self, call);
+    }
+}
+
+fn first_identifier_argument(call: &CallExpression) -> Option<String> {
+    match call.arguments.first()? {
+        Argument::Identifier(ident) => Some(ident.name.to_string()),
+        _ => None,
+    }
+}
+
+/// Rank names by count, highest first
+fn hotspots(counts: HashMap<&str, usize>) -> Vec<Hotspot> {
+    let mut hotspots: Vec<Hotspot> = counts
+        .into_iter()
+        .filter(|(_, count)| *count > 0)
+        .map(|(name, count)| Hotspot {
+            name: name.to_string(),
+            count,
+        })
+        .collect();
+    hotspots.sort_by(|a, b| b.count.cmp(&a.count).then_with(|| a.name.cmp(&b.name)));
+    hotspots.truncate(HOTSPOT_LIMIT);
+    hotspots
+}
+
+/// Link the Angular symbols of all files into a graph
+///
+/// Returns `None` if the project contains no Angular classes.
+pub fn build_angular_graph(results: &[FileAnalysisResult]) -> Option<AngularGraph> {
+    let mut symbols: Vec<AngularSymbol> = results
+        .iter()
+        .flat_map(|result| result.angular_symbols.iter().cloned())
+        .collect();
+
+    if symbols.is_empty() {
+        return None;
+    }
+    symbols.sort_by(|a, b| a.file.cmp(&b.file).then_with(|| a.name.cmp(&b.name)));
+
+    // Selector keys of all components and directives
+    let mut selectors: HashMap<String, &AngularSymbol> = HashMap::new();
+    for symbol in &symbols {
+        if let Some(selector) = &symbol.selector {
+            for key in selector_keys(selector) {
+                selectors.entry(key).or_insert(symbol);
+            }
+        }
+    }
+
+    let mut component_edges = Vec::new();
+    let mut injection_edges = Vec::new();
+    for symbol in &symbols {
+        for usage in &symbol.template_usages {
+            if let Some(child) = selectors.get(usage) {
+                if child.name != symbol.name {
+                    component_edges.push(ComponentEdge {
+                        parent: symbol.name.clone(),
+                        child: child.name.clone(),
+                        selector: usage.clone(),
+                    });
+                }
+            }
+        }
+
+        for injection in &symbol.injections {
+            injection_edges.push(InjectionEdge {
+                consumer: symbol.name.clone(),
+                token: injection.token.clone(),
+                via: injection.via.clone(),
+            });
+        }
+    }
+
+    let mut injected: HashMap<&str, usize> = HashMap::new();
+    for edge in &injection_edges {
+        *injected.entry(edge.token.as_str()).or_insert(0) += 1;
+    }
+    let mut children: HashMap<&str, usize> = HashMap::new();
+    for edge in &component_edges {
+        *children.entry(edge.parent.as_str()).or_insert(0) += 1;
+    }
+
+    let summary = AngularGraphSummary {
+        component_count: symbols.iter().filter(|s| s.kind == "component").count(),
+        service_count: symbols.iter().filter(|s| s.kind == "injectable").count(),
+        most_injected: hotspots(injected),
+        most_dependencies: hotspots(
+            symbols
+                .iter()
+                .map(|s| (s.name.as_str(), s.injections.len()))
+                .collect(),
+        ),
+        most_children: hotspots(children),
+    };
+
+    Some(AngularGraph {
+        schema_version: SCHEMA_VERSION,
+        symbols,
+        component_edges,
+        injection_edges,
+        summary,
+    })
+}
+
+/// Export the Angular graph to angular-graph.json
+pub fn export_angular_graph(
+    results: &[FileAnalysisResult],
+    debug_level: DebugLevel,
+    output_dir: &String,
+) {
+    let Some(graph) = build_angular_graph(results) else {
+        log(DebugLevel::Info, debug_level, "No Angular classes found");
+        return;
+    };
+
+    if let Err(e) = std::fs::create_dir_all(output_dir) {
+        log(
+            DebugLevel::Error,
+            debug_level,
+            &format!("Failed to create output directory {}: {}", output_dir, e),
+        );
+        return;
+    }
+
+    let file_path = format!("{}/angular-graph.json", output_dir);
+    let json = match serde_json::to_string_pretty(&graph) {
+        Ok(json) => json,
+        Err(e) => {
+            log(
+                DebugLevel::Error,
+                debug_level,
+                &format!("Failed to serialize Angular graph: {}", e),
+            );
+            return;
+        }
+    };
+
+    match std::fs::write(&file_path, json) {
+        Ok(_) => log(
+            DebugLevel::Info,
+            debug_level,
+            &format!(
+                "Exported Angular graph with {} classes, {} component edges and {} injection edges to {}",
+                graph.symbols.len(),
+                graph.component_edges.len(),
+                graph.injection_edges.len(),
+                file_path
+            ),
+        ),
+        Err(e) => log(
+            DebugLevel::Error,
+            debug_level,
+            &format!("Failed to write {}: {}", file_path, e),
+        ),
+    }
+}
@@ -0,0 +1,252 @@
+//! Snapshots of syntax trees for offline rule development
+//!
+//! `scoper ast dump <file>` captures a file as a JSON snapshot: its source text, the
+//! parser version and the flattened syntax tree. `scoper ast run-rule --ast=dump.json
+//! --rule=<id>` runs a single rule on a snapshot, without the project it was taken from,
+//! so rule authors can iterate on a captured fixture and bug reports can carry a
+//! reproducible case. `scoper ast inspect <file> --line=42 --col=7` shows the path of
+//! nodes down to a position and the innermost node there, without dumping the whole tree.
+//!
+//! Rules work on the semantic model of the parser, which lives in an arena and cannot be
+//! read back from JSON, so a snapshot is replayed by parsing its source text again. The
+//! tree of the snapshot is the reference: if this build parses the source into another
+//! tree, e.g. after a parser upgrade, the replay fails unless forced, since the rule would
+//! not see what the reporter saw.
+
+use crate::RuleDiagnostic;
+use crate::analyzer::read_source;
+use crate::inspect::{AstNodeInfo, flatten_tree};
+use crate::rules_registry::{RulesRegistry, configure_registry, create_default_registry};
+use crate::schema::BuildInfo;
+use crate::utilities::source::{ColumnUnit, diagnostic_span, offset_of_position};
+use oxc_allocator::Allocator;
+use oxc_parser::Parser;
+use oxc_semantic::SemanticBuilder;
+use oxc_span::SourceType;
+use serde::{Deserialize, Serialize};
+use serde_json::Value;
+use std::collections::HashMap;
+use std::fs;
+use std::path::Path;
+use std::sync::Arc;
+
+/// Version of the snapshot format, bumped on incompatible changes
+pub const AST_SNAPSHOT_VERSION: u32 = 1;
+
+/// A file with its syntax tree
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct AstSnapshot {
+    pub snapshot_version: u32,
+    /// Version of the parser that produced the tree
+    pub parser_version: String,
+    /// Path of the file, its extension selects the language
+    pub file_path: String,
+    pub source: String,
+    /// Syntax errors the parser recovered from
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub parse_errors: Vec<String>,
+    /// Nodes of the syntax tree in source order, parents before their children
+    pub nodes: Vec<AstNodeInfo>,
+}
+
+/// A finding of a rule on a snapshot
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct SnapshotFinding {
+    pub rule: String,
+    pub line: usize,
+    pub column: usize,
+    pub message: String,
+    /// Node kinds from the program down to the node the finding points at
+    pub nodes: Vec<String>,
+}
+
+/// The innermost node at a position of a file, with its ancestors
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct NodeInspection {
+    /// Node kinds from the program down to the node at the position
+    pub path: Vec<String>,
+    pub node: AstNodeInfo,
+    /// Source text of the node
+    pub text: String,
+    /// Direct children of the node
+    pub children: Vec<AstNodeInfo>,
+}
+
+/// Get the node kinds from the program down to a node of a snapshot
+fn node_path(snapshot: &AstSnapshot, innermost: Option<&AstNodeInfo>) -> Vec<String> {
+    let mut path = Vec::new();
+    let mut current = innermost;
+    while let Some(node) = current {
+        path.push(node.kind.clone());
+        current = node.parent.and_then(|parent| snapshot.nodes.get(parent));
+    }
+    path.reverse();
+    path
+}
+
+/// Parse source text into its flattened tree and the recovered syntax errors
+fn parse_tree(file_path: &str, source: &str) -> Result<(Vec<AstNodeInfo>, Vec<String>), String> {
+    let source_type = SourceType::from_path(Path::new(file_path))
+        .map_err(|_| format!("Unsupported file type: {}", file_path))?;
+    let allocator = Allocator::default();
+    let parse_result = Parser::new(&allocator, source, source_type).parse();
+    if parse_result.panicked {
+        return Err(format!("Failed to parse {}", file_path));
+    }
+    let semantic_result = SemanticBuilder::new().build(&parse_result.program);
+    let errors = parse_result
+        .errors
+        .iter()
+        .map(|error| error.message.to_string())
+        .collect();
+    Ok((flatten_tree(&semantic_result.semantic), errors))
+}
+
+/// Capture the syntax tree of a file
+pub fn dump_ast(file_path: &str) -> Result<AstSnapshot, String> {
+    let (source, _) = read_source(file_path, &mut Vec::new(), None)?;
+    let (nodes, parse_errors) = parse_tree(file_path, &source)?;
+    Ok(AstSnapshot {
+        snapshot_version: AST_SNAPSHOT_VERSION,
+        parser_version: BuildInfo::current().parser_version,
+        file_path: file_path.to_string(),
+        source: source.to_string(),
+        parse_errors,
+        nodes,
+    })
+}
+
+/// Read a snapshot written by `scoper ast dump`
+pub fn load_snapshot(path: &str) -> Result<AstSnapshot, String> {
+    let content = fs::read_to_string(path)
+        .map_err(|e| format!("Failed to read AST snapshot {}: {}", path, e))?;
+    let snapshot: AstSnapshot = serde_json::from_str(&content)
+        .map_err(|e| format!("Invalid AST snapshot {}: {}", path, e))?;
+    if snapshot.snapshot_version != AST_SNAPSHOT_VERSION {
+        return Err(format!(
+            "AST snapshot {} has version {}, this build reads version {}",
+            path, snapshot.snapshot_version, AST_SNAPSHOT_VERSION
+        ));
+    }
+    Ok(snapshot)
+}
+
+/// Create a registry with only one rule enabled, configured with its options
+pub fn single_rule_registry(rule: &str, options: Option<Value>) -> Result<RulesRegistry, String> {
+    let mut registry = create_default_registry();
+    if registry.get_rule(rule).is_none() {
+        return Err(format!("Unknown rule: {}", rule));
+    }
+    configure_registry(
+        &mut registry,
+        &[(rule.to_string(), options, "error".to_string())],
+    );
+    Ok(registry)
+}
+
+/// Run the enabled rules of a registry on a snapshot
+///
+/// Fails if the source text parses into another tree than the one of the snapshot, unless
+/// `force` is set.
+pub fn run_on_snapshot(
+    snapshot: &AstSnapshot,
+    registry: &RulesRegistry,
+    force: bool,
+) -> Result<Vec<RuleDiagnostic>, String> {
+    let source_type = SourceType::from_path(Path::new(&snapshot.file_path))
+        .map_err(|_| format!("Unsupported file type: {}", snapshot.file_path))?;
+    let source: Arc<str> = Arc::from(snapshot.source.as_str());
+    let allocator = Allocator::default();
+    let parse_result = Parser::new(&allocator, &source, source_type).parse();
+    if parse_result.panicked {
+        return Err(format!("Failed to parse {}", snapshot.file_path));
+    }
+    let semantic_result = SemanticBuilder::new().build(&parse_result.program);
+
+    if !force {
+        let nodes = flatten_tree(&semantic_result.semantic);
+        if let Some(index) = (0..nodes.len().max(snapshot.nodes.len()))
+            .find(|&index| nodes.get(index) != snapshot.nodes.get(index))
+        {
+            return Err(format!(
+                "The snapshot was taken with parser {} and this build (parser {}) parses it into another tree, from node {} on; use --force to run the rule anyway",
+                snapshot.parser_version,
+                BuildInfo::current().parser_version,
+                index
+            ));
+        }
+    }
+
+    let (diagnostics, _) = registry.run_rules_with_cache(
+        &semantic_result,
+        &snapshot.file_path,
+        &source,
+        HashMap::new(),
+    );
+    Ok(diagnostics)
+}
+
+/// Describe findings on a snapshot with the path of nodes they point at
+pub fn snapshot_findings(
+    snapshot: &AstSnapshot,
+    diagnostics: &[RuleDiagnostic],
+) -> Vec<SnapshotFinding> {
+    diagnostics
+        .iter()
+        .map(|diagnostic| {
+            // Children follow their parents, so the last containing node is the innermost one
+            let innermost = diagnostic_span(&diagnostic.diagnostic).and_then(|span| {
+                snapshot
+                    .nodes
+                    .iter()
+                    .filter(|node| node.start <= span.start && span.end <= node.end)
+                    .last()
+            });
+
+            SnapshotFinding {
+                rule: diagnostic.rule_id.clone(),
+                line: diagnostic.line_number,
+                column: diagnostic.column_number,
+                message: diagnostic.diagnostic.message.to_string(),
+                nodes: node_path(snapshot, innermost),
+            }
+        })
+        .collect()
+}
+
+/// Find the innermost node at a 1-based line and column of a snapshot
+///
+/// The column is counted in `unit`, like the columns of the findings.
+pub fn inspect_position(
+    snapshot: &AstSnapshot,
+    line: usize,
+    column: usize,
+    unit: ColumnUnit,
+) -> Result<NodeInspection, String> {
+    let offset = offset_of_position(&snapshot.source, line, column, unit)
+        .ok_or_else(|| format!("{} has no line {}", snapshot.file_path, line))?
+        as u32;
+    // Children follow their parents, so the last node around the offset is the innermost one
+    let node = snapshot
+        .nodes
+        .iter()
+        .filter(|node| node.start <= offset && offset < node.end)
+        .last()
+        .ok_or_else(|| format!("No node at {}:{}:{}", snapshot.file_path, line, column))?;
+
+    Ok(NodeInspection {
+        path: node_path(snapshot, Some(node)),
+        node: node.clone(),
+        text: snapshot
+            .source
+            .get(node.start as usize..node.end as usize)
+            .unwrap_or_default()
+            .to_string(),
+        children: snapshot
+            .nodes
+            .iter()
+            .filter(|child| child.parent == Some(node.id))
+            .cloned()
+            .collect(),
+    })
+}
@@ -0,0 +1,306 @@
+//! Quality summary per Backstage component
+//!
+//! `--format backstage` writes `backstage.json`, a summary of the findings per component
+//! of the Backstage software catalog, for a plugin card on the entity page. Components are
+//! read from the `catalog-info.yaml` files of the project: the files under the directory of
+//! a catalog file belong to its `Component` entities, and a file in nested component
+//! directories belongs to the innermost one. An entity can point to its sources with the
+//! `sentinel/source-path` annotation, relative to the catalog file:
+//!
+//! ```yaml
+//! apiVersion: backstage.io/v1alpha1
+//! kind: Component
+//! metadata:
+//!   name: checkout
+//!   annotations:
+//!     sentinel/source-path: src/app/checkout
+//! spec:
+//!   owner: team-payments
+//! ```
+//!
+//! Findings outside of every component are counted as `unassigned`.
+
+use crate::exporter::FindingsExport;
+use crate::output::{OutputSpec, OutputWriter};
+use crate::utilities::paths::PathBase;
+use serde::{Deserialize, Serialize};
+use serde_yaml::Value;
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::fs;
+use std::path::Path;
+use walkdir::WalkDir;
+
+/// Name of the catalog files
+pub const CATALOG_FILE: &str = "catalog-info.yaml";
+/// Annotation pointing a component to its sources, relative to the catalog file
+pub const SOURCE_PATH_ANNOTATION: &str = "sentinel/source-path";
+/// Number of rules listed per component
+const TOP_RULES: usize = 5;
+
+/// A `Component` entity of the catalog
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct CatalogComponent {
+    pub name: String,
+    pub namespace: String,
+    pub owner: Option<String>,
+    /// Directory of the sources, relative to the path base, `.` for the root
+    pub path: String,
+}
+
+impl CatalogComponent {
+    /// Get the reference of the entity, e.g. `component:default/checkout`
+    pub fn entity_ref(&self) -> String {
+        format!("component:{}/{}", self.namespace, self.name)
+    }
+
+    /// Check if a file, relative to the path base, belongs to the component directory
+    fn contains(&self, file: &str) -> bool {
+        self.path == "."
+            || file
+                .strip_prefix(self.path.as_str())
+                .is_some_and(|rest| rest.starts_with('/'))
+    }
+}
+
+/// Number of findings of a rule
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct RuleCount {
+    pub rule: String,
+    pub count: usize,
+}
+
+/// Quality summary of one component
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct ComponentQuality {
+    pub entity_ref: String,
+    pub name: String,
+    pub owner: Option<String>,
+    pub path: String,
+    /// `error`, `warning` or `ok`, after the most severe finding
+    pub status: String,
+    pub findings: usize,
+    pub files_with_findings: usize,
+    pub findings_by_severity: BTreeMap<String, usize>,
+    pub findings_by_category: BTreeMap<String, usize>,
+    /// Rules with the most findings, most first
+    pub top_rules: Vec<RuleCount>,
+}
+
+/// The summary written by `--format backstage`
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct BackstageReport {
+    pub analyzer_version: String,
+    pub timestamp: String,
+    pub components: Vec<ComponentQuality>,
+    /// Findings in files outside of every component
+    pub unassigned: usize,
+}
+
+/// Get a string field of a YAML mapping by path
+fn yaml_str<'a>(value: &'a Value, path: &[&str]) -> Option<&'a str> {
+    path.iter()
+        .try_fold(value, |value, key| value.get(*key))?
+        .as_str()
+}
+
+/// Read the `Component` entities of one catalog file
+///
+/// A catalog file can hold several entities as separate YAML documents. `dir` is the
+/// directory of the file, relative to the path base.
+fn parse_catalog(content: &str, dir: &str) -> Result<Vec<CatalogComponent>, String> {
+    let mut components = Vec::new();
+    for document in serde_yaml::Deserializer::from_str(content) {
+        let entity = Value::deserialize(document).map_err(|e| e.to_string())?;
+        if yaml_str(&entity, &["kind"]) != Some("Component") {
+            continue;
+        }
+        let Some(name) = yaml_str(&entity, &["metadata", "name"]) else {
+            continue;
+        };
+
+        let path = match yaml_str(
+            &entity,
+            &["metadata", "annotations", SOURCE_PATH_ANNOTATION],
+        ) {
+            Some(source) => normalize_dir(&format!("{}/{}", dir, source)),
+            None => dir.to_string(),
+        };
+        components.push(CatalogComponent {
+            name: name.to_string(),
+            namespace: yaml_str(&entity, &["metadata", "namespace"])
+                .unwrap_or("default")
+                .to_string(),
+            owner: yaml_str(&entity, &["spec", "owner"]).map(str::to_string),
+            path,
+        });
+    }
+    Ok(components)
+}
+
+/// Normalize a relative directory with forward slashes, `.` for the root
+fn normalize_dir(dir: &str) -> String {
+    let mut parts: Vec<&str> = Vec::new();
+    for part in dir.split(['/', '\\']) {
+        match part {
+            "" | "." => {}
+            ".." => {
+                parts.pop();
+            }
+            part => parts.push(part),
+        }
+    }
+    match parts.is_empty() {
+        true => ".".to_string(),
+        false => parts.join("/"),
+    }
+}
+
+/// Find the components of the catalog files under the path base
+///
+/// Dependencies and build output are skipped, and so are catalog files that cannot be
+/// parsed, which Backstage would reject as well.
+pub fn find_catalog_components(path_base: &PathBase) -> Vec<CatalogComponent> {
+    let root = path_base.resolve(".");
+    let mut components: Vec<CatalogComponent> = WalkDir::new(&root)
+        .into_iter()
+        .filter_entry(|entry| {
+            !matches!(
+                entry.file_name().to_str(),
+                Some("node_modules" | ".git" | "dist")
+            )
+        })
+        .filter_map(Result::ok)
+        .filter(|entry| entry.file_type().is_file() && entry.file_name() == CATALOG_FILE)
+        .filter_map(|entry| {
+            let content = fs::read_to_string(entry.path()).ok()?;
+            let dir = entry
+                .path()
+                .parent()
+                .and_then(|dir| dir.strip_prefix(&root).ok())
+                .unwrap_or(Path::new(""));
+            parse_catalog(&content, &normalize_dir(&dir.to_string_lossy())).ok()
+        })
+        .flatten()
+        .collect();
+    components.sort_by(|a, b| a.entity_ref().cmp(&b.entity_ref()));
+    components
+}
+
+/// Summarize the findings of an export per component
+pub fn build_backstage_report(
+    export: &FindingsExport,
+    components: &[CatalogComponent],
+) -> BackstageReport {
+    let mut summaries: Vec<ComponentQuality> = components
+        .iter()
+        .map(|component| ComponentQuality {
+            entity_ref: component.entity_ref(),
+            name: component.name.clone(),
+            owner: component.owner.clone(),
+            path: component.path.clone(),
+            status: "ok".to_string(),
+            findings: 0,
+            files_with_findings: 0,
+            findings_by_severity: BTreeMap::new(),
+            findings_by_category: BTreeMap::new(),
+            top_rules: Vec::new(),
+        })
+        .collect();
+    let mut rules: Vec<HashMap<&str, usize>> = vec![HashMap::new(); components.len()];
+    let mut files: Vec<HashSet<&str>> = vec![HashSet::new(); components.len()];
+    let mut unassigned = 0;
+
+    for finding in &export.findings {
+        // The innermost component directory wins
+        let owner = components
+            .iter()
+            .enumerate()
+            .filter(|(_, component)| component.contains(&finding.file))
+            .max_by_key(|(_, component)| match component.path.as_str() {
+                "." => 0,
+                path => path.len(),
+            })
+            .map(|(index, _)| index);
+        let Some(index) = owner else {
+            unassigned += 1;
+            continue;
+        };
+
+        let summary = &mut summaries[index];
+        summary.findings += 1;
+        *summary
+            .findings_by_severity
+            .entry(finding.severity.clone())
+            .or_insert(0) += 1;
+        *summary
+            .findings_by_category
+            .entry(finding.category.clone())
+            .or_insert(0) += 1;
+        *rules[index].entry(&finding.rule).or_insert(0) += 1;
+        files[index].insert(&finding.file);
+    }
+
+    for (index, summary) in summaries.iter_mut().enumerate() {
+        summary.files_with_findings = files[index].len();
+        summary.status = if summary.findings_by_severity.contains_key("error") {
+            "error"
+        } else if summary.findings_by_severity.contains_key("warning") {
+            "warning"
+        } else {
+            "ok"
+        }
+        .to_string();
+
+        let mut top: Vec<RuleCount> = rules[index]
+            .iter()
+            .map(|(rule, count)| RuleCount {
+                rule: rule.to_string(),
+                count: *count,
+            })
+            .collect();
+        top.sort_by(|a, b| b.count.cmp(&a.count).then_with(|| a.rule.cmp(&b.rule)));
+        top.truncate(TOP_RULES);
+        summary.top_rules = top;
+    }
+
+    BackstageReport {
+        analyzer_version: export.schema.analyzer_version.clone(),
+        timestamp: export.summary.timestamp.clone(),
+        components: summaries,
+        unassigned,
+    }
+}
+
+/// Writes the quality summary per catalog component
+pub struct BackstageWriter {
+    path_base: PathBase,
+}
+
+impl BackstageWriter {
+    /// Create a writer reading the catalog files under the path base
+    pub fn new(path_base: PathBase) -> Self {
+        Self { path_base }
+    }
+}
+
+impl OutputWriter for BackstageWriter {
+    fn format(&self) -> &'static str {
+        "backstage"
+    }
+
+    fn default_path(&self, _spec: &OutputSpec, output_dir: &str) -> Result<String, String> {
+        Ok(format!("{}/backstage.json", output_dir))
+    }
+
+    fn render(&self, export: &FindingsExport, _spec: &OutputSpec) -> Result<String, String> {
+        let components = find_catalog_components(&self.path_base);
+        if components.is_empty() {
+            return Err(format!(
+                "No Backstage components found, expected {} files with kind: Component",
+                CATALOG_FILE
+            ));
+        }
+        serde_json::to_string_pretty(&build_backstage_report(export, &components))
+            .map_err(|e| format!("Failed to serialize Backstage report: {}", e))
+    }
+}
@@ -0,0 +1,207 @@
+//! Authorship of findings from git blame
+//!
+//! With `--blame` (or `"blame": true` in `sentinel.json`) every finding records who last
+//! changed its line, in which commit and when, so findings can be routed to the people who
+//! touched the code. `git blame` runs once per file with findings.
+//!
+//! The authorship also enables "new code only" policies without a baseline:
+//!
+//! - `--only-mine` keeps the findings on lines last changed by the git user of the
+//!   analyzed repository (`git config user.email`)
+//! - `--since 2024-01-01` keeps the findings on lines changed on or after a date
+//!
+//! Both imply `--blame`. Lines that are not committed yet count as new code of the current
+//! user, and findings without authorship, e.g. in files outside of a repository, are kept,
+//! so a policy never hides a finding it cannot judge.
+
+use crate::FileAnalysisResult;
+use crate::hotspots::git;
+use crate::rules_registry::PROJECT_FILE;
+use crate::utilities::paths::PathBase;
+use crate::utilities::{DebugLevel, log};
+use chrono::NaiveDate;
+use rayon::prelude::*;
+use serde::{Deserialize, Serialize};
+use std::collections::HashMap;
+use std::path::Path;
+
+/// Who last changed the line of a finding
+#[derive(Serialize, Deserialize, Debug, Clone, Default, PartialEq)]
+pub struct Blame {
+    pub author: String,
+    pub email: String,
+    /// Hash of the commit, all zeros for changes that are not committed yet
+    pub commit: String,
+    /// Date of the change, `YYYY-MM-DD`
+    pub date: String,
+}
+
+impl Blame {
+    /// Check if the line has changes that are not committed yet
+    pub fn is_uncommitted(&self) -> bool {
+        self.commit.bytes().all(|byte| byte == b'0')
+    }
+}
+
+/// "New code only" policy on the authorship of findings, none by default
+#[derive(Debug, Clone, Default)]
+pub struct BlamePolicy {
+    /// Only keep the findings on lines last changed by this email
+    pub author_email: Option<String>,
+    /// Only keep the findings on lines changed on or after this date
+    pub since: Option<NaiveDate>,
+}
+
+impl BlamePolicy {
+    /// Check if any policy is set
+    pub fn is_empty(&self) -> bool {
+        self.author_email.is_none() && self.since.is_none()
+    }
+
+    /// Check if a finding with this authorship is kept
+    pub fn keeps(&self, blame: Option<&Blame>) -> bool {
+        let Some(blame) = blame else {
+            return true;
+        };
+        if blame.is_uncommitted() {
+            return true;
+        }
+        let by_author = self
+            .author_email
+            .as_ref()
+            .is_none_or(|email| blame.email.eq_ignore_ascii_case(email));
+        let since = self.since.is_none_or(|since| {
+            NaiveDate::parse_from_str(&blame.date, "%Y-%m-%d").is_ok_and(|date| date >= since)
+        });
+        by_author && since
+    }
+}
+
+/// Get the email of the git user of the repository containing a directory
+pub fn current_user_email(dir: &Path) -> Result<String, String> {
+    let email = git(dir, &["config", "user.email"])?.trim().to_string();
+    if email.is_empty() {
+        return Err("git user.email is not set, required by --only-mine".to_string());
+    }
+    Ok(email)
+}
+
+/// Parse the output of `git blame --porcelain` into the authorship of each line
+///
+/// The porcelain format lists the author of a commit only on its first line, so the
+/// commits are collected while the lines refer to them by hash.
+fn parse_porcelain(output: &str) -> HashMap<usize, Blame> {
+    let mut commits: HashMap<&str, Blame> = HashMap::new();
+    let mut lines = HashMap::new();
+    // Commit and final line number of the line being read
+    let mut current: Option<(&str, usize)> = None;
+
+    for line in output.lines() {
+        if line.starts_with('\t') {
+            if let Some((commit, number)) = current.take() {
+                if let Some(blame) = commits.get(commit) {
+                    lines.insert(number, blame.clone());
+                }
+            }
+            continue;
+        }
+
+        let mut parts = line.split(' ');
+        let first = parts.next().unwrap_or_default();
+        let is_hash =
+            matches!(first.len(), 40 | 64) && first.bytes().all(|byte| byte.is_ascii_hexdigit());
+        if is_hash {
+            let number = parts.nth(1).and_then(|n| n.parse().ok()).unwrap_or(0);
+            commits.entry(first).or_insert_with(|| Blame {
+                commit: first.to_string(),
+                ..Blame::default()
+            });
+            current = Some((first, number));
+            continue;
+        }
+
+        let Some(blame) = current.and_then(|(commit, _)| commits.get_mut(commit)) else {
+            continue;
+        };
+        if let Some(author) = line.strip_prefix("author ") {
+            blame.author = author.to_string();
+        } else if let Some(mail) = line.strip_prefix("author-mail ") {
+            blame.email = mail.trim_matches(['<', '>']).to_string();
+        } else if let Some(time) = line.strip_prefix("author-time ") {
+            blame.date = time
+                .trim()
+                .parse::<i64>()
+                .ok()
+                .and_then(|timestamp| chrono::DateTime::from_timestamp(timestamp, 0))
+                .map(|date| date.date_naive().to_string())
+                .unwrap_or_default();
+        }
+    }
+
+    lines
+}
+
+/// Get the authorship of every line of a file
+fn blame_file(path: &Path) -> Result<HashMap<usize, Blame>, String> {
+    let dir = path
+        .parent()
+        .ok_or_else(|| format!("{} has no directory", path.display()))?;
+    let name = path
+        .file_name()
+        .ok_or_else(|| format!("{} is not a file", path.display()))?
+        .to_string_lossy()
+        .into_owned();
+    let output = git(dir, &["blame", "--porcelain", "--", name.as_str()])?;
+    Ok(parse_porcelain(&output))
+}
+
+/// Attach the authorship of their lines to the findings
+///
+/// The file paths of the results are resolved against the path base. Files that cannot be
+/// blamed, e.g. untracked ones, keep their findings without authorship.
+pub fn annotate_blame(
+    results: &mut [FileAnalysisResult],
+    path_base: &PathBase,
+    debug_level: DebugLevel,
+) {
+    results
+        .par_iter_mut()
+        .filter(|result| !result.diagnostics.is_empty() && result.file_path != PROJECT_FILE)
+        .for_each(|result| {
+            let lines = match blame_file(&path_base.resolve(&result.file_path)) {
+                Ok(lines) => lines,
+                Err(err) => {
+                    log(
+                        DebugLevel::Debug,
+                        debug_level,
+                        &format!("No blame for {}: {}", result.file_path, err),
+                    );
+                    return;
+                }
+            };
+            for diagnostic in &mut result.diagnostics {
+                diagnostic.blame = lines.get(&diagnostic.line_number).cloned();
+            }
+        });
+}
+
+/// Drop the findings the policy does not keep
+///
+/// Returns the number of dropped findings.
+pub fn apply_blame_policy(results: &mut [FileAnalysisResult], policy: &BlamePolicy) -> usize {
+    if policy.is_empty() {
+        return 0;
+    }
+
+    let mut dropped = 0;
+    for result in results.iter_mut() {
+        result.diagnostics.retain(|diagnostic| {
+            let kept = policy.keeps(diagnostic.blame.as_ref());
+            if !kept {
+                dropped += 1;
+            }
+            kept
+        });
+    }
+    dropped
+}
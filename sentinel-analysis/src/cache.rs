@@ -0,0 +1,635 @@
+//! Per-rule cache of analysis results
+//!
+//! Results are cached per file and rule, keyed by the content hash of the file and the
+//! fingerprint of the rule (rule version and configuration). On the next run a
+//! rule only runs on a file if the file changed, the rule changed or the rule is new, so
+//! enabling an additional rule does not return stale results without it. Composite rules
+//! are never cached.
+//!
+//! The parse errors and Angular classes of a file are cached along with its rule results.
+//! The cache is checked before a file is parsed, and a file whose rules all hit the cache
+//! is not parsed at all; only composite rules run on the cached results.
+//!
+//! The cache is stored as JSON in `.sentinel-cache/rule-results.json` and enabled with
+//! `--cache` or `"cache": true` in `sentinel.json`.
+//!
+//! Runs that use a cache also write it to `analysis_results.json` in the output directory.
+//! Its paths are relative to the path base, so the file is a portable cache: a fresh CI
+//! checkout that only restored the reports of the last run passes it as
+//! `--previous=out/analysis_results.json`, which is read when the rule cache is missing.
+//!
+//! `scoper cache export cache.tar.zst` bundles the rule cache into an archive for CI
+//! artifact storage, and `scoper cache import cache.tar.zst` restores it, rejecting
+//! archives of another cache version and dropping the entries of files that are not in the
+//! current checkout.
+
+use crate::analyzer::parse_error_diagnostic;
+use crate::angular_graph::AngularSymbol;
+use crate::rules::PARSE_ERROR_RULE;
+use crate::rules_registry::RulesRegistry;
+use crate::utilities::paths::PathBase;
+use crate::utilities::source::{diagnostic_span, position_of_offset};
+use crate::utilities::{DebugLevel, log};
+use crate::{FileAnalysisResult, RuleDiagnostic};
+use oxc_diagnostics::{LabeledSpan, OxcDiagnostic, Severity};
+use oxc_span::Span;
+use serde::{Deserialize, Serialize};
+use serde_json::Value;
+use std::collections::{BTreeSet, HashMap};
+use std::fs;
+use std::io::Read;
+use std::path::Path;
+use std::sync::Arc;
+use std::time::Duration;
+
+/// Default location of the cache file
+pub const DEFAULT_CACHE_PATH: &str = ".sentinel-cache/rule-results.json";
+
+/// Name of the copy of the cache in the output directory, see `--previous`
+pub const RESULTS_FILE: &str = "analysis_results.json";
+
+/// Name of the cache file in an archive written by `export_archive`
+const ARCHIVE_ENTRY: &str = "rule-results.json";
+
+/// Version of the cache file format
+///
+/// Caches written with another version are discarded, so bump it whenever the stored
+/// entries change incompatibly.
+pub const CACHE_VERSION: u32 = 2;
+
+/// Hash file contents and configurations with 64-bit FNV-1a, which is stable across
+/// runs and platforms
+pub fn content_hash(bytes: &[u8]) -> u64 {
+    bytes.iter().fold(0xcbf29ce484222325, |hash, byte| {
+        (hash ^ *byte as u64).wrapping_mul(0x100000001b3)
+    })
+}
+
+/// A label of a cached diagnostic
+#[derive(Serialize, Deserialize, Debug, Clone)]
+struct CachedLabel {
+    offset: usize,
+    len: usize,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    label: Option<String>,
+}
+
+/// A diagnostic in a form that can be stored and turned back into an `OxcDiagnostic`
+#[derive(Serialize, Deserialize, Debug, Clone)]
+struct CachedDiagnostic {
+    message: String,
+    severity: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    help: Option<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    labels: Vec<CachedLabel>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    code_scope: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    code_number: Option<String>,
+    #[serde(default, skip_serializing_if = "HashMap::is_empty")]
+    metadata: HashMap<String, Value>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    suggestion: Option<String>,
+    line: usize,
+    column: usize,
+}
+
+impl CachedDiagnostic {
+    fn from_rule_diagnostic(rule_diagnostic: &RuleDiagnostic) -> Self {
+        let diagnostic = &rule_diagnostic.diagnostic;
+        Self {
+            message: diagnostic.message.to_string(),
+            severity: match diagnostic.severity {
+                Severity::Error => "error".to_string(),
+                Severity::Warning => "warning".to_string(),
+                _ => "info".to_string(),
+            },
+            help: diagnostic.help.as_ref().map(|help| help.to_string()),
+            labels: diagnostic
+                .labels
+                .iter()
+                .flatten()
+                .map(|label| CachedLabel {
+                    offset: label.offset(),
+                    len: label.len(),
+                    label: label.label().map(str::to_string),
+                })
+                .collect(),
+            code_scope: diagnostic.code.scope.as_ref().map(|s| s.to_string()),
+            code_number: diagnostic.code.number.as_ref().map(|n| n.to_string()),
+            metadata: rule_diagnostic.metadata.clone(),
+            suggestion: rule_diagnostic.suggestion.clone(),
+            line: rule_diagnostic.line_number,
+            column: rule_diagnostic.column_number,
+        }
+    }
+
+    fn to_oxc_diagnostic(&self) -> OxcDiagnostic {
+        let mut diagnostic = match self.severity.as_str() {
+            "error" => OxcDiagnostic::error(self.message.clone()),
+            _ => OxcDiagnostic::warn(self.message.clone()),
+        };
+        if let Some(help) = &self.help {
+            diagnostic = diagnostic.with_help(help.clone());
+        }
+        if !self.labels.is_empty() {
+            diagnostic = diagnostic.with_labels(
+                self.labels
+                    .iter()
+                    .map(|label| LabeledSpan::new(label.label.clone(), label.offset, label.len)),
+            );
+        }
+        if let (Some(scope), Some(number)) = (&self.code_scope, &self.code_number) {
+            diagnostic = diagnostic.with_error_code(scope.clone(), number.clone());
+        }
+        diagnostic
+    }
+
+    fn to_rule_diagnostic(
+        &self,
+        rule_name: &str,
+        registry: &RulesRegistry,
+        source_code: &Arc<str>,
+    ) -> Option<RuleDiagnostic> {
+        let diagnostic = self.to_oxc_diagnostic();
+
+        // Positions are recomputed, so the cache does not depend on the column unit
+        let (line_number, column_number) = diagnostic_span(&diagnostic)
+            .map_or((self.line, self.column), |span| {
+                position_of_offset(source_code, span.start as usize, registry.column_unit())
+            });
+
+        Some(RuleDiagnostic {
+            rule_id: rule_name.to_string(),
+            category: registry.get_rule_category(rule_name)?,
+            rule_version: registry.get_rule_version(rule_name)?,
+            docs_url: registry.get_rule(rule_name).map(|rule| rule.docs_url()),
+            diagnostic,
+            metadata: self.metadata.clone(),
+            suggestion: self.suggestion.clone(),
+            source_code: Arc::clone(source_code),
+            line_number,
+            column_number,
+            blame: None,
+        })
+    }
+}
+
+/// The cached results of one rule for a file
+#[derive(Serialize, Deserialize, Debug, Clone)]
+struct CachedRuleResult {
+    fingerprint: String,
+    diagnostics: Vec<CachedDiagnostic>,
+}
+
+/// An Angular class of a cached file, with the fields the findings export leaves out
+#[derive(Serialize, Deserialize, Debug, Clone)]
+struct CachedSymbol {
+    #[serde(flatten)]
+    symbol: AngularSymbol,
+    #[serde(default, skip_serializing_if = "BTreeSet::is_empty")]
+    template_usages: BTreeSet<String>,
+    start: u32,
+    end: u32,
+}
+
+impl CachedSymbol {
+    fn from_symbol(symbol: &AngularSymbol) -> Self {
+        Self {
+            symbol: symbol.clone(),
+            template_usages: symbol.template_usages.clone(),
+            start: symbol.span.start,
+            end: symbol.span.end,
+        }
+    }
+
+    fn to_symbol(&self) -> AngularSymbol {
+        AngularSymbol {
+            template_usages: self.template_usages.clone(),
+            span: Span::new(self.start, self.end),
+            ..self.symbol.clone()
+        }
+    }
+}
+
+/// The cached results of a file
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+struct CachedFile {
+    /// FNV-1a hash of the content, hex-encoded
+    content_hash: String,
+    rules: HashMap<String, CachedRuleResult>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    parse_errors: Vec<CachedDiagnostic>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    angular_symbols: Vec<CachedSymbol>,
+}
+
+/// What parsing an unchanged file gave, so it does not have to be parsed again
+#[derive(Debug, Clone, Default)]
+pub struct CachedParse {
+    pub parse_errors: Vec<OxcDiagnostic>,
+    pub angular_symbols: Vec<AngularSymbol>,
+}
+
+/// An analysis result written to disk while memory is short
+///
+/// Unlike cache entries, it keeps everything of the result, including the findings of
+/// composite rules and the source code the findings point into.
+#[derive(Serialize, Deserialize, Debug)]
+pub(crate) struct SpilledResult {
+    file_path: String,
+    parse_duration: Duration,
+    semantic_duration: Duration,
+    rule_durations: HashMap<String, Duration>,
+    total_duration: Duration,
+    /// Source code of the file, only kept if it has findings
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    source_code: Option<String>,
+    diagnostics: Vec<(String, CachedDiagnostic)>,
+    angular_symbols: Vec<CachedSymbol>,
+    content_hash: Option<u64>,
+    generated: bool,
+    skipped: Option<String>,
+}
+
+impl SpilledResult {
+    pub(crate) fn from_result(result: &FileAnalysisResult) -> Self {
+        Self {
+            file_path: result.file_path.clone(),
+            parse_duration: result.parse_duration,
+            semantic_duration: result.semantic_duration,
+            rule_durations: result.rule_durations.clone(),
+            total_duration: result.total_duration,
+            source_code: result
+                .diagnostics
+                .first()
+                .map(|d| d.source_code.to_string()),
+            diagnostics: result
+                .diagnostics
+                .iter()
+                .map(|d| (d.rule_id.clone(), CachedDiagnostic::from_rule_diagnostic(d)))
+                .collect(),
+            angular_symbols: result
+                .angular_symbols
+                .iter()
+                .map(CachedSymbol::from_symbol)
+                .collect(),
+            content_hash: result.content_hash,
+            generated: result.generated,
+            skipped: result.skipped.clone(),
+        }
+    }
+
+    pub(crate) fn into_result(self, registry: &RulesRegistry) -> FileAnalysisResult {
+        let source_code: Arc<str> = Arc::from(self.source_code.unwrap_or_default());
+        let diagnostics = self
+            .diagnostics
+            .iter()
+            .filter_map(|(rule_id, diagnostic)| {
+                if rule_id == PARSE_ERROR_RULE {
+                    return Some(parse_error_diagnostic(
+                        diagnostic.to_oxc_diagnostic(),
+                        &source_code,
+                        registry.column_unit(),
+                    ));
+                }
+                diagnostic.to_rule_diagnostic(rule_id, registry, &source_code)
+            })
+            .collect();
+        FileAnalysisResult {
+            file_path: self.file_path,
+            parse_duration: self.parse_duration,
+            semantic_duration: self.semantic_duration,
+            rule_durations: self.rule_durations,
+            total_duration: self.total_duration,
+            diagnostics,
+            angular_symbols: self
+                .angular_symbols
+                .iter()
+                .map(CachedSymbol::to_symbol)
+                .collect(),
+            content_hash: self.content_hash,
+            generated: self.generated,
+            skipped: self.skipped,
+        }
+    }
+}
+
+/// Cached rule results of all files
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+pub struct RuleCache {
+    /// Format version the cache was written with, see `CACHE_VERSION`
+    #[serde(default)]
+    version: u32,
+    /// Keyed by the path relative to `base`, see `PathBase`
+    files: HashMap<String, CachedFile>,
+    #[serde(skip)]
+    base: PathBase,
+}
+
+impl RuleCache {
+    /// Load the cache, starting with an empty cache if the file is missing or invalid
+    pub fn load(path: &str, base: PathBase, debug_level: DebugLevel) -> Self {
+        let Ok(content) = fs::read_to_string(path) else {
+            log(
+                DebugLevel::Info,
+                debug_level,
+                &format!("No rule cache found at {}, analyzing all files", path),
+            );
+            return Self {
+                version: CACHE_VERSION,
+                base,
+                ..Self::default()
+            };
+        };
+
+        let files = match serde_json::from_str::<RuleCache>(&content) {
+            Ok(cache) if cache.version == CACHE_VERSION => cache.files,
+            Ok(cache) => {
+                log(
+                    DebugLevel::Info,
+                    debug_level,
+                    &format!(
+                        "Ignoring rule cache {} written with cache version {}, expected {}",
+                        path, cache.version, CACHE_VERSION
+                    ),
+                );
+                HashMap::new()
+            }
+            Err(e) => {
+                log(
+                    DebugLevel::Warn,
+                    debug_level,
+                    &format!("Ignoring invalid rule cache {}: {}", path, e),
+                );
+                HashMap::new()
+            }
+        };
+        Self {
+            version: CACHE_VERSION,
+            files,
+            base,
+        }
+    }
+
+    /// Read a cache, failing if it is invalid or written with another cache version
+    fn read(content: &str, source: &str, base: PathBase) -> Result<Self, String> {
+        let cache: RuleCache = serde_json::from_str(content)
+            .map_err(|e| format!("Invalid rule cache {}: {}", source, e))?;
+        if cache.version != CACHE_VERSION {
+            return Err(format!(
+                "Rule cache {} was written with cache version {}, this build reads version {}",
+                source, cache.version, CACHE_VERSION
+            ));
+        }
+        Ok(Self { base, ..cache })
+    }
+
+    /// Remove the entries of files that no longer exist and get their number
+    fn prune(&mut self) -> usize {
+        let before = self.files.len();
+        let base = &self.base;
+        self.files.retain(|path, _| base.resolve(path).exists());
+        before - self.files.len()
+    }
+
+    /// Get the cached diagnostics of the rules that are still valid for a file
+    ///
+    /// Only rules that are enabled, cacheable and have the current fingerprint are
+    /// returned; a rule with no findings is returned with an empty list.
+    pub fn lookup(
+        &self,
+        file_path: &str,
+        source_code: &Arc<str>,
+        registry: &RulesRegistry,
+    ) -> HashMap<String, Vec<RuleDiagnostic>> {
+        let Some(file) = self.unchanged_file(file_path, source_code) else {
+            return HashMap::new();
+        };
+
+        registry
+            .get_enabled_rules()
+            .into_iter()
+            .filter(|rule_name| registry.is_cacheable(rule_name))
+            .filter(|rule_name| registry.rule_applies_to(rule_name, file_path))
+            .filter_map(|rule_name| {
+                let cached = file.rules.get(&rule_name)?;
+                if cached.fingerprint != registry.rule_fingerprint(&rule_name) {
+                    return None;
+                }
+                let diagnostics = cached
+                    .diagnostics
+                    .iter()
+                    .map(|d| d.to_rule_diagnostic(&rule_name, registry, source_code))
+                    .collect::<Option<Vec<_>>>()?;
+                Some((rule_name, diagnostics))
+            })
+            .collect()
+    }
+
+    /// Get the cached parse errors and Angular classes of a file, if it is unchanged
+    pub fn lookup_parse(&self, file_path: &str, source_code: &Arc<str>) -> Option<CachedParse> {
+        let file = self.unchanged_file(file_path, source_code)?;
+        Some(CachedParse {
+            parse_errors: file
+                .parse_errors
+                .iter()
+                .map(CachedDiagnostic::to_oxc_diagnostic)
+                .collect(),
+            angular_symbols: file
+                .angular_symbols
+                .iter()
+                .map(CachedSymbol::to_symbol)
+                .collect(),
+        })
+    }
+
+    /// Get the entry of a file if its content did not change since it was cached
+    fn unchanged_file(&self, file_path: &str, source_code: &Arc<str>) -> Option<&CachedFile> {
+        self.files
+            .get(&self.base.relative(file_path))
+            .filter(|file| {
+                file.content_hash == format!("{:016x}", content_hash(source_code.as_bytes()))
+            })
+    }
+
+    /// Store the results of an analysis run
+    ///
+    /// The results of every enabled, cacheable rule are stored, including rules without
+    /// findings, along with the parse errors and Angular classes of the files. Entries of files that no longer exist are removed.
+    pub fn update(&mut self, results: &[FileAnalysisResult], registry: &RulesRegistry) {
+        let rule_names: Vec<String> = registry
+            .get_enabled_rules()
+            .into_iter()
+            .filter(|rule_name| registry.is_cacheable(rule_name))
+            .collect();
+
+        // Files that could not be read or parsed have no content hash and are analyzed
+        // again next time
+        for result in results.iter().filter(|r| r.content_hash.is_some()) {
+            self.store(result, &rule_names, registry);
+        }
+
+        self.prune();
+    }
+
+    fn store(
+        &mut self,
+        result: &FileAnalysisResult,
+        rule_names: &[String],
+        registry: &RulesRegistry,
+    ) {
+        let Some(hash) = result.content_hash else {
+            return;
+        };
+
+        let rules = rule_names
+            .iter()
+            .filter(|rule_name| registry.rule_applies_to(rule_name, &result.file_path))
+            .map(|rule_name| {
+                let diagnostics = result
+                    .diagnostics
+                    .iter()
+                    .filter(|d| &d.rule_id == rule_name)
+                    .map(CachedDiagnostic::from_rule_diagnostic)
+                    .collect();
+                (
+                    rule_name.clone(),
+                    CachedRuleResult {
+                        fingerprint: registry.rule_fingerprint(rule_name),
+                        diagnostics,
+                    },
+                )
+            })
+            .collect();
+
+        self.files.insert(
+            self.base.relative(&result.file_path),
+            CachedFile {
+                content_hash: format!("{:016x}", hash),
+                rules,
+                parse_errors: result
+                    .diagnostics
+                    .iter()
+                    .filter(|d| d.rule_id == PARSE_ERROR_RULE)
+                    .map(CachedDiagnostic::from_rule_diagnostic)
+                    .collect(),
+                angular_symbols: result
+                    .angular_symbols
+                    .iter()
+                    .map(CachedSymbol::from_symbol)
+                    .collect(),
+            },
+        );
+    }
+
+    /// Write the cache to disk, logging the outcome
+    pub fn save(&self, path: &str, debug_level: DebugLevel) {
+        match self.write(path) {
+            Ok(()) => log(
+                DebugLevel::Info,
+                debug_level,
+                &format!("Rule cache written to {}", path),
+            ),
+            Err(e) => log(DebugLevel::Error, debug_level, &e),
+        }
+    }
+
+    /// Write the cache to disk
+    fn write(&self, path: &str) -> Result<(), String> {
+        if let Some(parent) = Path::new(path)
+            .parent()
+            .filter(|p| !p.as_os_str().is_empty())
+        {
+            fs::create_dir_all(parent).map_err(|e| {
+                format!(
+                    "Failed to create cache directory {}: {}",
+                    parent.display(),
+                    e
+                )
+            })?;
+        }
+
+        let json = serde_json::to_string(self)
+            .map_err(|e| format!("Failed to serialize rule cache: {}", e))?;
+        fs::write(path, json).map_err(|e| format!("Failed to write rule cache {}: {}", path, e))
+    }
+}
+
+/// Entries of an imported cache archive
+#[derive(Debug, Clone, PartialEq)]
+pub struct CacheImport {
+    /// Files whose results were restored
+    pub files: usize,
+    /// Files dropped because they are not in the checkout
+    pub pruned: usize,
+}
+
+/// Bundle the rule cache into a zstd-compressed tar archive and get its number of files
+///
+/// The archive only depends on the cached results: keys are sorted and the entry has no
+/// timestamp or owner, so the same cache always gives the same bytes.
+pub fn export_archive(cache_path: &str, archive_path: &str) -> Result<usize, String> {
+    let content = fs::read_to_string(cache_path)
+        .map_err(|e| format!("Failed to read rule cache {}: {}", cache_path, e))?;
+    let cache = RuleCache::read(&content, cache_path, PathBase::default())?;
+    // Unlike the maps of the cache, the maps of JSON values are sorted by key
+    let json = serde_json::to_value(&cache)
+        .and_then(|value| serde_json::to_vec(&value))
+        .map_err(|e| format!("Failed to serialize rule cache: {}", e))?;
+
+    let mut header = tar::Header::new_gnu();
+    header.set_size(json.len() as u64);
+    header.set_mode(0o644);
+    header.set_mtime(0);
+    header.set_uid(0);
+    header.set_gid(0);
+    header.set_entry_type(tar::EntryType::Regular);
+
+    let write_error =
+        |e: std::io::Error| format!("Failed to write cache archive {}: {}", archive_path, e);
+    let file = fs::File::create(archive_path).map_err(write_error)?;
+    let mut archive = tar::Builder::new(zstd::Encoder::new(file, 0).map_err(write_error)?);
+    archive
+        .append_data(&mut header, ARCHIVE_ENTRY, json.as_slice())
+        .and_then(|_| archive.into_inner())
+        .and_then(|encoder| encoder.finish())
+        .map_err(write_error)?;
+    Ok(cache.files.len())
+}
+
+/// Restore the rule cache from an archive written by `export_archive`
+///
+/// Fails on archives of another cache version. The entries of files that are not in the
+/// checkout at `base` are dropped before the cache is written to `cache_path`.
+pub fn import_archive(
+    archive_path: &str,
+    cache_path: &str,
+    base: PathBase,
+) -> Result<CacheImport, String> {
+    let read_error =
+        |e: std::io::Error| format!("Failed to read cache archive {}: {}", archive_path, e);
+    let file = fs::File::open(archive_path).map_err(read_error)?;
+    let mut archive = tar::Archive::new(zstd::Decoder::new(file).map_err(read_error)?);
+
+    let mut content = None;
+    for entry in archive.entries().map_err(read_error)? {
+        let mut entry = entry.map_err(read_error)?;
+        if entry.path().map_err(read_error)?.as_os_str() == ARCHIVE_ENTRY {
+            let mut text = String::new();
+            entry.read_to_string(&mut text).map_err(read_error)?;
+            content = Some(text);
+        }
+    }
+    let content = content
+        .ok_or_else(|| format!("Cache archive {} has no {}", archive_path, ARCHIVE_ENTRY))?;
+
+    let mut cache = RuleCache::read(&content, archive_path, base)?;
+    let pruned = cache.prune();
+    cache.write(cache_path)?;
+    Ok(CacheImport {
+        files: cache.files.len(),
+        pruned,
+    })
+}
@@ -0,0 +1,431 @@
+//! Split analyzed files into LLM-ready chunks
+//!
+//! Chunk boundaries come from the AST: every top-level statement of a file becomes a chunk,
+//! together with the comments and decorators in front of it, so a chunk never ends in the
+//! middle of a function or class. Classes above the token limit are split between their
+//! members instead. Import declarations are not chunked on their own, they are
+//! attached to every chunk of the file as context instead. The findings of the analysis
+//! that fall into the line range of a chunk are attached to it, so downstream review
+//! workflows get the code, what it depends on and what Sentinel found in one record.
+//!
+//! Each chunk records its size in tokens of the configured target model, counted by a
+//! `Tokenizer`. Chunks that stay above `max_chunk_tokens`, such as a single huge function,
+//! are flagged as oversized.
+//!
+//! Chunks are written to `chunks.jsonl` in the output directory, one JSON object per line,
+//! and are the input of the embedding index.
+
+use crate::FileAnalysisResult;
+use crate::rules_registry::PROJECT_FILE;
+use crate::tokenizer::{ApproximateTokenizer, Tokenizer, create_tokenizer};
+use crate::utilities::config::Config;
+use crate::utilities::paths::PathBase;
+use crate::utilities::source::{decode_source, line_of_offset, node_source};
+use crate::utilities::{DebugLevel, log};
+use oxc_allocator::Allocator;
+use oxc_ast::ast::{
+    BindingPatternKind, Class, Declaration, ExportDefaultDeclarationKind, Statement,
+};
+use oxc_diagnostics::Severity;
+use oxc_parser::Parser;
+use oxc_span::{GetSpan, SourceType};
+use rayon::prelude::*;
+use serde::{Deserialize, Serialize};
+use serde_json::Value;
+use std::collections::HashMap;
+use std::fs;
+use std::io::Write;
+use std::path::Path;
+
+/// Default token limit of a chunk
+const DEFAULT_MAX_CHUNK_TOKENS: usize = 2048;
+
+/// Settings for splitting files into chunks
+pub struct ChunkOptions {
+    pub tokenizer: Box<dyn Tokenizer>,
+    /// Token limit of a chunk for the target model
+    pub max_tokens: usize,
+}
+
+impl ChunkOptions {
+    /// Create the options from the `tokenizer` and `max_chunk_tokens` settings
+    pub fn from_config(config: &Config, debug_level: DebugLevel) -> Result<Self, String> {
+        Ok(Self {
+            tokenizer: create_tokenizer(
+                &config.tokenizer.clone().unwrap_or_default(),
+                debug_level,
+            )?,
+            max_tokens: config.max_chunk_tokens.unwrap_or(DEFAULT_MAX_CHUNK_TOKENS),
+        })
+    }
+}
+
+impl Default for ChunkOptions {
+    fn default() -> Self {
+        Self {
+            tokenizer: Box::new(
+                ApproximateTokenizer::new("cl100k_base").expect("Invalid default encoding"),
+            ),
+            max_tokens: DEFAULT_MAX_CHUNK_TOKENS,
+        }
+    }
+}
+
+/// A finding that falls within the line range of a chunk
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct ChunkFinding {
+    pub rule: String,
+    pub category: String,
+    pub severity: String,
+    pub message: String,
+    pub line: usize,
+    pub column: usize,
+    #[serde(default, skip_serializing_if = "HashMap::is_empty")]
+    pub metadata: HashMap<String, Value>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub suggestion: Option<String>,
+}
+
+/// A self-contained piece of a source file
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct Chunk {
+    /// Stable identifier of the chunk, `<file>:<start_line>-<end_line>`
+    pub id: String,
+    pub file: String,
+    /// The kind of the top-level statement, e.g. `class` or `function`
+    pub kind: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub name: Option<String>,
+    /// Position of the chunk among the parts of a split class, e.g. `2/3`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub part: Option<String>,
+    /// First line of the chunk, 1-based
+    pub start_line: usize,
+    /// Last line of the chunk, 1-based and inclusive
+    pub end_line: usize,
+    pub code: String,
+    /// Size of the code in tokens of the target model
+    pub token_count: usize,
+    /// Whether the chunk exceeds the token limit
+    #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+    pub oversized: bool,
+    /// The import declarations of the file
+    pub imports: Vec<String>,
+    pub findings: Vec<ChunkFinding>,
+}
+
+/// Get the kind and name of a declaration
+fn declaration_kind(declaration: &Declaration) -> (&'static str, Option<String>) {
+    match declaration {
+        Declaration::FunctionDeclaration(func) => {
+            ("function", func.id.as_ref().map(|id| id.name.to_string()))
+        }
+        Declaration::ClassDeclaration(class) => {
+            ("class", class.id.as_ref().map(|id| id.name.to_string()))
+        }
+        Declaration::VariableDeclaration(var) => (
+            "variable",
+            var.declarations
+                .first()
+                .and_then(|decl| match &decl.id.kind {
+                    BindingPatternKind::BindingIdentifier(id) => Some(id.name.to_string()),
+                    _ => None,
+                }),
+        ),
+        Declaration::TSInterfaceDeclaration(interface) => {
+            ("interface", Some(interface.id.name.to_string()))
+        }
+        Declaration::TSTypeAliasDeclaration(alias) => ("type", Some(alias.id.name.to_string())),
+        Declaration::TSEnumDeclaration(enum_decl) => ("enum", Some(enum_decl.id.name.to_string())),
+        _ => ("declaration", None),
+    }
+}
+
+/// Get the kind and name of a top-level statement
+fn statement_kind(statement: &Statement) -> (&'static str, Option<String>) {
+    match statement {
+        Statement::ExportNamedDeclaration(export) => match &export.declaration {
+            Some(declaration) => declaration_kind(declaration),
+            None => ("export", None),
+        },
+        Statement::ExportDefaultDeclaration(export) => match &export.declaration {
+            ExportDefaultDeclarationKind::FunctionDeclaration(func) => {
+                ("function", func.id.as_ref().map(|id| id.name.to_string()))
+            }
+            ExportDefaultDeclarationKind::ClassDeclaration(class) => {
+                ("class", class.id.as_ref().map(|id| id.name.to_string()))
+            }
+            _ => ("export", None),
+        },
+        Statement::ExportAllDeclaration(_) => ("export", None),
+        _ => match statement.as_declaration() {
+            Some(declaration) => declaration_kind(declaration),
+            None => ("statement", None),
+        },
+    }
+}
+
+/// Split a source file into chunks
+pub fn chunk_source(
+    source: &str,
+    source_type: SourceType,
+    file_path: &str,
+    options: &ChunkOptions,
+) -> Result<Vec<Chunk>, String> {
+    let allocator = Allocator::default();
+    let parse_result = Parser::new(&allocator, source, source_type).parse();
+    if parse_result.panicked {
+        return Err(format!("Failed to parse {}", file_path));
+    }
+
+    let body = &parse_result.program.body;
+    let imports: Vec<String> = body
+        .iter()
+        .filter(|statement| matches!(statement, Statement::ImportDeclaration(_)))
+        .map(|statement| node_source(statement, source).to_string())
+        .collect();
+
+    let mut chunks = Vec::new();
+    // Chunks start right after the previous statement, so leading comments and
+    // decorators stay with the statement they belong to
+    let mut previous_end = 0;
+
+    for statement in body {
+        let span = statement.span();
+        let start = previous_end;
+        previous_end = span.end as usize;
+
+        if matches!(statement, Statement::ImportDeclaration(_)) {
+            continue;
+        }
+
+        let end = span.end as usize;
+        if source[start..end].trim().is_empty() {
+            continue;
+        }
+
+        // Oversized classes are split between their members
+        let ranges = match statement_class(statement) {
+            Some(class)
+                if options.tokenizer.count_tokens(&source[start..end]) > options.max_tokens =>
+            {
+                split_class(source, class, start, end, options)
+            }
+            _ => vec![(start, end)],
+        };
+
+        let (kind, name) = statement_kind(statement);
+        let part_count = ranges.len();
+
+        for (index, (start, end)) in ranges.into_iter().enumerate() {
+            let code = source[start..end].trim();
+
+            // Skip the whitespace in front of the chunk when computing its first line
+            let leading_whitespace = source[start..].len() - source[start..].trim_start().len();
+            let start_line = line_of_offset(source, start + leading_whitespace);
+            let end_line = line_of_offset(source, end);
+            let token_count = options.tokenizer.count_tokens(code);
+
+            chunks.push(Chunk {
+                id: format!("{}:{}-{}", file_path, start_line, end_line),
+                file: file_path.to_string(),
+                kind: kind.to_string(),
+                name: name.clone(),
+                part: (part_count > 1).then(|| format!("{}/{}", index + 1, part_count)),
+                start_line,
+                end_line,
+                code: code.to_string(),
+                token_count,
+                oversized: token_count > options.max_tokens,
+                imports: imports.clone(),
+                findings: Vec::new(),
+            });
+        }
+    }
+
+    Ok(chunks)
+}
+
+/// Get the class declared by a top-level statement
+fn statement_class<'b, 'a>(statement: &'b Statement<'a>) -> Option<&'b Class<'a>> {
+    match statement {
+        Statement::ClassDeclaration(class) => Some(class),
+        Statement::ExportNamedDeclaration(export) => match &export.declaration {
+            Some(Declaration::ClassDeclaration(class)) => Some(class),
+            _ => None,
+        },
+        Statement::ExportDefaultDeclaration(export) => match &export.declaration {
+            ExportDefaultDeclarationKind::ClassDeclaration(class) => Some(class),
+            _ => None,
+        },
+        _ => None,
+    }
+}
+
+/// Split the source range of a class into parts at the boundaries of its members
+///
+/// Consecutive members are packed into a part as long as it stays within the token limit.
+/// A member is never split, so a single member above the limit becomes an oversized part.
+/// The first part holds the class header, the last one the closing brace.
+fn split_class(
+    source: &str,
+    class: &Class,
+    start: usize,
+    end: usize,
+    options: &ChunkOptions,
+) -> Vec<(usize, usize)> {
+    // Each segment ends after a member and holds the comments and decorators before it
+    let mut boundaries: Vec<usize> = class
+        .body
+        .body
+        .iter()
+        .map(|member| member.span().end as usize)
+        .collect();
+    if boundaries.len() < 2 {
+        return vec![(start, end)];
+    }
+    boundaries.pop();
+    boundaries.push(end);
+
+    let mut parts = Vec::new();
+    let mut part_start = start;
+    let mut part_tokens = 0;
+    let mut segment_start = start;
+
+    for boundary in boundaries {
+        let segment_tokens = options
+            .tokenizer
+            .count_tokens(&source[segment_start..boundary]);
+        if segment_start > part_start && part_tokens + segment_tokens > options.max_tokens {
+            parts.push((part_start, segment_start));
+            part_start = segment_start;
+            part_tokens = 0;
+        }
+        part_tokens += segment_tokens;
+        segment_start = boundary;
+    }
+    parts.push((part_start, end));
+
+    parts
+}
+
+/// Split an analyzed file into chunks and attach its findings
+///
+/// The file is read relative to `base`, see `PathBase`.
+pub fn chunk_file(
+    result: &FileAnalysisResult,
+    options: &ChunkOptions,
+    base: &PathBase,
+) -> Result<Vec<Chunk>, String> {
+    let source = fs::read(base.resolve(&result.file_path))
+        .map_err(|e| e.to_string())
+        .and_then(decode_source)
+        .map_err(|e| format!("Failed to read {}: {}", result.file_path, e))?
+        .content;
+    let source_type = SourceType::from_path(Path::new(&result.file_path))
+        .map_err(|_| format!("Unsupported file type: {}", result.file_path))?;
+
+    let mut chunks = chunk_source(&source, source_type, &result.file_path, options)?;
+
+    for rule_diagnostic in &result.diagnostics {
+        let line = rule_diagnostic.line_number;
+        if let Some(chunk) = chunks
+            .iter_mut()
+            .find(|chunk| chunk.start_line <= line && line <= chunk.end_line)
+        {
+            chunk.findings.push(ChunkFinding {
+                rule: rule_diagnostic.rule_id.clone(),
+                category: rule_diagnostic.category.to_string(),
+                severity: match rule_diagnostic.diagnostic.severity {
+                    Severity::Error => "error".to_string(),
+                    Severity::Warning => "warning".to_string(),
+                    _ => "info".to_string(),
+                },
+                message: rule_diagnostic.diagnostic.message.to_string(),
+                line,
+                column: rule_diagnostic.column_number,
+                metadata: rule_diagnostic.metadata.clone(),
+                suggestion: rule_diagnostic.suggestion.clone(),
+            });
+        }
+    }
+
+    Ok(chunks)
+}
+
+/// Split all analyzed files into chunks
+pub fn collect_chunks(
+    results: &[FileAnalysisResult],
+    options: &ChunkOptions,
+    base: &PathBase,
+    debug_level: DebugLevel,
+) -> Vec<Chunk> {
+    let chunks: Vec<Chunk> = results
+        .par_iter()
+        .filter(|result| result.file_path != PROJECT_FILE && !result.generated)
+        .flat_map_iter(|result| match chunk_file(result, options, base) {
+            Ok(chunks) => chunks,
+            Err(err) => {
+                log(DebugLevel::Warn, debug_level, &err);
+                Vec::new()
+            }
+        })
+        .collect();
+
+    let oversized = chunks.iter().filter(|chunk| chunk.oversized).count();
+    if oversized > 0 {
+        log(
+            DebugLevel::Warn,
+            debug_level,
+            &format!(
+                "{} chunks exceed the limit of {} {} tokens",
+                oversized,
+                options.max_tokens,
+                options.tokenizer.name()
+            ),
+        );
+    }
+
+    chunks
+}
+
+/// Export chunks to chunks.jsonl
+pub fn export_chunks(chunks: &[Chunk], debug_level: DebugLevel, output_dir: &String) {
+    if let Err(e) = fs::create_dir_all(output_dir) {
+        log(
+            DebugLevel::Error,
+            debug_level,
+            &format!("Failed to create output directory {}: {}", output_dir, e),
+        );
+        return;
+    }
+
+    let file_path = format!("{}/chunks.jsonl", output_dir);
+    let mut lines = Vec::new();
+    for chunk in chunks {
+        match serde_json::to_string(chunk) {
+            Ok(json) => {
+                lines.extend_from_slice(json.as_bytes());
+                lines.push(b'\n');
+            }
+            Err(e) => log(
+                DebugLevel::Error,
+                debug_level,
+                &format!("Failed to serialize chunk {}: {}", chunk.id, e),
+            ),
+        }
+    }
+
+    match fs::File::create(&file_path).and_then(|mut file| file.write_all(&lines)) {
+        Ok(_) => log(
+            DebugLevel::Info,
+            debug_level,
+            &format!("Exported {} chunks to {}", chunks.len(), file_path),
+        ),
+        Err(e) => log(
+            DebugLevel::Error,
+            debug_level,
+            &format!("Failed to write {}: {}", file_path, e),
+        ),
+    }
+}
@@ -0,0 +1,208 @@
+//! Counters of count-only rules
+//!
+//! Some patterns are worth tracking without being violations, like the `setTimeout` calls
+//! or `console.log` statements left in a codebase. Rules that return true from
+//! `Rule::counts_only` report their matches like any other rule, but the matches are taken
+//! out of the results before suppressions, filters and quality gates see them and are
+//! summed up per counter: the `counter` in the metadata of a match, or the rule name.
+//!
+//! The totals are written as `counters` to findings.json and, with `--history`, to
+//! `history.jsonl`, so a metric can be followed across runs.
+//!
+//! `counter_alerts` in `sentinel.json` sets thresholds on single counters, absolute or as
+//! the increase over the previous run in the same output directory:
+//!
+//! ```json
+//! "counter_alerts": {
+//!   "console-log": { "max": 50 },
+//!   "set-timeout": { "max_increase": 0, "fail_quality_gate": true }
+//! }
+//! ```
+//!
+//! Breached alerts are printed and exported as `counter_alerts` in the summary. Alerts with
+//! `fail_quality_gate` also become gates of the quality gate, passed or not. Alerts of
+//! counters that were not counted are ignored, and so is `max_increase` on the first run.
+
+use crate::FileAnalysisResult;
+use crate::quality_gates::GateOutcome;
+use crate::rules_registry::RulesRegistry;
+use crate::utilities::config::CounterAlert;
+use serde::{Deserialize, Serialize};
+use serde_json::Value;
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::fs;
+use std::path::Path;
+use tabled::{
+    builder::Builder,
+    settings::{Alignment, Style, object::Columns},
+};
+
+/// Metadata key a rule names the counter of a match with
+pub const COUNTER_METADATA: &str = "counter";
+
+/// Outcome of one threshold of a counter alert
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct CounterAlertOutcome {
+    pub counter: String,
+    /// Threshold that was checked, `max` or `max_increase`
+    pub alert: String,
+    /// Count, or increase over the previous run for `max_increase`
+    pub actual: usize,
+    pub threshold: usize,
+    pub breached: bool,
+    /// Whether the alert is also a gate of the quality gate
+    pub fail_quality_gate: bool,
+}
+
+impl CounterAlertOutcome {
+    /// Get the outcome as a gate of the quality gate
+    pub fn as_gate(&self) -> GateOutcome {
+        GateOutcome {
+            gate: format!("counter_alerts.{}.{}", self.counter, self.alert),
+            actual: self.actual as f64,
+            threshold: self.threshold as f64,
+            passed: !self.breached,
+        }
+    }
+}
+
+/// Take the matches of count-only rules out of the results and count them per counter
+pub fn take_counters(
+    results: &mut [FileAnalysisResult],
+    registry: &RulesRegistry,
+) -> BTreeMap<String, usize> {
+    let counting: HashSet<String> = registry
+        .get_enabled_rules()
+        .into_iter()
+        .filter(|rule| {
+            registry
+                .get_rule(rule)
+                .is_some_and(|rule| rule.counts_only())
+        })
+        .collect();
+    let mut counters: BTreeMap<String, usize> = BTreeMap::new();
+    if counting.is_empty() {
+        return counters;
+    }
+
+    for result in results.iter_mut() {
+        result.diagnostics.retain(|diagnostic| {
+            if !counting.contains(&diagnostic.rule_id) {
+                return true;
+            }
+            let counter = diagnostic
+                .metadata
+                .get(COUNTER_METADATA)
+                .and_then(Value::as_str)
+                .unwrap_or(&diagnostic.rule_id);
+            *counters.entry(counter.to_string()).or_insert(0) += 1;
+            false
+        });
+    }
+
+    // Counters without matches are reported as 0, so a metric that reached 0 is not missing
+    for rule in &counting {
+        if let Some(names) = registry.get_rule(rule).map(|rule| rule.counter_names()) {
+            for name in names {
+                counters.entry(name).or_insert(0);
+            }
+        }
+    }
+    counters
+}
+
+/// Get the counters of the previous run in an output directory
+pub fn previous_counters(output_dir: &str) -> Option<BTreeMap<String, usize>> {
+    let content = fs::read_to_string(Path::new(output_dir).join("findings.json")).ok()?;
+    let export: Value = serde_json::from_str(&content).ok()?;
+    // Runs before counters existed had none
+    match export.get("counters") {
+        Some(counters) => serde_json::from_value(counters.clone()).ok(),
+        None => Some(BTreeMap::new()),
+    }
+}
+
+/// Check the counters against their alert thresholds
+///
+/// Returns the outcome of every threshold that could be checked, sorted by counter.
+/// `previous` holds the counters of the previous run, `None` on the first run.
+pub fn evaluate_counter_alerts(
+    counters: &BTreeMap<String, usize>,
+    alerts: &HashMap<String, CounterAlert>,
+    previous: Option<&BTreeMap<String, usize>>,
+) -> Vec<CounterAlertOutcome> {
+    let mut names: Vec<&String> = alerts.keys().collect();
+    names.sort();
+
+    let mut outcomes = Vec::new();
+    for name in names {
+        let alert = &alerts[name];
+        let Some(&count) = counters.get(name) else {
+            continue;
+        };
+        let mut check = |kind: &str, actual: usize, threshold: usize| {
+            outcomes.push(CounterAlertOutcome {
+                counter: name.clone(),
+                alert: kind.to_string(),
+                actual,
+                threshold,
+                breached: actual > threshold,
+                fail_quality_gate: alert.fail_quality_gate,
+            });
+        };
+
+        if let Some(max) = alert.max {
+            check("max", count, max);
+        }
+        // A counter missing from the previous run was not counted, so it has no delta
+        let before = previous.and_then(|previous| previous.get(name));
+        if let (Some(max_increase), Some(&before)) = (alert.max_increase, before) {
+            check("max_increase", count.saturating_sub(before), max_increase);
+        }
+    }
+    outcomes
+}
+
+/// Print the breached counter alerts
+pub fn print_counter_alerts(breaches: &[CounterAlertOutcome]) {
+    println!("Counter alerts: {}", breaches.len());
+    for breach in breaches {
+        let actual = match breach.alert.as_str() {
+            "max_increase" => format!("+{}", breach.actual),
+            _ => breach.actual.to_string(),
+        };
+        println!(
+            "  {}: {} exceeds {} {}{}",
+            breach.counter,
+            actual,
+            breach.alert,
+            breach.threshold,
+            if breach.fail_quality_gate {
+                ", fails the quality gate"
+            } else {
+                ""
+            }
+        );
+    }
+    println!();
+}
+
+/// Print the counters as a table
+pub fn print_counters(counters: &BTreeMap<String, usize>) {
+    println!("\nCounters:");
+    println!("----------------");
+
+    let mut builder = Builder::new();
+    builder.push_record(["Counter", "Count"]);
+    for (counter, count) in counters {
+        builder.push_record([counter.clone(), count.to_string()]);
+    }
+
+    let mut table = builder.build();
+    table
+        .with(Style::ascii_rounded())
+        .modify(Columns::new(1..), Alignment::right());
+
+    println!("{}", table);
+    println!("----------------");
+}
@@ -0,0 +1,150 @@
+//! Findings rolled up per directory
+//!
+//! Counts the files, findings and severities of every directory down to a configurable
+//! depth below the common root of the analyzed files. The roll-up is exported as
+//! `by_directory` in `findings.json`, where it feeds module heatmaps, and printed as a tree
+//! with `--tree`.
+
+use crate::FileAnalysisResult;
+use crate::rules_registry::PROJECT_FILE;
+use oxc_diagnostics::Severity;
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, HashMap};
+use tabled::{
+    builder::Builder,
+    settings::{Alignment, Style, object::Columns},
+};
+
+/// Directory levels below the root rolled up, unless `directory_depth` is configured
+pub const DEFAULT_DIRECTORY_DEPTH: usize = 2;
+
+/// Findings of a directory and everything below it
+#[derive(Serialize, Deserialize, Debug, Clone, Default, PartialEq)]
+pub struct DirectorySummary {
+    /// Path relative to the root of the analyzed files, `.` for the root itself
+    pub path: String,
+    /// Number of directories between the root and this directory
+    pub depth: usize,
+    pub files: usize,
+    pub findings: usize,
+    pub findings_by_severity: HashMap<String, usize>,
+}
+
+/// Get the directory containing all analyzed files
+fn common_root(paths: &[&str]) -> Vec<String> {
+    let mut root: Option<Vec<&str>> = None;
+    for path in paths {
+        let directories: Vec<&str> = directories_of(path);
+        root = Some(match root {
+            None => directories,
+            Some(root) => root
+                .iter()
+                .zip(&directories)
+                .take_while(|(a, b)| a == b)
+                .map(|(a, _)| *a)
+                .collect(),
+        });
+    }
+    root.unwrap_or_default()
+        .into_iter()
+        .map(str::to_string)
+        .collect()
+}
+
+/// Get the directory components of a file path
+fn directories_of(path: &str) -> Vec<&str> {
+    let mut parts: Vec<&str> = path.split(['/', '\\']).collect();
+    parts.pop();
+    parts
+}
+
+/// Roll up the findings of all files per directory, sorted by path
+pub fn build_directory_summary(
+    results: &[FileAnalysisResult],
+    max_depth: usize,
+) -> Vec<DirectorySummary> {
+    let results: Vec<&FileAnalysisResult> = results
+        .iter()
+        .filter(|result| result.file_path != PROJECT_FILE)
+        .collect();
+    let paths: Vec<&str> = results.iter().map(|r| r.file_path.as_str()).collect();
+    let root = common_root(&paths);
+
+    // Keyed by path components, so subdirectories follow their parent
+    let mut directories: BTreeMap<Vec<&str>, DirectorySummary> = BTreeMap::new();
+    for result in results {
+        let relative: Vec<&str> = directories_of(&result.file_path)
+            .into_iter()
+            .skip(root.len())
+            .collect();
+
+        for depth in 0..=relative.len().min(max_depth) {
+            let entry = directories
+                .entry(relative[..depth].to_vec())
+                .or_insert_with(|| DirectorySummary {
+                    path: match depth {
+                        0 => ".".to_string(),
+                        _ => relative[..depth].join("/"),
+                    },
+                    depth,
+                    ..Default::default()
+                });
+
+            entry.files += 1;
+            entry.findings += result.diagnostics.len();
+            for diagnostic in &result.diagnostics {
+                let severity = match diagnostic.diagnostic.severity {
+                    Severity::Error => "error",
+                    Severity::Warning => "warning",
+                    _ => "info",
+                };
+                *entry
+                    .findings_by_severity
+                    .entry(severity.to_string())
+                    .or_insert(0) += 1;
+            }
+        }
+    }
+
+    directories.into_values().collect()
+}
+
+/// Print the directory roll-up as an indented tree
+pub fn print_directory_tree(directories: &[DirectorySummary]) {
+    println!("\nFindings by directory:");
+    println!("----------------");
+
+    let mut builder = Builder::new();
+    builder.push_record(["Directory", "Files", "Findings", "Errors", "Warnings"]);
+
+    for directory in directories {
+        let name = match directory.path.rsplit_once('/') {
+            Some((_, name)) => name,
+            None => directory.path.as_str(),
+        };
+        let count = |severity: &str| {
+            directory
+                .findings_by_severity
+                .get(severity)
+                .copied()
+                .unwrap_or(0)
+                .to_string()
+        };
+
+        builder.push_record([
+            format!("{}{}", "  ".repeat(directory.depth), name),
+            directory.files.to_string(),
+            directory.findings.to_string(),
+            count("error"),
+            count("warning"),
+        ]);
+    }
+
+    let mut table = builder.build();
+    table
+        .with(Style::ascii_rounded())
+        .modify(Columns::new(1..), Alignment::right());
+
+    println!("{}", table);
+    println!("----------------");
+}
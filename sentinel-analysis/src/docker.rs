@@ -0,0 +1,157 @@
+//! Single-shot mode for containers
+//!
+//! `scoper docker` analyzes the project mounted at `/workspace` and writes all reports to
+//! `/out`. Configuration comes from `/workspace/sentinel.json` (or `SENTINEL_CONFIG`) and
+//! `SENTINEL_*` environment variables. Every path the analyzer writes to is resolved
+//! inside `/out`, so a read-only workspace works and nothing is written to the working
+//! directory of the container.
+//!
+//! The exit code reflects the findings: `0` if there is none at or above the `fail_on`
+//! severity, `1` if there is or a quality gate failed, and `2` if the setup is invalid.
+
+use crate::FileAnalysisResult;
+use crate::cache::DEFAULT_CACHE_PATH;
+use crate::utilities::config::Config;
+use oxc_diagnostics::Severity;
+use std::env;
+use std::path::{Component, Path, PathBuf};
+
+/// Mount point of the project to analyze
+pub const WORKSPACE_DIR: &str = "/workspace";
+/// Mount point the reports are written to
+pub const OUT_DIR: &str = "/out";
+
+/// Exit code if there are no findings at or above the `fail_on` severity
+pub const EXIT_OK: i32 = 0;
+/// Exit code if there are findings at or above the `fail_on` severity
+pub const EXIT_FINDINGS: i32 = 1;
+/// Exit code if the mounts or the configuration are invalid
+pub const EXIT_INVALID_SETUP: i32 = 2;
+
+/// Directories of a container run
+#[derive(Debug, Clone)]
+pub struct DockerLayout {
+    pub workspace: PathBuf,
+    pub out: PathBuf,
+}
+
+impl DockerLayout {
+    /// Get the layout from `SENTINEL_WORKSPACE` and `SENTINEL_OUT`, defaulting to the
+    /// bind-mount conventions
+    pub fn from_env() -> Self {
+        Self {
+            workspace: env::var("SENTINEL_WORKSPACE")
+                .map_or_else(|_| PathBuf::from(WORKSPACE_DIR), PathBuf::from),
+            out: env::var("SENTINEL_OUT").map_or_else(|_| PathBuf::from(OUT_DIR), PathBuf::from),
+        }
+    }
+
+    /// Load the configuration of the workspace
+    ///
+    /// `SENTINEL_CONFIG` takes precedence over `sentinel.json` in the workspace; the
+    /// `SENTINEL_*` environment variables override both.
+    pub fn load_config(&self) -> Result<Config, String> {
+        let mut config = match env::var("SENTINEL_CONFIG") {
+            Ok(_) => Config::load(),
+            Err(_) => {
+                let workspace_config = self.workspace.join("sentinel.json");
+                Config::try_load_from_path(&workspace_config.to_string_lossy()).unwrap_or_default()
+            }
+        };
+        config.apply_env()?;
+        self.apply(&mut config)?;
+        Ok(config)
+    }
+
+    /// Point the input and all outputs of a configuration to the mounts
+    ///
+    /// Relative output paths are resolved inside the output directory; absolute paths
+    /// outside of it are rejected. Relative rule configurations are resolved inside the
+    /// workspace.
+    pub fn apply(&self, config: &mut Config) -> Result<(), String> {
+        if !self.workspace.is_dir() {
+            return Err(format!(
+                "Workspace {} does not exist, mount the project with -v $PWD:{}",
+                self.workspace.display(),
+                WORKSPACE_DIR
+            ));
+        }
+
+        config.path = Some(self.workspace.to_string_lossy().to_string());
+        config.output_dir = Some(self.out.to_string_lossy().to_string());
+
+        if let Some(rules_config) = &config.rules_config {
+            config.rules_config = Some(
+                self.workspace
+                    .join(rules_config)
+                    .to_string_lossy()
+                    .to_string(),
+            );
+        }
+
+        for path in [
+            &mut config.export_metrics_json,
+            &mut config.export_metrics_csv,
+        ] {
+            if let Some(value) = path.as_deref() {
+                *path = Some(self.output_path(value)?);
+            }
+        }
+        if let Some(path_base) = &config.path_base {
+            config.path_base = Some(self.workspace.join(path_base).to_string_lossy().to_string());
+        }
+        for output in config.outputs.iter_mut().flatten() {
+            if let Some(path) = output.path.as_deref() {
+                output.path = Some(self.output_path(path)?);
+            }
+        }
+        let cache_path = config.cache_path.as_deref().unwrap_or(DEFAULT_CACHE_PATH);
+        config.cache_path = Some(self.output_path(cache_path)?);
+
+        Ok(())
+    }
+
+    /// Resolve a path the analyzer writes to inside the output directory
+    pub fn output_path(&self, path: &str) -> Result<String, String> {
+        let candidate = Path::new(path);
+        let resolved = if candidate.is_absolute() {
+            candidate.to_path_buf()
+        } else {
+            self.out.join(candidate)
+        };
+
+        let escapes = resolved
+            .components()
+            .any(|component| component == Component::ParentDir);
+        if escapes || !resolved.starts_with(&self.out) {
+            return Err(format!(
+                "Output path {} is outside of {}, use a path relative to the output directory",
+                path,
+                self.out.display()
+            ));
+        }
+        Ok(resolved.to_string_lossy().to_string())
+    }
+}
+
+/// Get the exit code of a run from its findings and the `fail_on` policy
+///
+/// `fail_on` is `error` (default), `warning` or `never`.
+pub fn exit_code(results: &[FileAnalysisResult], fail_on: Option<&str>) -> i32 {
+    let failing = |severity: Severity| match fail_on.unwrap_or("error") {
+        "never" => false,
+        "warning" => matches!(severity, Severity::Error | Severity::Warning),
+        _ => severity == Severity::Error,
+    };
+
+    let has_failing_findings = results
+        .iter()
+        .flat_map(|result| &result.diagnostics)
+        .any(|diagnostic| failing(diagnostic.diagnostic.severity));
+
+    if has_failing_findings {
+        EXIT_FINDINGS
+    } else {
+        EXIT_OK
+    }
+}
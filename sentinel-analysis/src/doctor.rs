@@ -0,0 +1,206 @@
+//! Self-diagnostics of the setup
+//!
+//! `scoper doctor` checks everything an analysis run depends on and prints a pass/fail
+//! table, instead of failing on the first problem during a run: the configuration, the
+//! rules configuration and rules, the parser, the writability of the cache and output
+//! directories, and the availability of git for `--churn`.
+
+use crate::cache::DEFAULT_CACHE_PATH;
+use crate::rules::presets::expand_preset;
+use crate::rules_registry::setup_rules_registry;
+use crate::schema::check_rules_file;
+use crate::utilities::DebugLevel;
+use crate::utilities::config::{Config, get_column_unit, get_outputs};
+use oxc_allocator::Allocator;
+use oxc_parser::Parser;
+use oxc_semantic::SemanticBuilder;
+use oxc_span::SourceType;
+use std::env;
+use std::fs;
+use std::path::Path;
+use std::process::Command;
+use tabled::{builder::Builder, settings::Style};
+
+/// Snippet the parser has to turn into a valid AST
+const SAMPLE_SOURCE: &str = "export const answer: number = 42;\n";
+
+/// Outcome of a check
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum CheckStatus {
+    Pass,
+    /// The run works, but an optional feature does not
+    Warn,
+    Fail,
+}
+
+impl CheckStatus {
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            Self::Pass => "pass",
+            Self::Warn => "warn",
+            Self::Fail => "FAIL",
+        }
+    }
+}
+
+/// Result of one check
+#[derive(Debug, Clone)]
+pub struct Check {
+    pub name: &'static str,
+    pub status: CheckStatus,
+    pub details: String,
+}
+
+impl Check {
+    fn new(name: &'static str, result: Result<String, String>) -> Self {
+        match result {
+            Ok(details) => Self {
+                name,
+                status: CheckStatus::Pass,
+                details,
+            },
+            Err(details) => Self {
+                name,
+                status: CheckStatus::Fail,
+                details,
+            },
+        }
+    }
+}
+
+/// Check that the configuration file parses and its settings are valid
+fn check_config(config: &Config, args: &[String]) -> Result<String, String> {
+    let path = env::var("SENTINEL_CONFIG").unwrap_or_else(|_| "sentinel.json".to_string());
+    let source = match fs::read_to_string(&path) {
+        Ok(content) => {
+            serde_json::from_str::<Config>(&content)
+                .map_err(|e| format!("Invalid {}: {}", path, e))?;
+            path
+        }
+        Err(_) => "defaults, no sentinel.json found".to_string(),
+    };
+
+    get_column_unit(config)?;
+    get_outputs(config, args)?;
+    if let Some(preset) = &config.preset {
+        expand_preset(preset)?;
+    }
+    if let Some(fail_on) = config.fail_on.as_deref() {
+        if !matches!(fail_on, "error" | "warning" | "never") {
+            return Err(format!(
+                "Unknown fail_on {}, expected error, warning or never",
+                fail_on
+            ));
+        }
+    }
+    Ok(source)
+}
+
+/// Check that the rules configuration exists and is written for this schema version
+fn check_rules_config(config: &Config) -> Result<String, String> {
+    let Some(path) = &config.rules_config else {
+        return Ok("none, using the default rules".to_string());
+    };
+    let content = fs::read_to_string(path).map_err(|e| format!("Cannot read {}: {}", path, e))?;
+    serde_json::from_str::<serde_json::Value>(&content)
+        .map_err(|e| format!("Invalid {}: {}", path, e))?;
+    check_rules_file(path)?;
+    Ok(path.clone())
+}
+
+/// Check that rules are registered and enabled
+fn check_rules(config: &Config, args: &[String]) -> Result<String, String> {
+    let registry = setup_rules_registry(config, args, DebugLevel::None);
+    let enabled = registry.get_enabled_rules().len();
+    match enabled {
+        0 => Err("No rules enabled, check the rules configuration and selectors".to_string()),
+        _ => Ok(format!(
+            "{} of {} rules enabled",
+            enabled,
+            registry.get_registered_rules().len()
+        )),
+    }
+}
+
+/// Check that the parser returns a valid AST for a sample snippet
+fn check_parser() -> Result<String, String> {
+    let allocator = Allocator::default();
+    let source_type = SourceType::from_path(Path::new("sample.ts"))
+        .map_err(|_| "TypeScript is not supported".to_string())?;
+    let parsed = Parser::new(&allocator, SAMPLE_SOURCE, source_type).parse();
+    if let Some(error) = parsed.errors.first() {
+        return Err(format!("Failed to parse the sample snippet: {}", error));
+    }
+    if parsed.program.body.is_empty() {
+        return Err("The AST of the sample snippet is empty".to_string());
+    }
+    let semantic = SemanticBuilder::new().build(&parsed.program);
+    if let Some(error) = semantic.errors.first() {
+        return Err(format!("Semantic analysis of the sample snippet failed: {}", error));
+    }
+    Ok(format!(
+        "parsed sample snippet, {} nodes",
+        semantic.semantic.nodes().len()
+    ))
+}
+
+/// Check that files can be created in a directory
+fn check_writable(dir: &Path) -> Result<String, String> {
+    fs::create_dir_all(dir).map_err(|e| format!("Cannot create {}: {}", dir.display(), e))?;
+    let probe = dir.join(".sentinel-doctor");
+    fs::write(&probe, b"").map_err(|e| format!("Cannot write to {}: {}", dir.display(), e))?;
+    let _ = fs::remove_file(&probe);
+    Ok(format!("{} is writable", dir.display()))
+}
+
+/// Check that git can be run, which `--churn` needs
+fn check_git() -> Check {
+    let output = Command::new("git").arg("--version").output();
+    match output {
+        Ok(output) if output.status.success() => Check {
+            name: "git",
+            status: CheckStatus::Pass,
+            details: String::from_utf8_lossy(&output.stdout).trim().to_string(),
+        },
+        _ => Check {
+            name: "git",
+            status: CheckStatus::Warn,
+            details: "git not found, hotspots are ranked without churn".to_string(),
+        },
+    }
+}
+
+/// Run all checks
+pub fn run_checks(config: &Config, args: &[String]) -> Vec<Check> {
+    let cache_path = config.cache_path.as_deref().unwrap_or(DEFAULT_CACHE_PATH);
+    let cache_dir = Path::new(cache_path).parent().unwrap_or(Path::new("."));
+    let output_dir = config.output_dir.as_deref().unwrap_or("findings");
+
+    vec![
+        Check::new("configuration", check_config(config, args)),
+        Check::new("rules configuration", check_rules_config(config)),
+        Check::new("rules", check_rules(config, args)),
+        Check::new("parser", check_parser()),
+        Check::new("cache directory", check_writable(cache_dir)),
+        Check::new("output directory", check_writable(Path::new(output_dir))),
+        check_git(),
+    ]
+}
+
+/// Print the checks as a table
+pub fn print_checks(checks: &[Check]) {
+    println!("\nSetup checks:");
+    println!("----------------");
+
+    let mut builder = Builder::new();
+    builder.push_record(["Check", "Status", "Details"]);
+    for check in checks {
+        builder.push_record([check.name, check.status.as_str(), check.details.as_str()]);
+    }
+
+    let mut table = builder.build();
+    table.with(Style::ascii_rounded());
+
+    println!("{}", table);
+    println!("----------------");
+}
@@ -0,0 +1,95 @@
+//! Links that open the location of a finding in an editor
+//!
+//! With `"editor": "vscode"` in `sentinel.json` (or `--editor vscode`) every finding in the
+//! reports carries an `editor_url`, so templates can render findings as links that open the
+//! exact file, line and column. Besides the built-in editors, the value can be a URL
+//! template with these placeholders:
+//!
+//! - `{path}`: absolute path of the file
+//! - `{relpath}`: path of the file as reported, relative to the path base
+//! - `{line}` and `{column}`: 1-based position of the finding
+//!
+//! ```json
+//! "editor": "jetbrains"
+//! "editor": "zed://file/{path}:{line}:{column}"
+//! ```
+
+use crate::utilities::paths::PathBase;
+
+/// URL templates of the built-in editors
+pub const EDITOR_PRESETS: &[(&str, &str)] = &[
+    ("vscode", "vscode://file/{path}:{line}:{column}"),
+    (
+        "vscode-insiders",
+        "vscode-insiders://file/{path}:{line}:{column}",
+    ),
+    ("cursor", "cursor://file/{path}:{line}:{column}"),
+    (
+        "jetbrains",
+        "idea://open?file={path}&line={line}&column={column}",
+    ),
+    (
+        "sublime",
+        "subl://open?url=file://{path}&line={line}&column={column}",
+    ),
+    (
+        "textmate",
+        "txmt://open?url=file://{path}&line={line}&column={column}",
+    ),
+];
+
+/// Builds editor links of findings from a URL template
+#[derive(Debug, Clone)]
+pub struct EditorLinks {
+    template: String,
+    path_base: PathBase,
+}
+
+impl EditorLinks {
+    /// Create links for an editor name or URL template
+    ///
+    /// Reported paths are resolved against the path base.
+    pub fn new(editor: &str, path_base: PathBase) -> Result<Self, String> {
+        let template = match EDITOR_PRESETS.iter().find(|(name, _)| *name == editor) {
+            Some((_, template)) => template.to_string(),
+            None if editor.contains("{path}") || editor.contains("{relpath}") => editor.to_string(),
+            None => {
+                let names: Vec<&str> = EDITOR_PRESETS.iter().map(|(name, _)| *name).collect();
+                return Err(format!(
+                    "Unknown editor '{}', expected one of {} or a URL template with {{path}}",
+                    editor,
+                    names.join(", ")
+                ));
+            }
+        };
+        Ok(Self {
+            template,
+            path_base,
+        })
+    }
+
+    /// Get the link opening a location, with the file as reported
+    pub fn link(&self, file: &str, line: usize, column: usize) -> String {
+        let absolute = self.path_base.resolve(file);
+        let absolute = absolute.to_string_lossy().replace('\\', "/");
+        self.template
+            .replace("{path}", &encode_path(&absolute))
+            .replace("{relpath}", &encode_path(file))
+            .replace("{line}", &line.to_string())
+            .replace("{column}", &column.to_string())
+    }
+}
+
+/// Percent-encode the characters of a path that are not allowed in a URL
+fn encode_path(path: &str) -> String {
+    let mut encoded = String::with_capacity(path.len());
+    for byte in path.bytes() {
+        match byte {
+            b'A'..=b'Z' | b'a'..=b'z' | b'0'..=b'9' | b'/' | b'-' | b'_' | b'.' | b'~' | b':' => {
+                encoded.push(byte as char)
+            }
+            _ => encoded.push_str(&format!("%{:02X}", byte)),
+        }
+    }
+    encoded
+}
@@ -0,0 +1,447 @@
+//! Embedding-based semantic index over the analyzed code
+//!
+//! The chunks produced by the chunker are embedded by an `EmbeddingProvider` and stored
+//! with their location in `embeddings.json` in the output directory. `sentinel search`
+//! embeds a query with the same provider and ranks the chunks by cosine similarity.
+//!
+//! Providers:
+//! - `hashing` (default): offline feature hashing of identifiers and their sub-words,
+//!   no network access or model download required
+//! - `openai`: the OpenAI embeddings API
+//! - `local`: any OpenAI-compatible embeddings endpoint, e.g. a local ONNX model server
+
+use crate::chunker::Chunk;
+use crate::utilities::config::{Config, EmbeddingsConfig};
+use crate::utilities::{DebugLevel, log};
+use reqwest::blocking::Client;
+use serde::{Deserialize, Serialize};
+use serde_json::{Value, json};
+use std::fs;
+use tabled::{
+    builder::Builder,
+    settings::{Alignment, Style, object::Columns},
+};
+
+/// Dimensions of the vectors produced by the hashing provider
+const HASHING_DIMENSIONS: usize = 512;
+/// Number of chunks sent to a remote provider per request
+const REMOTE_BATCH_SIZE: usize = 64;
+/// Maximum number of characters of a chunk sent to a remote provider
+const REMOTE_MAX_CHARS: usize = 8000;
+
+const OPENAI_EMBEDDINGS_URL: &str = "https://api.openai.com/v1/embeddings";
+const OPENAI_DEFAULT_MODEL: &str = "text-embedding-3-small";
+const LOCAL_EMBEDDINGS_URL: &str = "http://localhost:8080/v1/embeddings";
+
+/// Turns texts into vectors
+pub trait EmbeddingProvider: Send + Sync {
+    /// Get the name of the provider, stored in the index
+    fn name(&self) -> &str;
+
+    /// Get the model used by the provider, stored in the index
+    fn model(&self) -> &str;
+
+    /// Embed a batch of texts, returning one vector per text
+    fn embed(&self, texts: &[String]) -> Result<Vec<Vec<f32>>, String>;
+}
+
+/// Offline provider that hashes identifiers and their sub-words into a fixed-size vector
+pub struct HashingProvider;
+
+impl HashingProvider {
+    /// Split code or a query into lowercase terms; identifiers are also split into
+    /// their camelCase and snake_case parts, so `getUserName` matches "user name"
+    fn terms(text: &str) -> Vec<String> {
+        let mut terms = Vec::new();
+
+        for word in text
+            .split(|c: char| !c.is_alphanumeric() && c != '_' && c != '$')
+            .filter(|word| word.len() > 1)
+        {
+            terms.push(word.to_lowercase());
+
+            let mut part = String::new();
+            let mut parts = Vec::new();
+            let mut previous_lowercase = false;
+            for c in word.chars() {
+                if (c.is_uppercase() && previous_lowercase) || c == '_' || c == '$' {
+                    parts.push(std::mem::take(&mut part));
+                }
+                if c != '_' && c != '$' {
+                    part.push(c);
+                }
+                previous_lowercase = c.is_lowercase() || c.is_ascii_digit();
+            }
+            parts.push(part);
+
+            if parts.len() > 1 {
+                terms.extend(
+                    parts
+                        .into_iter()
+                        .filter(|part| part.len() > 1)
+                        .map(|part| part.to_lowercase()),
+                );
+            }
+        }
+
+        terms
+    }
+
+    /// FNV-1a hash of a term
+    fn hash(term: &str) -> u64 {
+        term.bytes().fold(0xcbf29ce484222325, |hash, byte| {
+            (hash ^ byte as u64).wrapping_mul(0x100000001b3)
+        })
+    }
+}
+
+impl EmbeddingProvider for HashingProvider {
+    fn name(&self) -> &str {
+        "hashing"
+    }
+
+    fn model(&self) -> &str {
+        "fnv-512"
+    }
+
+    fn embed(&self, texts: &[String]) -> Result<Vec<Vec<f32>>, String> {
+        Ok(texts
+            .iter()
+            .map(|text| {
+                let mut vector = vec![0.0f32; HASHING_DIMENSIONS];
+                for term in Self::terms(text) {
+                    let hash = Self::hash(&term);
+                    let index = (hash % HASHING_DIMENSIONS as u64) as usize;
+                    // The sign bit spreads collisions so they cancel out on average
+                    let sign = if hash >> 63 == 0 { 1.0 } else { -1.0 };
+                    vector[index] += sign;
+                }
+                normalize(&mut vector);
+                vector
+            })
+            .collect())
+    }
+}
+
+/// Provider for the OpenAI embeddings API and compatible endpoints
+pub struct HttpProvider {
+    name: String,
+    model: String,
+    url: String,
+    api_key: Option<String>,
+    client: Client,
+}
+
+impl HttpProvider {
+    pub fn new(name: &str, model: String, url: String, api_key: Option<String>) -> Self {
+        Self {
+            name: name.to_string(),
+            model,
+            url,
+            api_key,
+            client: Client::new(),
+        }
+    }
+
+    fn embed_batch(&self, texts: &[String]) -> Result<Vec<Vec<f32>>, String> {
+        let input: Vec<String> = texts
+            .iter()
+            .map(|text| text.chars().take(REMOTE_MAX_CHARS).collect())
+            .collect();
+
+        let mut request = self
+            .client
+            .post(&self.url)
+            .json(&json!({ "model": self.model, "input": input }));
+        if let Some(api_key) = &self.api_key {
+            request = request.bearer_auth(api_key);
+        }
+
+        let response = request
+            .send()
+            .map_err(|e| format!("Embedding request to {} failed: {}", self.url, e))?;
+        let status = response.status();
+        let body: Value = response
+            .json()
+            .map_err(|e| format!("Invalid embedding response from {}: {}", self.url, e))?;
+        if !status.is_success() {
+            return Err(format!(
+                "Embedding request to {} failed with status {}: {}",
+                self.url, status, body
+            ));
+        }
+
+        let data = body
+            .get("data")
+            .and_then(Value::as_array)
+            .ok_or_else(|| format!("Embedding response from {} has no data", self.url))?;
+
+        data.iter()
+            .map(|item| {
+                item.get("embedding")
+                    .and_then(Value::as_array)
+                    .map(|values| {
+                        let mut vector: Vec<f32> = values
+                            .iter()
+                            .filter_map(Value::as_f64)
+                            .map(|v| v as f32)
+                            .collect();
+                        normalize(&mut vector);
+                        vector
+                    })
+                    .ok_or_else(|| "Embedding response item has no embedding".to_string())
+            })
+            .collect()
+    }
+}
+
+impl EmbeddingProvider for HttpProvider {
+    fn name(&self) -> &str {
+        &self.name
+    }
+
+    fn model(&self) -> &str {
+        &self.model
+    }
+
+    fn embed(&self, texts: &[String]) -> Result<Vec<Vec<f32>>, String> {
+        let mut vectors = Vec::with_capacity(texts.len());
+        for batch in texts.chunks(REMOTE_BATCH_SIZE) {
+            vectors.extend(self.embed_batch(batch)?);
+        }
+        Ok(vectors)
+    }
+}
+
+/// Create the provider configured in `sentinel.json`
+pub fn create_provider(config: &EmbeddingsConfig) -> Result<Box<dyn EmbeddingProvider>, String> {
+    match config.provider.as_deref().unwrap_or("hashing") {
+        "hashing" => Ok(Box::new(HashingProvider)),
+        "openai" => {
+            let api_key = config
+                .api_key
+                .clone()
+                .or_else(|| std::env::var("OPENAI_API_KEY").ok())
+                .ok_or_else(|| {
+                    "The openai embedding provider needs an api_key or OPENAI_API_KEY".to_string()
+                })?;
+            Ok(Box::new(HttpProvider::new(
+                "openai",
+                config
+                    .model
+                    .clone()
+                    .unwrap_or_else(|| OPENAI_DEFAULT_MODEL.to_string()),
+                config
+                    .url
+                    .clone()
+                    .unwrap_or_else(|| OPENAI_EMBEDDINGS_URL.to_string()),
+                Some(api_key),
+            )))
+        }
+        "local" => Ok(Box::new(HttpProvider::new(
+            "local",
+            config.model.clone().unwrap_or_default(),
+            config
+                .url
+                .clone()
+                .unwrap_or_else(|| LOCAL_EMBEDDINGS_URL.to_string()),
+            config.api_key.clone(),
+        ))),
+        other => Err(format!(
+            "Unknown embedding provider '{}', expected hashing, openai or local",
+            other
+        )),
+    }
+}
+
+/// Scale a vector to unit length, so the dot product is the cosine similarity
+fn normalize(vector: &mut [f32]) {
+    let norm = vector.iter().map(|v| v * v).sum::<f32>().sqrt();
+    if norm > 0.0 {
+        vector.iter_mut().for_each(|v| *v /= norm);
+    }
+}
+
+fn dot(a: &[f32], b: &[f32]) -> f32 {
+    a.iter().zip(b).map(|(x, y)| x * y).sum()
+}
+
+/// A chunk in the index
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct IndexEntry {
+    pub id: String,
+    pub file: String,
+    pub kind: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub name: Option<String>,
+    pub start_line: usize,
+    pub end_line: usize,
+    pub vector: Vec<f32>,
+}
+
+/// Embedded chunks of the analyzed code base
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct EmbeddingIndex {
+    pub provider: String,
+    pub model: String,
+    pub entries: Vec<IndexEntry>,
+}
+
+/// A chunk matching a search query
+#[derive(Debug, Clone)]
+pub struct SearchResult<'i> {
+    pub entry: &'i IndexEntry,
+    pub score: f32,
+}
+
+impl EmbeddingIndex {
+    /// Embed the chunks with the given provider
+    pub fn build(chunks: &[Chunk], provider: &dyn EmbeddingProvider) -> Result<Self, String> {
+        let texts: Vec<String> = chunks.iter().map(|chunk| chunk.code.clone()).collect();
+        let vectors = provider.embed(&texts)?;
+        if vectors.len() != chunks.len() {
+            return Err(format!(
+                "Embedding provider returned {} vectors for {} chunks",
+                vectors.len(),
+                chunks.len()
+            ));
+        }
+
+        Ok(Self {
+            provider: provider.name().to_string(),
+            model: provider.model().to_string(),
+            entries: chunks
+                .iter()
+                .zip(vectors)
+                .map(|(chunk, vector)| IndexEntry {
+                    id: chunk.id.clone(),
+                    file: chunk.file.clone(),
+                    kind: chunk.kind.clone(),
+                    name: chunk.name.clone(),
+                    start_line: chunk.start_line,
+                    end_line: chunk.end_line,
+                    vector,
+                })
+                .collect(),
+        })
+    }
+
+    /// Load an index written by `save`
+    pub fn load(path: &str) -> Result<Self, String> {
+        let content =
+            fs::read_to_string(path).map_err(|e| format!("Failed to read {}: {}", path, e))?;
+        serde_json::from_str(&content).map_err(|e| format!("Failed to parse {}: {}", path, e))
+    }
+
+    pub fn save(&self, path: &str) -> Result<(), String> {
+        let json = serde_json::to_string(self)
+            .map_err(|e| format!("Failed to serialize embedding index: {}", e))?;
+        fs::write(path, json).map_err(|e| format!("Failed to write {}: {}", path, e))
+    }
+
+    /// Rank the chunks by their similarity to an embedded query
+    pub fn search(&self, query: &[f32], limit: usize) -> Vec<SearchResult<'_>> {
+        let mut results: Vec<SearchResult> = self
+            .entries
+            .iter()
+            .filter(|entry| entry.vector.len() == query.len())
+            .map(|entry| SearchResult {
+                entry,
+                score: dot(&entry.vector, query),
+            })
+            .collect();
+        results.sort_by(|a, b| b.score.total_cmp(&a.score));
+        results.truncate(limit);
+        results
+    }
+}
+
+fn index_path(output_dir: &str) -> String {
+    format!("{}/embeddings.json", output_dir)
+}
+
+/// Embed the chunks and write the index to embeddings.json
+pub fn export_embeddings(
+    chunks: &[Chunk],
+    config: &Config,
+    debug_level: DebugLevel,
+    output_dir: &String,
+) {
+    let embeddings_config = config.embeddings.clone().unwrap_or_default();
+    let result = create_provider(&embeddings_config)
+        .and_then(|provider| EmbeddingIndex::build(chunks, provider.as_ref()))
+        .and_then(|index| {
+            fs::create_dir_all(output_dir)
+                .map_err(|e| format!("Failed to create output directory {}: {}", output_dir, e))?;
+            let path = index_path(output_dir);
+            index.save(&path)?;
+            Ok((index, path))
+        });
+
+    match result {
+        Ok((index, path)) => log(
+            DebugLevel::Info,
+            debug_level,
+            &format!(
+                "Indexed {} chunks with the {} embedding provider to {}",
+                index.entries.len(),
+                index.provider,
+                path
+            ),
+        ),
+        Err(err) => log(DebugLevel::Error, debug_level, &err),
+    }
+}
+
+/// Search the index of a previous run and print the best matches
+pub fn run_search(
+    query: &str,
+    limit: usize,
+    config: &Config,
+    output_dir: &str,
+) -> Result<(), String> {
+    let path = index_path(output_dir);
+    let index = EmbeddingIndex::load(&path)
+        .map_err(|e| format!("{} (run the analysis with --embed first)", e))?;
+
+    // The query has to be embedded by the provider that built the index
+    let mut embeddings_config = config.embeddings.clone().unwrap_or_default();
+    embeddings_config.provider = Some(index.provider.clone());
+    if !index.model.is_empty() && index.provider != "hashing" {
+        embeddings_config.model = Some(index.model.clone());
+    }
+    let provider = create_provider(&embeddings_config)?;
+
+    let query_vector = provider
+        .embed(&[query.to_string()])?
+        .pop()
+        .ok_or_else(|| "Embedding provider returned no vector for the query".to_string())?;
+
+    let results = index.search(&query_vector, limit);
+    if results.is_empty() {
+        println!("No matches for \"{}\"", query);
+        return Ok(());
+    }
+
+    let mut builder = Builder::new();
+    builder.push_record(["Score", "Location", "Kind", "Name"]);
+    for result in &results {
+        builder.push_record([
+            format!("{:.3}", result.score),
+            format!(
+                "{}:{}-{}",
+                result.entry.file, result.entry.start_line, result.entry.end_line
+            ),
+            result.entry.kind.clone(),
+            result.entry.name.clone().unwrap_or_default(),
+        ]);
+    }
+
+    let mut table = builder.build();
+    table
+        .with(Style::ascii_rounded())
+        .modify(Columns::single(0), Alignment::right());
+    println!("{}", table);
+
+    Ok(())
+}
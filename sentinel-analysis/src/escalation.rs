@@ -0,0 +1,132 @@
+//! Severity escalation of rules whose findings grow
+//!
+//! A rule listed under `escalation` in `sentinel.json` is raised to a higher severity
+//! when its number of findings grew by more than `threshold` since the previous run in
+//! the same output directory:
+//!
+//! ```json
+//! "escalation": {
+//!   "no-console": { "threshold": 5 },
+//!   "rxjs-subscription-leak": { "threshold": 0, "severity": "error" }
+//! }
+//! ```
+//!
+//! The findings of an escalated rule get the new severity everywhere: in `findings.json`,
+//! in the reports and in the exit code of `docker` runs. Escalations are listed in the
+//! summary as `escalations`. The previous counts are read from `findings.json` before it
+//! is overwritten, so the first run never escalates.
+
+use crate::FileAnalysisResult;
+use crate::utilities::config::EscalationPolicy;
+use oxc_diagnostics::Severity;
+use serde::{Deserialize, Serialize};
+use serde_json::Value;
+use std::collections::HashMap;
+use std::fs;
+use std::path::Path;
+
+/// A rule raised to a higher severity in this run
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct Escalation {
+    pub rule: String,
+    /// Findings of the previous run
+    pub previous: usize,
+    pub current: usize,
+    pub threshold: usize,
+    /// Severity the findings were raised to
+    pub severity: String,
+}
+
+/// Get the severity an escalation raises findings to, `error` by default
+fn target_severity(policy: &EscalationPolicy) -> Result<Severity, String> {
+    match policy.severity.as_deref().unwrap_or("error") {
+        "error" => Ok(Severity::Error),
+        "warning" | "warn" => Ok(Severity::Warning),
+        other => Err(format!(
+            "Invalid escalation severity {}, expected error or warning",
+            other
+        )),
+    }
+}
+
+/// Order severities from least to most severe
+fn severity_rank(severity: Severity) -> u8 {
+    match severity {
+        Severity::Error => 2,
+        Severity::Warning => 1,
+        _ => 0,
+    }
+}
+
+/// Get the number of findings per rule of the previous run in an output directory
+pub fn previous_counts(output_dir: &str) -> Option<HashMap<String, usize>> {
+    let content = fs::read_to_string(Path::new(output_dir).join("findings.json")).ok()?;
+    let export: Value = serde_json::from_str(&content).ok()?;
+    serde_json::from_value(export.get("summary")?.get("findings_by_rule")?.clone()).ok()
+}
+
+/// Raise the severity of the findings of rules that grew beyond their threshold
+pub fn apply_escalations(
+    results: &mut [FileAnalysisResult],
+    policies: &HashMap<String, EscalationPolicy>,
+    previous: &HashMap<String, usize>,
+) -> Result<Vec<Escalation>, String> {
+    let mut current: HashMap<&str, usize> = HashMap::new();
+    for diagnostic in results.iter().flat_map(|result| &result.diagnostics) {
+        *current.entry(diagnostic.rule_id.as_str()).or_insert(0) += 1;
+    }
+
+    let mut escalated: HashMap<String, Severity> = HashMap::new();
+    let mut escalations = Vec::new();
+    for (rule, policy) in policies {
+        let severity = target_severity(policy)?;
+        let (Some(&previous), Some(&current)) = (previous.get(rule), current.get(rule.as_str()))
+        else {
+            continue;
+        };
+        if current <= previous + policy.threshold {
+            continue;
+        }
+        escalated.insert(rule.clone(), severity);
+        escalations.push(Escalation {
+            rule: rule.clone(),
+            previous,
+            current,
+            threshold: policy.threshold,
+            severity: match severity {
+                Severity::Error => "error".to_string(),
+                _ => "warning".to_string(),
+            },
+        });
+    }
+
+    for diagnostic in results
+        .iter_mut()
+        .flat_map(|result| &mut result.diagnostics)
+    {
+        if let Some(&severity) = escalated.get(&diagnostic.rule_id) {
+            if severity_rank(diagnostic.diagnostic.severity) < severity_rank(severity) {
+                diagnostic.diagnostic = diagnostic.diagnostic.clone().with_severity(severity);
+            }
+        }
+    }
+
+    escalations.sort_by(|a, b| a.rule.cmp(&b.rule));
+    Ok(escalations)
+}
+
+/// Print the escalated rules, so regressions stand out in the summary
+pub fn print_escalations(escalations: &[Escalation]) {
+    println!("Severity escalations: {}", escalations.len());
+    for escalation in escalations {
+        println!(
+            "  {}: {} -> {} findings (threshold +{}), raised to {}",
+            escalation.rule,
+            escalation.previous,
+            escalation.current,
+            escalation.threshold,
+            escalation.severity
+        );
+    }
+    println!();
+}
@@ -1,8 +1,28 @@
-use crate::FileAnalysisResult;
+use crate::ai_suggestions::{AiSuggestion, attach_ai_suggestions};
+use crate::blame::Blame;
+use crate::cache::content_hash;
+use crate::counters::{CounterAlertOutcome, print_counter_alerts, print_counters};
+use crate::directories::{DirectorySummary, build_directory_summary, print_directory_tree};
+use crate::editor_links::EditorLinks;
+use crate::escalation::{Escalation, print_escalations};
+use crate::hotspots::{Hotspot, print_hotspots};
+use crate::limits::{FindingLimits, Truncation, print_truncation, truncate_findings};
+use crate::messages::{Locale, localize};
+use crate::quality_gates::{QualityGateReport, print_quality_gate};
+use crate::rules::TaxonomyMapping;
+use crate::schema::{BuildInfo, SchemaInfo};
+use crate::signal_migration::{
+    SignalMigrationReport, build_signal_migration_report, print_signal_migration_report,
+};
+use crate::utilities::config::AiSuggestionsConfig;
+use crate::utilities::paths::PathBase;
+use crate::utilities::source::{ColumnUnit, diagnostic_span, span_text};
 use crate::utilities::{DebugLevel, log};
+use crate::{FileAnalysisResult, RuleDiagnostic};
 use oxc_diagnostics::Severity;
 use serde::{Deserialize, Serialize};
-use std::collections::HashMap;
+use serde_json::Value;
+use std::collections::{BTreeMap, HashMap};
 use tabled::{
     builder::Builder,
     settings::{Alignment, Style, object::Columns},
@@ -11,20 +31,94 @@ use tabled::{
 /// Structure for JSON export of findings
 #[derive(Serialize, Deserialize)]
 pub struct FindingEntry {
+    /// Stable identifier of the finding, see `finding_fingerprint`
+    #[serde(default)]
+    pub fingerprint: String,
     pub rule: String,
+    /// Version of the rule, to tell findings of a changed rule from changed code
+    #[serde(default)]
+    pub rule_version: String,
+    pub category: String,
+    /// Message in the locale of the export
     pub message: String,
+    /// Catalog key of the message, if the catalog has it, see `messages`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub message_key: Option<String>,
+    /// Values filled into the templates of the message and help
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub message_params: BTreeMap<String, String>,
     pub file: String,
     pub line: usize,
     pub column: usize,
     pub severity: String,
+    /// Help text in the locale of the export
     pub help: Option<String>,
+    /// Catalog key of the help text, if the catalog has it
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub help_key: Option<String>,
+    /// Link to the documentation of the rule
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub docs_url: Option<String>,
+    #[serde(default, skip_serializing_if = "HashMap::is_empty")]
+    pub metadata: HashMap<String, Value>,
+    /// Replacement for the flagged code that fixes the issue, if the rule provides one
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub suggestion: Option<String>,
+    /// Fix suggested by an LLM, only present with --ai-suggestions
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub ai_suggestion: Option<AiSuggestion>,
+    /// Link opening the location in the configured editor, see `editor_links`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub editor_url: Option<String>,
+    /// Who last changed the line, only present with `--blame`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub blame: Option<Blame>,
 }
 
 /// Structure for findings export with summary
 #[derive(Serialize, Deserialize)]
 pub struct FindingsExport {
+    /// Schema version and capabilities of the analyzer that wrote the export
+    pub schema: SchemaInfo,
+    /// Build of the analyzer that wrote the export, to reproduce the run
+    #[serde(default)]
+    pub build: BuildInfo,
+    /// Unit of the `column` of the findings: utf-16, char or byte
+    #[serde(default)]
+    pub column_unit: String,
+    /// Language of the messages of the findings: en or de
+    #[serde(default)]
+    pub locale: String,
     pub findings: Vec<FindingEntry>,
     pub summary: FindingsSummary,
+    /// Files, findings and severities per directory, down to `directory_depth`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub by_directory: Vec<DirectorySummary>,
+    /// Files with the most findings per line, weighted by churn with `--churn`
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub hotspots: Vec<Hotspot>,
+    /// Findings left out of `findings` by `--max-findings` and the per-rule caps
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub truncation: Option<Truncation>,
+    /// Files that could not be read or decoded, with the reason
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub skipped_files: Vec<SkippedFile>,
+    /// Per-component signal migration readiness, if the project has Angular components
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub signal_migration: Option<SignalMigrationReport>,
+    /// Outcome of the `quality_gates`, if configured
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub quality_gate: Option<QualityGateReport>,
+    /// Matches of the count-only rules per counter, which are not findings
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub counters: BTreeMap<String, usize>,
+}
+
+/// A file that could not be analyzed
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct SkippedFile {
+    pub file: String,
+    pub reason: String,
 }
 
 /// Structure for findings summary
@@ -33,7 +127,23 @@ pub struct FindingsSummary {
     // Basic findings info
     pub total_findings: usize,
     pub findings_by_rule: HashMap<String, usize>,
+    pub findings_by_category: HashMap<String, usize>,
     pub findings_by_severity: HashMap<String, usize>,
+    /// Version of each rule with findings
+    #[serde(default)]
+    pub rule_versions: HashMap<String, String>,
+    /// Security standard entries of each enabled rule that declares any
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub rule_taxonomies: BTreeMap<String, Vec<TaxonomyMapping>>,
+    /// Number of generated files, which are not analyzed unless `include_generated` is set
+    #[serde(default)]
+    pub generated_files: usize,
+    /// Rules raised to a higher severity because their findings grew since the last run
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub escalations: Vec<Escalation>,
+    /// Counter alerts whose threshold was exceeded
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub counter_alerts: Vec<CounterAlertOutcome>,
     pub timestamp: String,
 
     // Performance metrics
@@ -65,15 +175,76 @@ fn get_total_duration_ms(metrics: &crate::Metrics) -> u64 {
     scan_ms + analysis_ms
 }
 
-/// Export diagnostics to findings.json
+/// Get a stable identifier of a finding
+///
+/// Hashes the rule, the file, the message and the flagged code, so the fingerprint stays
+/// the same when code above the finding moves it to another line.
+pub fn finding_fingerprint(rule_diagnostic: &RuleDiagnostic, file_path: &str) -> String {
+    let code = diagnostic_span(&rule_diagnostic.diagnostic)
+        .map_or("", |span| span_text(&rule_diagnostic.source_code, span));
+    let key = format!(
+        "{}\0{}\0{}\0{}",
+        rule_diagnostic.rule_id, file_path, rule_diagnostic.diagnostic.message, code
+    );
+    format!("{:016x}", content_hash(key.as_bytes()))
+}
+
+/// Settings and run-level data of an export, besides the results and metrics
+///
+/// New sections of findings.json get a field here, see `export_findings_json`.
+pub struct ExportOptions<'a> {
+    pub output_dir: &'a str,
+    /// Base the relative paths of the results are resolved against, see `PathBase`
+    pub path_base: &'a PathBase,
+    /// Settings of the AI fix suggestions, `None` unless enabled
+    pub ai_suggestions: Option<&'a AiSuggestionsConfig>,
+    /// Depth of the directories in `by_directory`
+    pub directory_depth: usize,
+    /// Whether to print the findings rolled up per directory
+    pub print_tree: bool,
+    pub hotspots: Vec<Hotspot>,
+    pub column_unit: ColumnUnit,
+    pub escalations: Vec<Escalation>,
+    pub limits: FindingLimits,
+    pub editor_links: Option<&'a EditorLinks>,
+    pub quality_gate: Option<QualityGateReport>,
+    pub locale: Locale,
+    pub rule_taxonomies: BTreeMap<String, Vec<TaxonomyMapping>>,
+    pub counters: BTreeMap<String, usize>,
+    /// Breached counter alerts
+    pub counter_alerts: Vec<CounterAlertOutcome>,
+}
+
+/// Export diagnostics to findings.json and get the export for further reports
 pub fn export_findings_json(
     results: &[FileAnalysisResult],
     metrics: &crate::Metrics,
+    options: ExportOptions,
     debug_level: DebugLevel,
-    output_dir: &String,
-) {
+) -> FindingsExport {
+    let ExportOptions {
+        output_dir,
+        path_base,
+        ai_suggestions,
+        directory_depth,
+        print_tree,
+        hotspots,
+        column_unit,
+        escalations,
+        limits,
+        editor_links,
+        quality_gate,
+        locale,
+        rule_taxonomies,
+        counters,
+        counter_alerts,
+    } = options;
     let mut findings: Vec<FindingEntry> = Vec::new();
     let mut rule_counts: HashMap<String, usize> = HashMap::new();
+    let mut rule_categories: HashMap<String, String> = HashMap::new();
+    let mut rule_versions: HashMap<String, String> = HashMap::new();
+    let mut rule_docs: HashMap<String, String> = HashMap::new();
+    let mut category_counts: HashMap<String, usize> = HashMap::new();
     let mut severity_counts: HashMap<String, usize> = HashMap::new();
 
     // Use static string references to avoid repeated allocations
@@ -106,8 +277,21 @@ pub fn export_findings_json(
                 &format!("Using rule ID '{}' for diagnostic: {}", rule_name, message),
             );
 
-            // Count occurrences by rule
+            // Count occurrences by rule and category
+            let category = rule_diagnostic.category.to_string();
             *rule_counts.entry(rule_name.clone()).or_insert(0) += 1;
+            *category_counts.entry(category.clone()).or_insert(0) += 1;
+            rule_categories
+                .entry(rule_name.clone())
+                .or_insert_with(|| category.clone());
+            rule_versions
+                .entry(rule_name.clone())
+                .or_insert_with(|| rule_diagnostic.rule_version.to_string());
+            if let Some(docs_url) = &rule_diagnostic.docs_url {
+                rule_docs
+                    .entry(rule_name.clone())
+                    .or_insert_with(|| docs_url.clone());
+            }
 
             // Get severity - reuse existing strings instead of creating new ones each time
             let severity = match rule_diagnostic.diagnostic.severity {
@@ -119,19 +303,46 @@ pub fn export_findings_json(
             // Count occurrences by severity
             *severity_counts.entry(severity.clone()).or_insert(0) += 1;
 
+            // Messages are exported in the locale, with their keys for other languages
+            let (message, message_ref) = localize(&rule_name, &message, locale);
+            let help = rule_diagnostic
+                .diagnostic
+                .help
+                .as_ref()
+                .map(|help| localize(&rule_name, help, locale));
+            let help_ref = help.as_ref().and_then(|(_, help_ref)| help_ref.as_ref());
+            let mut message_params = BTreeMap::new();
+            for params in message_ref.iter().chain(help_ref).map(|m| &m.params) {
+                message_params.extend(params.clone());
+            }
+
             // Create a basic finding entry
             let finding = FindingEntry {
+                fingerprint: finding_fingerprint(rule_diagnostic, &result.file_path),
                 rule: rule_name.clone(),
+                rule_version: rule_diagnostic.rule_version.to_string(),
+                category,
+                message_key: message_ref.as_ref().map(|m| m.key.to_string()),
+                message_params,
                 message,
                 file: result.file_path.clone(),
                 line: rule_diagnostic.line_number,
                 column: rule_diagnostic.column_number,
                 severity,
-                help: rule_diagnostic
-                    .diagnostic
-                    .help
-                    .as_ref()
-                    .map(|h| h.to_string()),
+                help_key: help_ref.map(|m| m.key.to_string()),
+                help: help.map(|(help, _)| help),
+                docs_url: rule_diagnostic.docs_url.clone(),
+                metadata: rule_diagnostic.metadata.clone(),
+                suggestion: rule_diagnostic.suggestion.clone(),
+                ai_suggestion: None,
+                editor_url: editor_links.map(|links| {
+                    links.link(
+                        &result.file_path,
+                        rule_diagnostic.line_number,
+                        rule_diagnostic.column_number,
+                    )
+                }),
+                blame: rule_diagnostic.blame.clone(),
             };
 
             // Add finding to the flat list
@@ -142,21 +353,28 @@ pub fn export_findings_json(
     // Print rule summary
     println!("\nRule hit summary:");
     println!("----------------");
-    let mut rules: Vec<(&String, &usize)> = rule_counts.iter().collect();
-    rules.sort_by(|a, b| a.0.cmp(b.0)); // Sort by rule name, alphabetically
+    let mut rules: Vec<(&String, &usize, &str)> = rule_counts
+        .iter()
+        .map(|(rule, count)| {
+            let category = rule_categories.get(rule).map_or("", |c| c.as_str());
+            (rule, count, category)
+        })
+        .collect();
+    rules.sort_by(|a, b| a.2.cmp(b.2).then_with(|| a.0.cmp(b.0))); // Group by category, then sort by rule name
 
     // Build table
     let mut builder = Builder::new();
-    builder.push_record(["Rule", "Hits"]);
+    builder.push_record(["Category", "Rule", "Hits", "Docs"]);
 
-    for (rule, count) in rules {
-        builder.push_record([rule.as_str(), &count.to_string()]);
+    for (rule, count, category) in rules {
+        let docs_url = rule_docs.get(rule).map_or("", |url| url.as_str());
+        builder.push_record([category, rule.as_str(), &count.to_string(), docs_url]);
     }
 
     let mut table = builder.build();
     table
         .with(Style::ascii_rounded())
-        .modify(Columns::single(1), Alignment::right()); // Right align the second column (Hits) using 0-based index
+        .modify(Columns::single(2), Alignment::right()); // Right align the third column (Hits) using 0-based index
 
     // Print the table
     println!("{}", table);
@@ -167,6 +385,71 @@ pub fn export_findings_json(
         rule_counts.values().sum::<usize>()
     );
 
+    // Escalated rules are regressions, so they are printed right after the totals
+    if !escalations.is_empty() {
+        print_escalations(&escalations);
+    }
+    if !counter_alerts.is_empty() {
+        print_counter_alerts(&counter_alerts);
+    }
+    if let Some(quality_gate) = &quality_gate {
+        print_quality_gate(quality_gate);
+    }
+
+    // The summary counts every finding, only the list of findings is capped
+    let truncation = truncate_findings(&mut findings, &limits);
+    if let Some(truncation) = &truncation {
+        print_truncation(truncation);
+    }
+
+    // Generated files are counted separately, so codegen output does not hide in the totals
+    let generated_files = results.iter().filter(|result| result.generated).count();
+    if generated_files > 0 {
+        println!("Generated files: {}\n", generated_files);
+    }
+
+    // Unreadable files are listed, so they are not mistaken for files without findings
+    let skipped_files: Vec<SkippedFile> = results
+        .iter()
+        .filter_map(|result| {
+            result.skipped.as_ref().map(|reason| SkippedFile {
+                file: result.file_path.clone(),
+                reason: reason.clone(),
+            })
+        })
+        .collect();
+    if !skipped_files.is_empty() {
+        println!("Skipped files: {}", skipped_files.len());
+        for skipped in &skipped_files {
+            println!("  {}: {}", skipped.file, skipped.reason);
+        }
+        println!();
+    }
+
+    if !hotspots.is_empty() {
+        print_hotspots(&hotspots);
+    }
+    if !counters.is_empty() {
+        print_counters(&counters);
+    }
+
+    // Roll up the findings per directory
+    let by_directory = build_directory_summary(results, directory_depth);
+    if print_tree {
+        print_directory_tree(&by_directory);
+    }
+
+    // Print the signal migration readiness section
+    let signal_migration = build_signal_migration_report(results);
+    if let Some(report) = &signal_migration {
+        print_signal_migration_report(report);
+    }
+
+    // Attach AI-generated fix suggestions for the selected rules
+    if let Some(ai_config) = ai_suggestions {
+        attach_ai_suggestions(&mut findings, ai_config, path_base, debug_level);
+    }
+
     // Get total duration in ms
     let total_duration_ms = get_total_duration_ms(metrics);
 
@@ -218,11 +501,21 @@ pub fn export_findings_json(
 
     // Create findings export structure
     let findings_export = FindingsExport {
+        schema: SchemaInfo::current(),
+        build: BuildInfo::current(),
+        column_unit: column_unit.as_str().to_string(),
+        locale: locale.as_str().to_string(),
         findings,
         summary: FindingsSummary {
             total_findings: rule_counts.values().sum::<usize>(),
             findings_by_rule: rule_counts,
+            findings_by_category: category_counts,
             findings_by_severity: severity_counts,
+            rule_versions,
+            rule_taxonomies,
+            generated_files,
+            escalations,
+            counter_alerts,
             timestamp: chrono::Utc::now().to_rfc3339(),
             total_duration_ms,
             files_processed,
@@ -232,10 +525,27 @@ pub fn export_findings_json(
             scan_duration_ms,
             analysis_duration_ms,
         },
+        by_directory,
+        hotspots,
+        truncation,
+        skipped_files,
+        signal_migration,
+        quality_gate,
+        counters,
     };
 
-    // Save to findings.json
-    if !findings_export.findings.is_empty() {
+    write_findings_json(&findings_export, debug_level, output_dir);
+    findings_export
+}
+
+/// Write an export to findings.json in the output directory
+fn write_findings_json(
+    findings_export: &FindingsExport,
+    debug_level: DebugLevel,
+    output_dir: &str,
+) {
+    // Save to findings.json, also for runs that only counted
+    if !findings_export.findings.is_empty() || !findings_export.counters.is_empty() {
         // Create the output directory if needed
         if let Err(e) = std::fs::create_dir_all(output_dir) {
             log(
@@ -250,7 +560,7 @@ pub fn export_findings_json(
         let file_path = format!("{}/findings.json", output_dir);
 
         // Write findings to JSON
-        let json = match serde_json::to_string_pretty(&findings_export) {
+        let json = match serde_json::to_string_pretty(findings_export) {
             Ok(json) => json,
             Err(e) => {
                 log(
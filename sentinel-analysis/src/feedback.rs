@@ -0,0 +1,190 @@
+//! False-positive feedback for rule maintainers
+//!
+//! `--report-fp <FINGERPRINT>` looks up a finding of the last run in `findings.json` and
+//! records it together with the flagged code and an optional `--comment`. Entries are
+//! appended to `feedback.jsonl` in the output directory, or posted as JSON to the
+//! configured `feedback_url`.
+//!
+//! Findings of the `secrets` category are recorded without the flagged code: the snippet
+//! would carry the plaintext secret that the finding itself only shows redacted, and
+//! context lines like the body of a private key are not matched by any pattern that
+//! could redact them. The snippets of other findings go through the redaction of
+//! `secrets-detection`, in case a secret is among their context lines.
+
+use crate::exporter::FindingEntry;
+use crate::rules::RuleCategory;
+use crate::schema::upgrade_findings;
+use crate::utilities::paths::PathBase;
+use crate::utilities::source::decode_source;
+use crate::utilities::{DebugLevel, log};
+use reqwest::blocking::Client;
+use serde::{Deserialize, Serialize};
+use serde_json::Value;
+use std::fs::{self, OpenOptions};
+use std::io::Write;
+use std::path::Path;
+
+/// Lines around the finding included in the feedback
+const SNIPPET_CONTEXT_LINES: usize = 2;
+
+/// A finding reported as false positive
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct FeedbackEntry {
+    pub fingerprint: String,
+    pub rule: String,
+    pub rule_version: String,
+    pub file: String,
+    pub line: usize,
+    pub message: String,
+    /// The flagged code with a few lines of context, empty if the file cannot be read and
+    /// for findings of secrets
+    pub snippet: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub comment: Option<String>,
+    pub timestamp: String,
+}
+
+/// Find a finding of the last run by its fingerprint
+fn find_finding(output_dir: &str, fingerprint: &str) -> Result<FindingEntry, String> {
+    let findings_path = Path::new(output_dir).join("findings.json");
+    let content = fs::read_to_string(&findings_path).map_err(|e| {
+        format!(
+            "Failed to read {}, run the analysis first: {}",
+            findings_path.display(),
+            e
+        )
+    })?;
+    let export: Value = serde_json::from_str(&content)
+        .map_err(|e| format!("Failed to parse {}: {}", findings_path.display(), e))
+        .and_then(upgrade_findings)
+        .map_err(|e| format!("{}: {}", findings_path.display(), e))?;
+
+    let findings = export
+        .get("findings")
+        .and_then(Value::as_array)
+        .ok_or_else(|| format!("{} contains no findings", findings_path.display()))?;
+    let finding = findings
+        .iter()
+        .find(|finding| finding.get("fingerprint").and_then(Value::as_str) == Some(fingerprint))
+        .ok_or_else(|| {
+            format!(
+                "No finding with fingerprint {} in {}",
+                fingerprint,
+                findings_path.display()
+            )
+        })?;
+
+    serde_json::from_value(finding.clone())
+        .map_err(|e| format!("Invalid finding {}: {}", fingerprint, e))
+}
+
+/// Get the lines around a 1-based line number of a file
+fn read_snippet(file: &Path, line: usize) -> String {
+    let Ok(source) = fs::read(file)
+        .map_err(|e| e.to_string())
+        .and_then(decode_source)
+    else {
+        return String::new();
+    };
+
+    let start = line.saturating_sub(SNIPPET_CONTEXT_LINES + 1);
+    source
+        .content
+        .lines()
+        .skip(start)
+        .take(SNIPPET_CONTEXT_LINES * 2 + 1)
+        .collect::<Vec<_>>()
+        .join("\n")
+}
+
+/// Redact what looks like a secret in a snippet, see `SecretsDetectionRule::redact_text`
+#[cfg(feature = "custom_rules")]
+fn redact_secrets(snippet: &str) -> String {
+    crate::rules::SecretsDetectionRule::new().redact_text(snippet)
+}
+
+#[cfg(not(feature = "custom_rules"))]
+fn redact_secrets(snippet: &str) -> String {
+    snippet.to_string()
+}
+
+/// Build the feedback entry of a finding of the last run
+///
+/// The file of the finding is read relative to `base`, see `PathBase`.
+pub fn build_feedback(
+    output_dir: &str,
+    fingerprint: &str,
+    comment: Option<String>,
+    base: &PathBase,
+) -> Result<FeedbackEntry, String> {
+    let finding = find_finding(output_dir, fingerprint)?;
+    let snippet = if finding.category == RuleCategory::Secrets.as_str() {
+        String::new()
+    } else {
+        redact_secrets(&read_snippet(&base.resolve(&finding.file), finding.line))
+    };
+
+    Ok(FeedbackEntry {
+        fingerprint: finding.fingerprint,
+        snippet,
+        rule: finding.rule,
+        rule_version: finding.rule_version,
+        file: finding.file,
+        line: finding.line,
+        message: finding.message,
+        comment,
+        timestamp: chrono::Utc::now().to_rfc3339(),
+    })
+}
+
+/// Record a finding as false positive, posting it to `feedback_url` if configured
+pub fn report_false_positive(
+    output_dir: &str,
+    fingerprint: &str,
+    comment: Option<String>,
+    feedback_url: Option<&str>,
+    base: &PathBase,
+    debug_level: DebugLevel,
+) -> Result<(), String> {
+    let entry = build_feedback(output_dir, fingerprint, comment, base)?;
+
+    match feedback_url {
+        Some(url) => {
+            let response = Client::new()
+                .post(url)
+                .json(&entry)
+                .send()
+                .map_err(|e| format!("Failed to send feedback to {}: {}", url, e))?;
+            if !response.status().is_success() {
+                return Err(format!(
+                    "Feedback endpoint {} returned {}",
+                    url,
+                    response.status()
+                ));
+            }
+            log(
+                DebugLevel::Info,
+                debug_level,
+                &format!("Sent feedback on {} to {}", entry.rule, url),
+            );
+        }
+        None => {
+            let path = Path::new(output_dir).join("feedback.jsonl");
+            let line = serde_json::to_string(&entry)
+                .map_err(|e| format!("Failed to serialize feedback: {}", e))?;
+            OpenOptions::new()
+                .create(true)
+                .append(true)
+                .open(&path)
+                .and_then(|mut file| writeln!(file, "{}", line))
+                .map_err(|e| format!("Failed to write {}: {}", path.display(), e))?;
+            log(
+                DebugLevel::Info,
+                debug_level,
+                &format!("Recorded feedback on {} in {}", entry.rule, path.display()),
+            );
+        }
+    }
+
+    Ok(())
+}
@@ -0,0 +1,103 @@
+//! Filters slicing the findings of a run
+//!
+//! `--filter-rule`, `--filter-severity` and `--filter-path` keep only the matching
+//! findings, e.g. to look at the security errors of one feature without touching the rules
+//! configuration. Each flag can be repeated or given a comma-separated list; a finding is
+//! kept if it matches one of the values of every given flag. Rules and paths are globs:
+//!
+//! ```bash
+//! scoper ./src --filter-rule 'security-*' --filter-severity error --filter-path 'src/app/checkout/**'
+//! ```
+//!
+//! The filters apply when the results are aggregated, after suppressions and escalations,
+//! so the summary, the reports and the exit code only count the kept findings. Paths are
+//! matched relative to the path base, like the files in `findings.json`.
+
+use crate::FileAnalysisResult;
+use crate::exporter::FindingEntry;
+use crate::utilities::glob::glob_match_any;
+use oxc_diagnostics::Severity;
+
+/// Severities accepted by `--filter-severity`
+pub const FILTER_SEVERITIES: &[&str] = &["error", "warning", "info"];
+
+/// Filters on the findings of a run, none by default
+#[derive(Debug, Clone, Default)]
+pub struct FindingFilter {
+    /// Globs of rule ids
+    pub rules: Vec<String>,
+    /// Severities, see `FILTER_SEVERITIES`
+    pub severities: Vec<String>,
+    /// Globs of file paths
+    pub paths: Vec<String>,
+}
+
+impl FindingFilter {
+    /// Check if any filter is set
+    pub fn is_empty(&self) -> bool {
+        self.rules.is_empty() && self.severities.is_empty() && self.paths.is_empty()
+    }
+
+    /// Check that the severities are known, so a typo does not silently drop every finding
+    pub fn validate(&self) -> Result<(), String> {
+        match self
+            .severities
+            .iter()
+            .find(|severity| !FILTER_SEVERITIES.contains(&severity.as_str()))
+        {
+            Some(severity) => Err(format!(
+                "Unknown severity '{}' in --filter-severity, expected one of {}",
+                severity,
+                FILTER_SEVERITIES.join(", ")
+            )),
+            None => Ok(()),
+        }
+    }
+
+    /// Check if a finding passes the filters
+    pub fn matches(&self, rule: &str, severity: &str, file: &str) -> bool {
+        (self.rules.is_empty() || glob_match_any(&self.rules, rule))
+            && (self.severities.is_empty() || self.severities.iter().any(|s| s == severity))
+            && (self.paths.is_empty() || glob_match_any(&self.paths, file))
+    }
+
+    /// Check if an exported finding passes the filters, e.g. of a previous `findings.json`
+    pub fn matches_entry(&self, finding: &FindingEntry) -> bool {
+        self.matches(&finding.rule, &finding.severity, &finding.file)
+    }
+}
+
+/// Get the name of a severity as used in the reports
+fn severity_name(severity: Severity) -> &'static str {
+    match severity {
+        Severity::Error => "error",
+        Severity::Warning => "warning",
+        _ => "info",
+    }
+}
+
+/// Drop the findings that do not pass the filters
+///
+/// Returns the number of dropped findings.
+pub fn filter_results(results: &mut [FileAnalysisResult], filter: &FindingFilter) -> usize {
+    if filter.is_empty() {
+        return 0;
+    }
+
+    let mut dropped = 0;
+    for result in results.iter_mut() {
+        let file_path = result.file_path.clone();
+        result.diagnostics.retain(|diagnostic| {
+            let kept = filter.matches(
+                &diagnostic.rule_id,
+                severity_name(diagnostic.diagnostic.severity),
+                &file_path,
+            );
+            if !kept {
+                dropped += 1;
+            }
+            kept
+        });
+    }
+    dropped
+}
@@ -0,0 +1,265 @@
+//! History of the findings per rule across runs
+//!
+//! With `--history` (or `"history": true` in `sentinel.json`) every run appends a record
+//! to `history.jsonl` in the output directory: the commit and, per rule, the fingerprints
+//! of its findings. `scoper rules stats` derives per-rule statistics from it:
+//!
+//! - matches of the latest run and the number of runs the rule had findings in,
+//! - the fix rate, the share of findings that disappeared in a later run,
+//! - the suppression rate, the share of findings reported as false positives with
+//!   `--report-fp` (read from `feedback.jsonl`).
+//!
+//! Rules with few fixes and many false positives are candidates for retirement.
+//!
+//! Records also keep the `counters` of the count-only rules, to follow metrics that are
+//! not findings across runs.
+
+use crate::exporter::FindingsExport;
+use crate::feedback::FeedbackEntry;
+use crate::publish::commit_info;
+use crate::utilities::{DebugLevel, log};
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, HashSet};
+use std::fs::{self, OpenOptions};
+use std::io::Write;
+use std::path::Path;
+use tabled::{
+    builder::Builder,
+    settings::{Alignment, Style, object::Columns},
+};
+
+/// File in the output directory the runs are appended to
+pub const HISTORY_FILE: &str = "history.jsonl";
+
+/// Findings of one rule in a run
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+pub struct RuleRun {
+    pub findings: usize,
+    pub fingerprints: Vec<String>,
+}
+
+/// One run in the history
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+pub struct RunRecord {
+    pub timestamp: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub commit: Option<String>,
+    pub rules: BTreeMap<String, RuleRun>,
+    /// Matches of the count-only rules per counter
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub counters: BTreeMap<String, usize>,
+}
+
+impl RunRecord {
+    /// Build the record of a run from its findings
+    ///
+    /// The counts come from the summary, so they include findings left out by the caps.
+    pub fn from_export(export: &FindingsExport, commit: Option<String>) -> Self {
+        let mut rules: BTreeMap<String, RuleRun> = BTreeMap::new();
+        for finding in &export.findings {
+            let rule = rules.entry(finding.rule.clone()).or_default();
+            rule.fingerprints.push(finding.fingerprint.clone());
+        }
+        for (rule, &findings) in &export.summary.findings_by_rule {
+            rules.entry(rule.clone()).or_default().findings = findings;
+        }
+        Self {
+            timestamp: export.summary.timestamp.clone(),
+            commit,
+            rules,
+            counters: export.counters.clone(),
+        }
+    }
+}
+
+/// Statistics of a rule over the history
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+pub struct RuleStats {
+    pub rule: String,
+    /// Findings of the latest run
+    pub matches: usize,
+    /// Runs the rule had findings in
+    pub runs_with_findings: usize,
+    /// Distinct findings over all runs
+    pub distinct_findings: usize,
+    /// Findings that disappeared in a later run
+    pub fixed: usize,
+    /// Findings reported as false positives
+    pub suppressed: usize,
+    pub fix_rate: f64,
+    pub suppression_rate: f64,
+}
+
+/// Append a run to the history of the output directory
+///
+/// The commit is read from `target`, the analyzed directory.
+pub fn record_run(
+    export: &FindingsExport,
+    output_dir: &str,
+    target: &Path,
+    debug_level: DebugLevel,
+) {
+    let record = RunRecord::from_export(export, commit_info(target).map(|commit| commit.sha));
+    let path = Path::new(output_dir).join(HISTORY_FILE);
+    let result = serde_json::to_string(&record)
+        .map_err(|e| e.to_string())
+        .and_then(|line| {
+            OpenOptions::new()
+                .create(true)
+                .append(true)
+                .open(&path)
+                .and_then(|mut file| writeln!(file, "{}", line))
+                .map_err(|e| e.to_string())
+        });
+    match result {
+        Ok(_) => log(
+            DebugLevel::Info,
+            debug_level,
+            &format!("Run appended to {}", path.display()),
+        ),
+        Err(e) => log(
+            DebugLevel::Error,
+            debug_level,
+            &format!("Failed to append the run to {}: {}", path.display(), e),
+        ),
+    }
+}
+
+/// Read the runs of the history, oldest first, skipping invalid lines
+pub fn load_history(output_dir: &str) -> Result<Vec<RunRecord>, String> {
+    let path = Path::new(output_dir).join(HISTORY_FILE);
+    let content = fs::read_to_string(&path).map_err(|e| {
+        format!(
+            "Failed to read {}, run the analysis with --history first: {}",
+            path.display(),
+            e
+        )
+    })?;
+    Ok(content
+        .lines()
+        .filter_map(|line| serde_json::from_str(line).ok())
+        .collect())
+}
+
+/// Read the fingerprints reported as false positives, per rule
+fn load_suppressions(output_dir: &str) -> BTreeMap<String, HashSet<String>> {
+    let path = Path::new(output_dir).join("feedback.jsonl");
+    let mut suppressions: BTreeMap<String, HashSet<String>> = BTreeMap::new();
+    for entry in fs::read_to_string(path)
+        .unwrap_or_default()
+        .lines()
+        .filter_map(|line| serde_json::from_str::<FeedbackEntry>(line).ok())
+    {
+        suppressions
+            .entry(entry.rule)
+            .or_default()
+            .insert(entry.fingerprint);
+    }
+    suppressions
+}
+
+/// Compute the statistics of every rule seen in the history
+///
+/// Sorted by suppression rate, then by matches, so the noisiest rules come first.
+pub fn rule_stats(
+    history: &[RunRecord],
+    suppressions: &BTreeMap<String, HashSet<String>>,
+) -> Vec<RuleStats> {
+    let rules: HashSet<&String> = history
+        .iter()
+        .flat_map(|run| run.rules.keys())
+        .chain(suppressions.keys())
+        .collect();
+
+    let mut stats: Vec<RuleStats> = rules
+        .into_iter()
+        .map(|rule| {
+            let runs: Vec<HashSet<&String>> = history
+                .iter()
+                .map(|run| {
+                    run.rules
+                        .get(rule)
+                        .map(|r| r.fingerprints.iter().collect())
+                        .unwrap_or_default()
+                })
+                .collect();
+            let distinct: HashSet<&String> = runs.iter().flatten().copied().collect();
+            let fixed: HashSet<&String> = runs
+                .windows(2)
+                .flat_map(|pair| pair[0].difference(&pair[1]).copied())
+                .collect();
+            let suppressed = suppressions.get(rule).map_or(0, |s| s.len());
+            let rate = |count: usize| match distinct.len() {
+                0 => 0.0,
+                total => count as f64 / total as f64,
+            };
+
+            RuleStats {
+                rule: rule.clone(),
+                matches: history
+                    .last()
+                    .and_then(|run| run.rules.get(rule))
+                    .map_or(0, |r| r.findings),
+                runs_with_findings: runs.iter().filter(|run| !run.is_empty()).count(),
+                distinct_findings: distinct.len(),
+                fixed: fixed.len(),
+                suppressed,
+                fix_rate: rate(fixed.len()),
+                suppression_rate: rate(suppressed).min(1.0),
+            }
+        })
+        .collect();
+
+    stats.sort_by(|a, b| {
+        b.suppression_rate
+            .total_cmp(&a.suppression_rate)
+            .then(b.matches.cmp(&a.matches))
+            .then(a.rule.cmp(&b.rule))
+    });
+    stats
+}
+
+/// Compute the rule statistics of an output directory
+pub fn collect_rule_stats(output_dir: &str) -> Result<(usize, Vec<RuleStats>), String> {
+    let history = load_history(output_dir)?;
+    let stats = rule_stats(&history, &load_suppressions(output_dir));
+    Ok((history.len(), stats))
+}
+
+/// Print the rule statistics as a table
+pub fn print_rule_stats(runs: usize, stats: &[RuleStats]) {
+    println!("\nRule statistics over {} runs:", runs);
+    println!("----------------");
+
+    let mut builder = Builder::new();
+    builder.push_record([
+        "Rule",
+        "Matches",
+        "Runs",
+        "Findings",
+        "Fixed",
+        "Fix rate",
+        "Suppressed",
+        "Suppression rate",
+    ]);
+    for rule in stats {
+        builder.push_record([
+            rule.rule.clone(),
+            rule.matches.to_string(),
+            rule.runs_with_findings.to_string(),
+            rule.distinct_findings.to_string(),
+            rule.fixed.to_string(),
+            format!("{:.0}%", rule.fix_rate * 100.0),
+            rule.suppressed.to_string(),
+            format!("{:.0}%", rule.suppression_rate * 100.0),
+        ]);
+    }
+
+    let mut table = builder.build();
+    table
+        .with(Style::ascii_rounded())
+        .modify(Columns::new(1..), Alignment::right());
+
+    println!("{}", table);
+    println!("----------------");
+}
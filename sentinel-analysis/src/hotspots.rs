@@ -0,0 +1,205 @@
+//! Files most in need of refactoring
+//!
+//! Ranks the analyzed files by their finding density, the findings per 100 lines. With
+//! `--churn` the density is weighted by the number of commits that touched the file
+//! according to `git log`, so files that are both problematic and frequently changed come
+//! first. The top files are exported as `hotspots` in `findings.json` and printed after the
+//! rule hit summary.
+
+use crate::FileAnalysisResult;
+use crate::rules_registry::PROJECT_FILE;
+use crate::utilities::paths::PathBase;
+use crate::utilities::source::decode_source;
+use crate::utilities::{DebugLevel, log};
+use serde::{Deserialize, Serialize};
+use std::collections::HashMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+use tabled::{
+    builder::Builder,
+    settings::{Alignment, Style, object::Columns},
+};
+
+/// Number of hotspots reported, unless `hotspot_limit` is configured
+pub const DEFAULT_HOTSPOT_LIMIT: usize = 10;
+
+/// A file ranked by its findings and churn
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct Hotspot {
+    pub file: String,
+    pub findings: usize,
+    pub lines: usize,
+    /// Findings per 100 lines
+    pub density: f64,
+    /// Commits that touched the file, only present with `--churn`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub churn: Option<usize>,
+    /// Density weighted by churn, higher is worse
+    pub score: f64,
+}
+
+/// Run git in a directory and get its output
+pub(crate) fn git(dir: &Path, args: &[&str]) -> Result<String, String> {
+    let output = Command::new("git")
+        .arg("-C")
+        .arg(dir)
+        .args(args)
+        .output()
+        .map_err(|e| format!("Failed to run git: {}", e))?;
+    if !output.status.success() {
+        return Err(format!(
+            "git {} failed: {}",
+            args.join(" "),
+            String::from_utf8_lossy(&output.stderr).trim()
+        ));
+    }
+    Ok(String::from_utf8_lossy(&output.stdout).to_string())
+}
+
+/// Count the commits touching each file of the repository containing the analyzed files
+///
+/// `since` limits the history to a `git log --since` date, e.g. `6 months ago`. The keys
+/// are absolute paths.
+pub fn git_churn(
+    results: &[FileAnalysisResult],
+    base: &PathBase,
+    since: Option<&str>,
+) -> Result<HashMap<PathBuf, usize>, String> {
+    let Some(first) = results
+        .iter()
+        .find(|result| result.file_path != PROJECT_FILE)
+    else {
+        return Ok(HashMap::new());
+    };
+    let file = base.resolve(&first.file_path);
+    let dir = file.parent().unwrap_or(Path::new("."));
+
+    let toplevel = PathBuf::from(git(dir, &["rev-parse", "--show-toplevel"])?.trim());
+    let since = since.map(|since| format!("--since={}", since));
+    let mut args = vec!["log", "--format=", "--name-only"];
+    if let Some(since) = &since {
+        args.push(since);
+    }
+
+    let mut churn: HashMap<PathBuf, usize> = HashMap::new();
+    for file in git(&toplevel, &args)?
+        .lines()
+        .filter(|line| !line.is_empty())
+    {
+        *churn.entry(toplevel.join(file)).or_insert(0) += 1;
+    }
+    Ok(churn)
+}
+
+/// Rank the files with findings, worst first
+///
+/// The score is the density, multiplied by `1 + churn` if churn is given. Ties are broken
+/// by the number of findings, then by path. Files are read relative to `base`.
+pub fn build_hotspots(
+    results: &[FileAnalysisResult],
+    base: &PathBase,
+    churn: Option<&HashMap<PathBuf, usize>>,
+    limit: usize,
+) -> Vec<Hotspot> {
+    let mut hotspots: Vec<Hotspot> = results
+        .iter()
+        .filter(|result| result.file_path != PROJECT_FILE && !result.diagnostics.is_empty())
+        .map(|result| {
+            let path = base.resolve(&result.file_path);
+            let lines = fs::read(&path)
+                .map_err(|e| e.to_string())
+                .and_then(decode_source)
+                .map_or(0, |source| source.content.lines().count())
+                .max(1);
+            let findings = result.diagnostics.len();
+            let density = findings as f64 * 100.0 / lines as f64;
+            let churn = churn.map(|churn| {
+                let path = fs::canonicalize(&path).unwrap_or_else(|_| path.clone());
+                churn.get(&path).copied().unwrap_or(0)
+            });
+
+            Hotspot {
+                file: result.file_path.clone(),
+                findings,
+                lines,
+                density,
+                churn,
+                score: density * (1 + churn.unwrap_or(0)) as f64,
+            }
+        })
+        .collect();
+
+    hotspots.sort_by(|a, b| {
+        b.score
+            .total_cmp(&a.score)
+            .then_with(|| b.findings.cmp(&a.findings))
+            .then_with(|| a.file.cmp(&b.file))
+    });
+    hotspots.truncate(limit);
+    hotspots
+}
+
+/// Rank the hotspots of a run, falling back to density only if git history is unavailable
+pub fn collect_hotspots(
+    results: &[FileAnalysisResult],
+    base: &PathBase,
+    with_churn: bool,
+    since: Option<&str>,
+    limit: usize,
+    debug_level: DebugLevel,
+) -> Vec<Hotspot> {
+    let churn = match with_churn {
+        true => match git_churn(results, base, since) {
+            Ok(churn) => Some(churn),
+            Err(err) => {
+                log(
+                    DebugLevel::Warn,
+                    debug_level,
+                    &format!("Ranking hotspots without churn: {}", err),
+                );
+                None
+            }
+        },
+        false => None,
+    };
+
+    build_hotspots(results, base, churn.as_ref(), limit)
+}
+
+/// Print the hotspots as a table
+pub fn print_hotspots(hotspots: &[Hotspot]) {
+    println!("\nHotspots:");
+    println!("----------------");
+
+    let with_churn = hotspots.iter().any(|hotspot| hotspot.churn.is_some());
+    let mut builder = Builder::new();
+    let mut header = vec!["File", "Findings", "Lines", "Per 100 lines"];
+    if with_churn {
+        header.push("Commits");
+    }
+    header.push("Score");
+    builder.push_record(header);
+
+    for hotspot in hotspots {
+        let mut record = vec![
+            hotspot.file.clone(),
+            hotspot.findings.to_string(),
+            hotspot.lines.to_string(),
+            format!("{:.1}", hotspot.density),
+        ];
+        if with_churn {
+            record.push(hotspot.churn.unwrap_or(0).to_string());
+        }
+        record.push(format!("{:.1}", hotspot.score));
+        builder.push_record(record);
+    }
+
+    let mut table = builder.build();
+    table
+        .with(Style::ascii_rounded())
+        .modify(Columns::new(1..), Alignment::right());
+
+    println!("{}", table);
+    println!("----------------");
+}
@@ -0,0 +1,437 @@
+//! Analyzing a single file and keeping what the analysis produced on the way
+//!
+//! The batch analysis keeps only the findings and timings of a file. Tools that work on one
+//! file at a time, such as an editor integration showing findings and declarations on hover
+//! or a command explaining why a rule matched, also need the syntax tree, the declarations
+//! and the imports and exports. `analyze_file_detailed` runs the same steps as the batch
+//! analysis on one file and returns those intermediate artifacts along with the findings.
+//!
+//! Everything in `FileDetails` is owned, so it outlives the allocator the file was parsed
+//! with; the syntax tree is flattened into a list of nodes linked to their parents.
+//!
+//! ```no_run
+//! use scoper::Sentinel;
+//! use scoper::utilities::config::Config;
+//!
+//! let details = Sentinel::new(Config::load()).analyze_file_detailed("src/app/app.component.ts")?;
+//! for declaration in &details.declarations {
+//!     println!("{} {} (line {})", declaration.kind, declaration.name, declaration.line);
+//! }
+//! # Ok::<(), String>(())
+//! ```
+
+use crate::RuleDiagnostic;
+use crate::analyzer::{parse_error_diagnostic, read_source};
+use crate::angular_graph::{AngularSymbol, extract_angular_symbols};
+use crate::rules::ancestry::enclosing_class;
+use crate::rules::class_context::property_key_name;
+use crate::rules_registry::RulesRegistry;
+use crate::utilities::source::{diagnostic_span, line_of_offset};
+
+use oxc_allocator::Allocator;
+use oxc_ast::AstKind;
+use oxc_ast::ast::{
+    BindingPatternKind, Declaration, ExportDefaultDeclarationKind, ImportDeclarationSpecifier,
+    Program, Statement,
+};
+use oxc_parser::Parser;
+use oxc_semantic::{AstNode, Semantic, SemanticBuilder};
+use oxc_span::{GetSpan, SourceType};
+use serde::{Deserialize, Serialize};
+use std::collections::{HashMap, HashSet};
+use std::path::Path;
+use std::sync::Arc;
+use std::time::{Duration, Instant};
+
+/// A node of the syntax tree
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct AstNodeInfo {
+    /// Index of the node in `FileDetails::ast`
+    pub id: usize,
+    /// Index of the parent node, `None` for the program
+    pub parent: Option<usize>,
+    /// Kind of the node, with the name for named nodes, e.g. `Class(AppComponent)`
+    pub kind: String,
+    /// Byte offsets of the node in the source text
+    pub start: u32,
+    pub end: u32,
+}
+
+/// A named declaration of the file
+#[derive(Debug, Clone)]
+pub struct DeclarationInfo {
+    /// `function`, `class`, `variable`, `interface`, `type`, `enum`, `method` or `property`
+    pub kind: &'static str,
+    pub name: String,
+    /// Class of a method or property
+    pub container: Option<String>,
+    /// 1-based line of the declaration
+    pub line: usize,
+    pub exported: bool,
+}
+
+/// A name imported by an import declaration
+#[derive(Debug, Clone)]
+pub struct ImportedName {
+    /// Exported name in the imported module, `default` or `*` for a namespace import
+    pub imported: String,
+    /// Name of the binding in this file
+    pub local: String,
+}
+
+/// An import declaration
+#[derive(Debug, Clone)]
+pub struct ImportInfo {
+    /// Module specifier, e.g. `@angular/core`
+    pub source: String,
+    /// Imported names, empty for side-effect imports like `import 'zone.js'`
+    pub names: Vec<ImportedName>,
+    pub type_only: bool,
+    pub line: usize,
+}
+
+/// A name exported by the file
+#[derive(Debug, Clone)]
+pub struct ExportInfo {
+    /// Exported name, `default` for the default export or `*` for `export * from`
+    pub name: String,
+    /// Name of the exported binding in this file, if it differs from the exported name
+    pub local: Option<String>,
+    /// Module the name is re-exported from
+    pub source: Option<String>,
+    pub line: usize,
+}
+
+/// Durations of the steps of the analysis
+#[derive(Debug, Clone, Default)]
+pub struct FileTimings {
+    pub read: Duration,
+    pub parse: Duration,
+    pub semantic: Duration,
+    /// Duration of each rule that ran
+    pub rules: HashMap<String, Duration>,
+    pub total: Duration,
+}
+
+/// The analysis of a single file with its intermediate artifacts
+#[derive(Debug, Clone)]
+pub struct FileDetails {
+    pub file_path: String,
+    pub source: Arc<str>,
+    /// Nodes of the syntax tree in source order, parents before their children
+    pub ast: Vec<AstNodeInfo>,
+    pub declarations: Vec<DeclarationInfo>,
+    pub imports: Vec<ImportInfo>,
+    pub exports: Vec<ExportInfo>,
+    /// Parse errors and findings of the enabled rules
+    pub diagnostics: Vec<RuleDiagnostic>,
+    /// Angular classes declared in the file
+    pub angular_symbols: Vec<AngularSymbol>,
+    /// Whether the file is generated code; rules are skipped on it unless included
+    pub generated: bool,
+    pub timings: FileTimings,
+}
+
+impl FileDetails {
+    /// Get the innermost node containing a byte offset, e.g. the node under the cursor
+    pub fn node_at(&self, offset: u32) -> Option<&AstNodeInfo> {
+        // Children follow their parents, so the last containing node is the innermost one
+        self.ast
+            .iter()
+            .filter(|node| node.start <= offset && offset < node.end)
+            .last()
+    }
+
+    /// Get the findings whose primary label contains a byte offset
+    pub fn diagnostics_at(&self, offset: u32) -> Vec<&RuleDiagnostic> {
+        self.diagnostics
+            .iter()
+            .filter(|diagnostic| {
+                diagnostic_span(&diagnostic.diagnostic)
+                    .is_some_and(|span| span.start <= offset && offset <= span.end)
+            })
+            .collect()
+    }
+}
+
+/// Analyze one file with the rules of a registry and keep the intermediate artifacts
+///
+/// Fails if the file cannot be read, is not JavaScript or TypeScript, or cannot be parsed
+/// at all; syntax errors the parser recovers from are part of the diagnostics. The rule
+/// cache is not used.
+pub fn analyze_file_detailed(
+    file_path: &str,
+    rules_registry: &RulesRegistry,
+) -> Result<FileDetails, String> {
+    let start = Instant::now();
+    let (source, _) = read_source(file_path, &mut Vec::new(), None)?;
+    let read_duration = start.elapsed();
+
+    let source_type = SourceType::from_path(Path::new(file_path))
+        .map_err(|_| format!("Unsupported file type: {}", file_path))?;
+
+    let allocator = Allocator::default();
+    let parse_start = Instant::now();
+    let parse_result = Parser::new(&allocator, &source, source_type).parse();
+    let parse_duration = parse_start.elapsed();
+    if parse_result.panicked {
+        return Err(format!("Failed to parse {}", file_path));
+    }
+    let mut diagnostics: Vec<RuleDiagnostic> = parse_result
+        .errors
+        .iter()
+        .map(|err| parse_error_diagnostic(err.clone(), &source, rules_registry.column_unit()))
+        .collect();
+
+    let semantic_start = Instant::now();
+    let semantic_result = SemanticBuilder::new().build(&parse_result.program);
+    let semantic_duration = semantic_start.elapsed();
+
+    let generated = rules_registry.is_generated_file(file_path, &source);
+    let rule_durations = if generated && rules_registry.skips_generated_files() {
+        HashMap::new()
+    } else {
+        let (rule_diagnostics, rule_durations) = rules_registry.run_rules_with_cache(
+            &semantic_result,
+            file_path,
+            &source,
+            HashMap::new(),
+        );
+        diagnostics.extend(rule_diagnostics);
+        rule_durations
+    };
+
+    let semantic = &semantic_result.semantic;
+    let nodes = semantic.nodes();
+    let ast = flatten_tree(semantic);
+
+    let imports = collect_imports(&parse_result.program, &source);
+    let exports = collect_exports(&parse_result.program, &source);
+    let exported: HashSet<&str> = exports
+        .iter()
+        .filter(|export| export.source.is_none())
+        .map(|export| export.local.as_deref().unwrap_or(&export.name))
+        .collect();
+    let mut declarations = Vec::new();
+    for node in nodes.iter() {
+        if let Some((kind, name, container)) = declaration_of(semantic, node) {
+            declarations.push(DeclarationInfo {
+                kind,
+                exported: container.is_none() && exported.contains(name.as_str()),
+                name,
+                container,
+                line: line_of_offset(&source, node.kind().span().start as usize),
+            });
+        }
+    }
+    let angular_symbols = extract_angular_symbols(&parse_result.program, file_path);
+
+    Ok(FileDetails {
+        file_path: file_path.to_string(),
+        source: Arc::clone(&source),
+        ast,
+        declarations,
+        imports,
+        exports,
+        diagnostics,
+        angular_symbols,
+        generated,
+        timings: FileTimings {
+            read: read_duration,
+            parse: parse_duration,
+            semantic: semantic_duration,
+            rules: rule_durations,
+            total: start.elapsed(),
+        },
+    })
+}
+
+/// Flatten the syntax tree, numbering the nodes in the order they were entered
+pub fn flatten_tree(semantic: &Semantic) -> Vec<AstNodeInfo> {
+    let nodes = semantic.nodes();
+    let mut ids = HashMap::new();
+    let mut ast = Vec::new();
+    for node in nodes.iter() {
+        let id = ast.len();
+        ids.insert(node.id(), id);
+        let span = node.kind().span();
+        ast.push(AstNodeInfo {
+            id,
+            parent: nodes
+                .parent_id(node.id())
+                .and_then(|parent| ids.get(&parent).copied()),
+            kind: node.kind().debug_name().into_owned(),
+            start: span.start,
+            end: span.end,
+        });
+    }
+    ast
+}
+
+/// Get the kind, name and containing class of a declaring node
+fn declaration_of(
+    semantic: &Semantic,
+    node: &AstNode,
+) -> Option<(&'static str, String, Option<String>)> {
+    // Name of the class whose body contains the node
+    let container = || {
+        enclosing_class(semantic, node.id())
+            .and_then(|class| class.id.as_ref())
+            .map(|id| id.name.to_string())
+    };
+
+    match node.kind() {
+        AstKind::Function(func) => Some(("function", func.id.as_ref()?.name.to_string(), None)),
+        AstKind::Class(class) => Some(("class", class.id.as_ref()?.name.to_string(), None)),
+        AstKind::VariableDeclarator(declarator) => match &declarator.id.kind {
+            BindingPatternKind::BindingIdentifier(ident) => {
+                Some(("variable", ident.name.to_string(), None))
+            }
+            _ => None,
+        },
+        AstKind::TSInterfaceDeclaration(interface) => {
+            Some(("interface", interface.id.name.to_string(), None))
+        }
+        AstKind::TSTypeAliasDeclaration(alias) => Some(("type", alias.id.name.to_string(), None)),
+        AstKind::TSEnumDeclaration(enum_decl) => {
+            Some(("enum", enum_decl.id.name.to_string(), None))
+        }
+        AstKind::MethodDefinition(method) => Some((
+            "method",
+            property_key_name(&method.key).to_string(),
+            container(),
+        )),
+        AstKind::PropertyDefinition(property) => Some((
+            "property",
+            property_key_name(&property.key).to_string(),
+            container(),
+        )),
+        _ => None,
+    }
+}
+
+/// Collect the import declarations of a program
+fn collect_imports(program: &Program, source: &str) -> Vec<ImportInfo> {
+    program
+        .body
+        .iter()
+        .filter_map(|statement| match statement {
+            Statement::ImportDeclaration(import) => Some(import),
+            _ => None,
+        })
+        .map(|import| {
+            let names = import
+                .specifiers
+                .iter()
+                .flatten()
+                .map(|specifier| match specifier {
+                    ImportDeclarationSpecifier::ImportSpecifier(specifier) => ImportedName {
+                        imported: specifier.imported.name().to_string(),
+                        local: specifier.local.name.to_string(),
+                    },
+                    ImportDeclarationSpecifier::ImportDefaultSpecifier(specifier) => ImportedName {
+                        imported: "default".to_string(),
+                        local: specifier.local.name.to_string(),
+                    },
+                    ImportDeclarationSpecifier::ImportNamespaceSpecifier(specifier) => {
+                        ImportedName {
+                            imported: "*".to_string(),
+                            local: specifier.local.name.to_string(),
+                        }
+                    }
+                })
+                .collect();
+            ImportInfo {
+                source: import.source.value.to_string(),
+                names,
+                type_only: import.import_kind.is_type(),
+                line: line_of_offset(source, import.span.start as usize),
+            }
+        })
+        .collect()
+}
+
+/// Collect the names exported by a program
+fn collect_exports(program: &Program, source: &str) -> Vec<ExportInfo> {
+    let mut exports = Vec::new();
+    for statement in &program.body {
+        let line = line_of_offset(source, statement.span().start as usize);
+        match statement {
+            Statement::ExportNamedDeclaration(export) => {
+                let module = export.source.as_ref().map(|s| s.value.to_string());
+                if let Some(declaration) = &export.declaration {
+                    for name in declared_names(declaration) {
+                        exports.push(ExportInfo {
+                            name,
+                            local: None,
+                            source: None,
+                            line,
+                        });
+                    }
+                }
+                for specifier in &export.specifiers {
+                    let name = specifier.exported.name().to_string();
+                    let local = specifier.local.name().to_string();
+                    exports.push(ExportInfo {
+                        local: (local != name).then_some(local),
+                        name,
+                        source: module.clone(),
+                        line,
+                    });
+                }
+            }
+            Statement::ExportDefaultDeclaration(export) => {
+                let local = match &export.declaration {
+                    ExportDefaultDeclarationKind::FunctionDeclaration(func) => {
+                        func.id.as_ref().map(|id| id.name.to_string())
+                    }
+                    ExportDefaultDeclarationKind::ClassDeclaration(class) => {
+                        class.id.as_ref().map(|id| id.name.to_string())
+                    }
+                    _ => None,
+                };
+                exports.push(ExportInfo {
+                    name: "default".to_string(),
+                    local,
+                    source: None,
+                    line,
+                });
+            }
+            Statement::ExportAllDeclaration(export) => exports.push(ExportInfo {
+                name: export
+                    .exported
+                    .as_ref()
+                    .map_or_else(|| "*".to_string(), |name| name.name().to_string()),
+                local: None,
+                source: Some(export.source.value.to_string()),
+                line,
+            }),
+            _ => {}
+        }
+    }
+    exports
+}
+
+/// Get the names a declaration binds
+fn declared_names(declaration: &Declaration) -> Vec<String> {
+    match declaration {
+        Declaration::VariableDeclaration(var) => var
+            .declarations
+            .iter()
+            .filter_map(|decl| match &decl.id.kind {
+                BindingPatternKind::BindingIdentifier(id) => Some(id.name.to_string()),
+                _ => None,
+            })
+            .collect(),
+        Declaration::FunctionDeclaration(func) => {
+            func.id.iter().map(|id| id.name.to_string()).collect()
+        }
+        Declaration::ClassDeclaration(class) => {
+            class.id.iter().map(|id| id.name.to_string()).collect()
+        }
+        Declaration::TSInterfaceDeclaration(interface) => vec![interface.id.name.to_string()],
+        Declaration::TSTypeAliasDeclaration(alias) => vec![alias.id.name.to_string()],
+        Declaration::TSEnumDeclaration(enum_decl) => vec![enum_decl.id.name.to_string()],
+        _ => Vec::new(),
+    }
+}
@@ -1,13 +1,54 @@
 // Expose the modules
+pub mod ai_suggestions;
 pub mod analyzer;
+pub mod angular_graph;
+pub mod ast_snapshot;
+pub mod backstage;
+pub mod blame;
+pub mod cache;
+pub mod chunker;
+pub mod counters;
+pub mod directories;
+pub mod docker;
+pub mod doctor;
+pub mod editor_links;
+pub mod embeddings;
+pub mod escalation;
 pub mod exporter;
+pub mod feedback;
+pub mod filters;
+pub mod history;
+pub mod hotspots;
+pub mod inspect;
+pub mod limits;
+pub mod messages;
 pub mod metrics;
+pub mod new_code;
+pub mod notifications;
+pub mod org;
+pub mod output;
+pub mod publish;
+pub mod quality_gates;
 pub mod rules;
 pub mod rules_registry;
+pub mod schema;
+pub mod security_report;
+pub mod sentinel;
+pub mod serve;
+pub mod signal_migration;
+pub mod styles;
+pub mod suppressions;
+pub mod templates;
+pub mod tokenizer;
 pub mod utilities;
 
+use angular_graph::AngularSymbol;
+use blame::Blame;
 use oxc_diagnostics::OxcDiagnostic;
+use rules::RuleCategory;
+use serde_json::Value;
 use std::collections::HashMap;
+use std::sync::Arc;
 use std::time::Duration;
 
 /// Structure that associates a rule ID with a diagnostic
@@ -15,13 +56,28 @@ use std::time::Duration;
 pub struct RuleDiagnostic {
     /// The ID of the rule that produced this diagnostic
     pub rule_id: String,
+    /// The category of the rule that produced this diagnostic
+    pub category: RuleCategory,
+    /// The version of the rule that produced this diagnostic
+    pub rule_version: &'static str,
+    /// Link to the documentation of the rule
+    pub docs_url: Option<String>,
     /// The actual diagnostic
     pub diagnostic: OxcDiagnostic,
+    /// Structured data attached to the diagnostic, e.g. a CWE identifier
+    pub metadata: HashMap<String, Value>,
+    /// Replacement for the code of the primary label that fixes the issue
+    pub suggestion: Option<String>,
     /// The source code of the file where the diagnostic was found
-    pub source_code: String,
+    ///
+    /// Shared by all diagnostics of the file, so a file with thousands of findings is not
+    /// held in memory thousands of times.
+    pub source_code: Arc<str>,
     // TBD
     pub line_number: usize,
     pub column_number: usize,
+    /// Who last changed the line, only present with `--blame`
+    pub blame: Option<Blame>,
 }
 
 /// Structure to hold analysis results for a single file
@@ -33,10 +89,19 @@ pub struct FileAnalysisResult {
     pub rule_durations: HashMap<String, Duration>,
     pub total_duration: Duration,
     pub diagnostics: Vec<RuleDiagnostic>,
+    /// Angular classes declared in the file
+    pub angular_symbols: Vec<AngularSymbol>,
+    /// Hash of the analyzed source code, `None` if the file could not be read or parsed
+    pub content_hash: Option<u64>,
+    /// Whether the file is generated code; rules are skipped on it unless included
+    pub generated: bool,
+    /// Why the file could not be analyzed, e.g. an unreadable encoding
+    pub skipped: Option<String>,
 }
 
 // Add any other public exports needed from the library modules here
 pub use metrics::Metrics;
 pub use rules::Rule;
 pub use rules_registry::RulesRegistry;
+pub use sentinel::{Analysis, Sentinel};
 pub use utilities::DebugLevel;
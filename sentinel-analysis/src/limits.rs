@@ -0,0 +1,137 @@
+//! Caps on the number of findings written to the reports
+//!
+//! A pathological rule or repository can produce millions of findings and a report of
+//! several gigabytes. `--max-findings` caps the findings of a run and
+//! `--max-findings-per-rule` the findings of every rule; `max_findings_by_rule` in
+//! `sentinel.json` sets the cap of single rules:
+//!
+//! ```json
+//! "max_findings": 10000,
+//! "max_findings_per_rule": 1000,
+//! "max_findings_by_rule": { "todo-comments": 100 }
+//! ```
+//!
+//! Per-rule caps keep the first findings of a rule in file order. The run cap keeps
+//! errors over warnings, so a noisy warning cannot push errors out of the report. The
+//! summary still counts every finding; what was left out is recorded as `truncation`.
+
+use crate::exporter::FindingEntry;
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, HashMap};
+
+/// Caps on the findings of a run, none by default
+#[derive(Debug, Clone, Default)]
+pub struct FindingLimits {
+    pub max_findings: Option<usize>,
+    /// Cap of every rule without its own cap
+    pub max_findings_per_rule: Option<usize>,
+    pub max_findings_by_rule: HashMap<String, usize>,
+}
+
+impl FindingLimits {
+    /// Check if any cap is set
+    pub fn is_empty(&self) -> bool {
+        self.max_findings.is_none()
+            && self.max_findings_per_rule.is_none()
+            && self.max_findings_by_rule.is_empty()
+    }
+
+    /// Get the cap of a rule
+    pub fn rule_limit(&self, rule: &str) -> Option<usize> {
+        self.max_findings_by_rule
+            .get(rule)
+            .copied()
+            .or(self.max_findings_per_rule)
+    }
+}
+
+/// Findings left out of the reports because of the caps
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+pub struct Truncation {
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub max_findings: Option<usize>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub max_findings_per_rule: Option<usize>,
+    /// Number of findings left out
+    pub omitted: usize,
+    /// Findings left out per rule
+    pub omitted_by_rule: BTreeMap<String, usize>,
+}
+
+/// Order severities from most to least severe
+fn severity_rank(severity: &str) -> u8 {
+    match severity {
+        "error" => 0,
+        "warning" => 1,
+        _ => 2,
+    }
+}
+
+/// Drop the findings beyond the caps, keeping the order of the others
+///
+/// Returns what was left out, `None` if nothing was.
+pub fn truncate_findings(
+    findings: &mut Vec<FindingEntry>,
+    limits: &FindingLimits,
+) -> Option<Truncation> {
+    if limits.is_empty() {
+        return None;
+    }
+
+    let mut kept_by_rule: HashMap<String, usize> = HashMap::new();
+    let mut keep: Vec<bool> = findings
+        .iter()
+        .map(|finding| {
+            let kept = kept_by_rule.entry(finding.rule.clone()).or_insert(0);
+            if limits
+                .rule_limit(&finding.rule)
+                .is_some_and(|limit| *kept >= limit)
+            {
+                return false;
+            }
+            *kept += 1;
+            true
+        })
+        .collect();
+
+    if let Some(max_findings) = limits.max_findings {
+        let mut kept: Vec<usize> = (0..findings.len()).filter(|&i| keep[i]).collect();
+        kept.sort_by_key(|&i| severity_rank(&findings[i].severity));
+        for &i in kept.iter().skip(max_findings) {
+            keep[i] = false;
+        }
+    }
+
+    let mut truncation = Truncation {
+        max_findings: limits.max_findings,
+        max_findings_per_rule: limits.max_findings_per_rule,
+        ..Truncation::default()
+    };
+    let mut index = 0;
+    findings.retain(|finding| {
+        let kept = keep[index];
+        index += 1;
+        if !kept {
+            truncation.omitted += 1;
+            *truncation
+                .omitted_by_rule
+                .entry(finding.rule.clone())
+                .or_insert(0) += 1;
+        }
+        kept
+    });
+
+    (truncation.omitted > 0).then_some(truncation)
+}
+
+/// Print what was left out, so a truncated report is not mistaken for a complete one
+pub fn print_truncation(truncation: &Truncation) {
+    println!(
+        "Findings truncated: {} left out of the reports",
+        truncation.omitted
+    );
+    for (rule, omitted) in &truncation.omitted_by_rule {
+        println!("  {}: {}", rule, omitted);
+    }
+    println!();
+}
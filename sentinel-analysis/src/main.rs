@@ -1,13 +1,29 @@
-use std::{env, sync::Arc};
+use std::env;
 
 use scoper::{
-    analyzer::process_files,
-    metrics::{aggregate_metrics, export_results},
-    rules_registry::setup_rules_registry,
+    Sentinel,
+    ast_snapshot::{
+        dump_ast, inspect_position, load_snapshot, run_on_snapshot, single_rule_registry,
+        snapshot_findings,
+    },
+    cache::{DEFAULT_CACHE_PATH, export_archive, import_archive},
+    docker::{DockerLayout, EXIT_FINDINGS, EXIT_INVALID_SETUP, exit_code},
+    doctor::{CheckStatus, print_checks, run_checks},
+    feedback::report_false_positive,
+    history::{collect_rule_stats, print_rule_stats},
+    embeddings::run_search,
+    rules::docs::generate_rules_reference,
+    rules_registry::create_default_registry,
+    org::{print_org_report, scan_org},
+    publish::publish_results,
+    schema::{BuildInfo, FINDINGS_JSON_SCHEMA, SchemaInfo},
+    serve::{DEFAULT_PORT, serve_results},
+    suppressions::{Baseline, DEFAULT_SUPPRESSIONS_PATH, list_suppressions, print_suppressions},
     utilities::{
         cli::{get_debug_level_from_args, parse_args},
-        config::{Config, get_target_path},
-        file_utils::find_files,
+        config::{
+            Config, get_column_unit, get_path_base, get_serve_options, get_target_path,
+        },
         threading::configure_thread_pool,
     },
 };
@@ -21,9 +37,24 @@ fn main() {
     let command = parse_args();
     let matches = command.get_matches();
 
-    // Initialize configuration and setup
-    let mut config = Config::load();
-    let debug_level = get_debug_level_from_args(&matches);
+    // Initialize configuration and setup; in containers, read /workspace and write to /out
+    let docker_layout = matches
+        .subcommand_matches("docker")
+        .map(|_| DockerLayout::from_env());
+    let mut config = match &docker_layout {
+        Some(layout) => match layout.load_config() {
+            Ok(config) => config,
+            Err(e) => {
+                eprintln!("ERROR: {}", e);
+                std::process::exit(EXIT_INVALID_SETUP);
+            }
+        },
+        None => Config::load(),
+    };
+    let debug_level = match (&docker_layout, config.debug_level) {
+        (Some(_), Some(level)) => level,
+        _ => get_debug_level_from_args(&matches),
+    };
 
     // Get output directory from command-line arguments
     if let Some(output_dir) = matches.get_one::<String>("output-dir") {
@@ -68,27 +99,432 @@ fn main() {
         return;
     }
 
-    // Configure thread pool and rules registry
-    configure_thread_pool(&config, debug_level);
-    let rules_registry_arc = Arc::new(setup_rules_registry(
-        &config,
-        &env::args().collect::<Vec<_>>(),
-        debug_level,
-    ));
+    // Report the schema version and capabilities to wrappers checking compatibility
+    if matches.get_flag("capabilities") {
+        match serde_json::to_string_pretty(&SchemaInfo::current()) {
+            Ok(json) => println!("{}", json),
+            Err(e) => eprintln!("ERROR: Failed to serialize capabilities: {}", e),
+        }
+        return;
+    }
+
+    // Report the build, e.g. to reproduce the run of a results file
+    if let Some(version_matches) = matches.subcommand_matches("version") {
+        let build = BuildInfo::current();
+        if version_matches.get_flag("json") {
+            match serde_json::to_string_pretty(&build) {
+                Ok(json) => println!("{}", json),
+                Err(e) => eprintln!("ERROR: Failed to serialize build information: {}", e),
+            }
+        } else {
+            println!("scoper {} ({}, built {})", build.version, build.commit, build.build_date);
+            println!("parser: {} {}", build.parser, build.parser_version);
+            println!(
+                "schema {}, cache {}, rule API {}",
+                build.schema_version, build.cache_version, build.rule_api_version
+            );
+        }
+        return;
+    }
+
+    // Print the JSON Schema of findings.json for consumers validating the reports
+    if let Some(schema_matches) = matches.subcommand_matches("schema") {
+        match schema_matches.get_one::<String>("output") {
+            Some(path) => {
+                if let Err(e) = std::fs::write(path, FINDINGS_JSON_SCHEMA) {
+                    eprintln!("ERROR: Failed to write schema to {}: {}", path, e);
+                    std::process::exit(1);
+                }
+            }
+            None => print!("{}", FINDINGS_JSON_SCHEMA),
+        }
+        return;
+    }
+
+    // Record a finding of the last run as false positive instead of analyzing
+    if let Some(fingerprint) = matches.get_one::<String>("report-fp") {
+        let output_dir = config.output_dir.as_deref().unwrap_or("findings");
+        let target_path = get_target_path(&config, &env::args().collect::<Vec<_>>());
+        if let Err(e) = report_false_positive(
+            output_dir,
+            fingerprint,
+            matches.get_one::<String>("comment").cloned(),
+            config.feedback_url.as_deref(),
+            &get_path_base(&config, &target_path),
+            debug_level,
+        ) {
+            eprintln!("ERROR: {}", e);
+            std::process::exit(1);
+        }
+        return;
+    }
+
+    // Bundle the rule cache for artifact storage, or restore it
+    if let Some(cache_matches) = matches.subcommand_matches("cache") {
+        let cache_path = config
+            .cache_path
+            .clone()
+            .unwrap_or_else(|| DEFAULT_CACHE_PATH.to_string());
+        if let Some(export_matches) = cache_matches.subcommand_matches("export") {
+            let archive = export_matches.get_one::<String>("ARCHIVE").unwrap();
+            match export_archive(&cache_path, archive) {
+                Ok(files) => println!(
+                    "Exported the cached results of {} files to {}",
+                    files, archive
+                ),
+                Err(e) => {
+                    eprintln!("ERROR: {}", e);
+                    std::process::exit(1);
+                }
+            }
+        } else if let Some(import_matches) = cache_matches.subcommand_matches("import") {
+            let archive = import_matches.get_one::<String>("ARCHIVE").unwrap();
+            let target_path = import_matches
+                .get_one::<String>("PATH")
+                .cloned()
+                .or_else(|| config.path.clone())
+                .unwrap_or_else(|| ".".to_string());
+            match import_archive(archive, &cache_path, get_path_base(&config, &target_path)) {
+                Ok(import) => println!(
+                    "Imported the cached results of {} files to {}, dropped {} files not in the checkout",
+                    import.files, cache_path, import.pruned
+                ),
+                Err(e) => {
+                    eprintln!("ERROR: {}", e);
+                    std::process::exit(1);
+                }
+            }
+        }
+        return;
+    }
+
+    // Check the setup and report every problem at once instead of failing during a run
+    if matches.subcommand_matches("doctor").is_some() {
+        let checks = run_checks(&config, &env::args().collect::<Vec<_>>());
+        print_checks(&checks);
+        if checks.iter().any(|check| check.status == CheckStatus::Fail) {
+            std::process::exit(1);
+        }
+        return;
+    }
+
+    // Capture the syntax tree of a file for offline rule development
+    if let Some(dump_matches) = matches
+        .subcommand_matches("ast")
+        .and_then(|ast_matches| ast_matches.subcommand_matches("dump"))
+    {
+        let file = dump_matches
+            .get_one::<String>("FILE")
+            .cloned()
+            .unwrap_or_default();
+        let json = match dump_ast(&file).and_then(|snapshot| {
+            serde_json::to_string_pretty(&snapshot)
+                .map_err(|e| format!("Failed to serialize AST snapshot: {}", e))
+        }) {
+            Ok(json) => json,
+            Err(e) => {
+                eprintln!("ERROR: {}", e);
+                std::process::exit(1);
+            }
+        };
+        match dump_matches.get_one::<String>("output") {
+            Some(path) => {
+                if let Err(e) = std::fs::write(path, json) {
+                    eprintln!("ERROR: Failed to write AST snapshot to {}: {}", path, e);
+                    std::process::exit(1);
+                }
+            }
+            None => println!("{}", json),
+        }
+        return;
+    }
+
+    // Run a single rule on a captured syntax tree instead of analyzing
+    if let Some(run_matches) = matches
+        .subcommand_matches("ast")
+        .and_then(|ast_matches| ast_matches.subcommand_matches("run-rule"))
+    {
+        let rule = run_matches
+            .get_one::<String>("rule")
+            .cloned()
+            .unwrap_or_default();
+        let findings = run_matches
+            .get_one::<String>("options")
+            .map(|options| {
+                serde_json::from_str::<Value>(options)
+                    .map_err(|e| format!("Invalid rule options: {}", e))
+            })
+            .transpose()
+            .and_then(|options| {
+                let snapshot = load_snapshot(run_matches.get_one::<String>("ast").unwrap())?;
+                let registry = single_rule_registry(&rule, options)?;
+                let diagnostics =
+                    run_on_snapshot(&snapshot, &registry, run_matches.get_flag("force"))?;
+                Ok(snapshot_findings(&snapshot, &diagnostics))
+            });
+        match findings {
+            Ok(findings) if run_matches.get_flag("json") => {
+                match serde_json::to_string_pretty(&findings) {
+                    Ok(json) => println!("{}", json),
+                    Err(e) => eprintln!("ERROR: Failed to serialize findings: {}", e),
+                }
+            }
+            Ok(findings) => {
+                for finding in &findings {
+                    println!(
+                        "{}:{} {}: {}",
+                        finding.line, finding.column, finding.rule, finding.message
+                    );
+                    println!("  {}", finding.nodes.join(" > "));
+                }
+                println!("{} findings of {}", findings.len(), rule);
+            }
+            Err(e) => {
+                eprintln!("ERROR: {}", e);
+                std::process::exit(1);
+            }
+        }
+        return;
+    }
+
+    // Show the node at a position of a file and its ancestors
+    if let Some(inspect_matches) = matches
+        .subcommand_matches("ast")
+        .and_then(|ast_matches| ast_matches.subcommand_matches("inspect"))
+    {
+        let file = inspect_matches
+            .get_one::<String>("FILE")
+            .cloned()
+            .unwrap_or_default();
+        let line = *inspect_matches.get_one::<usize>("line").unwrap();
+        let column = *inspect_matches.get_one::<usize>("col").unwrap();
+        let inspection = get_column_unit(&config).and_then(|unit| {
+            let snapshot = dump_ast(&file)?;
+            inspect_position(&snapshot, line, column, unit)
+        });
+        match inspection {
+            Ok(inspection) if inspect_matches.get_flag("json") => {
+                match serde_json::to_string_pretty(&inspection) {
+                    Ok(json) => println!("{}", json),
+                    Err(e) => eprintln!("ERROR: Failed to serialize node: {}", e),
+                }
+            }
+            Ok(inspection) => {
+                println!("{}", inspection.path.join(" > "));
+                match serde_json::to_string_pretty(&inspection.node) {
+                    Ok(json) => println!("{}", json),
+                    Err(e) => eprintln!("ERROR: Failed to serialize node: {}", e),
+                }
+                println!("{}", inspection.text);
+                if !inspection.children.is_empty() {
+                    let children: Vec<&str> = inspection
+                        .children
+                        .iter()
+                        .map(|child| child.kind.as_str())
+                        .collect();
+                    println!("Children: {}", children.join(", "));
+                }
+            }
+            Err(e) => {
+                eprintln!("ERROR: {}", e);
+                std::process::exit(1);
+            }
+        }
+        return;
+    }
+
+    // Show the effectiveness of the rules over the recorded runs
+    if let Some(stats_matches) = matches
+        .subcommand_matches("rules")
+        .and_then(|rules_matches| rules_matches.subcommand_matches("stats"))
+    {
+        let output_dir = stats_matches
+            .get_one::<String>("output-dir")
+            .cloned()
+            .or_else(|| config.output_dir.clone())
+            .unwrap_or_else(|| "findings".to_string());
+        match collect_rule_stats(&output_dir) {
+            Ok((_, stats)) if stats_matches.get_flag("json") => {
+                match serde_json::to_string_pretty(&stats) {
+                    Ok(json) => println!("{}", json),
+                    Err(e) => eprintln!("ERROR: Failed to serialize rule statistics: {}", e),
+                }
+            }
+            Ok((runs, stats)) => print_rule_stats(runs, &stats),
+            Err(e) => {
+                eprintln!("ERROR: {}", e);
+                std::process::exit(1);
+            }
+        }
+        return;
+    }
+
+    // Generate the rules reference from the registered rules
+    if let Some(docs_matches) = matches
+        .subcommand_matches("rules")
+        .and_then(|rules_matches| rules_matches.subcommand_matches("docs"))
+    {
+        let reference = generate_rules_reference(&create_default_registry());
+        match docs_matches.get_one::<String>("output") {
+            Some(path) => {
+                if let Err(e) = std::fs::write(path, reference) {
+                    eprintln!("ERROR: Failed to write rules reference to {}: {}", path, e);
+                    std::process::exit(1);
+                }
+            }
+            None => print!("{}", reference),
+        }
+        return;
+    }
+
+    // Search the semantic index of a previous run instead of analyzing
+    if let Some(search_matches) = matches.subcommand_matches("search") {
+        let query = search_matches
+            .get_one::<String>("QUERY")
+            .cloned()
+            .unwrap_or_default();
+        let limit = search_matches.get_one::<usize>("limit").copied().unwrap_or(10);
+        let output_dir = search_matches
+            .get_one::<String>("output-dir")
+            .cloned()
+            .or_else(|| config.output_dir.clone())
+            .unwrap_or_else(|| "findings".to_string());
+
+        if let Err(e) = run_search(&query, limit, &config, &output_dir) {
+            eprintln!("ERROR: {}", e);
+            std::process::exit(1);
+        }
+        return;
+    }
+
+    // Audit the suppressions instead of analyzing
+    if let Some(list_matches) = matches
+        .subcommand_matches("suppressions")
+        .and_then(|suppressions_matches| suppressions_matches.subcommand_matches("list"))
+    {
+        let target = list_matches
+            .get_one::<String>("PATH")
+            .cloned()
+            .or_else(|| config.path.clone())
+            .unwrap_or_else(|| ".".to_string());
+        let baseline = match Baseline::load(
+            config
+                .suppressions
+                .as_deref()
+                .unwrap_or(DEFAULT_SUPPRESSIONS_PATH),
+        ) {
+            Ok(baseline) => baseline,
+            Err(e) => {
+                eprintln!("ERROR: {}", e);
+                std::process::exit(1);
+            }
+        };
+
+        let listings = list_suppressions(&target, &baseline, debug_level);
+        if list_matches.get_flag("json") {
+            match serde_json::to_string_pretty(&listings) {
+                Ok(json) => println!("{}", json),
+                Err(e) => eprintln!("ERROR: Failed to serialize suppressions: {}", e),
+            }
+        } else {
+            print_suppressions(&listings);
+        }
+        return;
+    }
+
+    // Scan the repositories of an organization instead of a single project
+    if let Some(scan_matches) = matches
+        .subcommand_matches("org")
+        .and_then(|org_matches| org_matches.subcommand_matches("scan"))
+    {
+        configure_thread_pool(&config, debug_level);
+        let org_config = scan_matches
+            .get_one::<String>("config")
+            .map_or("repos.yaml", String::as_str);
+        match scan_org(org_config, debug_level) {
+            Ok(report) => {
+                print_org_report(&report);
+                if report.repos.iter().any(|repo| repo.error.is_some()) {
+                    std::process::exit(1);
+                }
+            }
+            Err(e) => {
+                eprintln!("ERROR: {}", e);
+                std::process::exit(1);
+            }
+        }
+        return;
+    }
+
+    // Upload the findings of the last run to the backend instead of analyzing
+    if let Some(publish_matches) = matches.subcommand_matches("publish") {
+        let output_dir = publish_matches
+            .get_one::<String>("dir")
+            .cloned()
+            .or_else(|| config.output_dir.clone())
+            .unwrap_or_else(|| "findings".to_string());
+        // The first argument is the subcommand, so the analyzed directory is the configured one
+        let target_path = config.path.clone().unwrap_or_else(|| ".".to_string());
+        let publish_config = config.publish.clone().unwrap_or_default();
+
+        if let Err(e) = publish_results(
+            &publish_config,
+            &output_dir,
+            std::path::Path::new(&target_path),
+            debug_level,
+        ) {
+            eprintln!("ERROR: {}", e);
+            std::process::exit(1);
+        }
+        return;
+    }
+
+    // Serve the reports of a previous run to the frontend instead of analyzing
+    if let Some(serve_matches) = matches.subcommand_matches("serve-results") {
+        let dir = serve_matches
+            .get_one::<String>("dir")
+            .cloned()
+            .or_else(|| config.output_dir.clone())
+            .unwrap_or_else(|| "findings".to_string());
+        let port = serve_matches
+            .get_one::<u16>("port")
+            .copied()
+            .unwrap_or(DEFAULT_PORT);
+
+        let options = get_serve_options(&config, &env::args().collect::<Vec<_>>());
+        if let Err(e) = serve_results(&dir, port, &options, debug_level) {
+            eprintln!("ERROR: {}", e);
+            std::process::exit(1);
+        }
+        return;
+    }
 
-    // Find and process files
+    // Configure thread pool and find the files to analyze
+    configure_thread_pool(&config, debug_level);
     let dir_path = match matches.get_one::<String>("PATH") {
         Some(path) => path.clone(),
+        None if docker_layout.is_some() => config.path.clone().unwrap_or_default(),
         None => get_target_path(&config, &env::args().collect::<Vec<_>>()),
     };
 
-    let (files, scan_duration) = find_files(&dir_path, debug_level);
-    let (analysis_results, analysis_duration) =
-        process_files(&files, &rules_registry_arc, debug_level);
-
-    // Export results
-    let metrics = aggregate_metrics(&analysis_results, scan_duration, analysis_duration);
-    export_results(&config, &metrics, &analysis_results, debug_level);
+    // Fails fast on a rules configuration written for another schema version
+    let analysis = match Sentinel::new(config.clone())
+        .with_args(env::args().collect())
+        .with_target(&dir_path)
+        .with_debug_level(debug_level)
+        .run()
+    {
+        Ok(analysis) => analysis,
+        Err(e) => {
+            eprintln!("ERROR: {}", e);
+            std::process::exit(if docker_layout.is_some() {
+                EXIT_INVALID_SETUP
+            } else {
+                1
+            });
+        }
+    };
+    analysis.export(&config, debug_level);
 
     // Determine the path to findings.json
     let output_dir_str = config.output_dir.as_deref().unwrap_or("findings");
@@ -121,6 +557,14 @@ fn main() {
             }
         }
     }
+
+    // A failed quality gate fails every run, the fail_on policy only container runs
+    if analysis.failed_quality_gate() {
+        std::process::exit(EXIT_FINDINGS);
+    }
+    if docker_layout.is_some() {
+        std::process::exit(exit_code(&analysis.results, config.fail_on.as_deref()));
+    }
 }
 
 fn send_results_to_api(
@@ -0,0 +1,651 @@
+//! Message catalog for localized findings
+//!
+//! Rules write their messages and help texts in English. The catalog maps each of them to a
+//! stable key and a template per locale, with `{name}` placeholders for the parts taken from
+//! the code, e.g. `security-eval.message`:
+//!
+//! ```text
+//! en: Dynamic code evaluation through {what}
+//! de: Dynamische Code-Auswertung über {what}
+//! ```
+//!
+//! Findings are exported with the key and the parameters next to the message rendered in the
+//! locale of `--locale` (or `locale` in `sentinel.json`), so a frontend can render them in the
+//! language of its user. Messages without a catalog entry, like parse errors or help texts
+//! from the configuration, are kept as written and exported without a key.
+
+use std::collections::BTreeMap;
+
+/// Languages of the catalog
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub enum Locale {
+    #[default]
+    En,
+    De,
+}
+
+impl Locale {
+    /// Parse a locale name, region suffixes like `de-CH` are ignored
+    pub fn parse(name: &str) -> Result<Self, String> {
+        let language = name.split(['-', '_']).next().unwrap_or_default();
+        match language.to_lowercase().as_str() {
+            "en" => Ok(Self::En),
+            "de" => Ok(Self::De),
+            _ => Err(format!("Unknown locale {}, expected en or de", name)),
+        }
+    }
+
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            Self::En => "en",
+            Self::De => "de",
+        }
+    }
+}
+
+/// A message of the catalog with its templates
+#[derive(Debug, Clone, Copy)]
+pub struct CatalogEntry {
+    /// Stable key, the ID of the rule followed by the name of the message
+    pub key: &'static str,
+    pub en: &'static str,
+    pub de: &'static str,
+}
+
+impl CatalogEntry {
+    /// Get the template of a locale
+    pub fn template(&self, locale: Locale) -> &'static str {
+        match locale {
+            Locale::En => self.en,
+            Locale::De => self.de,
+        }
+    }
+}
+
+/// A message identified in the catalog
+#[derive(Debug, Clone, PartialEq)]
+pub struct Message {
+    pub key: &'static str,
+    pub params: BTreeMap<String, String>,
+}
+
+impl Message {
+    /// Render the message in a locale
+    pub fn render(&self, locale: Locale) -> String {
+        let template = CATALOG
+            .iter()
+            .find(|entry| entry.key == self.key)
+            .map_or("", |entry| entry.template(locale));
+        fill_template(template, &self.params)
+    }
+}
+
+/// Messages of the built-in rules
+///
+/// Where templates of a rule overlap, the more specific one comes first.
+pub const CATALOG: &[CatalogEntry] = &[
+    CatalogEntry {
+        key: "no-debugger.message",
+        en: "`debugger` statement is not allowed",
+        de: "`debugger`-Anweisungen sind nicht erlaubt",
+    },
+    CatalogEntry {
+        key: "no-empty-pattern.message",
+        en: "empty destructuring pattern is not allowed",
+        de: "Leere Destrukturierungsmuster sind nicht erlaubt",
+    },
+    CatalogEntry {
+        key: "angular-bootstrap-module.message",
+        en: "Application is bootstrapped with bootstrapModule",
+        de: "Die Anwendung wird mit bootstrapModule gestartet",
+    },
+    CatalogEntry {
+        key: "angular-bootstrap-module.help",
+        en: "Use bootstrapApplication() with a standalone root component and an ApplicationConfig instead",
+        de: "Verwende stattdessen bootstrapApplication() mit einer Standalone-Root-Komponente und einer ApplicationConfig",
+    },
+    CatalogEntry {
+        key: "angular-common-module-import.message",
+        en: "CommonModule import can be replaced by specific imports",
+        de: "Der Import von CommonModule kann durch gezielte Imports ersetzt werden",
+    },
+    CatalogEntry {
+        key: "angular-common-module-import.help",
+        en: "Import only the directives and pipes the template uses (e.g. AsyncPipe, NgClass), and use the built-in control flow instead of NgIf/NgFor",
+        de: "Importiere nur die Direktiven und Pipes, die das Template verwendet (z. B. AsyncPipe, NgClass), und nutze den eingebauten Control Flow statt NgIf/NgFor",
+    },
+    CatalogEntry {
+        key: "angular-component-class-suffix.message",
+        en: "Angular component class '{class}' must have suffix '{suffix}'",
+        de: "Die Angular-Komponentenklasse '{class}' muss auf '{suffix}' enden",
+    },
+    CatalogEntry {
+        key: "angular-component-class-suffix.help",
+        en: "Rename the class to end with '{suffix}' to follow Angular naming convention",
+        de: "Benenne die Klasse gemäß der Angular-Namenskonvention so um, dass sie auf '{suffix}' endet",
+    },
+    CatalogEntry {
+        key: "angular-component-styles.missing-file",
+        en: "Style file {file} does not exist",
+        de: "Die Style-Datei {file} existiert nicht",
+    },
+    CatalogEntry {
+        key: "angular-component-styles.missing-file.help",
+        en: "Fix the path or remove the reference",
+        de: "Korrigiere den Pfad oder entferne den Verweis",
+    },
+    CatalogEntry {
+        key: "angular-component-styles.empty-file",
+        en: "Style file {file} contains no styles",
+        de: "Die Style-Datei {file} enthält keine Styles",
+    },
+    CatalogEntry {
+        key: "angular-component-styles.empty-file.help",
+        en: "Remove the empty style file and its reference from the component",
+        de: "Entferne die leere Style-Datei und ihren Verweis aus der Komponente",
+    },
+    CatalogEntry {
+        key: "angular-component-styles.ng-deep",
+        en: "::ng-deep used in {location}",
+        de: "::ng-deep wird in {location} verwendet",
+    },
+    CatalogEntry {
+        key: "angular-component-styles.ng-deep.help",
+        en: "::ng-deep is deprecated; style the child component through CSS custom properties or its inputs, or move the styles to a global stylesheet",
+        de: "::ng-deep ist veraltet; style die Kindkomponente über CSS Custom Properties oder ihre Inputs, oder verschiebe die Styles in ein globales Stylesheet",
+    },
+    CatalogEntry {
+        key: "angular-component-styles.nesting",
+        en: "Selector nested {depth} levels deep in {location}",
+        de: "Selektor in {location} ist {depth} Ebenen tief verschachtelt",
+    },
+    CatalogEntry {
+        key: "angular-component-styles.nesting.help",
+        en: "Flatten the selector to at most {max_depth} levels, e.g. with a class on the styled element",
+        de: "Reduziere den Selektor auf höchstens {max_depth} Ebenen, z. B. mit einer Klasse am gestylten Element",
+    },
+    CatalogEntry {
+        key: "angular-directive-class-suffix.message",
+        en: "Angular directive class '{class}' must have suffix '{suffix}'",
+        de: "Die Angular-Direktivenklasse '{class}' muss auf '{suffix}' enden",
+    },
+    CatalogEntry {
+        key: "angular-directive-class-suffix.help",
+        en: "Rename the class to end with '{suffix}' to follow Angular naming convention",
+        de: "Benenne die Klasse gemäß der Angular-Namenskonvention so um, dass sie auf '{suffix}' endet",
+    },
+    CatalogEntry {
+        key: "angular-deprecated-api.message",
+        en: "{subject} is deprecated since {package} {version}",
+        de: "{subject} ist seit {package} {version} veraltet",
+    },
+    CatalogEntry {
+        key: "angular-input-count.message",
+        en: "Too many Angular input properties detected",
+        de: "Zu viele Angular-Inputs gefunden",
+    },
+    CatalogEntry {
+        key: "angular-input-count.help",
+        en: "Consider breaking this component into smaller components with fewer inputs",
+        de: "Teile die Komponente in kleinere Komponenten mit weniger Inputs auf",
+    },
+    CatalogEntry {
+        key: "angular-legacy-decorators.message",
+        en: "Legacy Angular @{decorator} decorator detected",
+        de: "Veralteter Angular-Decorator @{decorator} gefunden",
+    },
+    CatalogEntry {
+        key: "angular-legacy-decorators.help",
+        en: "Replace @{decorator} decorator with the signal-based alternative {alternative}()",
+        de: "Ersetze den Decorator @{decorator} durch die signalbasierte Alternative {alternative}()",
+    },
+    CatalogEntry {
+        key: "angular-obsolete-standalone-true.message",
+        en: "Obsolete 'standalone: true' property detected",
+        de: "Überflüssige Eigenschaft 'standalone: true' gefunden",
+    },
+    CatalogEntry {
+        key: "angular-obsolete-standalone-true.help",
+        en: "you can safely remove this line when using angular 19+",
+        de: "Ab Angular 19 kann diese Zeile gefahrlos entfernt werden",
+    },
+    CatalogEntry {
+        key: "angular-on-push-change-detection.message",
+        en: "Component {component} does not use OnPush change detection",
+        de: "Die Komponente {component} verwendet keine OnPush-Change-Detection",
+    },
+    CatalogEntry {
+        key: "angular-on-push-change-detection.help",
+        en: "Add 'changeDetection: ChangeDetectionStrategy.OnPush' to the @Component decorator, and make sure state changes go through inputs, signals or observables",
+        de: "Ergänze 'changeDetection: ChangeDetectionStrategy.OnPush' im @Component-Decorator und stelle sicher, dass Zustandsänderungen über Inputs, Signals oder Observables laufen",
+    },
+    CatalogEntry {
+        key: "angular-output-event-collision.message",
+        en: "Output name '{name}' collides with native DOM event",
+        de: "Der Output-Name '{name}' kollidiert mit einem nativen DOM-Event",
+    },
+    CatalogEntry {
+        key: "angular-output-event-collision.help",
+        en: "Choose a different name to avoid confusion with native browser events",
+        de: "Wähle einen anderen Namen, um Verwechslungen mit nativen Browser-Events zu vermeiden",
+    },
+    CatalogEntry {
+        key: "angular-prefer-inject.message",
+        en: "Constructor injection of {tokens}",
+        de: "Konstruktor-Injection von {tokens}",
+    },
+    CatalogEntry {
+        key: "angular-prefer-inject.help",
+        en: "Replace the constructor parameters with fields initialized by inject(), e.g. 'private readonly http = inject(HttpClient);'",
+        de: "Ersetze die Konstruktorparameter durch Felder, die mit inject() initialisiert werden, z. B. 'private readonly http = inject(HttpClient);'",
+    },
+    CatalogEntry {
+        key: "angular-service-fan-in.message",
+        en: "{token} is injected directly by {count} components (limit {limit})",
+        de: "{token} wird direkt von {count} Komponenten injiziert (Grenze {limit})",
+    },
+    CatalogEntry {
+        key: "angular-service-fan-in.help",
+        en: "Introduce a facade per feature or pass the data through inputs. Consumers: {consumers}",
+        de: "Führe pro Feature eine Facade ein oder reiche die Daten über Inputs weiter. Verwender: {consumers}",
+    },
+    CatalogEntry {
+        key: "angular-standalone-candidate.declaration",
+        en: "'{name}' is declared in an NgModule and could be standalone",
+        de: "'{name}' ist in einem NgModule deklariert und könnte standalone sein",
+    },
+    CatalogEntry {
+        key: "angular-standalone-candidate.declaration.help",
+        en: "Remove the declaration from the NgModule and import it where it is used, or run `ng generate @angular/core:standalone`",
+        de: "Entferne die Deklaration aus dem NgModule und importiere sie dort, wo sie verwendet wird, oder führe `ng generate @angular/core:standalone` aus",
+    },
+    CatalogEntry {
+        key: "angular-standalone-candidate.opt-out",
+        en: "'standalone: false' opts out of standalone components",
+        de: "'standalone: false' verzichtet auf Standalone-Komponenten",
+    },
+    CatalogEntry {
+        key: "angular-standalone-candidate.opt-out.help",
+        en: "Remove 'standalone: false' and import the dependencies of the component directly",
+        de: "Entferne 'standalone: false' und importiere die Abhängigkeiten der Komponente direkt",
+    },
+    CatalogEntry {
+        key: "architecture-boundaries.message",
+        en: "Zone '{from}' must not import from zone '{to}'",
+        de: "Die Zone '{from}' darf nicht aus der Zone '{to}' importieren",
+    },
+    CatalogEntry {
+        key: "architecture-boundaries.no-dependencies.help",
+        en: "Zone '{from}' must not depend on other zones",
+        de: "Die Zone '{from}' darf von keiner anderen Zone abhängen",
+    },
+    CatalogEntry {
+        key: "architecture-boundaries.allowed.help",
+        en: "Zone '{from}' may only depend on: {allowed}",
+        de: "Die Zone '{from}' darf nur abhängen von: {allowed}",
+    },
+    CatalogEntry {
+        key: "i18n-untranslated-text.message",
+        en: "Untranslated text \"{text}\"",
+        de: "Unübersetzter Text \"{text}\"",
+    },
+    CatalogEntry {
+        key: "i18n-untranslated-text.localize.help",
+        en: "Mark the text for translation with $localize`...`",
+        de: "Markiere den Text mit $localize`...` zur Übersetzung",
+    },
+    CatalogEntry {
+        key: "i18n-untranslated-text.attribute.help",
+        en: "Add an i18n-{attribute} attribute to the element",
+        de: "Ergänze das Attribut i18n-{attribute} am Element",
+    },
+    CatalogEntry {
+        key: "i18n-untranslated-text.element.help",
+        en: "Add an i18n attribute to the element containing the text",
+        de: "Ergänze ein i18n-Attribut an dem Element, das den Text enthält",
+    },
+    CatalogEntry {
+        key: "large-class.message",
+        en: "Class {class} is too large ({exceeded})",
+        de: "Die Klasse {class} ist zu groß ({exceeded})",
+    },
+    CatalogEntry {
+        key: "large-class.help",
+        en: "Split the class by responsibility: move logic into services or facades and parts of the template into child components",
+        de: "Teile die Klasse nach Verantwortlichkeiten auf: verschiebe Logik in Services oder Facades und Teile des Templates in Kindkomponenten",
+    },
+    CatalogEntry {
+        key: "policy-banned-imports.message",
+        en: "Import from '{source}' is not allowed",
+        de: "Der Import aus '{source}' ist nicht erlaubt",
+    },
+    CatalogEntry {
+        key: "policy-license-header.message",
+        en: "Missing or invalid license header",
+        de: "Fehlender oder ungültiger Lizenz-Header",
+    },
+    CatalogEntry {
+        key: "policy-license-header.help",
+        en: "Add a header comment at the top of the file matching '{pattern}'",
+        de: "Füge am Anfang der Datei einen Header-Kommentar ein, der zu '{pattern}' passt",
+    },
+    CatalogEntry {
+        key: "rxjs-subscription-leak.take-until",
+        en: "takeUntil notifier is never triggered in {class}",
+        de: "Der takeUntil-Notifier wird in {class} nie ausgelöst",
+    },
+    CatalogEntry {
+        key: "rxjs-subscription-leak.take-until.help",
+        en: "Call next() and complete() on the takeUntil notifier in ngOnDestroy, or use takeUntilDestroyed() instead",
+        de: "Rufe in ngOnDestroy next() und complete() auf dem takeUntil-Notifier auf, oder verwende stattdessen takeUntilDestroyed()",
+    },
+    CatalogEntry {
+        key: "rxjs-subscription-leak.field",
+        en: "Subscription stored in '{field}' is never unsubscribed in {class}",
+        de: "Die Subscription in '{field}' wird in {class} nie beendet",
+    },
+    CatalogEntry {
+        key: "rxjs-subscription-leak.field.help",
+        en: "Call this.{field}.unsubscribe() in ngOnDestroy, or pipe the source through takeUntilDestroyed()",
+        de: "Rufe this.{field}.unsubscribe() in ngOnDestroy auf, oder leite die Quelle durch takeUntilDestroyed()",
+    },
+    CatalogEntry {
+        key: "rxjs-subscription-leak.possible",
+        en: "Possible subscription leak in {class}",
+        de: "Mögliches Subscription-Leck in {class}",
+    },
+    CatalogEntry {
+        key: "rxjs-subscription-leak.possible.help",
+        en: "Pipe the source through takeUntilDestroyed(), or store the subscription and unsubscribe in ngOnDestroy",
+        de: "Leite die Quelle durch takeUntilDestroyed(), oder speichere die Subscription und beende sie in ngOnDestroy",
+    },
+    CatalogEntry {
+        key: "secrets-detection.message",
+        en: "Possible hardcoded secret: {kind}",
+        de: "Mögliches fest codiertes Geheimnis: {kind}",
+    },
+    CatalogEntry {
+        key: "secrets-detection.help",
+        en: "Move the secret to an environment variable or a secret manager and rotate it, since it is part of the repository history",
+        de: "Verschiebe das Geheimnis in eine Umgebungsvariable oder einen Secret-Manager und tausche es aus, da es Teil der Repository-Historie ist",
+    },
+    CatalogEntry {
+        key: "security-bypass-security-trust.message",
+        en: "Call to DomSanitizer.{method} bypasses sanitization",
+        de: "Der Aufruf von DomSanitizer.{method} umgeht die Bereinigung",
+    },
+    CatalogEntry {
+        key: "security-bypass-security-trust.help",
+        en: "Make sure the value can never contain user-controlled data, or sanitize it with DomSanitizer.sanitize() instead",
+        de: "Stelle sicher, dass der Wert nie benutzerkontrollierte Daten enthält, oder bereinige ihn stattdessen mit DomSanitizer.sanitize()",
+    },
+    CatalogEntry {
+        key: "security-eval.message",
+        en: "Dynamic code evaluation through {what}",
+        de: "Dynamische Code-Auswertung über {what}",
+    },
+    CatalogEntry {
+        key: "security-eval.help",
+        en: "Avoid evaluating strings as code; use functions or a safe parser instead",
+        de: "Werte keine Zeichenketten als Code aus; verwende stattdessen Funktionen oder einen sicheren Parser",
+    },
+    CatalogEntry {
+        key: "security-http-url-concatenation.message",
+        en: "HttpClient request with a string-built URL",
+        de: "HttpClient-Anfrage mit einer aus Zeichenketten zusammengesetzten URL",
+    },
+    CatalogEntry {
+        key: "security-http-url-concatenation.help",
+        en: "Encode dynamic path segments with encodeURIComponent() and pass query parameters through the `params` option",
+        de: "Kodiere dynamische Pfadsegmente mit encodeURIComponent() und übergib Query-Parameter über die Option `params`",
+    },
+    CatalogEntry {
+        key: "security-inner-html.message",
+        en: "Unsanitized HTML written through {sink}",
+        de: "Unbereinigtes HTML wird über {sink} geschrieben",
+    },
+    CatalogEntry {
+        key: "security-inner-html.help",
+        en: "Use textContent, Angular template bindings or Renderer2 instead of writing raw HTML to the DOM",
+        de: "Verwende textContent, Angular-Template-Bindings oder Renderer2, statt rohes HTML in das DOM zu schreiben",
+    },
+    CatalogEntry {
+        key: "security-taint-flow.message",
+        en: "User-controlled data from '{source}' reaches '{sink}'",
+        de: "Benutzerkontrollierte Daten aus '{source}' erreichen '{sink}'",
+    },
+    CatalogEntry {
+        key: "security-taint-flow.help",
+        en: "Sanitize or encode the value before it reaches the sink",
+        de: "Bereinige oder kodiere den Wert, bevor er die Senke erreicht",
+    },
+    CatalogEntry {
+        key: "test-focused-tests.message",
+        en: "Focused test {call} skips all other tests",
+        de: "Der fokussierte Test {call} überspringt alle anderen Tests",
+    },
+    CatalogEntry {
+        key: "test-focused-tests.help",
+        en: "Remove the focus before committing, e.g. use describe/it instead of fdescribe/fit",
+        de: "Entferne den Fokus vor dem Commit, z. B. mit describe/it statt fdescribe/fit",
+    },
+    CatalogEntry {
+        key: "test-unawaited-when-stable.message",
+        en: "The promise of whenStable() is not awaited",
+        de: "Auf das Promise von whenStable() wird nicht gewartet",
+    },
+    CatalogEntry {
+        key: "test-unawaited-when-stable.help",
+        en: "Await the call, or chain the assertions with .then(), so they run once the fixture is stable",
+        de: "Warte mit await auf den Aufruf oder hänge die Assertions mit .then() an, damit sie erst laufen, wenn die Fixture stabil ist",
+    },
+    CatalogEntry {
+        key: "todo-comments.with-text",
+        en: "{marker} comment: {text}",
+        de: "{marker}-Kommentar: {text}",
+    },
+    CatalogEntry {
+        key: "todo-comments.message",
+        en: "{marker} comment",
+        de: "{marker}-Kommentar",
+    },
+    CatalogEntry {
+        key: "todo-comments.help",
+        en: "Resolve the comment or track it in an issue",
+        de: "Löse den Kommentar auf oder erfasse ihn in einem Issue",
+    },
+    CatalogEntry {
+        key: "typescript-no-any.annotation",
+        en: "Explicit 'any' type",
+        de: "Expliziter Typ 'any'",
+    },
+    CatalogEntry {
+        key: "typescript-no-any.annotation.help",
+        en: "Use a specific type, or 'unknown' and narrow it with type guards before use",
+        de: "Verwende einen konkreten Typ, oder 'unknown' und grenze ihn vor der Verwendung mit Type Guards ein",
+    },
+    CatalogEntry {
+        key: "typescript-no-any.cast",
+        en: "Cast to 'any'",
+        de: "Cast nach 'any'",
+    },
+    CatalogEntry {
+        key: "typescript-no-any.cast.help",
+        en: "Casting to 'any' switches off type checking for the value; validate the value or fix the types it is cast between",
+        de: "Ein Cast nach 'any' schaltet die Typprüfung für den Wert ab; validiere den Wert oder korrigiere die Typen, zwischen denen gecastet wird",
+    },
+    CatalogEntry {
+        key: "typescript-no-any.parameter",
+        en: "Parameter without a type is implicitly 'any'",
+        de: "Ein Parameter ohne Typ ist implizit 'any'",
+    },
+    CatalogEntry {
+        key: "typescript-no-any.parameter.help",
+        en: "Annotate the parameter with its type, or give it a default value to infer the type from",
+        de: "Annotiere den Parameter mit seinem Typ, oder gib ihm einen Standardwert, aus dem der Typ abgeleitet wird",
+    },
+    CatalogEntry {
+        key: "typescript-non-null-assertion.message",
+        en: "TypeScript non-null assertion operator (!) usage detected",
+        de: "Verwendung des TypeScript-Non-Null-Assertion-Operators (!) gefunden",
+    },
+    CatalogEntry {
+        key: "typescript-non-null-assertion.help",
+        en: "The non-null assertion operator tells TypeScript to ignore potential null/undefined values, which can lead to runtime errors if the value is actually null. Consider:\n1. Using optional chaining (?.) with nullish coalescing (??)\n2. Adding proper runtime checks\n3. Redesigning the code to handle null/undefined cases explicitly",
+        de: "Der Non-Null-Assertion-Operator weist TypeScript an, mögliche null/undefined-Werte zu ignorieren, was zu Laufzeitfehlern führen kann, wenn der Wert tatsächlich null ist. Erwäge:\n1. Optional Chaining (?.) mit Nullish Coalescing (??)\n2. Passende Laufzeitprüfungen\n3. Den Code so umzubauen, dass null/undefined explizit behandelt wird",
+    },
+    CatalogEntry {
+        key: "typescript-type-assertion.non-null",
+        en: "TypeScript non-null assertion operator (!) usage detected",
+        de: "Verwendung des TypeScript-Non-Null-Assertion-Operators (!) gefunden",
+    },
+    CatalogEntry {
+        key: "typescript-type-assertion.non-null.help",
+        en: "The non-null assertion operator tells TypeScript to ignore potential null/undefined values, which can lead to runtime errors. Consider:\n1. Using optional chaining (?.) with nullish coalescing (??)\n2. Adding proper runtime checks\n3. Redesigning the code to handle null/undefined cases explicitly",
+        de: "Der Non-Null-Assertion-Operator weist TypeScript an, mögliche null/undefined-Werte zu ignorieren, was zu Laufzeitfehlern führen kann. Erwäge:\n1. Optional Chaining (?.) mit Nullish Coalescing (??)\n2. Passende Laufzeitprüfungen\n3. Den Code so umzubauen, dass null/undefined explizit behandelt wird",
+    },
+    CatalogEntry {
+        key: "typescript-type-assertion.type",
+        en: "Unsafe TypeScript type assertion detected",
+        de: "Unsichere TypeScript-Typ-Assertion gefunden",
+    },
+    CatalogEntry {
+        key: "typescript-type-assertion.type.help",
+        en: "Type assertions bypass TypeScript's type checking and can lead to runtime errors. Instead:\n1. Use type guards: function isType(value: unknown): value is Type { ... }\n2. Use instanceof checks: if (value instanceof Type)\n3. Use typeof checks: if (typeof value === 'string')\n4. Add runtime validation\n5. Consider redesigning the code to use proper type definitions",
+        de: "Typ-Assertions umgehen die Typprüfung von TypeScript und können zu Laufzeitfehlern führen. Stattdessen:\n1. Type Guards verwenden: function isType(value: unknown): value is Type { ... }\n2. instanceof-Prüfungen verwenden: if (value instanceof Type)\n3. typeof-Prüfungen verwenden: if (typeof value === 'string')\n4. Laufzeitvalidierung ergänzen\n5. Den Code mit passenden Typdefinitionen umbauen",
+    },
+    CatalogEntry {
+        key: "typescript-type-assertion.any",
+        en: "Type assertion through 'any' detected",
+        de: "Typ-Assertion über 'any' gefunden",
+    },
+    CatalogEntry {
+        key: "typescript-type-assertion.any.help",
+        en: "Using 'any' in type assertions is particularly dangerous as it completely bypasses type checking. Consider:\n1. Using proper type definitions\n2. Implementing type guards\n3. Adding runtime validation\n4. Using more specific types",
+        de: "'any' in Typ-Assertions ist besonders gefährlich, da es die Typprüfung vollständig umgeht. Erwäge:\n1. Passende Typdefinitionen\n2. Type Guards\n3. Laufzeitvalidierung\n4. Spezifischere Typen",
+    },
+    CatalogEntry {
+        key: "typescript-type-assertion.double",
+        en: "Double type assertion detected",
+        de: "Doppelte Typ-Assertion gefunden",
+    },
+    CatalogEntry {
+        key: "typescript-type-assertion.double.help",
+        en: "Double type assertions (e.g., 'as any as Type') are extremely unsafe and bypass TypeScript's type checking completely. Consider:\n1. Using proper type guards\n2. Adding runtime validation\n3. Improving type definitions\n4. Using type predicates for complex type narrowing",
+        de: "Doppelte Typ-Assertions (z. B. 'as any as Type') sind äußerst unsicher und umgehen die Typprüfung von TypeScript vollständig. Erwäge:\n1. Passende Type Guards\n2. Laufzeitvalidierung\n3. Bessere Typdefinitionen\n4. Type Predicates für komplexe Typeingrenzungen",
+    },
+];
+
+/// Identify a message of a rule in the catalog, with the parameters filled into its template
+pub fn identify(rule: &str, text: &str) -> Option<Message> {
+    CATALOG
+        .iter()
+        .filter(|entry| {
+            entry
+                .key
+                .strip_prefix(rule)
+                .is_some_and(|name| name.starts_with('.'))
+        })
+        .find_map(|entry| {
+            match_template(entry.en, text).map(|params| Message {
+                key: entry.key,
+                params,
+            })
+        })
+}
+
+/// Part of a template
+enum Part<'a> {
+    Text(&'a str),
+    Param(&'a str),
+}
+
+/// Split a template into text and `{name}` placeholders
+///
+/// Only names of letters, digits and underscores are placeholders, so braces of code in a
+/// help text like `{ ... }` stay text.
+fn parse_template(template: &str) -> Vec<Part<'_>> {
+    let is_name = |name: &str| {
+        !name.is_empty() && name.chars().all(|c| c.is_ascii_alphanumeric() || c == '_')
+    };
+    let mut parts = Vec::new();
+    let mut text_start = 0;
+    let mut search = 0;
+    while let Some(start) = template[search..].find('{').map(|start| search + start) {
+        let Some(end) = template[start..].find('}').map(|end| start + end) else {
+            break;
+        };
+        let name = &template[start + 1..end];
+        if !is_name(name) {
+            search = start + 1;
+            continue;
+        }
+        if start > text_start {
+            parts.push(Part::Text(&template[text_start..start]));
+        }
+        parts.push(Part::Param(name));
+        text_start = end + 1;
+        search = text_start;
+    }
+    if text_start < template.len() {
+        parts.push(Part::Text(&template[text_start..]));
+    }
+    parts
+}
+
+/// Match a text against a template and get the values of its placeholders
+///
+/// A placeholder takes the text up to the first occurrence of the text following it, or up
+/// to the end if it is the last part.
+fn match_template(template: &str, text: &str) -> Option<BTreeMap<String, String>> {
+    let parts = parse_template(template);
+    let mut params = BTreeMap::new();
+    let mut rest = text;
+
+    for (index, part) in parts.iter().enumerate() {
+        match part {
+            Part::Text(literal) => rest = rest.strip_prefix(literal)?,
+            Part::Param(name) => {
+                let value = match parts.get(index + 1) {
+                    Some(Part::Text(next)) if index + 2 == parts.len() => {
+                        rest.strip_suffix(next)?
+                    }
+                    Some(Part::Text(next)) => &rest[..rest.find(next)?],
+                    _ => rest,
+                };
+                if value.is_empty() {
+                    return None;
+                }
+                params.insert(name.to_string(), value.to_string());
+                rest = &rest[value.len()..];
+            }
+        }
+    }
+
+    rest.is_empty().then_some(params)
+}
+
+/// Fill the placeholders of a template, unknown placeholders are left as they are
+fn fill_template(template: &str, params: &BTreeMap<String, String>) -> String {
+    parse_template(template)
+        .into_iter()
+        .map(|part| match part {
+            Part::Text(text) => text.to_string(),
+            Part::Param(name) => params
+                .get(name)
+                .cloned()
+                .unwrap_or_else(|| format!("{{{}}}", name)),
+        })
+        .collect()
+}
+
+/// Localize a message or help text of a rule
+///
+/// Returns the text in the locale and the catalog message, or the text as written if it
+/// is not in the catalog.
+pub fn localize(rule: &str, text: &str, locale: Locale) -> (String, Option<Message>) {
+    match identify(rule, text) {
+        Some(message) => (message.render(locale), Some(message)),
+        None => (text.to_string(), None),
+    }
+}
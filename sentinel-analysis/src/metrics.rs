@@ -1,9 +1,23 @@
 use crate::FileAnalysisResult;
-use crate::exporter::export_findings_json;
+use crate::angular_graph::export_angular_graph;
+use crate::chunker::{ChunkOptions, collect_chunks, export_chunks};
+use crate::counters::CounterAlertOutcome;
+use crate::directories::DEFAULT_DIRECTORY_DEPTH;
+use crate::embeddings::export_embeddings;
+use crate::escalation::Escalation;
+use crate::exporter::{ExportOptions, export_findings_json};
+use crate::history::record_run;
+use crate::hotspots::{DEFAULT_HOTSPOT_LIMIT, collect_hotspots};
+use crate::notifications::send_email_report;
+use crate::output::OutputRegistry;
+use crate::quality_gates::QualityGateReport;
+use crate::rules_registry::RulesRegistry;
+use crate::schema::BuildInfo;
 use crate::utilities::config::Config;
+use crate::utilities::paths::PathBase;
 use crate::utilities::{DebugLevel, log};
 use serde::{Deserialize, Serialize};
-use std::collections::HashMap;
+use std::collections::{BTreeMap, HashMap};
 use std::fs::{self, File, OpenOptions};
 use std::io::{Read, Write};
 use std::ops::AddAssign;
@@ -37,6 +51,9 @@ pub struct Metrics {
 #[derive(Serialize, Deserialize, Clone)]
 struct ExportableMetrics {
     timestamp: String,
+    // Build of the analyzer, to reproduce the run
+    #[serde(default)]
+    build: BuildInfo,
     // Wall time metrics
     total_duration_ms: u64,
     scan_duration_ms: u64,
@@ -120,6 +137,29 @@ impl Metrics {
         }
     }
 
+    /// Merge the metrics of another run, e.g. of another shard
+    ///
+    /// Per-file times of `other` replace those of the same file, rule times and counts are
+    /// added up, and scan and analysis durations are summed.
+    pub fn merge(&mut self, other: &Metrics) {
+        self.file_times.extend(other.file_times.clone());
+        self.parse_times.extend(other.parse_times.clone());
+        self.semantic_times.extend(other.semantic_times.clone());
+
+        for (rule_name, duration) in &other.rule_times {
+            self.rule_times
+                .entry(rule_name.clone())
+                .or_insert(Duration::default())
+                .add_assign(*duration);
+        }
+        for (rule_name, count) in &other.rule_counts {
+            *self.rule_counts.entry(rule_name.clone()).or_insert(0) += count;
+        }
+
+        self.scan_duration = sum_durations(self.scan_duration, other.scan_duration);
+        self.analysis_duration = sum_durations(self.analysis_duration, other.analysis_duration);
+    }
+
     /// Stop timing and record total duration
     pub fn stop(&mut self) {
         self.total_duration = Some(self.start_time.elapsed());
@@ -383,6 +423,7 @@ impl Metrics {
 
         Ok(ExportableMetrics {
             timestamp: chrono::Local::now().to_rfc3339(),
+            build: BuildInfo::current(),
             total_duration_ms: total_duration.as_millis() as u64,
             scan_duration_ms: scan_duration.as_millis() as u64,
             analysis_duration_ms: analysis_duration.as_millis() as u64,
@@ -530,6 +571,14 @@ impl Metrics {
     }
 }
 
+/// Add two optional durations, keeping `None` only if both are missing
+fn sum_durations(a: Option<Duration>, b: Option<Duration>) -> Option<Duration> {
+    match (a, b) {
+        (Some(a), Some(b)) => Some(a + b),
+        (a, b) => a.or(b),
+    }
+}
+
 /// Aggregate metrics from analysis results
 pub fn aggregate_metrics(
     analysis_results: &[FileAnalysisResult],
@@ -550,6 +599,10 @@ pub fn aggregate_metrics(
             rule_durations: result.rule_durations.clone(),
             total_duration: result.total_duration,
             diagnostics: Vec::new(), // Empty vec as diagnostics aren't needed for metrics
+            angular_symbols: Vec::new(),
+            content_hash: result.content_hash,
+            generated: result.generated,
+            skipped: result.skipped.clone(),
         };
         metrics.aggregate_file_result(result_to_aggregate);
     }
@@ -594,6 +647,12 @@ pub fn export_results(
     config: &Config,
     metrics: &Metrics,
     analysis_results: &[FileAnalysisResult],
+    path_base: &PathBase,
+    registry: &RulesRegistry,
+    escalations: &[Escalation],
+    quality_gate: Option<&QualityGateReport>,
+    counters: &BTreeMap<String, usize>,
+    counter_alerts: &[CounterAlertOutcome],
     debug_level: DebugLevel,
 ) {
     export_metrics(config, metrics, debug_level);
@@ -602,6 +661,97 @@ pub fn export_results(
     let output_dir =
         crate::utilities::config::get_output_dir(config, &std::env::args().collect::<Vec<_>>());
 
-    // Pass output_dir to export_findings_json
-    export_findings_json(analysis_results, metrics, debug_level, &output_dir);
+    let args = std::env::args().collect::<Vec<_>>();
+
+    // An invalid editor only loses the links, not the reports
+    let editor_links = crate::utilities::config::get_editor_links(config, &args, path_base)
+        .unwrap_or_else(|err| {
+            log(DebugLevel::Error, debug_level, &err);
+            None
+        });
+
+    // The locale is validated before the analysis, see `Sentinel::run`
+    let locale = crate::utilities::config::get_locale(config, &args).unwrap_or_default();
+
+    let options = ExportOptions {
+        output_dir: &output_dir,
+        path_base,
+        ai_suggestions: crate::utilities::config::get_ai_suggestions(config, &args),
+        directory_depth: config.directory_depth.unwrap_or(DEFAULT_DIRECTORY_DEPTH),
+        print_tree: crate::utilities::config::get_tree(&args),
+        hotspots: collect_hotspots(
+            analysis_results,
+            path_base,
+            crate::utilities::config::get_churn(config, &args),
+            config.churn_since.as_deref(),
+            config.hotspot_limit.unwrap_or(DEFAULT_HOTSPOT_LIMIT),
+            debug_level,
+        ),
+        column_unit: crate::utilities::config::get_column_unit(config).unwrap_or_default(),
+        escalations: escalations.to_vec(),
+        limits: crate::utilities::config::get_finding_limits(config, &args),
+        editor_links: editor_links.as_ref(),
+        quality_gate: quality_gate.cloned(),
+        locale,
+        rule_taxonomies: registry.get_rule_taxonomies(),
+        counters: counters.clone(),
+        counter_alerts: counter_alerts
+            .iter()
+            .filter(|outcome| outcome.breached)
+            .cloned()
+            .collect(),
+    };
+    let findings_export = export_findings_json(analysis_results, metrics, options, debug_level);
+
+    // Write the other formats next to findings.json, which --report-fp reads
+    match crate::utilities::config::get_outputs(config, &args) {
+        Ok(outputs) => OutputRegistry::for_project(path_base).write_all(
+            &findings_export,
+            &outputs,
+            &output_dir,
+            debug_level,
+        ),
+        Err(err) => log(DebugLevel::Error, debug_level, &err),
+    }
+    if crate::utilities::config::get_history(config, &args) {
+        record_run(
+            &findings_export,
+            &output_dir,
+            &path_base.resolve("."),
+            debug_level,
+        );
+    }
+    if let Some(email) = config
+        .notifications
+        .as_ref()
+        .and_then(|notifications| notifications.email.as_ref())
+    {
+        // A failed delivery is reported but does not fail the run
+        if let Err(err) = send_email_report(email, &findings_export, debug_level) {
+            log(
+                DebugLevel::Error,
+                debug_level,
+                &format!("Failed to email the report: {}", err),
+            );
+        }
+    }
+    export_angular_graph(analysis_results, debug_level, &output_dir);
+
+    // Chunks are only collected if they are written or embedded
+    let emit_chunks = crate::utilities::config::get_emit_chunks(config, &args);
+    let embed = crate::utilities::config::get_embed(config, &args);
+    if emit_chunks || embed {
+        match ChunkOptions::from_config(config, debug_level) {
+            Ok(options) => {
+                let chunks = collect_chunks(analysis_results, &options, path_base, debug_level);
+                if emit_chunks {
+                    export_chunks(&chunks, debug_level, &output_dir);
+                }
+                if embed {
+                    export_embeddings(&chunks, config, debug_level, &output_dir);
+                }
+            }
+            Err(err) => log(DebugLevel::Error, debug_level, &err),
+        }
+    }
 }
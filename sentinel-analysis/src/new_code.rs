@@ -0,0 +1,226 @@
+//! Enforcement on new code only
+//!
+//! With `--new-code-only` the findings are limited to the lines changed on the current
+//! branch, so a pull request gate reports and fails only on the findings it introduces.
+//! The changed lines are taken from `git diff` against the merge base with the base branch,
+//! which includes the changes that are not committed yet, and untracked files count as new
+//! as a whole. The base branch is `--new-code-base` (or `new_code_base` in
+//! `sentinel.json`), otherwise the first of `origin/HEAD`, `origin/main`, `origin/master`,
+//! `main` and `master` that exists.
+//!
+//! The baseline still applies first: suppressed findings on changed lines stay suppressed.
+//! Project-level findings are not tied to a line and are left out.
+
+use crate::FileAnalysisResult;
+use crate::hotspots::git;
+use crate::utilities::paths::PathBase;
+use std::collections::{HashMap, HashSet};
+use std::fs;
+use std::path::{Path, PathBuf};
+
+/// Branches tried in order when no base branch is configured
+pub const DEFAULT_BASE_BRANCHES: &[&str] = &[
+    "origin/HEAD",
+    "origin/main",
+    "origin/master",
+    "main",
+    "master",
+];
+
+/// Lines changed in a file
+#[derive(Debug, Clone, PartialEq)]
+enum FileChanges {
+    /// The whole file is new, e.g. untracked
+    All,
+    /// 1-based numbers of the added or modified lines
+    Lines(HashSet<usize>),
+}
+
+/// Lines changed on the current branch, by absolute path
+#[derive(Debug, Clone, Default)]
+pub struct ChangedLines {
+    files: HashMap<PathBuf, FileChanges>,
+}
+
+impl ChangedLines {
+    /// Collect the lines changed since the merge base with a base branch
+    ///
+    /// `dir` is any directory of the repository.
+    pub fn load(dir: &Path, base: Option<&str>) -> Result<Self, String> {
+        let toplevel = PathBuf::from(git(dir, &["rev-parse", "--show-toplevel"])?.trim());
+        let base = match base {
+            Some(base) => base.to_string(),
+            None => DEFAULT_BASE_BRANCHES
+                .iter()
+                .copied()
+                .find(|&branch| {
+                    git(&toplevel, &["rev-parse", "--verify", "--quiet", branch]).is_ok()
+                })
+                .map(str::to_string)
+                .ok_or("No base branch found for --new-code-only, set one with --new-code-base")?,
+        };
+        let merge_base = git(&toplevel, &["merge-base", "HEAD", &base])
+            .map_err(|e| format!("Cannot compare with base branch '{}': {}", base, e))?;
+
+        let diff = git(
+            &toplevel,
+            &[
+                "-c",
+                "core.quotePath=false",
+                "diff",
+                "--unified=0",
+                "--no-color",
+                "--no-ext-diff",
+                // Fixed prefixes, whatever diff.noprefix or diff.mnemonicPrefix say
+                "--src-prefix=a/",
+                "--dst-prefix=b/",
+                merge_base.trim(),
+            ],
+        )?;
+        let mut files: HashMap<PathBuf, FileChanges> = parse_diff(&diff)
+            .into_iter()
+            .map(|(file, lines)| (toplevel.join(file), FileChanges::Lines(lines)))
+            .collect();
+
+        // NUL-terminated, so names are not quoted
+        let untracked = git(
+            &toplevel,
+            &["ls-files", "-z", "--others", "--exclude-standard"],
+        )?;
+        for file in untracked.split('\0').filter(|file| !file.is_empty()) {
+            files.insert(toplevel.join(file), FileChanges::All);
+        }
+
+        Ok(Self { files })
+    }
+
+    /// Check if a line of a file is new, the file given by its canonical path
+    pub fn contains(&self, file: &Path, line: usize) -> bool {
+        match self.files.get(file) {
+            Some(FileChanges::All) => true,
+            Some(FileChanges::Lines(lines)) => lines.contains(&line),
+            None => false,
+        }
+    }
+}
+
+/// Parse the output of `git diff --unified=0` into the added lines of each file
+///
+/// The files are relative to the top level of the repository. Deleted files and hunks that
+/// only remove lines add nothing.
+fn parse_diff(diff: &str) -> HashMap<String, HashSet<usize>> {
+    let mut files: HashMap<String, HashSet<usize>> = HashMap::new();
+    let mut current: Option<String> = None;
+    let mut previous = "";
+
+    for line in diff.lines() {
+        // The file header is the only `+++` line that follows a `---` line
+        let is_header = previous.starts_with("--- ");
+        previous = line;
+        if let Some(file) = line.strip_prefix("+++ ").filter(|_| is_header) {
+            // Names with spaces are followed by a tab
+            let file = file.strip_suffix('\t').unwrap_or(file);
+            current = unquote_path(file)
+                .strip_prefix("b/")
+                .map(|file| file.to_string());
+            continue;
+        }
+
+        let Some(file) = &current else {
+            continue;
+        };
+        // @@ -10,2 +12,3 @@ context
+        let Some(range) = line
+            .strip_prefix("@@ ")
+            .and_then(|hunk| hunk.split(' ').find(|part| part.starts_with('+')))
+        else {
+            continue;
+        };
+        let mut parts = range[1..].split(',');
+        let start: usize = parts.next().and_then(|n| n.parse().ok()).unwrap_or(0);
+        let count: usize = parts.next().and_then(|n| n.parse().ok()).unwrap_or(1);
+        files
+            .entry(file.clone())
+            .or_default()
+            .extend(start..start + count);
+    }
+
+    files
+}
+
+/// Unquote a path git quoted C-style, e.g. `"b/tab\there.ts"`, or return it as is
+///
+/// Git quotes paths with double quotes, backslashes and control characters, and writes
+/// bytes outside of ASCII as octal escapes unless `core.quotePath` is off.
+fn unquote_path(path: &str) -> String {
+    let Some(quoted) = path
+        .strip_prefix('"')
+        .and_then(|path| path.strip_suffix('"'))
+    else {
+        return path.to_string();
+    };
+
+    let mut bytes = Vec::with_capacity(quoted.len());
+    let mut rest = quoted.as_bytes();
+    while let Some((&byte, tail)) = rest.split_first() {
+        rest = tail;
+        if byte != b'\\' {
+            bytes.push(byte);
+            continue;
+        }
+        let Some((&escaped, tail)) = rest.split_first() else {
+            bytes.push(byte);
+            break;
+        };
+        rest = tail;
+        match escaped {
+            b'a' => bytes.push(0x07),
+            b'b' => bytes.push(0x08),
+            b'f' => bytes.push(0x0c),
+            b'n' => bytes.push(b'\n'),
+            b'r' => bytes.push(b'\r'),
+            b't' => bytes.push(b'\t'),
+            b'v' => bytes.push(0x0b),
+            b'0'..=b'7' => {
+                // Three octal digits, the first of which is already taken
+                let digits = rest
+                    .iter()
+                    .take(2)
+                    .take_while(|digit| matches!(**digit, b'0'..=b'7'));
+                let mut value = (escaped - b'0') as u32;
+                let mut taken = 0;
+                for digit in digits {
+                    value = value * 8 + (digit - b'0') as u32;
+                    taken += 1;
+                }
+                rest = &rest[taken..];
+                bytes.push(value as u8);
+            }
+            other => bytes.push(other),
+        }
+    }
+    String::from_utf8_lossy(&bytes).into_owned()
+}
+
+/// Drop the findings that are not on changed lines
+///
+/// Returns the number of dropped findings.
+pub fn keep_new_code(
+    results: &mut [FileAnalysisResult],
+    changed_lines: &ChangedLines,
+    path_base: &PathBase,
+) -> usize {
+    let mut dropped = 0;
+    for result in results.iter_mut() {
+        let path = path_base.resolve(&result.file_path);
+        let path = fs::canonicalize(&path).unwrap_or(path);
+        result.diagnostics.retain(|diagnostic| {
+            let kept = changed_lines.contains(&path, diagnostic.line_number);
+            if !kept {
+                dropped += 1;
+            }
+            kept
+        });
+    }
+    dropped
+}
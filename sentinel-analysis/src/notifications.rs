@@ -0,0 +1,563 @@
+//! Delivery of the report of a run by email
+//!
+//! With `notifications.email` in `sentinel.json` every run that writes its reports, e.g. a
+//! scheduled run or a `docker` run, sends a summary to a list of recipients over SMTP, for
+//! teams without chat webhooks. The mail has a plain text summary and the HTML summary
+//! attached as `sentinel-report.html`:
+//!
+//! ```json
+//! "notifications": {
+//!   "email": {
+//!     "smtp_host": "smtp.example.com",
+//!     "username": "sentinel@example.com",
+//!     "from": "Sentinel <sentinel@example.com>",
+//!     "to": ["frontend-team@example.com"]
+//!   }
+//! }
+//! ```
+//!
+//! The connection is upgraded with STARTTLS on port 587 by default; `"tls": "tls"` connects
+//! with TLS right away (port 465) and `"tls": "none"` sends in plain text, e.g. to a local
+//! relay. The password defaults to the `SENTINEL_SMTP_PASSWORD` environment variable and
+//! is never sent over a connection without TLS.
+//!
+//! The subject is encoded as an RFC 2047 encoded word if it isn't plain ASCII. The text
+//! summary is sent as 8-bit text to servers announcing `8BITMIME`, and quoted-printable
+//! to the others.
+
+use crate::exporter::FindingsExport;
+use crate::utilities::config::EmailConfig;
+use crate::utilities::{DebugLevel, log};
+use base64::Engine;
+use base64::engine::general_purpose::STANDARD;
+use native_tls::{TlsConnector, TlsStream};
+use std::io::{Read, Write};
+use std::net::TcpStream;
+use std::time::Duration;
+
+/// Name of the attached HTML summary
+pub const REPORT_ATTACHMENT: &str = "sentinel-report.html";
+
+/// Timeout of every read and write on the SMTP connection
+const SMTP_TIMEOUT: Duration = Duration::from_secs(30);
+
+/// Rules listed in the summaries
+const SUMMARY_RULES: usize = 10;
+
+/// How the SMTP connection is secured
+#[derive(Debug, Clone, Copy, PartialEq)]
+enum TlsMode {
+    /// Plain connection upgraded with STARTTLS
+    StartTls,
+    /// TLS from the start
+    Tls,
+    /// No encryption
+    None,
+}
+
+impl TlsMode {
+    fn parse(mode: Option<&str>) -> Result<Self, String> {
+        match mode.unwrap_or("starttls") {
+            "starttls" => Ok(TlsMode::StartTls),
+            "tls" => Ok(TlsMode::Tls),
+            "none" => Ok(TlsMode::None),
+            other => Err(format!(
+                "Invalid email tls {}, expected starttls, tls or none",
+                other
+            )),
+        }
+    }
+
+    fn default_port(self) -> u16 {
+        match self {
+            TlsMode::StartTls => 587,
+            TlsMode::Tls => 465,
+            TlsMode::None => 25,
+        }
+    }
+}
+
+/// A plain or encrypted SMTP connection
+enum Connection {
+    Plain(TcpStream),
+    Tls(Box<TlsStream<TcpStream>>),
+}
+
+impl Read for Connection {
+    fn read(&mut self, buf: &mut [u8]) -> std::io::Result<usize> {
+        match self {
+            Connection::Plain(stream) => stream.read(buf),
+            Connection::Tls(stream) => stream.read(buf),
+        }
+    }
+}
+
+impl Write for Connection {
+    fn write(&mut self, buf: &[u8]) -> std::io::Result<usize> {
+        match self {
+            Connection::Plain(stream) => stream.write(buf),
+            Connection::Tls(stream) => stream.write(buf),
+        }
+    }
+
+    fn flush(&mut self) -> std::io::Result<()> {
+        match self {
+            Connection::Plain(stream) => stream.flush(),
+            Connection::Tls(stream) => stream.flush(),
+        }
+    }
+}
+
+/// A minimal SMTP client, enough to submit a single mail
+struct SmtpClient {
+    connection: Connection,
+    /// Reply to the last `EHLO`, listing the extensions of the server
+    extensions: String,
+}
+
+impl SmtpClient {
+    /// Connect and greet the server, upgrading the connection as configured
+    fn connect(host: &str, port: u16, mode: TlsMode) -> Result<Self, String> {
+        let stream = TcpStream::connect((host, port))
+            .map_err(|e| format!("Failed to connect to {}:{}: {}", host, port, e))?;
+        stream
+            .set_read_timeout(Some(SMTP_TIMEOUT))
+            .and_then(|_| stream.set_write_timeout(Some(SMTP_TIMEOUT)))
+            .map_err(|e| e.to_string())?;
+
+        let connection = match mode {
+            TlsMode::Tls => Connection::Tls(Box::new(tls_handshake(host, stream)?)),
+            _ => Connection::Plain(stream),
+        };
+        let mut client = Self {
+            connection,
+            extensions: String::new(),
+        };
+        client.expect(220)?;
+        client.extensions = client.command("EHLO sentinel", 250)?;
+
+        if mode == TlsMode::StartTls {
+            client.command("STARTTLS", 220)?;
+            let Connection::Plain(stream) = client.connection else {
+                unreachable!("STARTTLS on an encrypted connection");
+            };
+            client = Self {
+                connection: Connection::Tls(Box::new(tls_handshake(host, stream)?)),
+                extensions: String::new(),
+            };
+            client.extensions = client.command("EHLO sentinel", 250)?;
+        }
+        Ok(client)
+    }
+
+    /// Check if the server announced an extension like `8BITMIME` in its `EHLO` reply
+    fn supports(&self, extension: &str) -> bool {
+        // The first line greets, every other line names an extension after the code
+        self.extensions.lines().skip(1).any(|line| {
+            line.get(4..)
+                .and_then(|keywords| keywords.split_whitespace().next())
+                .is_some_and(|keyword| keyword.eq_ignore_ascii_case(extension))
+        })
+    }
+
+    /// Read a possibly multi-line reply and check its code
+    fn expect(&mut self, code: u16) -> Result<String, String> {
+        let mut reply = String::new();
+        loop {
+            let line = self.read_line()?;
+            reply.push_str(&line);
+            reply.push('\n');
+            // The last line of a reply has a space after the code, the others a dash
+            if line.as_bytes().get(3) != Some(&b'-') {
+                break;
+            }
+        }
+
+        match reply.get(..3).and_then(|status| status.parse::<u16>().ok()) {
+            Some(status) if status == code => Ok(reply),
+            _ => Err(format!("SMTP server replied: {}", reply.trim())),
+        }
+    }
+
+    /// Read a line of a reply, without its line break
+    fn read_line(&mut self) -> Result<String, String> {
+        let mut line = Vec::new();
+        let mut byte = [0u8; 1];
+        while !line.ends_with(b"\r\n") {
+            match self.connection.read(&mut byte) {
+                Ok(0) => return Err("SMTP server closed the connection".to_string()),
+                Ok(_) => line.push(byte[0]),
+                Err(e) => return Err(format!("Failed to read from SMTP server: {}", e)),
+            }
+        }
+        line.truncate(line.len() - 2);
+        Ok(String::from_utf8_lossy(&line).into_owned())
+    }
+
+    /// Send a command and check the code of its reply
+    fn command(&mut self, command: &str, code: u16) -> Result<String, String> {
+        self.connection
+            .write_all(format!("{}\r\n", command).as_bytes())
+            .map_err(|e| format!("Failed to write to SMTP server: {}", e))?;
+        self.expect(code)
+    }
+
+    /// Log in with `AUTH PLAIN`
+    fn login(&mut self, username: &str, password: &str) -> Result<(), String> {
+        let credentials = STANDARD.encode(format!("\0{}\0{}", username, password));
+        self.command(&format!("AUTH PLAIN {}", credentials), 235)
+            .map(|_| ())
+    }
+
+    /// Submit a mail from a sender to recipients, declaring an 8-bit body if it has one
+    fn send(
+        &mut self,
+        from: &str,
+        to: &[String],
+        message: &str,
+        eight_bit: bool,
+    ) -> Result<(), String> {
+        let body = if eight_bit { " BODY=8BITMIME" } else { "" };
+        self.command(&format!("MAIL FROM:<{}>{}", address(from), body), 250)?;
+        for recipient in to {
+            self.command(&format!("RCPT TO:<{}>", address(recipient)), 250)?;
+        }
+        self.command("DATA", 354)?;
+
+        // Lines starting with a dot are escaped, a lone dot ends the mail
+        let mut data = String::with_capacity(message.len() + 5);
+        for line in message
+            .strip_suffix("\r\n")
+            .unwrap_or(message)
+            .split("\r\n")
+        {
+            if line.starts_with('.') {
+                data.push('.');
+            }
+            data.push_str(line);
+            data.push_str("\r\n");
+        }
+        data.push_str(".\r\n");
+        self.connection
+            .write_all(data.as_bytes())
+            .map_err(|e| format!("Failed to write to SMTP server: {}", e))?;
+        self.expect(250)?;
+
+        // The mail is accepted at this point, a failed goodbye does not matter
+        let _ = self.command("QUIT", 221);
+        Ok(())
+    }
+}
+
+/// Encrypt a connection to a host
+fn tls_handshake(host: &str, stream: TcpStream) -> Result<TlsStream<TcpStream>, String> {
+    TlsConnector::new()
+        .map_err(|e| format!("Failed to set up TLS: {}", e))?
+        .connect(host, stream)
+        .map_err(|e| format!("TLS handshake with {} failed: {}", host, e))
+}
+
+/// Get the address of a mailbox like `Sentinel <sentinel@example.com>`
+fn address(mailbox: &str) -> &str {
+    match (mailbox.rfind('<'), mailbox.rfind('>')) {
+        (Some(start), Some(end)) if start < end => &mailbox[start + 1..end],
+        _ => mailbox.trim(),
+    }
+}
+
+/// Encode a header value as RFC 2047 encoded words if it isn't printable ASCII
+///
+/// Line breaks can't end up in the header this way, and non-ASCII text reaches servers
+/// without `SMTPUTF8` intact.
+fn encode_header(value: &str) -> String {
+    if value.chars().all(|c| matches!(c, ' '..='~')) {
+        return value.to_string();
+    }
+    // Encoded words are at most 75 characters, 45 bytes encode to 60 characters
+    let mut words = Vec::new();
+    let mut chunk = String::new();
+    for c in value.chars() {
+        if chunk.len() + c.len_utf8() > 45 {
+            words.push(format!("=?utf-8?B?{}?=", STANDARD.encode(&chunk)));
+            chunk.clear();
+        }
+        chunk.push(c);
+    }
+    words.push(format!("=?utf-8?B?{}?=", STANDARD.encode(&chunk)));
+    words.join("\r\n ")
+}
+
+/// Encode text as quoted-printable, for servers without `8BITMIME`
+///
+/// Line breaks are kept as `\r\n`, longer lines are wrapped at 76 characters with soft
+/// line breaks.
+fn quoted_printable(text: &str) -> String {
+    let mut encoded = String::with_capacity(text.len());
+    for (index, line) in text.split('\n').enumerate() {
+        if index > 0 {
+            encoded.push_str("\r\n");
+        }
+        let line = line.strip_suffix('\r').unwrap_or(line).as_bytes();
+        let mut width = 0;
+        for (position, &byte) in line.iter().enumerate() {
+            // Whitespace at the end of a line would be dropped in transit
+            let trailing = position + 1 == line.len();
+            let piece = match byte {
+                b'!'..=b'<' | b'>'..=b'~' => (byte as char).to_string(),
+                b' ' | b'\t' if !trailing => (byte as char).to_string(),
+                _ => format!("={:02X}", byte),
+            };
+            if width + piece.len() > 75 {
+                encoded.push_str("=\r\n");
+                width = 0;
+            }
+            width += piece.len();
+            encoded.push_str(&piece);
+        }
+    }
+    encoded
+}
+
+/// Escape text for HTML
+fn escape_html(text: &str) -> String {
+    text.replace('&', "&amp;")
+        .replace('<', "&lt;")
+        .replace('>', "&gt;")
+        .replace('"', "&quot;")
+}
+
+/// Get the rules with the most findings, most first
+fn top_rules(export: &FindingsExport) -> Vec<(&str, usize)> {
+    let mut rules: Vec<(&str, usize)> = export
+        .summary
+        .findings_by_rule
+        .iter()
+        .map(|(rule, count)| (rule.as_str(), *count))
+        .collect();
+    rules.sort_by(|a, b| b.1.cmp(&a.1).then_with(|| a.0.cmp(b.0)));
+    rules.truncate(SUMMARY_RULES);
+    rules
+}
+
+/// Get the number of findings of a severity
+fn severity_count(export: &FindingsExport, severity: &str) -> usize {
+    export
+        .summary
+        .findings_by_severity
+        .get(severity)
+        .copied()
+        .unwrap_or(0)
+}
+
+/// Render the plain text summary of a run
+pub fn render_text_summary(export: &FindingsExport) -> String {
+    let mut text = format!(
+        "{} findings in {} files: {} errors, {} warnings\n",
+        export.summary.total_findings,
+        export.summary.files_processed,
+        severity_count(export, "error"),
+        severity_count(export, "warning")
+    );
+    if let Some(quality_gate) = &export.quality_gate {
+        text.push_str(&format!(
+            "Quality gate: {}\n",
+            if quality_gate.passed {
+                "passed"
+            } else {
+                "FAILED"
+            }
+        ));
+    }
+
+    let rules = top_rules(export);
+    if !rules.is_empty() {
+        text.push_str("\nTop rules:\n");
+        for (rule, count) in rules {
+            text.push_str(&format!("  {}: {}\n", rule, count));
+        }
+    }
+    text.push_str(&format!(
+        "\nThe full summary is attached as {}.\n",
+        REPORT_ATTACHMENT
+    ));
+    text
+}
+
+/// Render the HTML summary of a run: totals, quality gate, top rules and hotspots
+pub fn render_html_summary(export: &FindingsExport) -> String {
+    let mut html = String::from(
+        "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>Sentinel report</title>\
+         <style>body{font-family:sans-serif}table{border-collapse:collapse}\
+         td,th{border:1px solid #ccc;padding:4px 8px;text-align:left}\
+         .failed{color:#b00020}.passed{color:#1b7f3b}</style></head><body>\n",
+    );
+    html.push_str(&format!(
+        "<h1>Sentinel report</h1>\n<p>{} findings in {} files: <b>{}</b> errors, {} warnings, \
+         {} info. Analyzed on {} by scoper {}.</p>\n",
+        export.summary.total_findings,
+        export.summary.files_processed,
+        severity_count(export, "error"),
+        severity_count(export, "warning"),
+        severity_count(export, "info"),
+        escape_html(&export.summary.timestamp),
+        escape_html(&export.schema.analyzer_version)
+    ));
+
+    if let Some(quality_gate) = &export.quality_gate {
+        let (class, status) = match quality_gate.passed {
+            true => ("passed", "passed"),
+            false => ("failed", "FAILED"),
+        };
+        html.push_str(&format!(
+            "<h2>Quality gate: <span class=\"{}\">{}</span></h2>\n<table>\n\
+             <tr><th>Gate</th><th>Actual</th><th>Threshold</th><th>Status</th></tr>\n",
+            class, status
+        ));
+        for gate in &quality_gate.gates {
+            html.push_str(&format!(
+                "<tr><td>{}</td><td>{}</td><td>{}</td><td class=\"{}\">{}</td></tr>\n",
+                escape_html(&gate.gate),
+                gate.actual,
+                gate.threshold,
+                if gate.passed { "passed" } else { "failed" },
+                if gate.passed { "passed" } else { "failed" }
+            ));
+        }
+        html.push_str("</table>\n");
+    }
+
+    let rules = top_rules(export);
+    if !rules.is_empty() {
+        html.push_str("<h2>Top rules</h2>\n<table>\n<tr><th>Rule</th><th>Findings</th></tr>\n");
+        for (rule, count) in rules {
+            html.push_str(&format!(
+                "<tr><td>{}</td><td>{}</td></tr>\n",
+                escape_html(rule),
+                count
+            ));
+        }
+        html.push_str("</table>\n");
+    }
+
+    if !export.hotspots.is_empty() {
+        html.push_str(
+            "<h2>Hotspots</h2>\n<table>\n<tr><th>File</th><th>Findings</th><th>Density</th></tr>\n",
+        );
+        for hotspot in &export.hotspots {
+            html.push_str(&format!(
+                "<tr><td>{}</td><td>{}</td><td>{:.1}</td></tr>\n",
+                escape_html(&hotspot.file),
+                hotspot.findings,
+                hotspot.density
+            ));
+        }
+        html.push_str("</table>\n");
+    }
+
+    html.push_str("</body></html>\n");
+    html
+}
+
+/// Build the MIME message with the text summary and the HTML summary attached
+///
+/// The text summary is sent as is with `eight_bit`, otherwise quoted-printable.
+fn build_message(
+    email: &EmailConfig,
+    subject: &str,
+    text: &str,
+    html: &str,
+    eight_bit: bool,
+) -> String {
+    let boundary = format!("sentinel-{:x}", chrono::Utc::now().timestamp_micros());
+    let attachment = STANDARD.encode(html);
+    let mut message = format!(
+        "From: {}\r\nTo: {}\r\nSubject: {}\r\nDate: {}\r\nMIME-Version: 1.0\r\n\
+         Content-Type: multipart/mixed; boundary=\"{}\"\r\n\r\n",
+        email.from,
+        email.to.join(", "),
+        encode_header(subject),
+        chrono::Utc::now().to_rfc2822(),
+        boundary
+    );
+    let (encoding, text) = match eight_bit {
+        true => ("8bit", text.replace('\n', "\r\n")),
+        false => ("quoted-printable", quoted_printable(text)),
+    };
+    message.push_str(&format!(
+        "--{}\r\nContent-Type: text/plain; charset=utf-8\r\n\
+         Content-Transfer-Encoding: {}\r\n\r\n{}\r\n",
+        boundary, encoding, text
+    ));
+    message.push_str(&format!(
+        "--{}\r\nContent-Type: text/html; charset=utf-8\r\n\
+         Content-Transfer-Encoding: base64\r\n\
+         Content-Disposition: attachment; filename=\"{}\"\r\n\r\n",
+        boundary, REPORT_ATTACHMENT
+    ));
+    // Base64 bodies are wrapped at 76 characters
+    for line in attachment.as_bytes().chunks(76) {
+        message.push_str(&String::from_utf8_lossy(line));
+        message.push_str("\r\n");
+    }
+    message.push_str(&format!("--{}--\r\n", boundary));
+    message
+}
+
+/// Email the summary of a run to the configured recipients
+///
+/// Runs without findings are only reported if `only_on_findings` is not set.
+pub fn send_email_report(
+    email: &EmailConfig,
+    export: &FindingsExport,
+    debug_level: DebugLevel,
+) -> Result<(), String> {
+    if email.only_on_findings.unwrap_or(false) && export.summary.total_findings == 0 {
+        return Ok(());
+    }
+    if email.to.is_empty() {
+        return Err("No recipients in notifications.email.to".to_string());
+    }
+
+    let mode = TlsMode::parse(email.tls.as_deref())?;
+    if email.username.is_some() && mode == TlsMode::None {
+        return Err(
+            "Refusing to send the SMTP password without TLS, set tls to starttls or tls"
+                .to_string(),
+        );
+    }
+    let port = email.smtp_port.unwrap_or(mode.default_port());
+    let subject = email.subject.clone().unwrap_or_else(|| {
+        format!(
+            "Sentinel: {} findings, {} errors",
+            export.summary.total_findings,
+            severity_count(export, "error")
+        )
+    });
+    let mut client = SmtpClient::connect(&email.smtp_host, port, mode)?;
+    if let Some(username) = &email.username {
+        let password = email
+            .password
+            .clone()
+            .or_else(|| std::env::var("SENTINEL_SMTP_PASSWORD").ok())
+            .ok_or(
+                "No SMTP password, set notifications.email.password or SENTINEL_SMTP_PASSWORD",
+            )?;
+        client.login(username, &password)?;
+    }
+    let eight_bit = client.supports("8BITMIME");
+    let message = build_message(
+        email,
+        &subject,
+        &render_text_summary(export),
+        &render_html_summary(export),
+        eight_bit,
+    );
+    client.send(&email.from, &email.to, &message, eight_bit)?;
+
+    log(
+        DebugLevel::Info,
+        debug_level,
+        &format!("Emailed the report to {}", email.to.join(", ")),
+    );
+    Ok(())
+}
@@ -0,0 +1,397 @@
+//! Scanning all repositories of an organization in one run
+//!
+//! `scoper org scan --config repos.yaml` shallow-clones or updates every listed
+//! repository, analyzes each one with its own configuration and writes a combined
+//! `org-report.json` next to the per-repository reports:
+//!
+//! ```yaml
+//! workdir: .sentinel-org        # where the repositories are cloned (default)
+//! output_dir: org-findings      # reports, one directory per repository (default)
+//! repos:
+//!   - name: shop
+//!     url: https://github.com/acme/shop.git
+//!     branch: main              # default: the default branch of the remote
+//!     path: apps/shop           # analyzed directory inside the repository (default: root)
+//!     config: sentinel.json     # configuration inside the repository (default)
+//! ```
+//!
+//! The file can also be written as JSON. A repository that fails to clone or analyze is
+//! reported with its error and does not stop the scan of the others.
+
+use crate::cache::DEFAULT_CACHE_PATH;
+use crate::exporter::FindingsExport;
+use crate::hotspots::git;
+use crate::schema::{BuildInfo, SchemaInfo};
+use crate::sentinel::Sentinel;
+use crate::utilities::config::Config;
+use crate::utilities::{DebugLevel, log};
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::fs;
+use std::path::{Component, Path, PathBuf};
+use std::process::Command;
+use tabled::{builder::Builder, settings::Style};
+
+/// A repository of the organization
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct RepoConfig {
+    /// Name of the repository, used for its clone and report directories
+    pub name: String,
+    pub url: String,
+    pub branch: Option<String>,
+    /// Directory analyzed inside the repository
+    pub path: Option<String>,
+    /// Configuration file inside the repository (default: sentinel.json)
+    pub config: Option<String>,
+}
+
+/// The repositories to scan and where to put them
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct OrgConfig {
+    /// Directory the repositories are cloned to (default: .sentinel-org)
+    pub workdir: Option<String>,
+    /// Directory of the reports (default: org-findings)
+    pub output_dir: Option<String>,
+    pub repos: Vec<RepoConfig>,
+}
+
+impl OrgConfig {
+    /// Load the repositories from a YAML or JSON file
+    pub fn load(path: &str) -> Result<Self, String> {
+        let content =
+            fs::read_to_string(path).map_err(|e| format!("Failed to read {}: {}", path, e))?;
+        let config: Self = if path.ends_with(".json") {
+            serde_json::from_str(&content).map_err(|e| format!("Invalid {}: {}", path, e))?
+        } else {
+            serde_yaml::from_str(&content).map_err(|e| format!("Invalid {}: {}", path, e))?
+        };
+
+        let mut names = HashSet::new();
+        for repo in &config.repos {
+            if repo.name.is_empty() || repo.name.contains(['/', '\\']) || repo.name.starts_with('.')
+            {
+                return Err(format!(
+                    "Invalid repository name '{}' in {}",
+                    repo.name, path
+                ));
+            }
+            // Values starting with a dash would be read as options by git
+            if repo.url.starts_with('-')
+                || repo
+                    .branch
+                    .as_deref()
+                    .is_some_and(|branch| branch.starts_with('-'))
+            {
+                return Err(format!(
+                    "Invalid url or branch of repository {} in {}",
+                    repo.name, path
+                ));
+            }
+            if !names.insert(repo.name.as_str()) {
+                return Err(format!(
+                    "Repository {} is listed twice in {}",
+                    repo.name, path
+                ));
+            }
+        }
+        Ok(config)
+    }
+}
+
+/// Results of one repository in the combined report
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+pub struct RepoSummary {
+    pub name: String,
+    pub url: String,
+    /// Commit that was analyzed
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub commit: Option<String>,
+    pub files_processed: usize,
+    pub total_findings: usize,
+    pub findings_by_severity: HashMap<String, usize>,
+    pub findings_by_rule: HashMap<String, usize>,
+    /// Why the repository could not be scanned
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub error: Option<String>,
+}
+
+/// Combined report of all repositories
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct OrgReport {
+    pub schema: SchemaInfo,
+    pub build: BuildInfo,
+    pub timestamp: String,
+    pub repos: Vec<RepoSummary>,
+    pub total_findings: usize,
+    /// Findings per rule over all repositories
+    pub findings_by_rule: BTreeMap<String, usize>,
+    /// Number of repositories each rule has findings in
+    pub repos_by_rule: BTreeMap<String, usize>,
+}
+
+/// Run git, reporting its error output
+fn run_git(args: &[&str]) -> Result<(), String> {
+    let output = Command::new("git")
+        .args(args)
+        .output()
+        .map_err(|e| format!("Failed to run git: {}", e))?;
+    if !output.status.success() {
+        return Err(format!(
+            "git {} failed: {}",
+            args.join(" "),
+            String::from_utf8_lossy(&output.stderr).trim()
+        ));
+    }
+    Ok(())
+}
+
+/// Shallow-clone a repository, or update an existing clone to the latest commit
+fn sync_repo(repo: &RepoConfig, dir: &Path) -> Result<String, String> {
+    let dir_str = dir.to_string_lossy().into_owned();
+    let dir_str = dir_str.as_str();
+    if dir.join(".git").exists() {
+        let branch = repo.branch.as_deref().unwrap_or("HEAD");
+        run_git(&[
+            "-C", dir_str, "fetch", "--depth", "1", "--", &repo.url, branch,
+        ])?;
+        run_git(&["-C", dir_str, "reset", "--hard", "FETCH_HEAD"])?;
+        run_git(&["-C", dir_str, "clean", "-fdx"])?;
+    } else {
+        let mut args = vec!["clone", "--depth", "1"];
+        if let Some(branch) = &repo.branch {
+            args.extend(["--branch", branch.as_str()]);
+        }
+        args.extend(["--", repo.url.as_str(), dir_str]);
+        run_git(&args)?;
+    }
+    git(dir, &["rev-parse", "HEAD"]).map(|sha| sha.trim().to_string())
+}
+
+/// Resolve a relative path of a repository configuration against a directory
+///
+/// Paths that are absolute or leave the directory with `..` are rejected instead of
+/// reading or writing outside of it, whether they come from the repository list or from
+/// the configuration inside the repository.
+fn resolve_in(dir: &Path, path: &Option<String>) -> Result<Option<String>, String> {
+    let Some(path) = path else {
+        return Ok(None);
+    };
+    let inside = Path::new(path)
+        .components()
+        .all(|component| matches!(component, Component::Normal(_) | Component::CurDir));
+    if !inside {
+        return Err(format!(
+            "Path {} of the repository configuration is outside of {}",
+            path,
+            dir.display()
+        ));
+    }
+    Ok(Some(dir.join(path).to_string_lossy().into_owned()))
+}
+
+/// Get the configuration of a repository, with its paths inside the repository and its
+/// reports in its own output directory
+fn repo_config(repo: &RepoConfig, dir: &Path, output_dir: &Path) -> Result<Config, String> {
+    let config_path = resolve_in(
+        dir,
+        &Some(repo.config.clone().unwrap_or("sentinel.json".into())),
+    )?
+    .unwrap_or_default();
+    let mut config = Config::try_load_from_path(&config_path).unwrap_or_default();
+
+    config.rules_config = resolve_in(dir, &config.rules_config)?;
+    config.template = resolve_in(dir, &config.template)?;
+    config.suppressions = resolve_in(dir, &config.suppressions)?;
+    config.previous = resolve_in(dir, &config.previous)?;
+    config.cache_path = resolve_in(
+        dir,
+        &Some(
+            config
+                .cache_path
+                .clone()
+                .unwrap_or_else(|| DEFAULT_CACHE_PATH.to_string()),
+        ),
+    )?;
+    config.path_base =
+        resolve_in(dir, &config.path_base)?.or_else(|| Some(dir.to_string_lossy().into_owned()));
+    config.export_metrics_json = resolve_in(output_dir, &config.export_metrics_json)?;
+    config.export_metrics_csv = resolve_in(output_dir, &config.export_metrics_csv)?;
+    for output in config.outputs.iter_mut().flatten() {
+        output.path = resolve_in(output_dir, &output.path)?;
+    }
+    config.output_dir = Some(output_dir.to_string_lossy().into_owned());
+    Ok(config)
+}
+
+/// Clone and analyze one repository
+fn scan_repo(
+    repo: &RepoConfig,
+    workdir: &Path,
+    output_dir: &Path,
+    debug_level: DebugLevel,
+) -> RepoSummary {
+    let mut summary = RepoSummary {
+        name: repo.name.clone(),
+        url: repo.url.clone(),
+        ..RepoSummary::default()
+    };
+    let dir = workdir.join(&repo.name);
+    let output_dir = output_dir.join(&repo.name);
+
+    log(
+        DebugLevel::Info,
+        debug_level,
+        &format!("Updating {} from {}", repo.name, repo.url),
+    );
+    let commit = match sync_repo(repo, &dir) {
+        Ok(commit) => commit,
+        Err(e) => {
+            summary.error = Some(e);
+            return summary;
+        }
+    };
+    summary.commit = Some(commit);
+
+    let config = match repo_config(repo, &dir, &output_dir) {
+        Ok(config) => config,
+        Err(e) => {
+            summary.error = Some(e);
+            return summary;
+        }
+    };
+    let target = match resolve_in(&dir, &Some(repo.path.clone().unwrap_or(".".to_string()))) {
+        Ok(target) => target.unwrap_or_default(),
+        Err(e) => {
+            summary.error = Some(e);
+            return summary;
+        }
+    };
+    let analysis = Sentinel::new(config.clone())
+        .with_args(vec!["scoper".to_string()])
+        .with_target(&target)
+        .with_debug_level(debug_level)
+        .run();
+    match analysis {
+        Ok(analysis) => analysis.export(&config, debug_level),
+        Err(e) => {
+            summary.error = Some(e);
+            return summary;
+        }
+    }
+
+    let findings_path = output_dir.join("findings.json");
+    let export = fs::read_to_string(&findings_path)
+        .map_err(|e| e.to_string())
+        .and_then(|content| {
+            serde_json::from_str::<FindingsExport>(&content).map_err(|e| e.to_string())
+        });
+    match export {
+        Ok(export) => {
+            summary.files_processed = export.summary.files_processed;
+            summary.total_findings = export.summary.total_findings;
+            summary.findings_by_severity = export.summary.findings_by_severity;
+            summary.findings_by_rule = export.summary.findings_by_rule;
+        }
+        Err(e) => {
+            summary.error = Some(format!("Failed to read {}: {}", findings_path.display(), e))
+        }
+    }
+    summary
+}
+
+/// Combine the results of all repositories
+fn build_org_report(repos: Vec<RepoSummary>) -> OrgReport {
+    let mut findings_by_rule = BTreeMap::new();
+    let mut repos_by_rule = BTreeMap::new();
+    for repo in &repos {
+        for (rule, count) in &repo.findings_by_rule {
+            *findings_by_rule.entry(rule.clone()).or_insert(0) += count;
+            *repos_by_rule.entry(rule.clone()).or_insert(0) += 1;
+        }
+    }
+    OrgReport {
+        schema: SchemaInfo::current(),
+        build: BuildInfo::current(),
+        timestamp: chrono::Utc::now().to_rfc3339(),
+        total_findings: repos.iter().map(|repo| repo.total_findings).sum(),
+        repos,
+        findings_by_rule,
+        repos_by_rule,
+    }
+}
+
+/// Print the findings of every repository as a table
+pub fn print_org_report(report: &OrgReport) {
+    println!("\nRepositories:");
+    println!("----------------");
+
+    let mut builder = Builder::new();
+    builder.push_record([
+        "Repository",
+        "Commit",
+        "Files",
+        "Findings",
+        "Errors",
+        "Status",
+    ]);
+    for repo in &report.repos {
+        let commit = repo
+            .commit
+            .as_deref()
+            .map_or("-", |sha| &sha[..sha.len().min(12)]);
+        let errors = repo.findings_by_severity.get("error").copied().unwrap_or(0);
+        builder.push_record([
+            repo.name.clone(),
+            commit.to_string(),
+            repo.files_processed.to_string(),
+            repo.total_findings.to_string(),
+            errors.to_string(),
+            repo.error.clone().unwrap_or_else(|| "ok".to_string()),
+        ]);
+    }
+
+    let mut table = builder.build();
+    table.with(Style::ascii_rounded());
+
+    println!("{}", table);
+    println!(
+        "Total: {} findings in {} repositories",
+        report.total_findings,
+        report.repos.len()
+    );
+    println!("----------------");
+}
+
+/// Scan all repositories of an organization and write the combined report
+///
+/// Fails only if the repository list cannot be read or the combined report cannot be
+/// written; failed repositories are part of the report.
+pub fn scan_org(config_path: &str, debug_level: DebugLevel) -> Result<OrgReport, String> {
+    let org = OrgConfig::load(config_path)?;
+    let workdir = PathBuf::from(org.workdir.as_deref().unwrap_or(".sentinel-org"));
+    let output_dir = PathBuf::from(org.output_dir.as_deref().unwrap_or("org-findings"));
+    fs::create_dir_all(&workdir)
+        .map_err(|e| format!("Failed to create {}: {}", workdir.display(), e))?;
+    fs::create_dir_all(&output_dir)
+        .map_err(|e| format!("Failed to create {}: {}", output_dir.display(), e))?;
+
+    // Repositories are scanned one after the other, each analysis is parallel already
+    let repos = org
+        .repos
+        .iter()
+        .map(|repo| scan_repo(repo, &workdir, &output_dir, debug_level))
+        .collect();
+    let report = build_org_report(repos);
+
+    let report_path = output_dir.join("org-report.json");
+    let json = serde_json::to_string_pretty(&report)
+        .map_err(|e| format!("Failed to serialize the organization report: {}", e))?;
+    fs::write(&report_path, json)
+        .map_err(|e| format!("Failed to write {}: {}", report_path.display(), e))?;
+    log(
+        DebugLevel::Info,
+        debug_level,
+        &format!("Organization report written to {}", report_path.display()),
+    );
+    Ok(report)
+}
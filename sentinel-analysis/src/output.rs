@@ -0,0 +1,336 @@
+//! Writers of the findings report
+//!
+//! A single run can write the report in several formats, each given as an output spec like
+//! `format=sarif,path=out/results.sarif`. Specs come from repeated `--output` arguments or
+//! the `outputs` configuration; any other key of a spec is an option of the writer, such as
+//! the `template` of the template writer. Formats are looked up in the `OutputRegistry`,
+//! which new writers are added to.
+
+use crate::backstage::BackstageWriter;
+use crate::exporter::FindingsExport;
+use crate::rules::Taxonomy;
+use crate::security_report::SecurityWriter;
+use crate::templates::{render_template, report_path};
+use crate::utilities::paths::PathBase;
+use crate::utilities::{DebugLevel, log};
+use serde::{Deserialize, Serialize};
+use serde_json::{Value, json};
+use std::collections::{BTreeMap, BTreeSet, HashMap};
+use std::fs;
+use std::path::Path;
+
+/// An output of a run
+#[derive(Serialize, Deserialize, Debug, Clone, Default, PartialEq)]
+pub struct OutputSpec {
+    pub format: String,
+    /// File the output is written to, defaults to a file in the output directory
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub path: Option<String>,
+    /// Options of the writer
+    #[serde(flatten)]
+    pub options: HashMap<String, String>,
+}
+
+impl OutputSpec {
+    /// Parse a spec of comma-separated `key=value` pairs
+    pub fn parse(spec: &str) -> Result<Self, String> {
+        let mut output = OutputSpec::default();
+        for pair in spec.split(',').filter(|pair| !pair.trim().is_empty()) {
+            let (key, value) = pair
+                .split_once('=')
+                .ok_or_else(|| format!("Invalid output {}, expected key=value pairs", spec))?;
+            let (key, value) = (key.trim(), value.trim().to_string());
+            match key {
+                "format" => output.format = value,
+                "path" => output.path = Some(value),
+                _ => {
+                    output.options.insert(key.to_string(), value);
+                }
+            }
+        }
+
+        if output.format.is_empty() {
+            return Err(format!("Output {} has no format", spec));
+        }
+        Ok(output)
+    }
+}
+
+/// Writer of one format of the findings report
+pub trait OutputWriter: Send + Sync {
+    /// Format name used in output specs
+    fn format(&self) -> &'static str;
+
+    /// File in the output directory written if the spec has no path
+    fn default_path(&self, spec: &OutputSpec, output_dir: &str) -> Result<String, String>;
+
+    /// Render the report
+    fn render(&self, export: &FindingsExport, spec: &OutputSpec) -> Result<String, String>;
+}
+
+/// Writes the findings export as JSON, like findings.json
+pub struct JsonWriter;
+
+impl OutputWriter for JsonWriter {
+    fn format(&self) -> &'static str {
+        "json"
+    }
+
+    fn default_path(&self, _spec: &OutputSpec, output_dir: &str) -> Result<String, String> {
+        Ok(format!("{}/findings.json", output_dir))
+    }
+
+    fn render(&self, export: &FindingsExport, _spec: &OutputSpec) -> Result<String, String> {
+        serde_json::to_string_pretty(export)
+            .map_err(|e| format!("Failed to serialize findings: {}", e))
+    }
+}
+
+/// Writes the findings as SARIF 2.1.0, for code scanning integrations
+pub struct SarifWriter;
+
+impl OutputWriter for SarifWriter {
+    fn format(&self) -> &'static str {
+        "sarif"
+    }
+
+    fn default_path(&self, _spec: &OutputSpec, output_dir: &str) -> Result<String, String> {
+        Ok(format!("{}/findings.sarif", output_dir))
+    }
+
+    fn render(&self, export: &FindingsExport, _spec: &OutputSpec) -> Result<String, String> {
+        let mut rules: BTreeMap<&str, Value> = BTreeMap::new();
+        let mut taxa: BTreeMap<Taxonomy, BTreeSet<&str>> = BTreeMap::new();
+        let mut results = Vec::with_capacity(export.findings.len());
+
+        for finding in &export.findings {
+            rules.entry(&finding.rule).or_insert_with(|| {
+                let mut rule = json!({
+                    "id": finding.rule,
+                    "properties": { "category": finding.category },
+                });
+                if let Some(docs_url) = &finding.docs_url {
+                    rule["helpUri"] = json!(docs_url);
+                }
+
+                // Rules relate to the taxa of the standards, CWEs are also tagged for
+                // code scanning services like GitHub
+                let mappings = export.summary.rule_taxonomies.get(&finding.rule);
+                if let Some(mappings) = mappings {
+                    let relationships: Vec<Value> = mappings
+                        .iter()
+                        .map(|mapping| {
+                            taxa.entry(mapping.taxonomy)
+                                .or_default()
+                                .insert(&mapping.id);
+                            json!({
+                                "target": {
+                                    "id": mapping.id,
+                                    "toolComponent": { "name": mapping.taxonomy.as_str() },
+                                },
+                                "kinds": ["superset"],
+                            })
+                        })
+                        .collect();
+                    let mut tags = vec!["security".to_string()];
+                    tags.extend(
+                        mappings
+                            .iter()
+                            .filter(|mapping| mapping.taxonomy == Taxonomy::Cwe)
+                            .map(|mapping| format!("external/cwe/{}", mapping.id.to_lowercase())),
+                    );
+                    rule["relationships"] = json!(relationships);
+                    rule["properties"]["tags"] = json!(tags);
+                }
+                rule
+            });
+
+            let level = match finding.severity.as_str() {
+                "error" => "error",
+                "warning" => "warning",
+                _ => "note",
+            };
+            results.push(json!({
+                "ruleId": finding.rule,
+                "level": level,
+                "message": { "text": finding.message },
+                "locations": [{
+                    "physicalLocation": {
+                        "artifactLocation": { "uri": finding.file },
+                        "region": {
+                            "startLine": finding.line.max(1),
+                            "startColumn": finding.column.max(1),
+                        },
+                    },
+                }],
+                "partialFingerprints": { "sentinel/v1": finding.fingerprint },
+            }));
+        }
+
+        // SARIF has no kind for byte columns, which consumers then read as UTF-16
+        let column_kind = match export.column_unit.as_str() {
+            "char" => Some("unicodeCodePoints"),
+            "utf-16" => Some("utf16CodeUnits"),
+            _ => None,
+        };
+
+        let mut sarif = json!({
+            "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+            "version": "2.1.0",
+            "runs": [{
+                "tool": {
+                    "driver": {
+                        "name": "scoper",
+                        "version": export.schema.analyzer_version,
+                        "informationUri": "https://github.com/rryter/sentinel",
+                        "rules": rules.into_values().collect::<Vec<_>>(),
+                        "properties": { "build": export.build },
+                    },
+                },
+                "results": results,
+            }],
+        });
+        if let Some(column_kind) = column_kind {
+            sarif["runs"][0]["columnKind"] = json!(column_kind);
+        }
+        if !taxa.is_empty() {
+            let taxonomies: Vec<Value> = taxa
+                .into_iter()
+                .map(|(taxonomy, ids)| {
+                    json!({
+                        "name": taxonomy.as_str(),
+                        "taxa": ids.into_iter().map(|id| json!({ "id": id })).collect::<Vec<_>>(),
+                    })
+                })
+                .collect();
+            sarif["runs"][0]["taxonomies"] = json!(taxonomies);
+        }
+        serde_json::to_string_pretty(&sarif)
+            .map_err(|e| format!("Failed to serialize SARIF report: {}", e))
+    }
+}
+
+/// Renders the findings export through the template given as `template` option
+pub struct TemplateWriter;
+
+impl TemplateWriter {
+    fn template(spec: &OutputSpec) -> Result<&str, String> {
+        spec.options
+            .get("template")
+            .map(String::as_str)
+            .ok_or_else(|| {
+                "Format template requires a template, e.g. template=report.md.tmpl".to_string()
+            })
+    }
+}
+
+impl OutputWriter for TemplateWriter {
+    fn format(&self) -> &'static str {
+        "template"
+    }
+
+    fn default_path(&self, spec: &OutputSpec, output_dir: &str) -> Result<String, String> {
+        Ok(report_path(Self::template(spec)?, output_dir))
+    }
+
+    fn render(&self, export: &FindingsExport, spec: &OutputSpec) -> Result<String, String> {
+        render_template(export, Self::template(spec)?)
+    }
+}
+
+/// Registry of the available output formats
+pub struct OutputRegistry {
+    writers: HashMap<&'static str, Box<dyn OutputWriter>>,
+}
+
+impl OutputRegistry {
+    /// Create a registry with the built-in writers
+    pub fn new() -> Self {
+        let mut registry = Self {
+            writers: HashMap::new(),
+        };
+        registry.register(Box::new(JsonWriter));
+        registry.register(Box::new(SarifWriter));
+        registry.register(Box::new(TemplateWriter));
+        registry.register(Box::new(BackstageWriter::new(PathBase::new("."))));
+        registry.register(Box::new(SecurityWriter));
+        registry
+    }
+
+    /// Create a registry with the built-in writers for the project under a path base
+    ///
+    /// Writers that read project files, like the catalog files of `backstage`, find them
+    /// under the path base rather than the working directory.
+    pub fn for_project(path_base: &PathBase) -> Self {
+        let mut registry = Self::new();
+        registry.register(Box::new(BackstageWriter::new(path_base.clone())));
+        registry
+    }
+
+    /// Register a writer, replacing any writer of the same format
+    pub fn register(&mut self, writer: Box<dyn OutputWriter>) {
+        self.writers.insert(writer.format(), writer);
+    }
+
+    /// Get the names of all formats, sorted
+    pub fn formats(&self) -> Vec<&'static str> {
+        let mut formats: Vec<&'static str> = self.writers.keys().copied().collect();
+        formats.sort();
+        formats
+    }
+
+    /// Write one output, returning the path written to
+    pub fn write(
+        &self,
+        export: &FindingsExport,
+        spec: &OutputSpec,
+        output_dir: &str,
+    ) -> Result<String, String> {
+        let writer = self.writers.get(spec.format.as_str()).ok_or_else(|| {
+            format!(
+                "Unknown format {}, expected one of: {}",
+                spec.format,
+                self.formats().join(", ")
+            )
+        })?;
+
+        let path = match &spec.path {
+            Some(path) => path.clone(),
+            None => writer.default_path(spec, output_dir)?,
+        };
+        let report = writer.render(export, spec)?;
+
+        if let Some(parent) = Path::new(&path).parent() {
+            fs::create_dir_all(parent)
+                .map_err(|e| format!("Failed to create directory {}: {}", parent.display(), e))?;
+        }
+        fs::write(&path, report).map_err(|e| format!("Failed to write {}: {}", path, e))?;
+        Ok(path)
+    }
+
+    /// Write all outputs of a run, logging the ones that fail
+    pub fn write_all(
+        &self,
+        export: &FindingsExport,
+        specs: &[OutputSpec],
+        output_dir: &str,
+        debug_level: DebugLevel,
+    ) {
+        for spec in specs {
+            match self.write(export, spec, output_dir) {
+                Ok(path) => log(
+                    DebugLevel::Info,
+                    debug_level,
+                    &format!("Wrote {} report to {}", spec.format, path),
+                ),
+                Err(err) => log(DebugLevel::Error, debug_level, &err),
+            }
+        }
+    }
+}
+
+impl Default for OutputRegistry {
+    fn default() -> Self {
+        Self::new()
+    }
+}
@@ -0,0 +1,190 @@
+//! Upload of findings to the sentinel-backend
+//!
+//! `scoper publish` posts `findings.json` of the last run to the analysis submissions
+//! endpoint of the backend, `/api/v1/projects/<project_id>/analysis_submissions`, so CLI
+//! runs show up in the dashboard. The findings are sent as-is, with the commit they were
+//! produced from added as `commit`. Connection errors, rate limits and server errors are
+//! retried with exponential backoff; other errors fail right away.
+
+use crate::hotspots::git;
+use crate::schema::upgrade_findings;
+use crate::utilities::config::PublishConfig;
+use crate::utilities::{DebugLevel, log};
+use reqwest::StatusCode;
+use reqwest::blocking::Client;
+use serde::Serialize;
+use serde_json::Value;
+use std::fs;
+use std::path::Path;
+use std::thread;
+use std::time::Duration;
+
+/// Retries of a failed upload without `retries` in the configuration
+const DEFAULT_RETRIES: u32 = 3;
+
+/// Delay before the first retry, doubled for every further retry
+const INITIAL_BACKOFF: Duration = Duration::from_secs(1);
+
+/// The commit the findings were produced from
+#[derive(Serialize, Debug, Clone)]
+pub struct CommitInfo {
+    pub sha: String,
+    pub branch: String,
+    pub message: String,
+    /// Commit date as ISO 8601
+    pub committed_at: String,
+}
+
+/// Get the commit checked out in a directory, `None` outside of a git repository
+pub fn commit_info(dir: &Path) -> Option<CommitInfo> {
+    let output = git(dir, &["log", "-1", "--format=%H%n%cI%n%s"]).ok()?;
+    let mut lines = output.lines();
+    let (sha, committed_at) = (lines.next()?.to_string(), lines.next()?.to_string());
+    let message = lines.next().unwrap_or_default().to_string();
+    let branch = git(dir, &["rev-parse", "--abbrev-ref", "HEAD"])
+        .map(|branch| branch.trim().to_string())
+        .unwrap_or_default();
+    Some(CommitInfo {
+        sha,
+        branch,
+        message,
+        committed_at,
+    })
+}
+
+/// Get the submissions endpoint of the configured project
+fn submissions_url(config: &PublishConfig) -> Result<String, String> {
+    let url = config
+        .url
+        .as_deref()
+        .filter(|url| !url.is_empty())
+        .ok_or("No publish url configured, add a publish section to sentinel.json")?;
+    let project_id = config
+        .project_id
+        .ok_or("No publish project_id configured")?;
+    Ok(format!(
+        "{}/api/v1/projects/{}/analysis_submissions",
+        url.trim_end_matches('/'),
+        project_id
+    ))
+}
+
+/// Whether a failed upload can succeed when retried
+fn is_retryable(status: StatusCode) -> bool {
+    status == StatusCode::TOO_MANY_REQUESTS || status.is_server_error()
+}
+
+/// Post the payload, retrying transient failures
+fn post_with_retries(
+    url: &str,
+    token: Option<&str>,
+    payload: &Value,
+    retries: u32,
+    debug_level: DebugLevel,
+) -> Result<(), String> {
+    let client = Client::new();
+    let mut backoff = INITIAL_BACKOFF;
+    let mut attempt = 0;
+    loop {
+        let mut request = client.post(url).json(payload);
+        if let Some(token) = token {
+            request = request.bearer_auth(token);
+        }
+
+        let error = match request.send() {
+            Ok(response) if response.status().is_success() => return Ok(()),
+            Ok(response) => {
+                let status = response.status();
+                let body = response.text().unwrap_or_default();
+                let error = format!("The backend answered {}: {}", status, body.trim());
+                if !is_retryable(status) {
+                    return Err(error);
+                }
+                error
+            }
+            Err(e) => format!("Failed to reach {}: {}", url, e),
+        };
+
+        if attempt >= retries {
+            return Err(error);
+        }
+        attempt += 1;
+        log(
+            DebugLevel::Warn,
+            debug_level,
+            &format!(
+                "{}, retrying in {}s ({}/{})",
+                error,
+                backoff.as_secs(),
+                attempt,
+                retries
+            ),
+        );
+        thread::sleep(backoff);
+        backoff *= 2;
+    }
+}
+
+/// Upload findings.json of the output directory to the backend
+///
+/// The commit is read from the analyzed directory `target`.
+pub fn publish_results(
+    config: &PublishConfig,
+    output_dir: &str,
+    target: &Path,
+    debug_level: DebugLevel,
+) -> Result<(), String> {
+    let url = submissions_url(config)?;
+    let token = config
+        .token
+        .clone()
+        .or_else(|| std::env::var("SENTINEL_PUBLISH_TOKEN").ok())
+        .filter(|token| !token.is_empty());
+
+    let findings_path = Path::new(output_dir).join("findings.json");
+    let content = fs::read_to_string(&findings_path).map_err(|e| {
+        format!(
+            "Failed to read {}, run the analysis first: {}",
+            findings_path.display(),
+            e
+        )
+    })?;
+    let mut payload = serde_json::from_str::<Value>(&content)
+        .map_err(|e| e.to_string())
+        .and_then(upgrade_findings)
+        .map_err(|e| format!("Failed to parse {}: {}", findings_path.display(), e))?;
+
+    match commit_info(target) {
+        Some(commit) => {
+            payload["commit"] = serde_json::to_value(&commit)
+                .map_err(|e| format!("Failed to serialize the commit: {}", e))?;
+        }
+        None => log(
+            DebugLevel::Warn,
+            debug_level,
+            &format!(
+                "{} is not in a git repository, publishing without commit",
+                target.display()
+            ),
+        ),
+    }
+
+    log(
+        DebugLevel::Info,
+        debug_level,
+        &format!("Publishing {} to {}", findings_path.display(), url),
+    );
+    post_with_retries(
+        &url,
+        token.as_deref(),
+        &payload,
+        config.retries.unwrap_or(DEFAULT_RETRIES),
+        debug_level,
+    )?;
+    log(
+        DebugLevel::Info,
+        debug_level,
+        &format!("Published {} to {}", findings_path.display(), url),
+    );
+    Ok(())
+}
@@ -0,0 +1,168 @@
+//! Quality gates a run must pass
+//!
+//! `quality_gates` in `sentinel.json` sets conditions on the aggregated findings, like the
+//! quality gates of SonarQube:
+//!
+//! ```json
+//! "quality_gates": {
+//!   "max_new_errors": 0,
+//!   "max_warnings_by_category": { "security": 0, "rxjs": 20 },
+//!   "min_migration_readiness": 80
+//! }
+//! ```
+//!
+//! - `max_new_errors`: errors beyond those of the previous run in the same output
+//!   directory. Every error is new on the first run, and with `--new-code-only` every
+//!   reported error is new.
+//! - `max_warnings_by_category`: warnings of a category in total, a parent category like
+//!   `migration` includes its subcategories
+//! - `min_migration_readiness`: average signal migration readiness score of the components
+//!
+//! The gates are evaluated on the reported findings, after suppressions and filters.
+//! Counter alerts with `fail_quality_gate` are added as gates as well, see `counters`. The
+//! outcome of every gate is printed after the rule hit summary and exported as
+//! `quality_gate` in `findings.json`, and a failed gate fails the run with exit code 1.
+
+use crate::FileAnalysisResult;
+use crate::signal_migration::build_signal_migration_report;
+use crate::utilities::config::QualityGates;
+use oxc_diagnostics::Severity;
+use serde::{Deserialize, Serialize};
+use serde_json::Value;
+use std::fs;
+use std::path::Path;
+
+/// Outcome of a single gate
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct GateOutcome {
+    /// Name of the gate, with the category for `max_warnings_by_category`
+    pub gate: String,
+    pub actual: f64,
+    pub threshold: f64,
+    pub passed: bool,
+}
+
+/// Outcome of all configured gates
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct QualityGateReport {
+    /// Whether every gate passed
+    pub passed: bool,
+    pub gates: Vec<GateOutcome>,
+}
+
+/// Get the number of errors of the previous run in an output directory
+pub fn previous_errors(output_dir: &str) -> Option<usize> {
+    let content = fs::read_to_string(Path::new(output_dir).join("findings.json")).ok()?;
+    let export: Value = serde_json::from_str(&content).ok()?;
+    let errors = export
+        .get("summary")?
+        .get("findings_by_severity")?
+        .get("error")
+        .and_then(Value::as_u64)
+        .unwrap_or(0);
+    Some(errors as usize)
+}
+
+/// Evaluate the gates on the reported findings
+///
+/// `previous_errors` is the number of errors of the baseline run new errors are counted
+/// against, zero to count every error as new.
+pub fn evaluate_quality_gates(
+    results: &[FileAnalysisResult],
+    gates: &QualityGates,
+    previous_errors: usize,
+) -> QualityGateReport {
+    let diagnostics = || results.iter().flat_map(|result| &result.diagnostics);
+    let mut outcomes = Vec::new();
+    let mut check = |gate: String, actual: f64, threshold: f64, passed: bool| {
+        outcomes.push(GateOutcome {
+            gate,
+            actual,
+            threshold,
+            passed,
+        });
+    };
+
+    if let Some(max) = gates.max_new_errors {
+        let errors = diagnostics()
+            .filter(|diagnostic| diagnostic.diagnostic.severity == Severity::Error)
+            .count();
+        let new_errors = errors.saturating_sub(previous_errors);
+        check(
+            "max_new_errors".to_string(),
+            new_errors as f64,
+            max as f64,
+            new_errors <= max,
+        );
+    }
+
+    let mut categories: Vec<(&String, &usize)> = gates.max_warnings_by_category.iter().collect();
+    categories.sort();
+    for (category, &max) in categories {
+        let warnings = diagnostics()
+            .filter(|diagnostic| {
+                diagnostic.diagnostic.severity == Severity::Warning
+                    && diagnostic.category.matches_selector(category)
+            })
+            .count();
+        check(
+            format!("max_warnings_by_category.{}", category),
+            warnings as f64,
+            max as f64,
+            warnings <= max,
+        );
+    }
+
+    if let Some(min) = gates.min_migration_readiness {
+        // A project without components has nothing left to migrate
+        let score = build_signal_migration_report(results).map_or(100.0, |r| r.average_score);
+        check(
+            "min_migration_readiness".to_string(),
+            score,
+            min,
+            score >= min,
+        );
+    }
+
+    QualityGateReport {
+        passed: outcomes.iter().all(|outcome| outcome.passed),
+        gates: outcomes,
+    }
+}
+
+/// Add gates evaluated elsewhere, like the counter alerts, to the outcome of the gates
+///
+/// The outcome is created for them if no `quality_gates` are configured.
+pub fn add_gates(
+    report: Option<QualityGateReport>,
+    gates: Vec<GateOutcome>,
+) -> Option<QualityGateReport> {
+    if gates.is_empty() {
+        return report;
+    }
+    let mut report = report.unwrap_or(QualityGateReport {
+        passed: true,
+        gates: Vec::new(),
+    });
+    report.gates.extend(gates);
+    report.passed = report.gates.iter().all(|outcome| outcome.passed);
+    Some(report)
+}
+
+/// Print the outcome of the gates, failed gates marked so they stand out
+pub fn print_quality_gate(report: &QualityGateReport) {
+    println!(
+        "Quality gate: {}",
+        if report.passed { "passed" } else { "FAILED" }
+    );
+    for outcome in &report.gates {
+        println!(
+            "  {} {}: {} (threshold {})",
+            if outcome.passed { "ok    " } else { "FAILED" },
+            outcome.gate,
+            outcome.actual,
+            outcome.threshold
+        );
+    }
+    println!();
+}
@@ -0,0 +1,110 @@
+//! Walking the semantic nodes of a file with their ancestors
+//!
+//! The semantic model stores the parent of every node, so rules can ask where a node is
+//! located ("inside the constructor of a `@Component` class") without keeping their own
+//! stack in a visitor.
+
+use oxc_ast::AstKind;
+use oxc_ast::ast::{Class, MethodDefinitionKind};
+use oxc_semantic::{AstNode, NodeId, Semantic};
+
+use crate::rules::class_context::decorator_name;
+
+/// What the walker does after visiting a node
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum WalkControl {
+    /// Visit the children of the node
+    Continue,
+    /// Do not visit the children of the node, continue with its next sibling
+    SkipChildren,
+    /// End the walk
+    Stop,
+}
+
+/// Visit all nodes in source order together with their ancestors
+///
+/// The visitor receives the node and its ancestors, ordered from the root (`Program`)
+/// down to the parent of the node.
+pub fn walk_with_ancestors<'a, F>(semantic: &Semantic<'a>, mut visit: F)
+where
+    F: FnMut(&AstNode<'a>, &[&AstNode<'a>]) -> WalkControl,
+{
+    let nodes = semantic.nodes();
+    let mut stack: Vec<&AstNode<'a>> = Vec::new();
+    // Depth of the stack at which children are skipped
+    let mut skip_depth: Option<usize> = None;
+
+    // Nodes are stored in the order they are entered, so parents come before children
+    for node in nodes.iter() {
+        let parent_id = nodes.parent_id(node.id());
+        while stack.last().is_some_and(|top| Some(top.id()) != parent_id) {
+            stack.pop();
+        }
+        if skip_depth.is_some_and(|depth| stack.len() < depth) {
+            skip_depth = None;
+        }
+
+        if skip_depth.is_none() {
+            match visit(node, &stack) {
+                WalkControl::Continue => {}
+                WalkControl::SkipChildren => skip_depth = Some(stack.len() + 1),
+                WalkControl::Stop => return,
+            }
+        }
+
+        stack.push(node);
+    }
+}
+
+/// Iterate over the ancestors of a node, from its parent up to the root
+pub fn ancestors<'s, 'a>(
+    semantic: &'s Semantic<'a>,
+    node_id: NodeId,
+) -> impl Iterator<Item = &'s AstNode<'a>> {
+    let nodes = semantic.nodes();
+    std::iter::successors(nodes.parent_id(node_id), move |&id| nodes.parent_id(id))
+        .map(move |id| nodes.get_node(id))
+}
+
+/// Get the innermost class containing a node
+pub fn enclosing_class<'s, 'a>(
+    semantic: &'s Semantic<'a>,
+    node_id: NodeId,
+) -> Option<&'s Class<'a>> {
+    ancestors(semantic, node_id).find_map(|node| match node.kind() {
+        AstKind::Class(class) => Some(class),
+        _ => None,
+    })
+}
+
+/// Check if a node is inside the constructor of a class with one of the given decorators,
+/// e.g. `["Component", "Directive"]`
+///
+/// Functions nested in the constructor count as well, since callbacks passed to
+/// `subscribe()` or `effect()` there run in the context of the class.
+pub fn is_in_decorated_constructor(
+    semantic: &Semantic,
+    node_id: NodeId,
+    decorators: &[&str],
+) -> bool {
+    let mut in_constructor = false;
+    for node in ancestors(semantic, node_id) {
+        match node.kind() {
+            AstKind::MethodDefinition(method)
+                if method.kind == MethodDefinitionKind::Constructor =>
+            {
+                in_constructor = true;
+            }
+            AstKind::Class(class) => {
+                return in_constructor
+                    && class
+                        .decorators
+                        .iter()
+                        .filter_map(decorator_name)
+                        .any(|name| decorators.contains(&name.as_str()));
+            }
+            _ => {}
+        }
+    }
+    false
+}
@@ -0,0 +1,247 @@
+//! Architecture boundaries between zones of a code base
+//!
+//! Users map files to zones with glob patterns (`src/app/core/**` → `core`) and declare
+//! which zones each zone may depend on. Every import is an edge of the import graph from
+//! the importing file to the resolved import target; an edge between two zones that is
+//! not allowed is a `BoundaryViolation`.
+//!
+//! Resolution is purely lexical: relative specifiers are joined with the directory of the
+//! importing file and configured path aliases (`@app/*` → `src/app/*`) are expanded, the
+//! file system is never touched. Package imports are matched against the zone patterns as
+//! they are written, so a zone can also group packages (`@angular/**`).
+
+use serde_json::Value;
+use std::collections::HashMap;
+
+use crate::utilities::glob::glob_match;
+
+/// A named group of files or packages
+#[derive(Debug, Clone)]
+pub struct Zone {
+    pub name: String,
+    pub patterns: Vec<String>,
+}
+
+/// Zones, the dependencies allowed between them and the aliases used to resolve imports
+#[derive(Debug, Clone, Default)]
+pub struct BoundaryConfig {
+    /// Zones in matching order, the first matching zone wins
+    pub zones: Vec<Zone>,
+    /// Zones each zone may import from; zones without an entry are unrestricted
+    pub allow: HashMap<String, Vec<String>>,
+    /// Path aliases as (prefix, replacement), e.g. `("@app/", "src/app/")`
+    pub paths: Vec<(String, String)>,
+}
+
+/// The target of an import after resolution
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum ImportTarget {
+    /// A file of the code base, without extension
+    Local(String),
+    /// A package from `node_modules`
+    Package(String),
+}
+
+/// An import edge between two zones that is not allowed
+#[derive(Debug, Clone)]
+pub struct BoundaryViolation {
+    pub from_zone: String,
+    pub to_zone: String,
+    /// The resolved import target
+    pub target: String,
+    /// The zones `from_zone` may import from
+    pub allowed: Vec<String>,
+}
+
+impl BoundaryConfig {
+    /// Parse the configuration from rule options
+    ///
+    /// ```json
+    /// {
+    ///   "zones": { "core": ["src/app/core/**"], "feature": ["src/app/features/**"] },
+    ///   "allow": { "core": [], "feature": ["core"] },
+    ///   "paths": { "@app/*": "src/app/*" }
+    /// }
+    /// ```
+    ///
+    /// Zones can also be declared as an array of `{ "name": ..., "patterns": [...] }`
+    /// objects to control the matching order.
+    pub fn from_value(config: &Value) -> Result<Self, String> {
+        let obj = config
+            .as_object()
+            .ok_or_else(|| "Boundary configuration must be an object".to_string())?;
+
+        let mut boundaries = BoundaryConfig::default();
+
+        match obj.get("zones") {
+            Some(Value::Object(zones)) => {
+                for (name, patterns) in zones {
+                    boundaries.zones.push(Zone {
+                        name: name.clone(),
+                        patterns: string_list(patterns).ok_or_else(|| {
+                            format!("Patterns of zone '{}' must be a list of strings", name)
+                        })?,
+                    });
+                }
+            }
+            Some(Value::Array(zones)) => {
+                for zone in zones {
+                    let name = zone
+                        .get("name")
+                        .and_then(Value::as_str)
+                        .ok_or_else(|| "Every zone needs a 'name'".to_string())?;
+                    let patterns = zone.get("patterns").and_then(string_list).ok_or_else(|| {
+                        format!("Patterns of zone '{}' must be a list of strings", name)
+                    })?;
+                    boundaries.zones.push(Zone {
+                        name: name.to_string(),
+                        patterns,
+                    });
+                }
+            }
+            Some(_) => return Err("'zones' must be an object or an array".to_string()),
+            None => {}
+        }
+
+        if let Some(allow) = obj.get("allow") {
+            let allow = allow
+                .as_object()
+                .ok_or_else(|| "'allow' must be an object".to_string())?;
+            for (zone, allowed) in allow {
+                if !boundaries.zones.iter().any(|z| &z.name == zone) {
+                    return Err(format!("'allow' references unknown zone '{}'", zone));
+                }
+                let allowed = string_list(allowed).ok_or_else(|| {
+                    format!("Allowed zones of '{}' must be a list of strings", zone)
+                })?;
+                boundaries.allow.insert(zone.clone(), allowed);
+            }
+        }
+
+        if let Some(paths) = obj.get("paths").and_then(Value::as_object) {
+            for (alias, target) in paths {
+                if let Some(target) = target.as_str() {
+                    boundaries.paths.push((
+                        alias.trim_end_matches('*').to_string(),
+                        target.trim_end_matches('*').to_string(),
+                    ));
+                }
+            }
+            // Longest alias first, so `@app/core/*` wins over `@app/*`
+            boundaries.paths.sort_by(|a, b| b.0.len().cmp(&a.0.len()));
+        }
+
+        Ok(boundaries)
+    }
+
+    /// Resolve an import specifier relative to the importing file
+    pub fn resolve_import(&self, importer: &str, specifier: &str) -> ImportTarget {
+        if specifier.starts_with("./") || specifier.starts_with("../") {
+            let importer = normalize_path(importer);
+            let dir = importer.rsplit_once('/').map_or("", |(dir, _)| dir);
+            return ImportTarget::Local(normalize_path(&format!("{}/{}", dir, specifier)));
+        }
+
+        for (alias, target) in &self.paths {
+            if let Some(rest) = specifier.strip_prefix(alias.as_str()) {
+                return ImportTarget::Local(normalize_path(&format!("{}{}", target, rest)));
+            }
+        }
+
+        ImportTarget::Package(specifier.to_string())
+    }
+
+    /// Get the zone of a file path
+    pub fn zone_of_path(&self, path: &str) -> Option<&str> {
+        let path = normalize_path(path);
+        self.zones
+            .iter()
+            .find(|zone| {
+                zone.patterns
+                    .iter()
+                    .any(|pattern| path_matches(pattern, &path))
+            })
+            .map(|zone| zone.name.as_str())
+    }
+
+    /// Get the zone of an import target
+    pub fn zone_of_target(&self, target: &ImportTarget) -> Option<&str> {
+        match target {
+            ImportTarget::Local(path) => self.zone_of_path(path),
+            ImportTarget::Package(name) => self
+                .zones
+                .iter()
+                .find(|zone| {
+                    zone.patterns
+                        .iter()
+                        .any(|pattern| glob_match(pattern, name))
+                })
+                .map(|zone| zone.name.as_str()),
+        }
+    }
+
+    /// Check a single edge of the import graph
+    pub fn check_import(&self, importer: &str, specifier: &str) -> Option<BoundaryViolation> {
+        let from_zone = self.zone_of_path(importer)?;
+        let allowed = self.allow.get(from_zone)?;
+
+        let target = self.resolve_import(importer, specifier);
+        let to_zone = self.zone_of_target(&target)?;
+
+        if to_zone == from_zone || allowed.iter().any(|zone| zone == to_zone) {
+            return None;
+        }
+
+        Some(BoundaryViolation {
+            from_zone: from_zone.to_string(),
+            to_zone: to_zone.to_string(),
+            target: match target {
+                ImportTarget::Local(path) | ImportTarget::Package(path) => path,
+            },
+            allowed: allowed.clone(),
+        })
+    }
+}
+
+/// Match a file path against a pattern, either from the root or at any depth, since
+/// analyzed paths may be absolute or relative to a different directory than the config
+fn path_matches(pattern: &str, path: &str) -> bool {
+    glob_match(pattern, path)
+        || (!pattern.starts_with('/') && glob_match(&format!("**/{}", pattern), path))
+}
+
+/// Normalize separators and resolve `.` and `..` segments without touching the file system
+pub fn normalize_path(path: &str) -> String {
+    let path = path.replace('\\', "/");
+    let absolute = path.starts_with('/');
+
+    let mut segments: Vec<&str> = Vec::new();
+    for segment in path.split('/') {
+        match segment {
+            "" | "." => {}
+            ".." => {
+                if segments.last().is_some_and(|last| *last != "..") {
+                    segments.pop();
+                } else if !absolute {
+                    segments.push("..");
+                }
+            }
+            _ => segments.push(segment),
+        }
+    }
+
+    let joined = segments.join("/");
+    if absolute {
+        format!("/{}", joined)
+    } else {
+        joined
+    }
+}
+
+fn string_list(value: &Value) -> Option<Vec<String>> {
+    value
+        .as_array()?
+        .iter()
+        .map(|item| item.as_str().map(str::to_string))
+        .collect()
+}
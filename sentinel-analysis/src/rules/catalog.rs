@@ -34,11 +34,116 @@ impl From<&str> for RuleSeverity {
 }
 
 /// Represents the category of a rule
-#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+#[serde(rename_all = "kebab-case")]
 pub enum RuleCategory {
     Angular,
     BestPractices,
     Correctness,
+    /// User-facing text without translation markers
+    I18n,
+    #[serde(rename = "migration/standalone")]
+    MigrationStandalone,
+    /// Count-only rules that track metrics rather than violations
+    Metrics,
     Performance,
+    Policy,
+    Rxjs,
+    Secrets,
+    Security,
     Style,
-} 
\ No newline at end of file
+    /// Rules for spec and stories files, only run with `analyze_tests`
+    TestRules,
+    TypeScript,
+}
+
+impl RuleCategory {
+    /// Check if the category matches a selector, either exactly or as a parent
+    /// category (`migration` matches `migration/standalone`)
+    pub fn matches_selector(&self, selector: &str) -> bool {
+        let name = self.as_str();
+        name == selector
+            || name
+                .strip_prefix(selector)
+                .is_some_and(|rest| rest.starts_with('/'))
+    }
+
+    /// Get the selector name used on the command line and in the output
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            RuleCategory::Angular => "angular",
+            RuleCategory::BestPractices => "best-practices",
+            RuleCategory::Correctness => "correctness",
+            RuleCategory::I18n => "i18n",
+            RuleCategory::MigrationStandalone => "migration/standalone",
+            RuleCategory::Metrics => "metrics",
+            RuleCategory::Performance => "performance",
+            RuleCategory::Policy => "policy",
+            RuleCategory::Rxjs => "rxjs",
+            RuleCategory::Secrets => "secrets",
+            RuleCategory::Security => "security",
+            RuleCategory::Style => "style",
+            RuleCategory::TestRules => "test-rules",
+            RuleCategory::TypeScript => "typescript",
+        }
+    }
+}
+
+impl fmt::Display for RuleCategory {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}", self.as_str())
+    }
+}
+
+/// Security standards that rules can be mapped to, see `Rule::taxonomies`
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize, Deserialize)]
+pub enum Taxonomy {
+    /// Common Weakness Enumeration, e.g. `CWE-79`
+    #[serde(rename = "CWE")]
+    Cwe,
+    /// OWASP Application Security Verification Standard 4.0, e.g. `V5.3.3`
+    #[serde(rename = "OWASP ASVS")]
+    OwaspAsvs,
+    /// OWASP Top 10 2021, e.g. `A03:2021`
+    #[serde(rename = "OWASP Top 10")]
+    OwaspTop10,
+}
+
+impl Taxonomy {
+    /// Get the name of the standard, as used in the reports
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            Taxonomy::Cwe => "CWE",
+            Taxonomy::OwaspAsvs => "OWASP ASVS",
+            Taxonomy::OwaspTop10 => "OWASP Top 10",
+        }
+    }
+}
+
+impl fmt::Display for Taxonomy {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{}", self.as_str())
+    }
+}
+
+/// An entry of a security standard a rule checks for
+#[derive(Debug, Clone, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize, Deserialize)]
+pub struct TaxonomyMapping {
+    pub taxonomy: Taxonomy,
+    pub id: String,
+}
+
+/// Well-known tags that rules can declare in addition to their category
+///
+/// Tags describe the maturity and the performance cost of a rule so that users
+/// can select or exclude subsets like `--rules-exclude=experimental,expensive`.
+pub mod tags {
+    /// The rule is considered stable and is safe to enable by default
+    pub const STABLE: &str = "stable";
+    /// The rule is new and may still produce false positives
+    pub const EXPERIMENTAL: &str = "experimental";
+    /// The rule only inspects single nodes and is cheap to run
+    pub const CHEAP: &str = "cheap";
+    /// The rule walks larger subtrees and is comparatively expensive to run
+    pub const EXPENSIVE: &str = "expensive";
+}
@@ -0,0 +1,378 @@
+use oxc_ast::ast::{
+    Argument, ArrayExpressionElement, AssignmentExpression, AssignmentTarget, CallExpression,
+    Class, ClassElement, Decorator, Expression, MethodDefinition, ObjectProperty,
+    ObjectPropertyKind, PropertyDefinition, PropertyKey, TSType, TSTypeName,
+};
+use oxc_ast_visit::{Visit, walk};
+use oxc_span::Span;
+use std::collections::HashSet;
+
+/// RxJS operators that complete a stream on their own or when the class is destroyed
+const TEARDOWN_OPERATORS: &[&str] = &["takeUntilDestroyed", "take", "first", "takeWhile"];
+
+/// A field declared on a class
+#[derive(Debug, Clone)]
+pub struct ClassField {
+    pub name: String,
+    /// The name of the declared type, if it is a simple type reference
+    pub type_name: Option<String>,
+    pub span: Span,
+}
+
+/// A `subscribe()` call made inside a class
+#[derive(Debug, Clone)]
+pub struct SubscribeCall {
+    pub span: Span,
+    /// The method the call was made in, `None` for field initializers
+    pub method: Option<String>,
+    /// The teardown operator found in the `pipe()` before the call, if any
+    pub teardown_operator: Option<String>,
+    /// The notifier field passed to `takeUntil(this.notifier)`, if any
+    pub take_until_notifier: Option<String>,
+    /// Whether `takeUntilDestroyed()` is called without a `DestroyRef`, which only works
+    /// in an injection context like the constructor or a field initializer
+    pub destroyed_without_ref: bool,
+    /// The field the returned subscription is stored in (`this.sub = ...subscribe()`)
+    pub stored_in: Option<String>,
+    /// The field the subscription is added to (`this.subs.add(...subscribe())`)
+    pub added_to: Option<String>,
+}
+
+/// Information about a class that is collected in a single pass, so rules can reason
+/// about a class as a whole instead of individual nodes
+#[derive(Debug, Clone, Default)]
+pub struct ClassContext {
+    pub name: Option<String>,
+    pub span: Span,
+    /// Names of the decorators applied to the class, e.g. `Component`
+    pub decorators: Vec<String>,
+    pub fields: Vec<ClassField>,
+    pub methods: Vec<String>,
+    pub subscriptions: Vec<SubscribeCall>,
+    /// Whether the class implements `ngOnDestroy`
+    pub has_ng_on_destroy: bool,
+    /// Fields on which `unsubscribe()` is called in `ngOnDestroy`
+    pub unsubscribed_fields: HashSet<String>,
+    /// Fields on which `next()` or `complete()` is called in `ngOnDestroy`
+    pub completed_fields: HashSet<String>,
+    /// Whether `DestroyRef` is injected through the constructor or `inject()`
+    pub injects_destroy_ref: bool,
+}
+
+impl ClassContext {
+    /// Collect the context of a class
+    pub fn from_class(class: &Class) -> Self {
+        let mut collector = ClassContextCollector {
+            context: ClassContext {
+                name: class.id.as_ref().map(|id| id.name.to_string()),
+                span: class.span,
+                decorators: class.decorators.iter().filter_map(decorator_name).collect(),
+                ..Default::default()
+            },
+            current_method: None,
+            stored_calls: Vec::new(),
+            added_calls: Vec::new(),
+        };
+
+        for element in &class.body.body {
+            match element {
+                ClassElement::PropertyDefinition(prop) => {
+                    collector.context.fields.push(ClassField {
+                        name: property_key_name(&prop.key).to_string(),
+                        type_name: prop
+                            .type_annotation
+                            .as_ref()
+                            .and_then(|t| type_reference_name(&t.type_annotation)),
+                        span: prop.span,
+                    });
+                }
+                ClassElement::MethodDefinition(method) => {
+                    collector
+                        .context
+                        .methods
+                        .push(property_key_name(&method.key).to_string());
+                }
+                _ => {}
+            }
+        }
+
+        collector.visit_class_body(&class.body);
+        collector.context
+    }
+
+    /// Check if the class has one of the given decorators
+    pub fn has_decorator(&self, names: &[&str]) -> bool {
+        self.decorators.iter().any(|d| names.contains(&d.as_str()))
+    }
+}
+
+/// Get the name of a decorator, e.g. `Component` for `@Component({...})`
+pub fn decorator_name(decorator: &Decorator) -> Option<String> {
+    match &decorator.expression {
+        Expression::Identifier(ident) => Some(ident.name.to_string()),
+        Expression::CallExpression(call) => match &call.callee {
+            Expression::Identifier(ident) => Some(ident.name.to_string()),
+            _ => None,
+        },
+        _ => None,
+    }
+}
+
+/// Get a property of the object literal passed to a decorator, e.g. `declarations`
+/// in `@NgModule({ declarations: [...] })`
+pub fn decorator_property<'b, 'a>(
+    decorator: &'b Decorator<'a>,
+    name: &str,
+) -> Option<&'b ObjectProperty<'a>> {
+    let Expression::CallExpression(call) = &decorator.expression else {
+        return None;
+    };
+    let Some(Argument::ObjectExpression(object)) = call.arguments.first() else {
+        return None;
+    };
+
+    object.properties.iter().find_map(|property| match property {
+        ObjectPropertyKind::ObjectProperty(prop) if property_key_name(&prop.key) == name => {
+            Some(&**prop)
+        }
+        _ => None,
+    })
+}
+
+/// Get the identifier names listed in an array literal, e.g. `[CommonModule, FooComponent]`
+pub fn array_identifiers<'a>(expr: &Expression<'a>) -> Vec<(&'a str, Span)> {
+    let Expression::ArrayExpression(array) = expr else {
+        return Vec::new();
+    };
+
+    array
+        .elements
+        .iter()
+        .filter_map(|element| match element {
+            ArrayExpressionElement::Identifier(ident) => Some((ident.name.as_str(), ident.span)),
+            _ => None,
+        })
+        .collect()
+}
+
+/// Get the name of a property key, or an empty string for computed keys
+pub fn property_key_name<'a>(key: &PropertyKey<'a>) -> &'a str {
+    match key {
+        PropertyKey::StaticIdentifier(ident) => ident.name.as_str(),
+        PropertyKey::PrivateIdentifier(ident) => ident.name.as_str(),
+        _ => "",
+    }
+}
+
+/// Get the name of a simple type reference such as `Subscription`
+pub fn type_reference_name(ts_type: &TSType) -> Option<String> {
+    match ts_type {
+        TSType::TSTypeReference(type_ref) => match &type_ref.type_name {
+            TSTypeName::IdentifierReference(ident) => Some(ident.name.to_string()),
+            _ => None,
+        },
+        _ => None,
+    }
+}
+
+/// Get the property name of a method call like `obj.name(...)`
+pub fn member_call_name<'a>(call: &CallExpression<'a>) -> Option<&'a str> {
+    match &call.callee {
+        Expression::StaticMemberExpression(member) => Some(member.property.name.as_str()),
+        _ => None,
+    }
+}
+
+/// Get the field name of a `this.field` expression
+pub fn this_member_name<'a>(expr: &Expression<'a>) -> Option<&'a str> {
+    match expr {
+        Expression::StaticMemberExpression(member)
+            if matches!(member.object, Expression::ThisExpression(_)) =>
+        {
+            Some(member.property.name.as_str())
+        }
+        _ => None,
+    }
+}
+
+fn callee_identifier<'a>(call: &CallExpression<'a>) -> Option<&'a str> {
+    match &call.callee {
+        Expression::Identifier(ident) => Some(ident.name.as_str()),
+        _ => None,
+    }
+}
+
+/// Find the subscribe call in an expression like `obs$.subscribe(...)`
+fn as_subscribe_call<'b, 'a>(expr: &'b Expression<'a>) -> Option<&'b CallExpression<'a>> {
+    match expr {
+        Expression::CallExpression(call) if member_call_name(call) == Some("subscribe") => {
+            Some(call)
+        }
+        _ => None,
+    }
+}
+
+struct ClassContextCollector {
+    context: ClassContext,
+    current_method: Option<String>,
+    /// Spans of subscribe calls whose subscription is stored in a field
+    stored_calls: Vec<(Span, String)>,
+    /// Spans of subscribe calls whose subscription is added to a composite subscription
+    added_calls: Vec<(Span, String)>,
+}
+
+impl ClassContextCollector {
+    fn in_ng_on_destroy(&self) -> bool {
+        self.current_method.as_deref() == Some("ngOnDestroy")
+    }
+
+    fn record_subscribe_call(&mut self, call: &CallExpression) {
+        let mut teardown_operator = None;
+        let mut take_until_notifier = None;
+        let mut destroyed_without_ref = false;
+
+        // Look for teardown operators in `source$.pipe(...).subscribe()`
+        if let Expression::StaticMemberExpression(member) = &call.callee {
+            if let Expression::CallExpression(pipe_call) = &member.object {
+                if member_call_name(pipe_call) == Some("pipe") {
+                    for argument in &pipe_call.arguments {
+                        let Argument::CallExpression(operator_call) = argument else {
+                            continue;
+                        };
+                        let Some(operator) = callee_identifier(operator_call) else {
+                            continue;
+                        };
+
+                        if operator == "takeUntil" {
+                            teardown_operator = Some(operator.to_string());
+                            take_until_notifier = operator_call
+                                .arguments
+                                .first()
+                                .and_then(|arg| arg.as_expression())
+                                .and_then(this_member_name)
+                                .map(str::to_string);
+                        } else if TEARDOWN_OPERATORS.contains(&operator) {
+                            teardown_operator = Some(operator.to_string());
+                            destroyed_without_ref = operator == "takeUntilDestroyed"
+                                && operator_call.arguments.is_empty();
+                        }
+                    }
+                }
+            }
+        }
+
+        let find_field = |calls: &[(Span, String)]| {
+            calls
+                .iter()
+                .find(|(span, _)| *span == call.span)
+                .map(|(_, field)| field.clone())
+        };
+
+        self.context.subscriptions.push(SubscribeCall {
+            span: call.span,
+            method: self.current_method.clone(),
+            teardown_operator,
+            take_until_notifier,
+            destroyed_without_ref,
+            stored_in: find_field(&self.stored_calls),
+            added_to: find_field(&self.added_calls),
+        });
+    }
+}
+
+impl<'a> Visit<'a> for ClassContextCollector {
+    fn visit_class(&mut self, _class: &Class<'a>) {
+        // Nested classes and class expressions get a context of their own
+    }
+
+    fn visit_method_definition(&mut self, method: &MethodDefinition<'a>) {
+        let name = property_key_name(&method.key).to_string();
+        if name == "ngOnDestroy" {
+            self.context.has_ng_on_destroy = true;
+        }
+
+        // Constructor injection of DestroyRef
+        for param in &method.value.params.items {
+            if let Some(annotation) = &param.pattern.type_annotation {
+                if type_reference_name(&annotation.type_annotation).as_deref()
+                    == Some("DestroyRef")
+                {
+                    self.context.injects_destroy_ref = true;
+                }
+            }
+        }
+
+        let previous = self.current_method.replace(name);
+        walk::walk_method_definition(self, method);
+        self.current_method = previous;
+    }
+
+    fn visit_property_definition(&mut self, prop: &PropertyDefinition<'a>) {
+        // Field initializer: `sub = this.source$.subscribe()`
+        if let Some(call) = prop.value.as_ref().and_then(as_subscribe_call) {
+            self.stored_calls
+                .push((call.span, property_key_name(&prop.key).to_string()));
+        }
+
+        let previous = self.current_method.take();
+        walk::walk_property_definition(self, prop);
+        self.current_method = previous;
+    }
+
+    fn visit_assignment_expression(&mut self, expr: &AssignmentExpression<'a>) {
+        // Assignment: `this.sub = this.source$.subscribe()`
+        if let AssignmentTarget::StaticMemberExpression(member) = &expr.left {
+            if matches!(member.object, Expression::ThisExpression(_)) {
+                if let Some(call) = as_subscribe_call(&expr.right) {
+                    self.stored_calls
+                        .push((call.span, member.property.name.to_string()));
+                }
+            }
+        }
+
+        walk::walk_assignment_expression(self, expr);
+    }
+
+    fn visit_call_expression(&mut self, call: &CallExpression<'a>) {
+        match (member_call_name(call), callee_identifier(call)) {
+            (Some("subscribe"), _) => self.record_subscribe_call(call),
+            (Some("add"), _) => {
+                // Composite subscription: `this.subs.add(source$.subscribe())`
+                if let Expression::StaticMemberExpression(member) = &call.callee {
+                    if let Some(field) = this_member_name(&member.object) {
+                        for argument in &call.arguments {
+                            if let Some(inner) = argument.as_expression().and_then(as_subscribe_call)
+                            {
+                                self.added_calls.push((inner.span, field.to_string()));
+                            }
+                        }
+                    }
+                }
+            }
+            (Some(method @ ("unsubscribe" | "next" | "complete")), _)
+                if self.in_ng_on_destroy() =>
+            {
+                if let Expression::StaticMemberExpression(member) = &call.callee {
+                    if let Some(field) = this_member_name(&member.object) {
+                        if method == "unsubscribe" {
+                            self.context.unsubscribed_fields.insert(field.to_string());
+                        } else {
+                            self.context.completed_fields.insert(field.to_string());
+                        }
+                    }
+                }
+            }
+            (None, Some("inject")) => {
+                let injects_destroy_ref = call.arguments.first().is_some_and(|arg| {
+                    matches!(arg, Argument::Identifier(ident) if ident.name.as_str() == "DestroyRef")
+                });
+                if injects_destroy_ref {
+                    self.context.injects_destroy_ref = true;
+                }
+            }
+            _ => {}
+        }
+
+        walk::walk_call_expression(self, call);
+    }
+}
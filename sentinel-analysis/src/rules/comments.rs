@@ -0,0 +1,195 @@
+use oxc_semantic::Semantic;
+use oxc_span::Span;
+
+/// The kind of a comment
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum CommentKind {
+    /// `// ...`
+    Line,
+    /// `/* ... */`
+    Block,
+    /// `/** ... */`
+    JsDoc,
+}
+
+/// A comment of a file with its text
+#[derive(Debug, Clone)]
+pub struct SourceComment<'s> {
+    pub kind: CommentKind,
+    /// Span of the comment including its delimiters
+    pub span: Span,
+    /// Text of the comment without the delimiters
+    pub text: &'s str,
+}
+
+/// A tag of a JSDoc comment, e.g. `@deprecated Use inject() instead`
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct JsDocTag {
+    /// Name of the tag without the `@`
+    pub name: String,
+    /// Text following the tag name, joined over continuation lines
+    pub text: String,
+}
+
+/// A parsed JSDoc comment
+#[derive(Debug, Clone, Default)]
+pub struct JsDoc {
+    /// Text before the first tag
+    pub description: String,
+    pub tags: Vec<JsDocTag>,
+}
+
+impl JsDoc {
+    /// Parse the text of a `/** ... */` comment without its delimiters
+    pub fn parse(text: &str) -> Self {
+        let mut jsdoc = JsDoc::default();
+        let mut description = Vec::new();
+
+        for line in text.lines() {
+            // Strip the leading ` * ` of each line
+            let line = line.trim();
+            let line = line.strip_prefix('*').unwrap_or(line).trim();
+
+            if let Some(tag) = line.strip_prefix('@') {
+                let (name, text) = tag.split_once(char::is_whitespace).unwrap_or((tag, ""));
+                jsdoc.tags.push(JsDocTag {
+                    name: name.to_string(),
+                    text: text.trim().to_string(),
+                });
+            } else if let Some(tag) = jsdoc.tags.last_mut() {
+                if !line.is_empty() {
+                    if !tag.text.is_empty() {
+                        tag.text.push(' ');
+                    }
+                    tag.text.push_str(line);
+                }
+            } else {
+                description.push(line);
+            }
+        }
+
+        jsdoc.description = description.join("\n").trim().to_string();
+        jsdoc
+    }
+
+    /// Get the first tag with a name
+    pub fn tag(&self, name: &str) -> Option<&JsDocTag> {
+        self.tags.iter().find(|tag| tag.name == name)
+    }
+
+    /// Check if the comment has a tag, e.g. `deprecated`
+    pub fn has_tag(&self, name: &str) -> bool {
+        self.tag(name).is_some()
+    }
+}
+
+/// The comments of a file, so rules can inspect them without re-reading the file
+///
+/// Comments are attached to a node if they end right before it, with only whitespace
+/// between them, which covers JSDoc blocks and `//` comments in front of declarations.
+#[derive(Debug, Clone, Default)]
+pub struct Comments<'s> {
+    source: &'s str,
+    comments: Vec<SourceComment<'s>>,
+}
+
+impl<'s> Comments<'s> {
+    /// Collect the comments found by the parser
+    pub fn from_semantic(semantic: &Semantic<'s>) -> Self {
+        Self::new(
+            semantic.source_text(),
+            semantic.comments().iter().map(|comment| comment.span),
+        )
+    }
+
+    /// Create the comments of a source from their spans
+    pub fn new(source: &'s str, spans: impl IntoIterator<Item = Span>) -> Self {
+        let mut comments: Vec<SourceComment> = spans
+            .into_iter()
+            .filter_map(|span| {
+                let raw = source.get(span.start as usize..span.end as usize)?;
+                let (kind, text) = if let Some(text) = raw.strip_prefix("//") {
+                    (CommentKind::Line, text)
+                } else if let Some(text) = raw.strip_prefix("/**").filter(|_| raw != "/**/") {
+                    (CommentKind::JsDoc, text.strip_suffix("*/").unwrap_or(text))
+                } else {
+                    let text = raw.strip_prefix("/*").unwrap_or(raw);
+                    (CommentKind::Block, text.strip_suffix("*/").unwrap_or(text))
+                };
+                Some(SourceComment { kind, span, text })
+            })
+            .collect();
+        comments.sort_by_key(|comment| comment.span.start);
+
+        Self { source, comments }
+    }
+
+    /// Iterate over all comments of the file in source order
+    pub fn iter(&self) -> impl Iterator<Item = &SourceComment<'s>> {
+        self.comments.iter()
+    }
+
+    /// Get the comments directly in front of a node, in source order
+    ///
+    /// Pass the span of the outermost node, e.g. the `export` declaration or the first
+    /// decorator, since comments in front of those are not directly before the inner node.
+    pub fn leading(&self, span: Span) -> Vec<&SourceComment<'s>> {
+        let end = self
+            .comments
+            .partition_point(|comment| comment.span.end <= span.start);
+
+        let mut position = span.start as usize;
+        let mut leading = Vec::new();
+        for comment in self.comments[..end].iter().rev() {
+            let between = &self.source[comment.span.end as usize..position];
+            if !between.trim().is_empty() {
+                break;
+            }
+            leading.push(comment);
+            position = comment.span.start as usize;
+        }
+
+        leading.reverse();
+        leading
+    }
+
+    /// Get the JSDoc comment of a node, the last `/** ... */` comment in front of it
+    pub fn jsdoc(&self, span: Span) -> Option<JsDoc> {
+        self.leading(span)
+            .into_iter()
+            .rev()
+            .find(|comment| comment.kind == CommentKind::JsDoc)
+            .map(|comment| JsDoc::parse(comment.text))
+    }
+
+    /// Check if a node is marked as `@deprecated` in its JSDoc
+    pub fn is_deprecated(&self, span: Span) -> bool {
+        self.jsdoc(span)
+            .is_some_and(|jsdoc| jsdoc.has_tag("deprecated"))
+    }
+}
+
+/// Find a debt marker such as `TODO` or `FIXME` in a comment
+///
+/// Returns the marker and the text after it. Markers only count as whole words at the
+/// start of a comment line, so `todos` or `// see the TODO list` do not match.
+pub fn debt_marker<'c>(
+    comment: &SourceComment<'c>,
+    markers: &[String],
+) -> Option<(String, &'c str)> {
+    comment.text.lines().find_map(|line| {
+        let line = line.trim_start();
+        let line = line.strip_prefix('*').unwrap_or(line).trim_start();
+        markers.iter().find_map(|marker| {
+            let rest = line.strip_prefix(marker.as_str())?;
+            let boundary = rest
+                .chars()
+                .next()
+                .is_none_or(|c| !c.is_alphanumeric() && c != '_');
+            boundary.then(|| {
+                let text = rest.trim_start_matches(|c: char| c == ':' || c.is_whitespace());
+                (marker.clone(), text.trim_end())
+            })
+        })
+    })
+}
@@ -0,0 +1,113 @@
+//! Context passed to rules while they run on a file
+//!
+//! Rules must not write to the file system themselves: a log file or a dumped tree in
+//! `/tmp` leaks into every run and races between the files analyzed in parallel. A rule
+//! that needs to explain itself logs through its `RuleContext` instead, which only emits
+//! anything for the rules selected with `--rule-debug=<id>`:
+//!
+//! - `debug` prints a message to stderr, prefixed with the rule and the file
+//! - `artifact` writes a file to `<output_dir>/rule-debug/<rule>/`, named after the
+//!   analyzed file, e.g. the tree a rule matched against
+
+use std::collections::HashSet;
+use std::fs;
+use std::path::PathBuf;
+
+/// Rules selected for debugging and the directory their artifacts are written to
+#[derive(Debug, Clone)]
+pub struct RuleDebug {
+    rules: HashSet<String>,
+    artifact_dir: PathBuf,
+}
+
+impl RuleDebug {
+    pub fn new(rules: impl IntoIterator<Item = String>, artifact_dir: impl Into<PathBuf>) -> Self {
+        Self {
+            rules: rules.into_iter().collect(),
+            artifact_dir: artifact_dir.into(),
+        }
+    }
+
+    /// Whether a rule is selected for debugging
+    pub fn is_enabled(&self, rule_name: &str) -> bool {
+        self.rules.contains(rule_name)
+    }
+
+    /// Get the rules selected for debugging
+    pub fn rules(&self) -> Vec<&str> {
+        let mut rules: Vec<&str> = self.rules.iter().map(String::as_str).collect();
+        rules.sort();
+        rules
+    }
+}
+
+/// The rule and file being analyzed, with the debug output of the rule
+pub struct RuleContext<'a> {
+    rule_name: &'a str,
+    file_path: &'a str,
+    debug: Option<&'a RuleDebug>,
+}
+
+impl<'a> RuleContext<'a> {
+    pub fn new(rule_name: &'a str, file_path: &'a str, debug: Option<&'a RuleDebug>) -> Self {
+        Self {
+            rule_name,
+            file_path,
+            debug: debug.filter(|debug| debug.is_enabled(rule_name)),
+        }
+    }
+
+    /// Get the path of the file being analyzed
+    pub fn file_path(&self) -> &'a str {
+        self.file_path
+    }
+
+    /// Whether the rule is selected for debugging
+    ///
+    /// Rules can check it before building expensive debug output.
+    pub fn is_debugging(&self) -> bool {
+        self.debug.is_some()
+    }
+
+    /// Print a debug message of the rule, if it is selected for debugging
+    pub fn debug(&self, message: &str) {
+        if self.is_debugging() {
+            eprintln!("[{}] {}: {}", self.rule_name, self.file_path, message);
+        }
+    }
+
+    /// Write a debug artifact of the rule, if it is selected for debugging
+    ///
+    /// Returns the path of the written file.
+    pub fn artifact(&self, name: &str, content: &str) -> Option<PathBuf> {
+        let debug = self.debug?;
+        let dir = debug.artifact_dir.join(self.rule_name);
+        let path = dir.join(format!("{}.{}", artifact_stem(self.file_path), name));
+        let written = fs::create_dir_all(&dir).and_then(|_| fs::write(&path, content));
+        match written {
+            Ok(()) => {
+                self.debug(&format!("wrote {}", path.display()));
+                Some(path)
+            }
+            Err(e) => {
+                self.debug(&format!("failed to write {}: {}", path.display(), e));
+                None
+            }
+        }
+    }
+}
+
+/// Turn a file path into a file name, e.g. `src/app/app.ts` into `src_app_app.ts`
+fn artifact_stem(file_path: &str) -> String {
+    file_path
+        .trim_start_matches(['.', '/', '\\'])
+        .chars()
+        .map(|c| {
+            if c.is_ascii_alphanumeric() || matches!(c, '.' | '-' | '_') {
+                c
+            } else {
+                '_'
+            }
+        })
+        .collect()
+}
@@ -0,0 +1,68 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::Expression;
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
+
+/// Rule that detects applications bootstrapped through an NgModule
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// platformBrowserDynamic().bootstrapModule(AppModule);
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// bootstrapApplication(AppComponent, appConfig);
+/// ```
+pub struct AngularBootstrapModuleRule {}
+
+impl AngularBootstrapModuleRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn create_diagnostic(span: Span) -> OxcDiagnostic {
+        OxcDiagnostic::warn("Application is bootstrapped with bootstrapModule")
+            .with_help("Use bootstrapApplication() with a standalone root component and an ApplicationConfig instead")
+            .with_label(span.label("bootstrapModule call"))
+    }
+}
+
+impl Rule for AngularBootstrapModuleRule {
+    fn name(&self) -> &'static str {
+        "angular-bootstrap-module"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects bootstrapModule calls that can be replaced by bootstrapApplication"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::MigrationStandalone
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        match node {
+            AstKind::CallExpression(call) => match &call.callee {
+                Expression::StaticMemberExpression(member)
+                    if member.property.name.as_str() == "bootstrapModule" =>
+                {
+                    vec![Self::create_diagnostic(member.property.span)]
+                }
+                _ => Vec::new(),
+            },
+            _ => Vec::new(),
+        }
+    }
+}
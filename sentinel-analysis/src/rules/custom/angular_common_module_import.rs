@@ -0,0 +1,78 @@
+use oxc_ast::AstKind;
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+
+use crate::rules::catalog::tags;
+use crate::rules::class_context::{array_identifiers, decorator_name, decorator_property};
+use crate::rules::{Rule, RuleCategory};
+
+/// Rule that detects `CommonModule` imports that can be replaced by specific imports
+///
+/// Standalone components should only import what their template uses. `CommonModule`
+/// pulls in every common directive and pipe; most templates only need a few of them or
+/// none at all with the built-in control flow (`@if`, `@for`).
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// @Component({ selector: 'app-user', imports: [CommonModule] })
+/// export class UserComponent {}
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// @Component({ selector: 'app-user', imports: [AsyncPipe, NgClass] })
+/// export class UserComponent {}
+/// ```
+pub struct AngularCommonModuleImportRule {}
+
+impl AngularCommonModuleImportRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn create_diagnostic(span: Span) -> OxcDiagnostic {
+        OxcDiagnostic::warn("CommonModule import can be replaced by specific imports")
+            .with_help("Import only the directives and pipes the template uses (e.g. AsyncPipe, NgClass), and use the built-in control flow instead of NgIf/NgFor")
+            .with_label(span.label("CommonModule import"))
+    }
+}
+
+impl Rule for AngularCommonModuleImportRule {
+    fn name(&self) -> &'static str {
+        "angular-common-module-import"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects CommonModule imports that can be replaced by specific directive and pipe imports"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::MigrationStandalone
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        let AstKind::Decorator(decorator) = node else {
+            return Vec::new();
+        };
+
+        if !matches!(decorator_name(decorator).as_deref(), Some("Component")) {
+            return Vec::new();
+        }
+
+        decorator_property(decorator, "imports")
+            .map(|prop| array_identifiers(&prop.value))
+            .unwrap_or_default()
+            .into_iter()
+            .filter(|(name, _)| *name == "CommonModule")
+            .map(|(_, span)| Self::create_diagnostic(span))
+            .collect()
+    }
+}
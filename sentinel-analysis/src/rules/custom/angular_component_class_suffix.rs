@@ -5,7 +5,8 @@ use oxc_diagnostics::OxcDiagnostic;
 use oxc_span::Span;
 use serde_json::Value;
 
-use crate::rules::Rule;
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
 
 /// Rule that enforces Angular component class naming convention
 ///
@@ -120,6 +121,14 @@ impl Rule for AngularComponentClassSuffixRule {
         "Enforces that classes decorated with @Component have the suffix 'Component' (or custom suffix)"
     }
 
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Angular
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
     fn set_config(&mut self, config: Value) {
         if let Some(obj) = config.as_object() {
             if let Some(suffixes) = obj.get("suffixes") {
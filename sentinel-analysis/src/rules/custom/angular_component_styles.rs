@@ -0,0 +1,242 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{ArrayExpressionElement, Class, Expression};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::{GetSpan, Span};
+use serde_json::Value;
+use std::path::Path;
+
+use crate::angular_graph::static_string;
+use crate::rules::catalog::tags;
+use crate::rules::class_context::{decorator_name, decorator_property};
+use crate::rules::{Rule, RuleCategory, RuleExamples};
+use crate::styles::{Stylesheet, parse_stylesheet};
+
+/// A stylesheet of a component, with where it is referenced
+struct ComponentStyle {
+    /// File name of an external stylesheet, `None` for inline styles
+    file: Option<String>,
+    /// `None` if the file cannot be read
+    stylesheet: Option<Stylesheet>,
+    /// Span of the `styleUrl` entry or inline style in the component
+    span: Span,
+}
+
+/// Rule that checks the stylesheets of Angular components
+///
+/// The styles of a component are parsed with the lightweight SCSS parser in
+/// `crate::styles`, for inline `styles` as well as the files of `styleUrl` and
+/// `styleUrls`. The rule reports:
+///
+/// - `::ng-deep`, which is deprecated and leaks styles out of the component
+/// - selectors nested deeper than `maxSelectorDepth`, which are brittle and slow to match
+/// - referenced style files that contain no style rules or do not exist
+///
+/// Findings point at the reference in the component, with the line in the stylesheet in
+/// the message.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// @Component({
+///   selector: 'app-card',
+///   styles: [':host ::ng-deep .mat-card { padding: 0; }'],
+/// })
+/// export class CardComponent {}
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// @Component({
+///   selector: 'app-card',
+///   styles: [':host { --card-padding: 0; }'],
+/// })
+/// export class CardComponent {}
+/// ```
+///
+/// ## Rule Options
+///
+/// - `maxSelectorDepth`: Maximum number of compound selectors in a selector (default: 4)
+/// - `allowNgDeep`: Set to `true` to not report `::ng-deep` (default: false)
+pub struct AngularComponentStylesRule {
+    max_selector_depth: usize,
+    allow_ng_deep: bool,
+}
+
+impl AngularComponentStylesRule {
+    pub fn new() -> Self {
+        Self {
+            max_selector_depth: 4,
+            allow_ng_deep: false,
+        }
+    }
+
+    /// Collect the inline styles and style files of a component
+    fn component_styles(class: &Class, file_path: &str) -> Vec<ComponentStyle> {
+        let Some(decorator) = class
+            .decorators
+            .iter()
+            .find(|decorator| decorator_name(decorator).as_deref() == Some("Component"))
+        else {
+            return Vec::new();
+        };
+
+        let dir = Path::new(file_path).parent().unwrap_or(Path::new(""));
+        let mut styles = Vec::new();
+        let mut add_file = |url: String, span: Span| {
+            let stylesheet = std::fs::read_to_string(dir.join(&url))
+                .ok()
+                .map(|content| parse_stylesheet(&content));
+            styles.push(ComponentStyle {
+                file: Some(url),
+                stylesheet,
+                span,
+            });
+        };
+
+        for name in ["styleUrl", "styleUrls"] {
+            let Some(prop) = decorator_property(decorator, name) else {
+                continue;
+            };
+            match &prop.value {
+                Expression::ArrayExpression(array) => {
+                    for element in &array.elements {
+                        if let Some(url) = element.as_expression().and_then(static_string) {
+                            add_file(url, element.span());
+                        }
+                    }
+                }
+                value => {
+                    if let Some(url) = static_string(value) {
+                        add_file(url, value.span());
+                    }
+                }
+            }
+        }
+
+        if let Some(prop) = decorator_property(decorator, "styles") {
+            let inline: Vec<&Expression> = match &prop.value {
+                Expression::ArrayExpression(array) => array
+                    .elements
+                    .iter()
+                    .filter_map(ArrayExpressionElement::as_expression)
+                    .collect(),
+                value => vec![value],
+            };
+            for value in inline {
+                if let Some(css) = static_string(value) {
+                    styles.push(ComponentStyle {
+                        file: None,
+                        stylesheet: Some(parse_stylesheet(&css)),
+                        span: value.span(),
+                    });
+                }
+            }
+        }
+
+        styles
+    }
+
+    fn check_style(&self, style: &ComponentStyle) -> Vec<OxcDiagnostic> {
+        let location = |line: usize| match &style.file {
+            Some(file) => format!("{}:{}", file, line),
+            None => format!("inline styles, line {}", line),
+        };
+
+        let Some(stylesheet) = &style.stylesheet else {
+            let file = style.file.as_deref().unwrap_or_default();
+            return vec![
+                OxcDiagnostic::warn(format!("Style file {} does not exist", file))
+                    .with_help("Fix the path or remove the reference")
+                    .with_label(style.span.label("Missing style file")),
+            ];
+        };
+        if stylesheet.is_empty() && style.file.is_some() {
+            let file = style.file.as_deref().unwrap_or_default();
+            return vec![
+                OxcDiagnostic::warn(format!("Style file {} contains no styles", file))
+                    .with_help("Remove the empty style file and its reference from the component")
+                    .with_label(style.span.label("Unused style file")),
+            ];
+        }
+
+        let mut diagnostics = Vec::new();
+        for rule in &stylesheet.rules {
+            if !self.allow_ng_deep && rule.selectors.iter().any(|s| s.contains("::ng-deep")) {
+                diagnostics.push(
+                    OxcDiagnostic::warn(format!("::ng-deep used in {}", location(rule.line)))
+                        .with_help("::ng-deep is deprecated; style the child component through CSS custom properties or its inputs, or move the styles to a global stylesheet")
+                        .with_label(style.span.label("::ng-deep")),
+                );
+            }
+            let depth = rule.depth();
+            if depth > self.max_selector_depth {
+                diagnostics.push(
+                    OxcDiagnostic::warn(format!(
+                        "Selector nested {} levels deep in {}",
+                        depth,
+                        location(rule.line)
+                    ))
+                    .with_help(format!(
+                        "Flatten the selector to at most {} levels, e.g. with a class on the styled element",
+                        self.max_selector_depth
+                    ))
+                    .with_label(style.span.label("Deep selector")),
+                );
+            }
+        }
+        diagnostics
+    }
+}
+
+impl Rule for AngularComponentStylesRule {
+    fn name(&self) -> &'static str {
+        "angular-component-styles"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects ::ng-deep, deeply nested selectors and unused style files of components"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Style
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::EXPERIMENTAL, tags::EXPENSIVE]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "@Component({\n  selector: 'app-card',\n  styles: [':host ::ng-deep .mat-card { padding: 0; }'],\n})\nexport class CardComponent {}",
+            ],
+            correct: &[
+                "@Component({\n  selector: 'app-card',\n  styles: [':host { --card-padding: 0; }'],\n})\nexport class CardComponent {}",
+            ],
+        }
+    }
+
+    fn set_config(&mut self, config: Value) {
+        if let Some(obj) = config.as_object() {
+            if let Some(max) = obj.get("maxSelectorDepth").and_then(Value::as_u64) {
+                self.max_selector_depth = max as usize;
+            }
+            if let Some(allow) = obj.get("allowNgDeep").and_then(Value::as_bool) {
+                self.allow_ng_deep = allow;
+            }
+        }
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, file_path: &str) -> Vec<OxcDiagnostic> {
+        let AstKind::Class(class) = node else {
+            return Vec::new();
+        };
+        Self::component_styles(class, file_path)
+            .iter()
+            .flat_map(|style| self.check_style(style))
+            .collect()
+    }
+}
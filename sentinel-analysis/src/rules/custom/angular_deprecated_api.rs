@@ -0,0 +1,278 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{ImportDeclaration, ImportDeclarationSpecifier};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+use serde::Deserialize;
+use serde_json::Value;
+use std::collections::HashMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::sync::{Arc, LazyLock, Mutex};
+
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory, RuleExamples};
+
+/// Deprecations shipped with the analyzer
+static BUILTIN_DEPRECATIONS: LazyLock<Vec<Deprecation>> = LazyLock::new(|| {
+    serde_json::from_str::<DeprecationDatabase>(include_str!(
+        "../../../data/angular-deprecations.json"
+    ))
+    .expect("Invalid deprecation database")
+    .deprecations
+});
+
+/// A deprecation database, see `data/angular-deprecations.json`
+#[derive(Debug, Deserialize)]
+struct DeprecationDatabase {
+    deprecations: Vec<Deprecation>,
+}
+
+/// A deprecated export of a package
+#[derive(Debug, Clone, Deserialize)]
+struct Deprecation {
+    /// Module specifier the symbol is imported from, e.g. `@angular/common/http`
+    package: String,
+    /// Exported name, or `*` if the whole entry point is deprecated
+    symbol: String,
+    /// Version of the package that deprecated the symbol
+    since: String,
+    replacement: Option<String>,
+}
+
+/// Versions of the dependencies declared in a `package.json`
+type DependencyVersions = HashMap<String, String>;
+
+/// Rule that detects imports of deprecated Angular and RxJS APIs
+///
+/// The deprecated symbols come from a database listing each symbol with the version that
+/// deprecated it. Only symbols deprecated at or below the version the project depends on
+/// are reported, so a project on Angular 17 is not told about deprecations of Angular 19.
+/// The version is read from the nearest `package.json` of the analyzed file; Angular
+/// packages without an own entry use the version of `@angular/core`. If no version can be
+/// found, every deprecation is reported.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code in a project on Angular 18:
+///
+/// ```typescript
+/// import { HttpClientModule } from '@angular/common/http';
+/// import { ComponentFactoryResolver } from '@angular/core';
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// import { provideHttpClient } from '@angular/common/http';
+/// import { ViewContainerRef } from '@angular/core';
+/// ```
+///
+/// ## Rule Options
+///
+/// - `versions`: Package versions overriding the ones in `package.json`, e.g. `{ "@angular/core": "17.3.0" }`
+/// - `database`: Path of an additional deprecation database in the format of the built-in one
+pub struct AngularDeprecatedApiRule {
+    deprecations: Vec<Deprecation>,
+    versions: DependencyVersions,
+    /// Dependency versions of the nearest `package.json`, per directory of analyzed files
+    projects: Mutex<HashMap<PathBuf, Option<Arc<DependencyVersions>>>>,
+}
+
+impl AngularDeprecatedApiRule {
+    pub fn new() -> Self {
+        Self {
+            deprecations: BUILTIN_DEPRECATIONS.clone(),
+            versions: HashMap::new(),
+            projects: Mutex::new(HashMap::new()),
+        }
+    }
+
+    /// Get the dependency versions of the nearest `package.json` of a file
+    fn project_versions(&self, file_path: &str) -> Option<Arc<DependencyVersions>> {
+        let dir = Path::new(file_path).parent()?.to_path_buf();
+        if let Some(cached) = self.projects.lock().ok()?.get(&dir) {
+            return cached.clone();
+        }
+
+        let versions = dir
+            .ancestors()
+            .find_map(|ancestor| fs::read_to_string(ancestor.join("package.json")).ok())
+            .and_then(|content| serde_json::from_str::<Value>(&content).ok())
+            .map(|manifest| {
+                let mut versions = DependencyVersions::new();
+                for section in ["peerDependencies", "devDependencies", "dependencies"] {
+                    if let Some(dependencies) = manifest.get(section).and_then(Value::as_object) {
+                        for (name, version) in dependencies {
+                            if let Some(version) = version.as_str() {
+                                versions.insert(name.clone(), version.to_string());
+                            }
+                        }
+                    }
+                }
+                Arc::new(versions)
+            });
+
+        if let Ok(mut projects) = self.projects.lock() {
+            projects.insert(dir, versions.clone());
+        }
+        versions
+    }
+
+    /// Get the version of the package a module specifier belongs to
+    fn package_version(&self, package: &str, file_path: &str) -> Option<(u64, u64, u64)> {
+        let name = package_name(package);
+        let lookup = |versions: &DependencyVersions| {
+            versions.get(name).cloned().or_else(|| {
+                name.starts_with("@angular/")
+                    .then(|| versions.get("@angular/core").cloned())
+                    .flatten()
+            })
+        };
+
+        let version = lookup(&self.versions)
+            .or_else(|| self.project_versions(file_path).and_then(|v| lookup(&v)))?;
+        parse_version(&version)
+    }
+
+    fn is_deprecated(&self, deprecation: &Deprecation, file_path: &str) -> bool {
+        let Some(since) = parse_version(&deprecation.since) else {
+            return false;
+        };
+        self.package_version(&deprecation.package, file_path)
+            .is_none_or(|version| version >= since)
+    }
+
+    fn check_import(&self, import: &ImportDeclaration, file_path: &str) -> Vec<OxcDiagnostic> {
+        let source = import.source.value.as_str();
+        let mut diagnostics = Vec::new();
+
+        for deprecation in self.deprecations.iter().filter(|d| d.package == source) {
+            if deprecation.symbol == "*" {
+                if self.is_deprecated(deprecation, file_path) {
+                    diagnostics.push(Self::create_diagnostic(deprecation, import.source.span));
+                }
+                continue;
+            }
+
+            let Some(specifiers) = &import.specifiers else {
+                continue;
+            };
+            for specifier in specifiers {
+                let ImportDeclarationSpecifier::ImportSpecifier(specifier) = specifier else {
+                    continue;
+                };
+                if specifier.imported.name().as_str() == deprecation.symbol
+                    && self.is_deprecated(deprecation, file_path)
+                {
+                    diagnostics.push(Self::create_diagnostic(deprecation, specifier.span));
+                }
+            }
+        }
+
+        diagnostics
+    }
+
+    fn create_diagnostic(deprecation: &Deprecation, span: Span) -> OxcDiagnostic {
+        let subject = if deprecation.symbol == "*" {
+            format!("'{}'", deprecation.package)
+        } else {
+            format!("'{}' from '{}'", deprecation.symbol, deprecation.package)
+        };
+        let diagnostic = OxcDiagnostic::warn(format!(
+            "{} is deprecated since {} {}",
+            subject,
+            package_name(&deprecation.package),
+            deprecation.since
+        ))
+        .with_label(span.label("Deprecated API"));
+
+        match &deprecation.replacement {
+            Some(replacement) => diagnostic.with_help(replacement.clone()),
+            None => diagnostic,
+        }
+    }
+}
+
+/// Get the package of a module specifier, e.g. `@angular/common` for `@angular/common/http`
+fn package_name(specifier: &str) -> &str {
+    let segments = if specifier.starts_with('@') { 2 } else { 1 };
+    match specifier.match_indices('/').nth(segments - 1) {
+        Some((index, _)) => &specifier[..index],
+        None => specifier,
+    }
+}
+
+/// Parse the lowest version of a version or range like `^17.3.0`, `~16.2` or `18.x`
+fn parse_version(version: &str) -> Option<(u64, u64, u64)> {
+    let version = version.trim_start_matches(|c: char| !c.is_ascii_digit());
+    let mut parts = version.split('.').map(|part| {
+        let digits: String = part.chars().take_while(char::is_ascii_digit).collect();
+        digits.parse::<u64>().ok()
+    });
+    let major = parts.next()??;
+    Some((
+        major,
+        parts.next().flatten().unwrap_or(0),
+        parts.next().flatten().unwrap_or(0),
+    ))
+}
+
+impl Rule for AngularDeprecatedApiRule {
+    fn name(&self) -> &'static str {
+        "angular-deprecated-api"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects imports of Angular and RxJS APIs deprecated in the version the project uses"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Angular
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &["import { HttpClientModule } from '@angular/common/http';"],
+            correct: &["import { provideHttpClient } from '@angular/common/http';"],
+        }
+    }
+
+    fn set_config(&mut self, config: Value) {
+        if let Some(obj) = config.as_object() {
+            if let Some(versions) = obj.get("versions").and_then(Value::as_object) {
+                self.versions = versions
+                    .iter()
+                    .filter_map(|(name, version)| {
+                        Some((name.clone(), version.as_str()?.to_string()))
+                    })
+                    .collect();
+            }
+            if let Some(path) = obj.get("database").and_then(Value::as_str) {
+                let database = fs::read_to_string(path)
+                    .map_err(|err| err.to_string())
+                    .and_then(|content| {
+                        serde_json::from_str::<DeprecationDatabase>(&content)
+                            .map_err(|err| err.to_string())
+                    });
+                match database {
+                    Ok(database) => self.deprecations.extend(database.deprecations),
+                    Err(err) => eprintln!(
+                        "Warning: invalid deprecation database {} for angular-deprecated-api: {}",
+                        path, err
+                    ),
+                }
+            }
+        }
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, file_path: &str) -> Vec<OxcDiagnostic> {
+        match node {
+            AstKind::ImportDeclaration(import) => self.check_import(import, file_path),
+            _ => Vec::new(),
+        }
+    }
+}
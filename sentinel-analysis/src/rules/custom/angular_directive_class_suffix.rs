@@ -5,7 +5,8 @@ use oxc_diagnostics::OxcDiagnostic;
 use oxc_span::Span;
 use serde_json::Value;
 
-use crate::rules::Rule;
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
 
 /// Rule that enforces Angular directive class naming convention
 ///
@@ -119,6 +120,14 @@ impl Rule for AngularDirectiveClassSuffixRule {
         "Enforces that classes decorated with @Directive have the suffix 'Directive' (or custom suffix)"
     }
 
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Angular
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
     fn set_config(&mut self, config: Value) {
         if let Some(obj) = config.as_object() {
             if let Some(suffixes) = obj.get("suffixes") {
@@ -5,7 +5,8 @@ use oxc_diagnostics::OxcDiagnostic;
 use oxc_span::Span;
 use serde_json::Value;
 
-use crate::rules::Rule;
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
 
 /// Rule that checks for excessive Angular signal inputs
 ///
@@ -124,6 +125,14 @@ impl Rule for AngularInputCountRule {
         "Checks for excessive Angular signal inputs"
     }
 
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Angular
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::EXPENSIVE]
+    }
+
     fn set_config(&mut self, config: Value) {
         if let Some(obj) = config.as_object() {
             if let Some(max_inputs) = obj.get("maxInputs") {
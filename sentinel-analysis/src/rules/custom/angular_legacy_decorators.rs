@@ -4,7 +4,8 @@ use oxc_diagnostics::OxcDiagnostic;
 use oxc_span::Span;
 use std::collections::HashSet;
 
-use crate::rules::Rule;
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
 
 /// Rule that checks for legacy Angular decorators that should be replaced with signal-based alternatives
 ///
@@ -68,6 +69,14 @@ impl Rule for AngularLegacyDecoratorsRule {
         "Detects usage of legacy Angular decorators that should be replaced with signal-based alternatives"
     }
 
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Angular
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
     fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
         let mut diagnostics = Vec::new();
 
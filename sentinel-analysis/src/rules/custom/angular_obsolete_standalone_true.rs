@@ -5,7 +5,8 @@ use oxc_ast_visit::Visit;
 use oxc_diagnostics::OxcDiagnostic;
 use oxc_span::Span;
 
-use crate::rules::Rule;
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
 
 /// Rule that enforces maximum lines in Angular component inline declarations
 pub struct AngularObsoleteStandaloneTrueRule {}
@@ -111,6 +112,14 @@ impl Rule for AngularObsoleteStandaloneTrueRule {
         "Alerts when standalone is set to true, because since v19 this is the default"
     }
 
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Angular
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
     fn run_on_node(&self, _node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
         let mut visitor = DecoratorPropertyVisitor::new();
 
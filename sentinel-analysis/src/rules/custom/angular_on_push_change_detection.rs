@@ -0,0 +1,260 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{
+    CallExpression, Class, Expression, PropertyDefinition, TSTypeName, TSTypeReference,
+};
+use oxc_ast_visit::{Visit, walk};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+
+use crate::angular_graph::static_string;
+use crate::rules::catalog::tags;
+use crate::rules::class_context::{decorator_name, decorator_property, property_key_name};
+use crate::rules::{DiagnosticData, Rule, RuleCategory, RuleExamples};
+
+/// Functions that create signals or signal-based inputs, queries and models
+const SIGNAL_FUNCTIONS: &[&str] = &[
+    "computed",
+    "contentChild",
+    "contentChildren",
+    "input",
+    "linkedSignal",
+    "model",
+    "signal",
+    "toSignal",
+    "viewChild",
+    "viewChildren",
+];
+
+/// Types of observables and subjects
+const OBSERVABLE_TYPES: &[&str] = &["BehaviorSubject", "Observable", "ReplaySubject", "Subject"];
+
+/// Whether a component already works with signals or observables
+///
+/// Components on signals or the `async` pipe usually switch to OnPush without further
+/// changes, while components mutating plain fields need their change detection reviewed.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+struct Reactivity {
+    signals: bool,
+    observables: bool,
+}
+
+impl Reactivity {
+    fn to_label(self) -> &'static str {
+        match (self.signals, self.observables) {
+            (true, true) => "Default change detection, the component uses signals and observables",
+            (true, false) => "Default change detection, the component uses signals",
+            (false, true) => "Default change detection, the component uses observables",
+            (false, false) => {
+                "Default change detection, the component uses neither signals nor observables"
+            }
+        }
+    }
+
+    fn from_label(label: &str) -> Self {
+        if label.ends_with("neither signals nor observables") {
+            return Self::default();
+        }
+        Self {
+            signals: label.contains("signals"),
+            observables: label.contains("observables"),
+        }
+    }
+}
+
+/// Visitor that looks for signals and observables in a class
+#[derive(Default)]
+struct ReactivityVisitor {
+    reactivity: Reactivity,
+}
+
+impl<'a> Visit<'a> for ReactivityVisitor {
+    fn visit_call_expression(&mut self, call: &CallExpression<'a>) {
+        match &call.callee {
+            Expression::Identifier(ident) => {
+                let name = ident.name.as_str();
+                if SIGNAL_FUNCTIONS.contains(&name) {
+                    self.reactivity.signals = true;
+                } else if name == "toObservable" {
+                    self.reactivity.observables = true;
+                }
+            }
+            // `input.required()`, `viewChild.required()`, `obs$.pipe()`, `obs$.subscribe()`
+            Expression::StaticMemberExpression(member) => match member.property.name.as_str() {
+                "required" => {
+                    if let Expression::Identifier(object) = &member.object {
+                        if SIGNAL_FUNCTIONS.contains(&object.name.as_str()) {
+                            self.reactivity.signals = true;
+                        }
+                    }
+                }
+                "pipe" | "subscribe" => self.reactivity.observables = true,
+                _ => {}
+            },
+            _ => {}
+        }
+
+        walk::walk_call_expression(self, call);
+    }
+
+    fn visit_ts_type_reference(&mut self, reference: &TSTypeReference<'a>) {
+        if let TSTypeName::IdentifierReference(ident) = &reference.type_name {
+            if OBSERVABLE_TYPES.contains(&ident.name.as_str()) {
+                self.reactivity.observables = true;
+            }
+        }
+
+        walk::walk_ts_type_reference(self, reference);
+    }
+
+    fn visit_property_definition(&mut self, property: &PropertyDefinition<'a>) {
+        // `users$` by convention holds an observable
+        if property_key_name(&property.key).ends_with('$') {
+            self.reactivity.observables = true;
+        }
+
+        walk::walk_property_definition(self, property);
+    }
+}
+
+/// Rule that detects components without OnPush change detection
+///
+/// With the default strategy Angular checks a component on every change detection cycle
+/// of the application. `ChangeDetectionStrategy.OnPush` only checks it when its inputs
+/// change, an event fires in it or a signal or `async` pipe it reads emits, which is
+/// faster and required for zoneless applications.
+///
+/// Every finding records in its `metadata` whether the component uses signals
+/// (`usesSignals`) and observables (`usesObservables`). Components already based on them
+/// are usually the cheapest to convert.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// @Component({
+///   selector: 'app-user',
+///   template: '{{ user().name }}',
+/// })
+/// export class UserComponent {
+///   user = input.required<User>();
+/// }
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// @Component({
+///   selector: 'app-user',
+///   template: '{{ user().name }}',
+///   changeDetection: ChangeDetectionStrategy.OnPush,
+/// })
+/// export class UserComponent {
+///   user = input.required<User>();
+/// }
+/// ```
+pub struct AngularOnPushChangeDetectionRule {}
+
+impl AngularOnPushChangeDetectionRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn check_class(class: &Class) -> Option<OxcDiagnostic> {
+        let decorator = class
+            .decorators
+            .iter()
+            .find(|decorator| decorator_name(decorator).as_deref() == Some("Component"))?;
+
+        let on_push = decorator_property(decorator, "changeDetection").is_some_and(|prop| {
+            match &prop.value {
+                Expression::StaticMemberExpression(member) => member.property.name == "OnPush",
+                _ => false,
+            }
+        });
+        if on_push {
+            return None;
+        }
+
+        let mut visitor = ReactivityVisitor::default();
+        visitor.visit_class_body(&class.body);
+        // The `async` pipe in an inline template subscribes to observables as well
+        if decorator_property(decorator, "template")
+            .and_then(|prop| static_string(&prop.value))
+            .is_some_and(|template| template.contains("| async"))
+        {
+            visitor.reactivity.observables = true;
+        }
+
+        let name = class.id.as_ref().map_or("anonymous", |id| id.name.as_str());
+        Some(Self::create_diagnostic(
+            name,
+            decorator.span,
+            visitor.reactivity,
+        ))
+    }
+
+    fn create_diagnostic(name: &str, span: Span, reactivity: Reactivity) -> OxcDiagnostic {
+        OxcDiagnostic::warn(format!(
+            "Component {} does not use OnPush change detection",
+            name
+        ))
+        .with_help("Add 'changeDetection: ChangeDetectionStrategy.OnPush' to the @Component decorator, and make sure state changes go through inputs, signals or observables")
+        .with_label(span.label(reactivity.to_label()))
+    }
+}
+
+impl Rule for AngularOnPushChangeDetectionRule {
+    fn name(&self) -> &'static str {
+        "angular-on-push-change-detection"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects components without ChangeDetectionStrategy.OnPush"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Angular
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "@Component({ selector: 'app-user', template: '' })\nexport class UserComponent {}",
+            ],
+            correct: &[
+                "@Component({\n  selector: 'app-user',\n  template: '',\n  changeDetection: ChangeDetectionStrategy.OnPush,\n})\nexport class UserComponent {}",
+            ],
+        }
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        match node {
+            AstKind::Class(class) => Self::check_class(class).into_iter().collect(),
+            _ => Vec::new(),
+        }
+    }
+
+    fn diagnostic_data(&self, diagnostic: &OxcDiagnostic, _source_code: &str) -> DiagnosticData {
+        let mut data = DiagnosticData::default();
+        let Some(label) = diagnostic
+            .labels
+            .as_ref()
+            .and_then(|labels| labels.first())
+            .and_then(|label| label.label())
+        else {
+            return data;
+        };
+
+        let reactivity = Reactivity::from_label(label);
+        data.metadata
+            .insert("usesSignals".to_string(), reactivity.signals.into());
+        data.metadata
+            .insert("usesObservables".to_string(), reactivity.observables.into());
+        data
+    }
+}
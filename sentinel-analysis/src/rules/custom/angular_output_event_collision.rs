@@ -4,7 +4,8 @@ use oxc_ast_visit::Visit;
 use oxc_diagnostics::OxcDiagnostic;
 use oxc_span::Span;
 
-use crate::rules::Rule;
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
 
 /// Rule that prevents naming collisions between Angular outputs and native DOM events
 pub struct AngularOutputEventCollisionRule {}
@@ -76,6 +77,14 @@ impl Rule for AngularOutputEventCollisionRule {
         "Prevents naming collisions between Angular outputs and native DOM events"
     }
 
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Angular
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::EXPERIMENTAL, tags::EXPENSIVE]
+    }
+
     fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
         let mut visitor = OutputEventVisitor::new();
         
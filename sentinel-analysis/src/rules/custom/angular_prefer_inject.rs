@@ -0,0 +1,286 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{
+    BindingPatternKind, Class, ClassElement, Expression, FormalParameter, MethodDefinition,
+    MethodDefinitionKind, TSAccessibility,
+};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+
+use crate::rules::catalog::tags;
+use crate::rules::class_context::{decorator_name, type_reference_name};
+use crate::rules::{DiagnosticData, Rule, RuleCategory, RuleExamples};
+use crate::utilities::source::{diagnostic_span, span_text};
+
+/// Decorators of Angular classes that are created by the injector
+const INJECTABLE_DECORATORS: &[&str] = &["Component", "Directive", "Injectable", "Pipe"];
+
+/// Parameter decorators with their `inject()` option
+const RESOLUTION_DECORATORS: &[(&str, &str)] = &[
+    ("Host", "host"),
+    ("Optional", "optional"),
+    ("Self", "self"),
+    ("SkipSelf", "skipSelf"),
+];
+
+const MESSAGE_PREFIX: &str = "Constructor injection of ";
+const REWRITABLE_LABEL: &str = "Migrate to inject()";
+const MANUAL_LABEL: &str = "Migrate to inject() by hand";
+
+/// A constructor parameter resolved by the injector
+struct InjectedParameter {
+    token: Option<String>,
+    /// The field replacing the parameter, if it can be rewritten mechanically
+    field: Option<String>,
+    span: Span,
+}
+
+impl InjectedParameter {
+    fn from_param(param: &FormalParameter) -> Self {
+        let mut token = param
+            .pattern
+            .type_annotation
+            .as_ref()
+            .and_then(|t| type_reference_name(&t.type_annotation));
+        let mut options = Vec::new();
+        let mut rewritable = true;
+
+        for decorator in &param.decorators {
+            let name = decorator_name(decorator);
+            match name.as_deref() {
+                // `@Inject(TOKEN)` takes precedence over the declared type
+                Some("Inject") => {
+                    token = match &decorator.expression {
+                        Expression::CallExpression(call) => call
+                            .arguments
+                            .first()
+                            .and_then(|argument| argument.as_expression())
+                            .and_then(|argument| match argument {
+                                Expression::Identifier(ident) => Some(ident.name.to_string()),
+                                _ => None,
+                            }),
+                        _ => None,
+                    };
+                }
+                Some(name) => match RESOLUTION_DECORATORS.iter().find(|(d, _)| *d == name) {
+                    Some((_, option)) => options.push(format!("{}: true", option)),
+                    None => rewritable = false,
+                },
+                None => rewritable = false,
+            }
+        }
+
+        // Only parameter properties become fields; other parameters are used in the body
+        let is_property = param.accessibility.is_some() || param.readonly;
+        let name = match &param.pattern.kind {
+            BindingPatternKind::BindingIdentifier(ident) => Some(ident.name.as_str()),
+            _ => None,
+        };
+
+        let field = match (&token, name) {
+            (Some(token), Some(name)) if rewritable && is_property => {
+                let mut modifiers = String::new();
+                match param.accessibility {
+                    Some(TSAccessibility::Private) => modifiers.push_str("private "),
+                    Some(TSAccessibility::Protected) => modifiers.push_str("protected "),
+                    Some(TSAccessibility::Public) => modifiers.push_str("public "),
+                    None => {}
+                }
+                if param.readonly {
+                    modifiers.push_str("readonly ");
+                }
+                let arguments = if options.is_empty() {
+                    token.clone()
+                } else {
+                    format!("{}, {{ {} }}", token, options.join(", "))
+                };
+                Some(format!("{}{} = inject({});", modifiers, name, arguments))
+            }
+            _ => None,
+        };
+
+        Self {
+            token,
+            field,
+            span: param.span,
+        }
+    }
+
+    fn label(&self) -> String {
+        match (&self.field, &self.token) {
+            (Some(field), _) => field.clone(),
+            (None, Some(token)) => format!("inject({})", token),
+            (None, None) => "Token cannot be determined".to_string(),
+        }
+    }
+}
+
+/// Rule that detects dependencies injected through the constructor
+///
+/// `inject()` is the recommended way to get dependencies in Angular. It works in field
+/// initializers, keeps the constructor free for logic, supports inheritance without
+/// repeating the parameters in `super()` calls and does not depend on decorator metadata.
+///
+/// Every finding lists the injected tokens, also as `tokens` in its `metadata`. Simple
+/// constructors, with only parameter properties and an empty body, come with a
+/// `suggestion` that replaces the constructor with `inject()` fields; `@Optional()`,
+/// `@Self()`, `@SkipSelf()` and `@Host()` become the options of `inject()`.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// @Component({ selector: 'app-user' })
+/// export class UserComponent {
+///   constructor(private readonly http: HttpClient, @Optional() private logger: Logger) {}
+/// }
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// @Component({ selector: 'app-user' })
+/// export class UserComponent {
+///   private readonly http = inject(HttpClient);
+///   private logger = inject(Logger, { optional: true });
+/// }
+/// ```
+pub struct AngularPreferInjectRule {}
+
+impl AngularPreferInjectRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn check_class(class: &Class) -> Option<OxcDiagnostic> {
+        let is_injectable = class.decorators.iter().any(|decorator| {
+            decorator_name(decorator)
+                .is_some_and(|name| INJECTABLE_DECORATORS.contains(&name.as_str()))
+        });
+        if !is_injectable {
+            return None;
+        }
+
+        let constructor = class.body.body.iter().find_map(|element| match element {
+            ClassElement::MethodDefinition(method)
+                if method.kind == MethodDefinitionKind::Constructor =>
+            {
+                Some(method)
+            }
+            _ => None,
+        })?;
+        if constructor.value.params.items.is_empty() {
+            return None;
+        }
+
+        Some(Self::create_diagnostic(constructor))
+    }
+
+    fn create_diagnostic(constructor: &MethodDefinition) -> OxcDiagnostic {
+        let parameters: Vec<InjectedParameter> = constructor
+            .value
+            .params
+            .items
+            .iter()
+            .map(InjectedParameter::from_param)
+            .collect();
+        let tokens: Vec<&str> = parameters
+            .iter()
+            .filter_map(|param| param.token.as_deref())
+            .collect();
+
+        let empty_body = constructor
+            .value
+            .body
+            .as_ref()
+            .is_some_and(|body| body.statements.is_empty());
+        let rewritable = empty_body
+            && constructor.value.params.rest.is_none()
+            && parameters.iter().all(|param| param.field.is_some());
+
+        let mut labels = vec![constructor.span.label(if rewritable {
+            REWRITABLE_LABEL
+        } else {
+            MANUAL_LABEL
+        })];
+        labels.extend(
+            parameters
+                .iter()
+                .map(|param| param.span.label(param.label())),
+        );
+
+        OxcDiagnostic::warn(format!("{}{}", MESSAGE_PREFIX, tokens.join(", ")))
+            .with_help("Replace the constructor parameters with fields initialized by inject(), e.g. 'private readonly http = inject(HttpClient);'")
+            .with_labels(labels)
+    }
+}
+
+impl Rule for AngularPreferInjectRule {
+    fn name(&self) -> &'static str {
+        "angular-prefer-inject"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects constructor injection in Angular classes and suggests inject()"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Angular
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "@Injectable({ providedIn: 'root' })\nexport class UserService {\n  constructor(private readonly http: HttpClient) {}\n}",
+            ],
+            correct: &[
+                "@Injectable({ providedIn: 'root' })\nexport class UserService {\n  private readonly http = inject(HttpClient);\n}",
+            ],
+        }
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        match node {
+            AstKind::Class(class) => Self::check_class(class).into_iter().collect(),
+            _ => Vec::new(),
+        }
+    }
+
+    fn diagnostic_data(&self, diagnostic: &OxcDiagnostic, source_code: &str) -> DiagnosticData {
+        let mut data = DiagnosticData::default();
+        let Some(tokens) = diagnostic.message.strip_prefix(MESSAGE_PREFIX) else {
+            return data;
+        };
+        let tokens: Vec<&str> = tokens.split(", ").filter(|t| !t.is_empty()).collect();
+        data.metadata.insert("tokens".to_string(), tokens.into());
+
+        let Some(labels) = diagnostic.labels.as_ref() else {
+            return data;
+        };
+        let Some((constructor, parameters)) = labels.split_first() else {
+            return data;
+        };
+        if constructor.label() != Some(REWRITABLE_LABEL) {
+            return data;
+        }
+
+        // Keep the fields at the indentation of the constructor
+        let Some(span) = diagnostic_span(diagnostic) else {
+            return data;
+        };
+        let before = span_text(source_code, Span::new(0, span.start));
+        let line = &before[before.rfind('\n').map_or(0, |i| i + 1)..];
+        let indent: String = line.chars().take_while(|c| c.is_whitespace()).collect();
+
+        let fields: Vec<&str> = parameters
+            .iter()
+            .filter_map(|label| label.label())
+            .collect();
+        data.suggestion = Some(fields.join(&format!("\n{}", indent)));
+        data
+    }
+}
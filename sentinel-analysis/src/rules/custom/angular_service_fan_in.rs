@@ -0,0 +1,143 @@
+use oxc_diagnostics::OxcDiagnostic;
+use serde_json::Value;
+use std::collections::{BTreeMap, BTreeSet};
+
+use crate::FileAnalysisResult;
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
+
+/// Angular and RxJS tokens that are expected to be injected everywhere
+const DEFAULT_IGNORED_TOKENS: &[&str] = &[
+    "ActivatedRoute",
+    "ChangeDetectorRef",
+    "DestroyRef",
+    "ElementRef",
+    "HttpClient",
+    "Injector",
+    "NgZone",
+    "Renderer2",
+    "Router",
+];
+
+/// Rule that reports services injected directly by too many components
+///
+/// A service injected into a large share of the components couples them to its API and
+/// makes it hard to change. The rule runs once after all files are analyzed and reports
+/// its findings under the `project` pseudo-file.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code, with more components than `maxConsumers`:
+///
+/// ```typescript
+/// @Component({ selector: 'app-orders' })
+/// export class OrdersComponent {
+///     private readonly state = inject(GlobalStateService);
+/// }
+/// // ... and the same in 20 other components
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// @Component({ selector: 'app-orders' })
+/// export class OrdersComponent {
+///     private readonly orders = inject(OrdersFacade);
+/// }
+/// ```
+///
+/// ## Rule Options
+///
+/// - `maxConsumers`: Maximum number of components injecting the same service (default: 10)
+/// - `ignore`: Tokens that are not counted (default: common Angular services such as `Router` and `HttpClient`)
+pub struct AngularServiceFanInRule {
+    max_consumers: usize,
+    ignore: Vec<String>,
+}
+
+impl AngularServiceFanInRule {
+    pub fn new() -> Self {
+        Self {
+            max_consumers: 10,
+            ignore: DEFAULT_IGNORED_TOKENS
+                .iter()
+                .map(|token| token.to_string())
+                .collect(),
+        }
+    }
+}
+
+impl Rule for AngularServiceFanInRule {
+    fn name(&self) -> &'static str {
+        "angular-service-fan-in"
+    }
+
+    fn description(&self) -> &'static str {
+        "Reports services injected directly by more components than allowed"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Angular
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::EXPERIMENTAL, tags::CHEAP]
+    }
+
+    fn set_config(&mut self, config: Value) {
+        if let Some(obj) = config.as_object() {
+            if let Some(max) = obj.get("maxConsumers") {
+                match max.as_u64() {
+                    Some(max) => self.max_consumers = max as usize,
+                    None => eprintln!(
+                        "Warning: invalid maxConsumers for angular-service-fan-in: {}",
+                        max
+                    ),
+                }
+            }
+            if let Some(ignore) = obj.get("ignore").and_then(Value::as_array) {
+                self.ignore = ignore
+                    .iter()
+                    .filter_map(Value::as_str)
+                    .map(str::to_string)
+                    .collect();
+            }
+        }
+    }
+
+    fn run_on_project(&self, results: &[FileAnalysisResult]) -> Vec<OxcDiagnostic> {
+        // Components injecting each token, ordered for a stable output
+        let mut consumers: BTreeMap<&str, BTreeSet<&str>> = BTreeMap::new();
+        for symbol in results
+            .iter()
+            .flat_map(|result| &result.angular_symbols)
+            .filter(|symbol| symbol.kind == "component")
+        {
+            for injection in &symbol.injections {
+                if !self.ignore.contains(&injection.token) {
+                    consumers
+                        .entry(injection.token.as_str())
+                        .or_default()
+                        .insert(symbol.name.as_str());
+                }
+            }
+        }
+
+        consumers
+            .into_iter()
+            .filter(|(_, components)| components.len() > self.max_consumers)
+            .map(|(token, components)| {
+                OxcDiagnostic::warn(format!(
+                    "{} is injected directly by {} components (limit {})",
+                    token,
+                    components.len(),
+                    self.max_consumers
+                ))
+                .with_help(format!(
+                    "Introduce a facade per feature or pass the data through inputs. Consumers: {}",
+                    components.into_iter().collect::<Vec<_>>().join(", ")
+                ))
+            })
+            .collect()
+    }
+}
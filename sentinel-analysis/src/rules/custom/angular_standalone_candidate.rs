@@ -0,0 +1,98 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::Expression;
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+
+use crate::rules::catalog::tags;
+use crate::rules::class_context::{array_identifiers, decorator_name, decorator_property};
+use crate::rules::{Rule, RuleCategory};
+
+/// Rule that detects components, directives and pipes that could be migrated to standalone
+///
+/// Declarations listed in an `NgModule` and classes that opt out with `standalone: false`
+/// are reported, since standalone is the default since Angular 19.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// @NgModule({
+///   declarations: [UserListComponent],
+/// })
+/// export class UserModule {}
+///
+/// @Component({ selector: 'app-user', standalone: false })
+/// export class UserComponent {}
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// @Component({ selector: 'app-user', imports: [UserListComponent] })
+/// export class UserComponent {}
+/// ```
+pub struct AngularStandaloneCandidateRule {}
+
+impl AngularStandaloneCandidateRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn create_declaration_diagnostic(name: &str, span: Span) -> OxcDiagnostic {
+        OxcDiagnostic::warn(format!(
+            "'{}' is declared in an NgModule and could be standalone",
+            name
+        ))
+        .with_help("Remove the declaration from the NgModule and import it where it is used, or run `ng generate @angular/core:standalone`")
+        .with_label(span.label("NgModule declaration"))
+    }
+
+    fn create_opt_out_diagnostic(span: Span) -> OxcDiagnostic {
+        OxcDiagnostic::warn("'standalone: false' opts out of standalone components")
+            .with_help("Remove 'standalone: false' and import the dependencies of the component directly")
+            .with_label(span.label("standalone opt-out"))
+    }
+}
+
+impl Rule for AngularStandaloneCandidateRule {
+    fn name(&self) -> &'static str {
+        "angular-standalone-candidate"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects NgModule-declared components, directives and pipes that could be standalone"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::MigrationStandalone
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        let AstKind::Decorator(decorator) = node else {
+            return Vec::new();
+        };
+
+        match decorator_name(decorator).as_deref() {
+            Some("NgModule") => decorator_property(decorator, "declarations")
+                .map(|prop| array_identifiers(&prop.value))
+                .unwrap_or_default()
+                .into_iter()
+                .map(|(name, span)| Self::create_declaration_diagnostic(name, span))
+                .collect(),
+            Some("Component" | "Directive" | "Pipe") => {
+                match decorator_property(decorator, "standalone") {
+                    Some(prop) if matches!(&prop.value, Expression::BooleanLiteral(b) if !b.value) => {
+                        vec![Self::create_opt_out_diagnostic(prop.span)]
+                    }
+                    _ => Vec::new(),
+                }
+            }
+            _ => Vec::new(),
+        }
+    }
+}
@@ -0,0 +1,128 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{Expression, StringLiteral};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+use serde_json::Value;
+
+use crate::rules::boundaries::{BoundaryConfig, BoundaryViolation};
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
+
+/// Rule that enforces the allowed dependencies between architectural zones
+///
+/// Files are mapped to zones (layers, libraries, feature areas) with glob patterns, and each
+/// zone declares the zones it may import from. Every import and re-export is checked as an
+/// edge of the import graph; edges into a zone that is not allowed are reported together
+/// with both ends of the edge. Imports within a zone, from files outside of any zone and
+/// from zones without an `allow` entry are not restricted.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code with `core` only allowed to import from `shared`:
+///
+/// ```typescript
+/// // src/app/core/auth.service.ts
+/// import { CheckoutStore } from '../features/checkout/checkout.store';
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// // src/app/features/checkout/checkout.component.ts
+/// import { AuthService } from '../../core/auth.service';
+/// ```
+///
+/// ## Rule Options
+///
+/// - `zones`: Zone names mapped to glob patterns of their files or packages
+/// - `allow`: Zone names mapped to the zones they may import from
+/// - `paths`: Path aliases used to resolve imports, e.g. `{ "@app/*": "src/app/*" }`
+pub struct ArchitectureBoundariesRule {
+    boundaries: BoundaryConfig,
+}
+
+impl ArchitectureBoundariesRule {
+    pub fn new() -> Self {
+        Self {
+            boundaries: BoundaryConfig::default(),
+        }
+    }
+
+    fn check_source(&self, source: &StringLiteral, file_path: &str) -> Option<OxcDiagnostic> {
+        let violation = self
+            .boundaries
+            .check_import(file_path, source.value.as_str())?;
+        Some(Self::create_diagnostic(&violation, source.span))
+    }
+
+    fn create_diagnostic(violation: &BoundaryViolation, span: Span) -> OxcDiagnostic {
+        let help = if violation.allowed.is_empty() {
+            format!(
+                "Zone '{}' must not depend on other zones",
+                violation.from_zone
+            )
+        } else {
+            format!(
+                "Zone '{}' may only depend on: {}",
+                violation.from_zone,
+                violation.allowed.join(", ")
+            )
+        };
+
+        OxcDiagnostic::error(format!(
+            "Zone '{}' must not import from zone '{}'",
+            violation.from_zone, violation.to_zone
+        ))
+        .with_help(help)
+        .with_label(span.label(format!(
+            "{} -> {} ({})",
+            violation.from_zone, violation.to_zone, violation.target
+        )))
+    }
+}
+
+impl Rule for ArchitectureBoundariesRule {
+    fn name(&self) -> &'static str {
+        "architecture-boundaries"
+    }
+
+    fn description(&self) -> &'static str {
+        "Enforces the allowed dependencies between architectural zones"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Policy
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::EXPERIMENTAL, tags::CHEAP]
+    }
+
+    fn set_config(&mut self, config: Value) {
+        match BoundaryConfig::from_value(&config) {
+            Ok(boundaries) => self.boundaries = boundaries,
+            Err(err) => eprintln!(
+                "Warning: invalid configuration for architecture-boundaries: {}",
+                err
+            ),
+        }
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, file_path: &str) -> Vec<OxcDiagnostic> {
+        let source = match node {
+            AstKind::ImportDeclaration(import) => Some(&import.source),
+            AstKind::ExportAllDeclaration(export) => Some(&export.source),
+            AstKind::ExportNamedDeclaration(export) => export.source.as_ref(),
+            AstKind::ImportExpression(import) => match &import.source {
+                Expression::StringLiteral(source) => Some(&**source),
+                _ => None,
+            },
+            _ => None,
+        };
+
+        source
+            .and_then(|source| self.check_source(source, file_path))
+            .into_iter()
+            .collect()
+    }
+}
@@ -0,0 +1,370 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{
+    AssignmentExpression, AssignmentTarget, Class, Expression, ObjectProperty, PropertyDefinition,
+};
+use oxc_ast_visit::{Visit, walk};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::{GetSpan, Span};
+use regex::Regex;
+use std::path::Path;
+use std::sync::LazyLock;
+
+use crate::angular_graph::static_string;
+use crate::rules::catalog::tags;
+use crate::rules::class_context::{decorator_name, decorator_property, property_key_name};
+use crate::rules::{DiagnosticData, Rule, RuleCategory, RuleExamples};
+
+/// Opening and closing tags of a template
+static TAG: LazyLock<Regex> =
+    LazyLock::new(|| Regex::new(r"<(/?)([A-Za-z][\w-]*)([^>]*)>").expect("Invalid tag pattern"));
+
+/// Attributes with user-facing text, e.g. `placeholder="Search"`
+static TEXT_ATTRIBUTE: LazyLock<Regex> = LazyLock::new(|| {
+    Regex::new(r#"\s(placeholder|title|alt|aria-label)\s*=\s*"([^"]*)""#)
+        .expect("Invalid attribute pattern")
+});
+
+/// Interpolations and control flow syntax, which are not text
+static NON_TEXT: LazyLock<Regex> = LazyLock::new(|| {
+    Regex::new(r"\{\{.*?\}\}|@\w+\s*(\([^)]*\))?\s*\{|[{}]|&\w+;").expect("Invalid pattern")
+});
+
+/// Elements without content
+const VOID_ELEMENTS: &[&str] = &[
+    "area", "br", "col", "hr", "img", "input", "link", "meta", "source", "wbr",
+];
+
+/// Parts of property names that hold user-facing text
+const TEXT_PROPERTIES: &[&str] = &[
+    "caption",
+    "description",
+    "heading",
+    "label",
+    "message",
+    "placeholder",
+    "text",
+    "title",
+    "tooltip",
+];
+
+const TEMPLATE_LABEL: &str = "Template text without i18n";
+const ATTRIBUTE_LABEL: &str = "Attribute without i18n-";
+const COMPONENT_LABEL: &str = "Component string without $localize";
+
+/// Longest text quoted in a message
+const MAX_QUOTED_CHARS: usize = 60;
+
+/// A user-facing text found without a translation marker
+struct UntranslatedText {
+    text: String,
+    /// Offset of the text in the template
+    offset: usize,
+    /// The attribute holding the text, `None` for element content
+    attribute: Option<String>,
+}
+
+/// Get the text a template fragment shows, without interpolations and control flow
+///
+/// Returns `None` if nothing but whitespace, punctuation and numbers is left.
+fn visible_text(fragment: &str) -> Option<String> {
+    let text = NON_TEXT.replace_all(fragment, " ");
+    let text = text.split_whitespace().collect::<Vec<_>>().join(" ");
+    text.chars().any(char::is_alphabetic).then_some(text)
+}
+
+/// Collect the texts of a template that are not marked for translation
+///
+/// Element content counts as marked if the element or one of its ancestors has an `i18n`
+/// attribute; an attribute like `placeholder` needs its own `i18n-placeholder`.
+fn untranslated_texts(template: &str) -> Vec<UntranslatedText> {
+    let mut texts = Vec::new();
+    // Open elements, with whether their content is marked for translation
+    let mut open: Vec<(String, bool)> = Vec::new();
+    let mut text_start = 0;
+
+    let push_content = |texts: &mut Vec<UntranslatedText>, start: usize, end: usize| {
+        let fragment = &template[start..end];
+        if let Some(text) = visible_text(fragment) {
+            texts.push(UntranslatedText {
+                text,
+                offset: start + fragment.len() - fragment.trim_start().len(),
+                attribute: None,
+            });
+        }
+    };
+
+    for tag in TAG.captures_iter(template) {
+        let whole = tag.get(0).expect("Match without a group 0");
+        let in_i18n = open.last().is_some_and(|(_, marked)| *marked);
+        if !in_i18n {
+            push_content(&mut texts, text_start, whole.start());
+        }
+        text_start = whole.end();
+
+        let name = tag[2].to_lowercase();
+        let attributes = tag.get(3).expect("Tag without attributes group");
+        if &tag[1] == "/" {
+            if let Some(index) = open.iter().rposition(|(open_name, _)| *open_name == name) {
+                open.truncate(index);
+            }
+            continue;
+        }
+
+        let has_attribute = |marker: &str| {
+            attributes
+                .as_str()
+                .split_whitespace()
+                .any(|part| part == marker || part.starts_with(&format!("{}=", marker)))
+        };
+        for attribute in TEXT_ATTRIBUTE.captures_iter(attributes.as_str()) {
+            if has_attribute(&format!("i18n-{}", &attribute[1])) {
+                continue;
+            }
+            if let Some(text) = visible_text(&attribute[2]) {
+                // Skip the whitespace in front of the attribute name
+                let offset = attributes.start() + attribute.get(1).map_or(0, |m| m.start());
+                texts.push(UntranslatedText {
+                    text,
+                    offset,
+                    attribute: Some(attribute[1].to_string()),
+                });
+            }
+        }
+
+        let self_closing = attributes.as_str().trim_end().ends_with('/');
+        if !self_closing && !VOID_ELEMENTS.contains(&name.as_str()) {
+            open.push((name, in_i18n || has_attribute("i18n")));
+        }
+    }
+
+    if !open.last().is_some_and(|(_, marked)| *marked) {
+        push_content(&mut texts, text_start, template.len());
+    }
+
+    texts
+}
+
+/// Visitor that collects string literals assigned to text properties of a component
+#[derive(Default)]
+struct ComponentTextVisitor {
+    /// Text and span of every literal found
+    texts: Vec<(String, Span)>,
+}
+
+impl ComponentTextVisitor {
+    fn check_value(&mut self, name: &str, value: &Expression) {
+        let name = name.to_lowercase();
+        if !TEXT_PROPERTIES.iter().any(|part| name.contains(part)) {
+            return;
+        }
+        // `$localize` is a tagged template, so it is never a static string
+        if let Some(text) = static_string(value).as_deref().and_then(visible_text) {
+            self.texts.push((text, value.span()));
+        }
+    }
+}
+
+impl<'a> Visit<'a> for ComponentTextVisitor {
+    fn visit_property_definition(&mut self, property: &PropertyDefinition<'a>) {
+        if let Some(value) = &property.value {
+            self.check_value(property_key_name(&property.key), value);
+        }
+        walk::walk_property_definition(self, property);
+    }
+
+    fn visit_assignment_expression(&mut self, assignment: &AssignmentExpression<'a>) {
+        if let AssignmentTarget::StaticMemberExpression(member) = &assignment.left {
+            if matches!(member.object, Expression::ThisExpression(_)) {
+                self.check_value(&member.property.name, &assignment.right);
+            }
+        }
+        walk::walk_assignment_expression(self, assignment);
+    }
+
+    fn visit_object_property(&mut self, property: &ObjectProperty<'a>) {
+        // Texts in configuration objects, e.g. `{ label: 'Save' }` of a menu entry
+        self.check_value(property_key_name(&property.key), &property.value);
+        walk::walk_object_property(self, property);
+    }
+}
+
+/// Rule that reports user-facing text without translation markers
+///
+/// Localization teams need to know how much of the UI can be translated. The rule reports,
+/// under the `i18n` category, the texts of component templates that lack an `i18n`
+/// attribute (`i18n-placeholder` and the like for `placeholder`, `title`, `alt` and
+/// `aria-label`), and the string literals a component assigns to text properties such as
+/// `title` or `label` without `$localize`. The number of findings per file is a measure of
+/// the i18n coverage; each finding has the `text` and its `source` in its `metadata`.
+///
+/// Texts of external templates are reported at the `templateUrl` of the component.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// @Component({
+///   selector: 'app-save',
+///   template: '<button title="Save the form">Save</button>',
+/// })
+/// export class SaveComponent {
+///   label = 'Unsaved changes';
+/// }
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// @Component({
+///   selector: 'app-save',
+///   template: '<button i18n i18n-title title="Save the form">Save</button>',
+/// })
+/// export class SaveComponent {
+///   label = $localize`Unsaved changes`;
+/// }
+/// ```
+pub struct I18nUntranslatedTextRule {}
+
+impl I18nUntranslatedTextRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn check_class(class: &Class, file_path: &str) -> Vec<OxcDiagnostic> {
+        let Some(decorator) = class
+            .decorators
+            .iter()
+            .find(|decorator| decorator_name(decorator).as_deref() == Some("Component"))
+        else {
+            return Vec::new();
+        };
+
+        let mut diagnostics = Vec::new();
+
+        if let Some(prop) = decorator_property(decorator, "template") {
+            if let Some(template) = static_string(&prop.value) {
+                // Inline templates start after the opening quote
+                let start = prop.value.span().start + 1;
+                for text in untranslated_texts(&template) {
+                    let offset = (start + text.offset as u32).min(prop.value.span().end);
+                    diagnostics.push(Self::create_diagnostic(&text, Span::new(offset, offset)));
+                }
+            }
+        } else if let Some(prop) = decorator_property(decorator, "templateUrl") {
+            let template = static_string(&prop.value).and_then(|url| {
+                let dir = Path::new(file_path).parent()?;
+                std::fs::read_to_string(dir.join(url)).ok()
+            });
+            for text in template
+                .as_deref()
+                .map(untranslated_texts)
+                .unwrap_or_default()
+            {
+                diagnostics.push(Self::create_diagnostic(&text, prop.value.span()));
+            }
+        }
+
+        let mut visitor = ComponentTextVisitor::default();
+        visitor.visit_class_body(&class.body);
+        for (text, span) in visitor.texts {
+            diagnostics.push(
+                OxcDiagnostic::warn(format!("Untranslated text \"{}\"", quote(&text)))
+                    .with_help("Mark the text for translation with $localize`...`")
+                    .with_label(span.label(COMPONENT_LABEL)),
+            );
+        }
+
+        diagnostics
+    }
+
+    fn create_diagnostic(text: &UntranslatedText, span: Span) -> OxcDiagnostic {
+        let diagnostic =
+            OxcDiagnostic::warn(format!("Untranslated text \"{}\"", quote(&text.text)));
+        match &text.attribute {
+            Some(attribute) => diagnostic
+                .with_help(format!(
+                    "Add an i18n-{} attribute to the element",
+                    attribute
+                ))
+                .with_label(span.label(format!("{}{}", ATTRIBUTE_LABEL, attribute))),
+            None => diagnostic
+                .with_help("Add an i18n attribute to the element containing the text")
+                .with_label(span.label(TEMPLATE_LABEL)),
+        }
+    }
+}
+
+/// Shorten a text for a message
+fn quote(text: &str) -> String {
+    if text.chars().count() <= MAX_QUOTED_CHARS {
+        return text.to_string();
+    }
+    let shortened: String = text.chars().take(MAX_QUOTED_CHARS).collect();
+    format!("{}…", shortened)
+}
+
+impl Rule for I18nUntranslatedTextRule {
+    fn name(&self) -> &'static str {
+        "i18n-untranslated-text"
+    }
+
+    fn description(&self) -> &'static str {
+        "Reports user-facing text in components and templates without i18n markers"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::I18n
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::EXPERIMENTAL, tags::CHEAP]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "@Component({ selector: 'app-save', template: '<button>Save</button>' })\nexport class SaveComponent {}",
+            ],
+            correct: &[
+                "@Component({ selector: 'app-save', template: '<button i18n>Save</button>' })\nexport class SaveComponent {}",
+            ],
+        }
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, file_path: &str) -> Vec<OxcDiagnostic> {
+        match node {
+            AstKind::Class(class) => Self::check_class(class, file_path),
+            _ => Vec::new(),
+        }
+    }
+
+    fn diagnostic_data(&self, diagnostic: &OxcDiagnostic, _source_code: &str) -> DiagnosticData {
+        let mut data = DiagnosticData::default();
+        if let Some(text) = diagnostic
+            .message
+            .strip_prefix("Untranslated text \"")
+            .and_then(|rest| rest.strip_suffix('"'))
+        {
+            data.metadata.insert("text".to_string(), text.into());
+        }
+
+        let label = diagnostic
+            .labels
+            .as_ref()
+            .and_then(|labels| labels.first())
+            .and_then(|label| label.label());
+        let source = match label {
+            Some(TEMPLATE_LABEL) => Some("template".to_string()),
+            Some(COMPONENT_LABEL) => Some("component".to_string()),
+            Some(label) => label
+                .strip_prefix(ATTRIBUTE_LABEL)
+                .map(|attribute| format!("template:{}", attribute)),
+            None => None,
+        };
+        if let Some(source) = source {
+            data.metadata.insert("source".to_string(), source.into());
+        }
+        data
+    }
+}
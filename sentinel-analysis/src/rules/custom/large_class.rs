@@ -0,0 +1,322 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{Class, ClassElement, Expression, MethodDefinitionKind};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_semantic::SemanticBuilderReturn;
+use regex::Regex;
+use serde_json::Value;
+use std::path::Path;
+use std::sync::LazyLock;
+
+use crate::angular_graph::static_string;
+use crate::rules::catalog::tags;
+use crate::rules::class_context::{decorator_name, decorator_property};
+use crate::rules::{DiagnosticData, Rule, RuleCategory, RuleExamples};
+use crate::utilities::source::line_of_offset;
+
+/// Interpolations and property, event and two-way bindings in a template
+static TEMPLATE_BINDING: LazyLock<Regex> = LazyLock::new(|| {
+    Regex::new(r"\{\{|\s(?:\[\(?[\w.@-]+\)?\]|\([\w.@-]+\)|(?:bind|on|bindon)-[\w-]+)\s*=")
+        .expect("Invalid binding pattern")
+});
+
+/// Size of a class, measured once per class
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+struct ClassMeasures {
+    methods: usize,
+    lines: usize,
+    /// Constructor parameters and fields initialized with `inject()`
+    dependencies: usize,
+    /// Bindings in the template, `None` for classes that are not components or whose
+    /// template cannot be read
+    template_bindings: Option<usize>,
+}
+
+impl ClassMeasures {
+    /// Format the measures as the label of a finding, e.g. `24 methods, 410 lines`
+    fn to_label(&self) -> String {
+        let mut parts = vec![
+            format!("{} methods", self.methods),
+            format!("{} lines", self.lines),
+            format!("{} dependencies", self.dependencies),
+        ];
+        if let Some(bindings) = self.template_bindings {
+            parts.push(format!("{} template bindings", bindings));
+        }
+        parts.join(", ")
+    }
+
+    /// Read the measures back from the label of a finding
+    fn from_label(label: &str) -> Self {
+        let mut measures = Self::default();
+        for part in label.split(", ") {
+            let Some((value, measure)) = part.split_once(' ') else {
+                continue;
+            };
+            let Ok(value) = value.parse() else {
+                continue;
+            };
+            match measure {
+                "methods" => measures.methods = value,
+                "lines" => measures.lines = value,
+                "dependencies" => measures.dependencies = value,
+                "template bindings" => measures.template_bindings = Some(value),
+                _ => {}
+            }
+        }
+        measures
+    }
+}
+
+/// Rule that reports classes that grew too large
+///
+/// Components and services that collect more and more methods, dependencies and template
+/// bindings become hard to understand, test and change. The rule measures every class and
+/// reports those exceeding one of the thresholds, with all measured values in the
+/// `metadata` of the finding, so refactoring programs can rank and track them.
+///
+/// Dependencies are the constructor parameters and the fields initialized with
+/// `inject()`. Template bindings are the interpolations and the property, event and
+/// two-way bindings in the inline template or the `templateUrl` of a component.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code with `maxMethods: 3`:
+///
+/// ```typescript
+/// export class CheckoutComponent {
+///   loadCart() {}
+///   applyCoupon() {}
+///   validateAddress() {}
+///   submitPayment() {}
+/// }
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// export class CheckoutComponent {
+///   private readonly cart = inject(CartFacade);
+///   private readonly payment = inject(PaymentFacade);
+///
+///   submit() {}
+/// }
+/// ```
+///
+/// ## Rule Options
+///
+/// - `maxMethods`: Maximum number of methods, accessors and the constructor excluded (default: 20)
+/// - `maxLines`: Maximum number of lines of the class (default: 500)
+/// - `maxDependencies`: Maximum number of injected dependencies (default: 8)
+/// - `maxTemplateBindings`: Maximum number of bindings in the template of a component (default: 60)
+pub struct LargeClassRule {
+    max_methods: usize,
+    max_lines: usize,
+    max_dependencies: usize,
+    max_template_bindings: usize,
+}
+
+impl LargeClassRule {
+    pub fn new() -> Self {
+        Self {
+            max_methods: 20,
+            max_lines: 500,
+            max_dependencies: 8,
+            max_template_bindings: 60,
+        }
+    }
+
+    fn measure(class: &Class, source_code: &str, file_path: &str) -> ClassMeasures {
+        let mut measures = ClassMeasures {
+            lines: line_of_offset(source_code, class.span.end as usize)
+                - line_of_offset(source_code, class.span.start as usize)
+                + 1,
+            template_bindings: Self::template(class, file_path)
+                .map(|template| TEMPLATE_BINDING.find_iter(&template).count()),
+            ..ClassMeasures::default()
+        };
+
+        for element in &class.body.body {
+            match element {
+                ClassElement::MethodDefinition(method) => match method.kind {
+                    MethodDefinitionKind::Method => measures.methods += 1,
+                    MethodDefinitionKind::Constructor => {
+                        measures.dependencies += method.value.params.items.len();
+                    }
+                    _ => {}
+                },
+                ClassElement::PropertyDefinition(property) => {
+                    if property.value.as_ref().is_some_and(is_inject_call) {
+                        measures.dependencies += 1;
+                    }
+                }
+                _ => {}
+            }
+        }
+
+        measures
+    }
+
+    /// Get the inline template of a component, or read its `templateUrl` relative to the file
+    fn template(class: &Class, file_path: &str) -> Option<String> {
+        let decorator = class
+            .decorators
+            .iter()
+            .find(|decorator| decorator_name(decorator).as_deref() == Some("Component"))?;
+        let property_string = |name: &str| {
+            decorator_property(decorator, name).and_then(|prop| static_string(&prop.value))
+        };
+
+        property_string("template").or_else(|| {
+            let template_url = property_string("templateUrl")?;
+            let dir = Path::new(file_path).parent()?;
+            std::fs::read_to_string(dir.join(template_url)).ok()
+        })
+    }
+
+    /// Get the names of the measures above their threshold
+    fn exceeded(&self, measures: &ClassMeasures) -> Vec<&'static str> {
+        let mut exceeded = Vec::new();
+        if measures.methods > self.max_methods {
+            exceeded.push("methods");
+        }
+        if measures.lines > self.max_lines {
+            exceeded.push("lines");
+        }
+        if measures.dependencies > self.max_dependencies {
+            exceeded.push("dependencies");
+        }
+        if measures
+            .template_bindings
+            .is_some_and(|bindings| bindings > self.max_template_bindings)
+        {
+            exceeded.push("templateBindings");
+        }
+        exceeded
+    }
+
+    fn create_diagnostic(
+        class: &Class,
+        measures: &ClassMeasures,
+        exceeded: &[&str],
+    ) -> OxcDiagnostic {
+        let (name, span) = match &class.id {
+            Some(id) => (id.name.as_str(), id.span),
+            None => ("anonymous", class.span),
+        };
+
+        OxcDiagnostic::warn(format!(
+            "Class {} is too large ({})",
+            name,
+            exceeded.join(", ")
+        ))
+        .with_help("Split the class by responsibility: move logic into services or facades and parts of the template into child components")
+        .with_label(span.label(measures.to_label()))
+    }
+}
+
+/// Check if an expression is an `inject(Token)` call
+fn is_inject_call(expr: &Expression) -> bool {
+    let Expression::CallExpression(call) = expr else {
+        return false;
+    };
+    matches!(&call.callee, Expression::Identifier(ident) if ident.name == "inject")
+}
+
+impl Rule for LargeClassRule {
+    fn name(&self) -> &'static str {
+        "large-class"
+    }
+
+    fn description(&self) -> &'static str {
+        "Reports classes with too many methods, lines, dependencies or template bindings"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::BestPractices
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "export class CheckoutComponent {\n  loadCart() {}\n  applyCoupon() {}\n  validateAddress() {}\n  submitPayment() {}\n}",
+            ],
+            correct: &[
+                "export class CheckoutComponent {\n  private readonly cart = inject(CartFacade);\n\n  submit() {}\n}",
+            ],
+        }
+    }
+
+    fn set_config(&mut self, config: Value) {
+        if let Some(obj) = config.as_object() {
+            let limit = |name: &str| {
+                obj.get(name)
+                    .and_then(Value::as_u64)
+                    .map(|max| max as usize)
+            };
+            if let Some(max) = limit("maxMethods") {
+                self.max_methods = max;
+            }
+            if let Some(max) = limit("maxLines") {
+                self.max_lines = max;
+            }
+            if let Some(max) = limit("maxDependencies") {
+                self.max_dependencies = max;
+            }
+            if let Some(max) = limit("maxTemplateBindings") {
+                self.max_template_bindings = max;
+            }
+        }
+    }
+
+    fn run_on_semantic(
+        &self,
+        semantic_result: &SemanticBuilderReturn,
+        file_path: &str,
+    ) -> Vec<OxcDiagnostic> {
+        let semantic = &semantic_result.semantic;
+        semantic
+            .nodes()
+            .iter()
+            .filter_map(|node| match node.kind() {
+                AstKind::Class(class) => {
+                    let measures = Self::measure(class, semantic.source_text(), file_path);
+                    let exceeded = self.exceeded(&measures);
+                    (!exceeded.is_empty())
+                        .then(|| Self::create_diagnostic(class, &measures, &exceeded))
+                }
+                _ => None,
+            })
+            .collect()
+    }
+
+    fn diagnostic_data(&self, diagnostic: &OxcDiagnostic, _source_code: &str) -> DiagnosticData {
+        let mut data = DiagnosticData::default();
+        let Some(label) = diagnostic
+            .labels
+            .as_ref()
+            .and_then(|labels| labels.first())
+            .and_then(|label| label.label())
+        else {
+            return data;
+        };
+
+        let measures = ClassMeasures::from_label(label);
+        data.metadata
+            .insert("methods".to_string(), measures.methods.into());
+        data.metadata
+            .insert("lines".to_string(), measures.lines.into());
+        data.metadata
+            .insert("dependencies".to_string(), measures.dependencies.into());
+        if let Some(bindings) = measures.template_bindings {
+            data.metadata
+                .insert("templateBindings".to_string(), bindings.into());
+        }
+        data.metadata
+            .insert("exceeded".to_string(), self.exceeded(&measures).into());
+        data
+    }
+}
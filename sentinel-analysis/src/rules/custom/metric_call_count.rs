@@ -0,0 +1,180 @@
+use oxc_ast::AstKind;
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_semantic::SemanticBuilderReturn;
+use serde_json::Value;
+
+use crate::counters::COUNTER_METADATA;
+use crate::rules::catalog::tags;
+use crate::rules::taint::{expression_path, path_matches};
+use crate::rules::{DiagnosticData, Rule, RuleCategory, RuleExamples};
+
+/// A counter and the callees it counts
+#[derive(Debug, Clone)]
+struct CallCounter {
+    name: String,
+    callees: Vec<String>,
+}
+
+/// Rule that counts the calls of configured functions
+///
+/// The calls are not violations: the rule only counts them, and the number of calls per
+/// counter ends up in the `counters` of the report and the history, to follow metrics like
+/// the remaining `setTimeout` calls of a codebase across runs. A callee matches the member
+/// chain of a call exactly or as its end, so `setTimeout` also counts `window.setTimeout`.
+///
+/// ## Rule Details
+///
+/// Examples of counted code with the default configuration:
+///
+/// ```typescript
+/// setTimeout(() => this.refresh(), 1000);
+/// console.log('loaded', user);
+/// ```
+///
+/// ## Rule Options
+///
+/// - `counters`: Counter names with the callee, or list of callees, they count (default:
+///   `{ "set-timeout": "setTimeout", "console-log": "console.log" }`)
+pub struct MetricCallCountRule {
+    counters: Vec<CallCounter>,
+}
+
+impl MetricCallCountRule {
+    pub fn new() -> Self {
+        let counter = |name: &str, callee: &str| CallCounter {
+            name: name.to_string(),
+            callees: vec![callee.to_string()],
+        };
+
+        Self {
+            counters: vec![
+                counter("set-timeout", "setTimeout"),
+                counter("console-log", "console.log"),
+            ],
+        }
+    }
+
+    fn parse_counter(name: &str, value: &Value) -> Option<CallCounter> {
+        let callees: Vec<String> = match value {
+            Value::String(callee) => vec![callee.clone()],
+            Value::Array(callees) => callees
+                .iter()
+                .filter_map(Value::as_str)
+                .map(str::to_string)
+                .collect(),
+            _ => Vec::new(),
+        };
+        let callees: Vec<String> = callees
+            .into_iter()
+            .filter(|callee| !callee.is_empty())
+            .collect();
+        (!name.is_empty() && !callees.is_empty()).then(|| CallCounter {
+            name: name.to_string(),
+            callees,
+        })
+    }
+}
+
+impl Rule for MetricCallCountRule {
+    fn name(&self) -> &'static str {
+        "metric-call-count"
+    }
+
+    fn description(&self) -> &'static str {
+        "Counts the calls of configured functions, such as setTimeout or console.log"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Metrics
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn counts_only(&self) -> bool {
+        true
+    }
+
+    fn counter_names(&self) -> Vec<String> {
+        self.counters
+            .iter()
+            .map(|counter| counter.name.clone())
+            .collect()
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "setTimeout(() => this.refresh(), 1000);",
+                "console.log('loaded', user);",
+            ],
+            correct: &["console.error('Failed to load', error);"],
+        }
+    }
+
+    fn set_config(&mut self, config: Value) {
+        let Some(counters) = config.get("counters").and_then(Value::as_object) else {
+            return;
+        };
+        let mut parsed = Vec::new();
+        for (name, value) in counters {
+            match Self::parse_counter(name, value) {
+                Some(counter) => parsed.push(counter),
+                None => eprintln!(
+                    "Warning: metric-call-count counter '{}' needs a callee or a list of callees",
+                    name
+                ),
+            }
+        }
+        if parsed.is_empty() {
+            eprintln!(
+                "Warning: metric-call-count needs at least one counter, keeping the defaults"
+            );
+        } else {
+            self.counters = parsed;
+        }
+    }
+
+    fn run_on_semantic(
+        &self,
+        semantic_result: &SemanticBuilderReturn,
+        _file_path: &str,
+    ) -> Vec<OxcDiagnostic> {
+        semantic_result
+            .semantic
+            .nodes()
+            .iter()
+            .filter_map(|node| {
+                let AstKind::CallExpression(call) = node.kind() else {
+                    return None;
+                };
+                let path = expression_path(&call.callee)?;
+                let counter = self.counters.iter().find(|counter| {
+                    counter
+                        .callees
+                        .iter()
+                        .any(|callee| path_matches(&path, callee))
+                })?;
+                Some(
+                    OxcDiagnostic::warn(format!("Call of {}", path))
+                        .with_label(call.span.label(counter.name.clone())),
+                )
+            })
+            .collect()
+    }
+
+    fn diagnostic_data(&self, diagnostic: &OxcDiagnostic, _source_code: &str) -> DiagnosticData {
+        let mut data = DiagnosticData::default();
+        if let Some(counter) = diagnostic
+            .labels
+            .as_ref()
+            .and_then(|labels| labels.first())
+            .and_then(|label| label.label())
+        {
+            data.metadata
+                .insert(COUNTER_METADATA.to_string(), counter.into());
+        }
+        data
+    }
+}
@@ -1,22 +1,72 @@
 use oxc_ast::ast::PropertyKey;
 
 // Module declarations for custom rules
+pub mod angular_bootstrap_module;
+pub mod angular_common_module_import;
 pub mod angular_component_class_suffix;
+pub mod angular_component_styles;
+pub mod angular_deprecated_api;
 pub mod angular_directive_class_suffix;
 pub mod angular_input_count;
 pub mod angular_legacy_decorators;
 pub mod angular_obsolete_standalone_true;
+pub mod angular_on_push_change_detection;
 pub mod angular_output_event_collision;
+pub mod angular_prefer_inject;
+pub mod angular_service_fan_in;
+pub mod angular_standalone_candidate;
+pub mod architecture_boundaries;
+pub mod i18n_untranslated_text;
+pub mod large_class;
+pub mod metric_call_count;
+pub mod policy_banned_imports;
+pub mod policy_license_header;
+pub mod rxjs_subscription_leak;
+pub mod secrets_detection;
+pub mod security_bypass_security_trust;
+pub mod security_eval;
+pub mod security_http_url_concatenation;
+pub mod security_inner_html;
+pub mod security_taint_flow;
+pub mod test_focused_tests;
+pub mod test_unawaited_when_stable;
+pub mod todo_comments;
+pub mod typescript_no_any;
 pub mod typescript_non_null_assertion_operator;
 pub mod typescript_type_assertion;
 
 // Re-export custom rules
+pub use angular_bootstrap_module::AngularBootstrapModuleRule;
+pub use angular_common_module_import::AngularCommonModuleImportRule;
 pub use angular_component_class_suffix::AngularComponentClassSuffixRule;
+pub use angular_component_styles::AngularComponentStylesRule;
+pub use angular_deprecated_api::AngularDeprecatedApiRule;
 pub use angular_directive_class_suffix::AngularDirectiveClassSuffixRule;
 pub use angular_input_count::AngularInputCountRule;
 pub use angular_legacy_decorators::AngularLegacyDecoratorsRule;
 pub use angular_obsolete_standalone_true::AngularObsoleteStandaloneTrueRule;
+pub use angular_on_push_change_detection::AngularOnPushChangeDetectionRule;
 pub use angular_output_event_collision::AngularOutputEventCollisionRule;
+pub use angular_prefer_inject::AngularPreferInjectRule;
+pub use angular_service_fan_in::AngularServiceFanInRule;
+pub use angular_standalone_candidate::AngularStandaloneCandidateRule;
+pub use architecture_boundaries::ArchitectureBoundariesRule;
+pub use i18n_untranslated_text::I18nUntranslatedTextRule;
+pub use large_class::LargeClassRule;
+pub use metric_call_count::MetricCallCountRule;
+pub use policy_banned_imports::PolicyBannedImportsRule;
+pub use policy_license_header::PolicyLicenseHeaderRule;
+pub use rxjs_subscription_leak::RxjsSubscriptionLeakRule;
+pub use secrets_detection::SecretsDetectionRule;
+pub use security_bypass_security_trust::SecurityBypassSecurityTrustRule;
+pub use security_eval::SecurityEvalRule;
+pub use security_http_url_concatenation::SecurityHttpUrlConcatenationRule;
+pub use security_inner_html::SecurityInnerHtmlRule;
+pub use security_taint_flow::SecurityTaintFlowRule;
+pub use test_focused_tests::TestFocusedTestsRule;
+pub use test_unawaited_when_stable::TestUnawaitedWhenStableRule;
+pub use todo_comments::TodoCommentsRule;
+pub use typescript_no_any::TypeScriptNoAnyRule;
 pub use typescript_non_null_assertion_operator::TypeScriptNonNullAssertionRule;
 pub use typescript_type_assertion::TypeScriptAssertionRule;
 
@@ -0,0 +1,155 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::StringLiteral;
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+use serde_json::Value;
+
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
+use crate::utilities::glob::{glob_match, glob_match_any};
+
+/// A denied import source
+#[derive(Debug, Clone)]
+struct DeniedImport {
+    /// Glob pattern of the module specifier
+    source: String,
+    /// Explanation shown in the diagnostic
+    message: Option<String>,
+}
+
+/// Rule that bans imports from configured module sources
+///
+/// Use it to prevent root imports of large libraries (`lodash`), deep imports into the
+/// internals of other packages or libraries (`@myorg/*/src/**`), or deprecated packages.
+/// Sources are glob patterns (`*` within a path segment, `**` across segments). The
+/// `allow` list takes precedence over the `deny` list.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code with the default configuration:
+///
+/// ```typescript
+/// import { get } from 'lodash';
+/// import { Subscriber } from 'rxjs/internal/Subscriber';
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// import get from 'lodash/get';
+/// import { Subscriber } from 'rxjs';
+/// ```
+///
+/// ## Rule Options
+///
+/// - `deny`: Denied sources, either as strings or `{ "source": "...", "message": "..." }`
+/// - `allow`: Sources that are always allowed, even if they match a denied source
+pub struct PolicyBannedImportsRule {
+    deny: Vec<DeniedImport>,
+    allow: Vec<String>,
+}
+
+impl PolicyBannedImportsRule {
+    pub fn new() -> Self {
+        let deny = |source: &str, message: &str| DeniedImport {
+            source: source.to_string(),
+            message: Some(message.to_string()),
+        };
+
+        Self {
+            deny: vec![
+                deny("lodash", "Import single functions from 'lodash/<fn>' or use 'lodash-es' to keep bundles small"),
+                deny("rxjs/internal/**", "Import from the public 'rxjs' entry points instead"),
+                deny("@angular/*/src/**", "Deep imports into Angular packages are not part of the public API"),
+            ],
+            allow: Vec::new(),
+        }
+    }
+
+    fn check_source(&self, source: &StringLiteral) -> Option<OxcDiagnostic> {
+        let specifier = source.value.as_str();
+        if glob_match_any(&self.allow, specifier) {
+            return None;
+        }
+
+        let denied = self
+            .deny
+            .iter()
+            .find(|denied| glob_match(&denied.source, specifier))?;
+
+        Some(Self::create_diagnostic(specifier, denied, source.span))
+    }
+
+    fn create_diagnostic(specifier: &str, denied: &DeniedImport, span: Span) -> OxcDiagnostic {
+        let diagnostic = OxcDiagnostic::error(format!("Import from '{}' is not allowed", specifier))
+            .with_label(span.label(format!("Matches banned source '{}'", denied.source)));
+
+        match &denied.message {
+            Some(message) => diagnostic.with_help(message.clone()),
+            None => diagnostic,
+        }
+    }
+}
+
+impl Rule for PolicyBannedImportsRule {
+    fn name(&self) -> &'static str {
+        "policy-banned-imports"
+    }
+
+    fn description(&self) -> &'static str {
+        "Bans imports from configured module sources, such as lodash root imports or deep imports"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Policy
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn set_config(&mut self, config: Value) {
+        if let Some(obj) = config.as_object() {
+            if let Some(deny) = obj.get("deny").and_then(Value::as_array) {
+                self.deny = deny
+                    .iter()
+                    .filter_map(|entry| match entry {
+                        Value::String(source) => Some(DeniedImport {
+                            source: source.clone(),
+                            message: None,
+                        }),
+                        Value::Object(entry) => Some(DeniedImport {
+                            source: entry.get("source")?.as_str()?.to_string(),
+                            message: entry
+                                .get("message")
+                                .and_then(Value::as_str)
+                                .map(str::to_string),
+                        }),
+                        _ => None,
+                    })
+                    .collect();
+            }
+            if let Some(allow) = obj.get("allow").and_then(Value::as_array) {
+                self.allow = allow
+                    .iter()
+                    .filter_map(Value::as_str)
+                    .map(str::to_string)
+                    .collect();
+            }
+        }
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        let source = match node {
+            AstKind::ImportDeclaration(import) => Some(&import.source),
+            AstKind::ExportAllDeclaration(export) => Some(&export.source),
+            AstKind::ExportNamedDeclaration(export) => export.source.as_ref(),
+            _ => None,
+        };
+
+        source
+            .and_then(|source| self.check_source(source))
+            .into_iter()
+            .collect()
+    }
+}
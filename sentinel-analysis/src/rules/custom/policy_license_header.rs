@@ -0,0 +1,144 @@
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+use regex::Regex;
+use serde_json::Value;
+
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
+use crate::utilities::glob::glob_match_any;
+
+/// Rule that requires a license or copyright header at the top of every file
+///
+/// The leading comment block of the file (after an optional shebang) must match the
+/// configured pattern.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// import { Component } from '@angular/core';
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// // Copyright (c) 2024 ACME Corp. All rights reserved.
+/// // SPDX-License-Identifier: MIT
+/// import { Component } from '@angular/core';
+/// ```
+///
+/// ## Rule Options
+///
+/// - `pattern`: Regular expression the header comment must match (default: `(?i)copyright|SPDX-License-Identifier`)
+/// - `exclude`: Glob patterns of files that do not need a header, e.g. `["**/*.spec.ts"]`
+pub struct PolicyLicenseHeaderRule {
+    pattern: Regex,
+    exclude: Vec<String>,
+}
+
+impl PolicyLicenseHeaderRule {
+    pub fn new() -> Self {
+        Self {
+            pattern: Regex::new(r"(?i)copyright|SPDX-License-Identifier")
+                .expect("Invalid default header pattern"),
+            exclude: Vec::new(),
+        }
+    }
+
+    fn create_diagnostic(&self) -> OxcDiagnostic {
+        OxcDiagnostic::error("Missing or invalid license header")
+            .with_help(format!(
+                "Add a header comment at the top of the file matching '{}'",
+                self.pattern.as_str()
+            ))
+            .with_label(Span::new(0, 0).label("File header"))
+    }
+}
+
+/// Get the comment block at the top of a file, skipping a shebang line
+pub fn leading_comment_block(source_code: &str) -> String {
+    let source = source_code.trim_start_matches('\u{feff}');
+    let source = match source.strip_prefix("#!") {
+        Some(rest) => rest.split_once('\n').map_or("", |(_, rest)| rest),
+        None => source,
+    };
+
+    let mut block = String::new();
+    let mut in_block_comment = false;
+
+    for line in source.lines() {
+        let trimmed = line.trim();
+        if in_block_comment {
+            block.push_str(trimmed);
+            block.push('\n');
+            if trimmed.contains("*/") {
+                in_block_comment = false;
+            }
+        } else if trimmed.starts_with("//") {
+            block.push_str(trimmed);
+            block.push('\n');
+        } else if trimmed.starts_with("/*") {
+            block.push_str(trimmed);
+            block.push('\n');
+            in_block_comment = !trimmed.contains("*/");
+        } else if trimmed.is_empty() && block.is_empty() {
+            continue;
+        } else {
+            break;
+        }
+    }
+
+    block
+}
+
+impl Rule for PolicyLicenseHeaderRule {
+    fn name(&self) -> &'static str {
+        "policy-license-header"
+    }
+
+    fn description(&self) -> &'static str {
+        "Requires a license or copyright header comment at the top of every file"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Policy
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn set_config(&mut self, config: Value) {
+        if let Some(obj) = config.as_object() {
+            if let Some(pattern) = obj.get("pattern").and_then(Value::as_str) {
+                match Regex::new(pattern) {
+                    Ok(regex) => self.pattern = regex,
+                    Err(err) => eprintln!(
+                        "Warning: invalid header pattern for policy-license-header: {}",
+                        err
+                    ),
+                }
+            }
+            if let Some(exclude) = obj.get("exclude").and_then(Value::as_array) {
+                self.exclude = exclude
+                    .iter()
+                    .filter_map(Value::as_str)
+                    .map(str::to_string)
+                    .collect();
+            }
+        }
+    }
+
+    fn run_on_source(&self, source_code: &str, file_path: &str) -> Vec<OxcDiagnostic> {
+        if glob_match_any(&self.exclude, file_path) {
+            return Vec::new();
+        }
+
+        if self.pattern.is_match(&leading_comment_block(source_code)) {
+            Vec::new()
+        } else {
+            vec![self.create_diagnostic()]
+        }
+    }
+}
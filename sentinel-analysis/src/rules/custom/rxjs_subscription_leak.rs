@@ -0,0 +1,200 @@
+use oxc_diagnostics::OxcDiagnostic;
+use serde_json::Value;
+
+use crate::rules::catalog::tags;
+use crate::rules::class_context::SubscribeCall;
+use crate::rules::{ClassContext, Rule, RuleCategory};
+
+/// Rule that detects RxJS subscriptions in Angular components and directives that are never torn down
+///
+/// Subscriptions that outlive the component keep the component and everything it references
+/// in memory, and keep running their side effects after the view is gone. The rule looks at
+/// the whole class: `subscribe()` calls, subscriptions stored in fields and the teardown
+/// logic in `ngOnDestroy`.
+///
+/// A subscription is considered torn down if
+/// - the source is piped through `takeUntilDestroyed()`, `take()`, `first()` or `takeWhile()`;
+///   outside of the constructor and field initializers `takeUntilDestroyed()` needs the
+///   injected `DestroyRef` as its argument, without it the subscription throws
+/// - the source is piped through `takeUntil(this.destroy$)` and `ngOnDestroy` calls
+///   `this.destroy$.next()` or `this.destroy$.complete()`
+/// - the subscription is stored in a field (or added to a composite subscription field)
+///   on which `ngOnDestroy` calls `unsubscribe()`
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// @Component({...})
+/// export class UserComponent implements OnInit {
+///   ngOnInit() {
+///     this.store.user$.subscribe(user => this.user = user);
+///   }
+/// }
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// @Component({...})
+/// export class UserComponent implements OnInit {
+///   private destroyRef = inject(DestroyRef);
+///
+///   ngOnInit() {
+///     this.store.user$
+///       .pipe(takeUntilDestroyed(this.destroyRef))
+///       .subscribe(user => this.user = user);
+///   }
+/// }
+/// ```
+///
+/// ## Rule Options
+///
+/// - `decorators`: Class decorators whose classes are checked (default: `["Component", "Directive"]`)
+pub struct RxjsSubscriptionLeakRule {
+    /// Decorators of the classes that are checked
+    decorators: Vec<String>,
+}
+
+impl RxjsSubscriptionLeakRule {
+    pub fn new() -> Self {
+        Self {
+            decorators: vec!["Component".to_string(), "Directive".to_string()],
+        }
+    }
+
+    fn is_checked_class(&self, class_context: &ClassContext) -> bool {
+        class_context
+            .decorators
+            .iter()
+            .any(|decorator| self.decorators.contains(decorator))
+    }
+
+    fn is_torn_down(&self, class_context: &ClassContext, subscription: &SubscribeCall) -> bool {
+        match subscription.teardown_operator.as_deref() {
+            // takeUntil only helps if the notifier actually emits on destroy
+            Some("takeUntil") => {
+                return match &subscription.take_until_notifier {
+                    Some(notifier) => class_context.completed_fields.contains(notifier),
+                    None => true,
+                };
+            }
+            Some("takeUntilDestroyed") => {
+                return !subscription.destroyed_without_ref
+                    || Self::in_injection_context(subscription);
+            }
+            Some(_) => return true,
+            None => {}
+        }
+
+        subscription
+            .stored_in
+            .iter()
+            .chain(subscription.added_to.iter())
+            .any(|field| class_context.unsubscribed_fields.contains(field))
+    }
+
+    /// Whether a subscription is made in the constructor or a field initializer
+    fn in_injection_context(subscription: &SubscribeCall) -> bool {
+        subscription
+            .method
+            .as_deref()
+            .is_none_or(|method| method == "constructor")
+    }
+
+    fn create_diagnostic(
+        &self,
+        class_context: &ClassContext,
+        subscription: &SubscribeCall,
+    ) -> OxcDiagnostic {
+        let class_name = class_context.name.as_deref().unwrap_or("anonymous class");
+        let field = subscription
+            .stored_in
+            .as_ref()
+            .or(subscription.added_to.as_ref());
+
+        let (message, help) = match (subscription.teardown_operator.as_deref(), field) {
+            (Some("takeUntilDestroyed"), _) => (
+                format!(
+                    "takeUntilDestroyed() needs a DestroyRef outside of the constructor in {}",
+                    class_name
+                ),
+                if class_context.injects_destroy_ref {
+                    "Pass the injected DestroyRef: takeUntilDestroyed(this.destroyRef)".to_string()
+                } else {
+                    "Inject DestroyRef in a field and pass it: takeUntilDestroyed(this.destroyRef)".to_string()
+                },
+            ),
+            (Some("takeUntil"), _) => (
+                format!("takeUntil notifier is never triggered in {}", class_name),
+                "Call next() and complete() on the takeUntil notifier in ngOnDestroy, or use takeUntilDestroyed() instead".to_string(),
+            ),
+            (_, Some(field)) => (
+                format!(
+                    "Subscription stored in '{}' is never unsubscribed in {}",
+                    field, class_name
+                ),
+                format!(
+                    "Call this.{}.unsubscribe() in ngOnDestroy, or pipe the source through takeUntilDestroyed()",
+                    field
+                ),
+            ),
+            _ => (
+                format!("Possible subscription leak in {}", class_name),
+                "Pipe the source through takeUntilDestroyed(), or store the subscription and unsubscribe in ngOnDestroy".to_string(),
+            ),
+        };
+
+        OxcDiagnostic::warn(message)
+            .with_help(help)
+            .with_label(subscription.span.label("Subscription is never torn down"))
+    }
+}
+
+impl Rule for RxjsSubscriptionLeakRule {
+    fn name(&self) -> &'static str {
+        "rxjs-subscription-leak"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects RxJS subscriptions in components and directives that are never torn down"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Rxjs
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::EXPERIMENTAL, tags::EXPENSIVE]
+    }
+
+    fn set_config(&mut self, config: Value) {
+        if let Some(obj) = config.as_object() {
+            if let Some(decorators) = obj.get("decorators").and_then(Value::as_array) {
+                self.decorators = decorators
+                    .iter()
+                    .filter_map(|v| v.as_str())
+                    .map(str::to_string)
+                    .collect();
+            }
+        }
+    }
+
+    fn uses_class_context(&self) -> bool {
+        true
+    }
+
+    fn run_on_class(&self, class_context: &ClassContext, _file_path: &str) -> Vec<OxcDiagnostic> {
+        if !self.is_checked_class(class_context) {
+            return Vec::new();
+        }
+
+        class_context
+            .subscriptions
+            .iter()
+            .filter(|subscription| !self.is_torn_down(class_context, subscription))
+            .map(|subscription| self.create_diagnostic(class_context, subscription))
+            .collect()
+    }
+}
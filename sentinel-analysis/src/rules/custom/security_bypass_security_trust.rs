@@ -0,0 +1,84 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::Expression;
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory, Taxonomy};
+
+/// Rule that flags calls to Angular's `DomSanitizer.bypassSecurityTrust*` methods
+///
+/// Bypassing the sanitizer marks a value as trusted, so any user-controlled data that
+/// reaches these calls can lead to cross-site scripting (CWE-79).
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// this.html = this.sanitizer.bypassSecurityTrustHtml(comment.body);
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// this.html = this.sanitizer.sanitize(SecurityContext.HTML, comment.body);
+/// ```
+pub struct SecurityBypassSecurityTrustRule {}
+
+impl SecurityBypassSecurityTrustRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn create_diagnostic(method: &str, span: Span) -> OxcDiagnostic {
+        OxcDiagnostic::error(format!("Call to DomSanitizer.{} bypasses sanitization", method))
+            .with_help("Make sure the value can never contain user-controlled data, or sanitize it with DomSanitizer.sanitize() instead")
+            .with_label(span.label("Sanitizer bypass"))
+            .with_error_code("CWE", "79")
+    }
+}
+
+impl Rule for SecurityBypassSecurityTrustRule {
+    fn name(&self) -> &'static str {
+        "security-bypass-security-trust"
+    }
+
+    fn description(&self) -> &'static str {
+        "Flags calls to DomSanitizer.bypassSecurityTrust* methods"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Security
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn taxonomies(&self) -> &'static [(Taxonomy, &'static str)] {
+        &[
+            (Taxonomy::Cwe, "CWE-79"),
+            (Taxonomy::OwaspAsvs, "V5.2.1"),
+            (Taxonomy::OwaspTop10, "A03:2021"),
+        ]
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        let AstKind::CallExpression(call) = node else {
+            return Vec::new();
+        };
+
+        match &call.callee {
+            Expression::StaticMemberExpression(member)
+                if member.property.name.as_str().starts_with("bypassSecurityTrust") =>
+            {
+                vec![Self::create_diagnostic(
+                    member.property.name.as_str(),
+                    call.span,
+                )]
+            }
+            _ => Vec::new(),
+        }
+    }
+}
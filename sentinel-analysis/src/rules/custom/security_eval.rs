@@ -0,0 +1,146 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{Argument, Expression};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_semantic::{Semantic, SemanticBuilderReturn};
+use oxc_span::Span;
+
+use crate::rules::catalog::tags;
+use crate::rules::references::is_global_reference;
+use crate::rules::{Rule, RuleCategory, RuleExamples, Taxonomy};
+
+/// Rule that flags dynamic code evaluation
+///
+/// `eval()`, `new Function()` and string arguments to `setTimeout`/`setInterval`
+/// execute arbitrary strings as code (CWE-95). Calls of local functions that shadow
+/// these globals are not reported.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// eval(expression);
+/// const fn = new Function('a', 'b', body);
+/// setTimeout('refresh()', 1000);
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// setTimeout(() => this.refresh(), 1000);
+///
+/// function evaluate(eval: (code: string) => number) {
+///     return eval('1 + 1');
+/// }
+/// ```
+pub struct SecurityEvalRule {}
+
+impl SecurityEvalRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn create_diagnostic(what: &str, span: Span) -> OxcDiagnostic {
+        OxcDiagnostic::error(format!("Dynamic code evaluation through {}", what))
+            .with_help("Avoid evaluating strings as code; use functions or a safe parser instead")
+            .with_label(span.label("Code evaluation"))
+            .with_error_code("CWE", "95")
+    }
+
+    fn check_node(&self, node: &AstKind, semantic: &Semantic) -> Vec<OxcDiagnostic> {
+        match node {
+            AstKind::CallExpression(call) => {
+                let Expression::Identifier(callee) = &call.callee else {
+                    return Vec::new();
+                };
+                if !is_global_reference(semantic, callee) {
+                    return Vec::new();
+                }
+
+                match callee.name.as_str() {
+                    "eval" => vec![Self::create_diagnostic("eval()", call.span)],
+                    name @ ("setTimeout" | "setInterval") => {
+                        let has_string_handler = matches!(
+                            call.arguments.first(),
+                            Some(Argument::StringLiteral(_) | Argument::TemplateLiteral(_))
+                        );
+                        if has_string_handler {
+                            vec![Self::create_diagnostic(
+                                &format!("{}() with a string argument", name),
+                                call.span,
+                            )]
+                        } else {
+                            Vec::new()
+                        }
+                    }
+                    _ => Vec::new(),
+                }
+            }
+            AstKind::NewExpression(new_expr) => match &new_expr.callee {
+                Expression::Identifier(callee)
+                    if callee.name.as_str() == "Function"
+                        && is_global_reference(semantic, callee) =>
+                {
+                    vec![Self::create_diagnostic("new Function()", new_expr.span)]
+                }
+                _ => Vec::new(),
+            },
+            _ => Vec::new(),
+        }
+    }
+}
+
+impl Rule for SecurityEvalRule {
+    fn name(&self) -> &'static str {
+        "security-eval"
+    }
+
+    fn description(&self) -> &'static str {
+        "Flags eval, new Function and string-based setTimeout/setInterval calls"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Security
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn taxonomies(&self) -> &'static [(Taxonomy, &'static str)] {
+        &[
+            (Taxonomy::Cwe, "CWE-95"),
+            (Taxonomy::OwaspAsvs, "V5.2.4"),
+            (Taxonomy::OwaspTop10, "A03:2021"),
+        ]
+    }
+
+    // 2: locals shadowing `eval` and the timer functions are no longer reported
+    fn version(&self) -> &'static str {
+        "2"
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "eval(expression);",
+                "const fn = new Function('a', 'b', body);",
+                "setTimeout('refresh()', 1000);",
+            ],
+            correct: &["setTimeout(() => this.refresh(), 1000);"],
+        }
+    }
+
+    fn run_on_semantic(
+        &self,
+        semantic_result: &SemanticBuilderReturn,
+        _file_path: &str,
+    ) -> Vec<OxcDiagnostic> {
+        let semantic = &semantic_result.semantic;
+        semantic
+            .nodes()
+            .iter()
+            .flat_map(|node| self.check_node(&node.kind(), semantic))
+            .collect()
+    }
+}
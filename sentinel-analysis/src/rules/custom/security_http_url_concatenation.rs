@@ -0,0 +1,135 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{Argument, BinaryOperator, Expression};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::{GetSpan, Span};
+
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory, Taxonomy};
+
+/// HttpClient methods that take the URL as first argument
+const HTTP_METHODS: &[&str] = &["get", "post", "put", "patch", "delete", "head", "options", "jsonp"];
+
+/// Rule that flags HttpClient requests with string-built URLs
+///
+/// URLs built by concatenation or template literals allow values to change the path or
+/// query of a request if they are not encoded (CWE-74).
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// this.http.get(`/api/users/${id}/orders?filter=${filter}`);
+/// this.http.get('/api/users/' + id);
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// this.http.get(`/api/users/${encodeURIComponent(id)}/orders`, {
+///   params: { filter },
+/// });
+/// ```
+pub struct SecurityHttpUrlConcatenationRule {}
+
+impl SecurityHttpUrlConcatenationRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn create_diagnostic(span: Span) -> OxcDiagnostic {
+        OxcDiagnostic::error("HttpClient request with a string-built URL")
+            .with_help("Encode dynamic path segments with encodeURIComponent() and pass query parameters through the `params` option")
+            .with_label(span.label("String-built URL"))
+            .with_error_code("CWE", "74")
+    }
+
+    /// Check if the object of the call looks like an HttpClient, e.g. `this.http` or `httpClient`
+    fn is_http_client(object: &Expression) -> bool {
+        let name = match object {
+            Expression::Identifier(ident) => ident.name.as_str(),
+            Expression::StaticMemberExpression(member) => member.property.name.as_str(),
+            _ => return false,
+        };
+        name.to_lowercase().contains("http")
+    }
+
+    /// Check if a URL argument contains interpolated values that are not encoded
+    fn is_string_built(argument: &Argument) -> bool {
+        match argument {
+            Argument::TemplateLiteral(template) => template
+                .expressions
+                .iter()
+                .any(|expr| !Self::is_encoded(expr)),
+            Argument::BinaryExpression(binary) => {
+                binary.operator == BinaryOperator::Addition
+                    && !(Self::is_literal(&binary.left) && Self::is_literal(&binary.right))
+            }
+            _ => false,
+        }
+    }
+
+    fn is_encoded(expr: &Expression) -> bool {
+        match expr {
+            Expression::CallExpression(call) => matches!(
+                &call.callee,
+                Expression::Identifier(ident) if ident.name.as_str() == "encodeURIComponent"
+            ),
+            Expression::NumericLiteral(_) => true,
+            _ => false,
+        }
+    }
+
+    fn is_literal(expr: &Expression) -> bool {
+        matches!(
+            expr,
+            Expression::StringLiteral(_) | Expression::NumericLiteral(_)
+        )
+    }
+}
+
+impl Rule for SecurityHttpUrlConcatenationRule {
+    fn name(&self) -> &'static str {
+        "security-http-url-concatenation"
+    }
+
+    fn description(&self) -> &'static str {
+        "Flags HttpClient requests whose URL is built from unencoded string interpolation"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Security
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::EXPERIMENTAL, tags::CHEAP]
+    }
+
+    fn taxonomies(&self) -> &'static [(Taxonomy, &'static str)] {
+        &[
+            (Taxonomy::Cwe, "CWE-74"),
+            (Taxonomy::OwaspAsvs, "V5.3.1"),
+            (Taxonomy::OwaspTop10, "A03:2021"),
+        ]
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        let AstKind::CallExpression(call) = node else {
+            return Vec::new();
+        };
+        let Expression::StaticMemberExpression(member) = &call.callee else {
+            return Vec::new();
+        };
+
+        if !HTTP_METHODS.contains(&member.property.name.as_str())
+            || !Self::is_http_client(&member.object)
+        {
+            return Vec::new();
+        }
+
+        match call.arguments.first() {
+            Some(url) if Self::is_string_built(url) => vec![Self::create_diagnostic(url.span())],
+            _ => Vec::new(),
+        }
+    }
+}
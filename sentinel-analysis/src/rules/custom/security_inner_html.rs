@@ -0,0 +1,110 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{AssignmentTarget, Expression};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory, Taxonomy};
+
+/// Properties that parse the assigned string as HTML
+const HTML_SINK_PROPERTIES: &[&str] = &["innerHTML", "outerHTML"];
+
+/// Methods that parse their string argument as HTML
+const HTML_SINK_METHODS: &[&str] = &["insertAdjacentHTML", "write", "writeln"];
+
+/// Rule that flags direct HTML injection through the DOM
+///
+/// Assigning to `innerHTML`/`outerHTML` or calling `insertAdjacentHTML()` and
+/// `document.write()` bypasses Angular's template sanitization and can lead to
+/// cross-site scripting (CWE-79).
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// this.el.nativeElement.innerHTML = comment.body;
+/// document.write(banner);
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// this.el.nativeElement.textContent = comment.body;
+/// ```
+pub struct SecurityInnerHtmlRule {}
+
+impl SecurityInnerHtmlRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn create_diagnostic(sink: &str, span: Span) -> OxcDiagnostic {
+        OxcDiagnostic::error(format!("Unsanitized HTML written through {}", sink))
+            .with_help("Use textContent, Angular template bindings or Renderer2 instead of writing raw HTML to the DOM")
+            .with_label(span.label("HTML sink"))
+            .with_error_code("CWE", "79")
+    }
+}
+
+impl Rule for SecurityInnerHtmlRule {
+    fn name(&self) -> &'static str {
+        "security-inner-html"
+    }
+
+    fn description(&self) -> &'static str {
+        "Flags innerHTML/outerHTML assignments, insertAdjacentHTML and document.write calls"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Security
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn taxonomies(&self) -> &'static [(Taxonomy, &'static str)] {
+        &[
+            (Taxonomy::Cwe, "CWE-79"),
+            (Taxonomy::OwaspAsvs, "V5.3.3"),
+            (Taxonomy::OwaspTop10, "A03:2021"),
+        ]
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        match node {
+            AstKind::AssignmentExpression(assignment) => match &assignment.left {
+                AssignmentTarget::StaticMemberExpression(member)
+                    if HTML_SINK_PROPERTIES.contains(&member.property.name.as_str()) =>
+                {
+                    vec![Self::create_diagnostic(
+                        member.property.name.as_str(),
+                        assignment.span,
+                    )]
+                }
+                _ => Vec::new(),
+            },
+            AstKind::CallExpression(call) => match &call.callee {
+                Expression::StaticMemberExpression(member)
+                    if HTML_SINK_METHODS.contains(&member.property.name.as_str()) =>
+                {
+                    // write() and writeln() are only sinks on the document
+                    let is_document = match &member.object {
+                        Expression::Identifier(ident) => ident.name.as_str() == "document",
+                        _ => false,
+                    };
+                    if member.property.name.as_str() != "insertAdjacentHTML" && !is_document {
+                        return Vec::new();
+                    }
+                    vec![Self::create_diagnostic(
+                        member.property.name.as_str(),
+                        call.span,
+                    )]
+                }
+                _ => Vec::new(),
+            },
+            _ => Vec::new(),
+        }
+    }
+}
@@ -0,0 +1,206 @@
+use oxc_ast::AstKind;
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_semantic::SemanticBuilderReturn;
+use serde_json::Value;
+
+use crate::rules::catalog::tags;
+use crate::rules::taint::{SinkKind, TaintFlow, TaintSink, TaintSpec, analyze_program};
+use crate::rules::{Rule, RuleCategory, Taxonomy};
+
+/// Expressions that return user-controlled data
+const DEFAULT_SOURCES: &[&str] = &[
+    "location.href",
+    "location.search",
+    "location.hash",
+    "location.pathname",
+    "document.URL",
+    "document.referrer",
+    "document.cookie",
+    "window.name",
+    "localStorage.getItem",
+    "sessionStorage.getItem",
+    "snapshot.params",
+    "snapshot.queryParams",
+    "snapshot.fragment",
+    "paramMap.get",
+    "queryParamMap.get",
+    "target.value",
+    "event.data",
+];
+
+/// Functions whose return value is safe to use in the default sinks
+const DEFAULT_SANITIZERS: &[&str] = &[
+    "encodeURIComponent",
+    "encodeURI",
+    "sanitize",
+    "DOMPurify.sanitize",
+    "escapeHtml",
+    "parseInt",
+    "parseFloat",
+    "Number",
+];
+
+fn default_sinks() -> Vec<TaintSink> {
+    let property = |pattern: &str, cwe| TaintSink {
+        pattern: pattern.to_string(),
+        kind: SinkKind::Property,
+        cwe,
+    };
+    let argument = |pattern: &str, index, cwe| TaintSink {
+        pattern: pattern.to_string(),
+        kind: SinkKind::Argument(index),
+        cwe,
+    };
+    let last_argument = |pattern: &str, cwe| TaintSink {
+        pattern: pattern.to_string(),
+        kind: SinkKind::LastArgument,
+        cwe,
+    };
+
+    vec![
+        property("innerHTML", "79"),
+        property("outerHTML", "79"),
+        property("location.href", "601"),
+        argument("insertAdjacentHTML", 1, "79"),
+        argument("document.write", 0, "79"),
+        argument("bypassSecurityTrustHtml", 0, "79"),
+        argument("bypassSecurityTrustScript", 0, "79"),
+        argument("bypassSecurityTrustUrl", 0, "79"),
+        argument("bypassSecurityTrustResourceUrl", 0, "79"),
+        argument("eval", 0, "95"),
+        last_argument("Function", "95"),
+        argument("window.open", 0, "601"),
+    ]
+}
+
+/// Rule that reports user-controlled data flowing into dangerous sinks
+///
+/// Unlike the syntactic security rules, this rule only reports a sink if a value from
+/// a known source (URL, cookies, storage, route parameters, DOM input) reaches it within
+/// the same function without passing through a sanitizer.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// const name = this.route.snapshot.queryParams['name'];
+/// this.el.nativeElement.innerHTML = `<b>${name}</b>`;
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// const name = this.route.snapshot.queryParams['name'];
+/// this.el.nativeElement.innerHTML = `<b>${escapeHtml(name)}</b>`;
+/// ```
+///
+/// ## Rule Options
+///
+/// - `sources`: Additional source paths, e.g. `["userInput.value"]`
+/// - `sanitizers`: Additional sanitizer function paths
+/// - `sinks`: Additional sink function paths; the first argument is checked
+pub struct SecurityTaintFlowRule {
+    spec: TaintSpec,
+}
+
+impl SecurityTaintFlowRule {
+    pub fn new() -> Self {
+        Self {
+            spec: TaintSpec {
+                sources: DEFAULT_SOURCES.iter().map(|s| s.to_string()).collect(),
+                sinks: default_sinks(),
+                sanitizers: DEFAULT_SANITIZERS.iter().map(|s| s.to_string()).collect(),
+            },
+        }
+    }
+
+    fn create_diagnostic(flow: &TaintFlow) -> OxcDiagnostic {
+        OxcDiagnostic::error(format!(
+            "User-controlled data from '{}' reaches '{}'",
+            flow.source, flow.sink
+        ))
+        .with_help("Sanitize or encode the value before it reaches the sink")
+        .with_label(flow.span.label(format!("Tainted value from '{}'", flow.source)))
+        .with_error_code("CWE", flow.cwe)
+    }
+}
+
+fn string_list(value: Option<&Value>) -> Vec<String> {
+    value
+        .and_then(Value::as_array)
+        .map(|items| {
+            items
+                .iter()
+                .filter_map(Value::as_str)
+                .map(str::to_string)
+                .collect()
+        })
+        .unwrap_or_default()
+}
+
+impl Rule for SecurityTaintFlowRule {
+    fn name(&self) -> &'static str {
+        "security-taint-flow"
+    }
+
+    fn description(&self) -> &'static str {
+        "Reports user-controlled data that reaches dangerous sinks without sanitization"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Security
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::EXPERIMENTAL, tags::EXPENSIVE]
+    }
+
+    fn taxonomies(&self) -> &'static [(Taxonomy, &'static str)] {
+        &[
+            (Taxonomy::Cwe, "CWE-74"),
+            (Taxonomy::Cwe, "CWE-79"),
+            (Taxonomy::Cwe, "CWE-95"),
+            (Taxonomy::Cwe, "CWE-601"),
+            (Taxonomy::OwaspAsvs, "V5.1.5"),
+            (Taxonomy::OwaspAsvs, "V5.2.4"),
+            (Taxonomy::OwaspAsvs, "V5.3.3"),
+            (Taxonomy::OwaspTop10, "A01:2021"),
+            (Taxonomy::OwaspTop10, "A03:2021"),
+        ]
+    }
+
+    fn set_config(&mut self, config: Value) {
+        if let Some(obj) = config.as_object() {
+            self.spec.sources.extend(string_list(obj.get("sources")));
+            self.spec.sanitizers.extend(string_list(obj.get("sanitizers")));
+            self.spec
+                .sinks
+                .extend(string_list(obj.get("sinks")).into_iter().map(|pattern| TaintSink {
+                    pattern,
+                    kind: SinkKind::Argument(0),
+                    cwe: "74",
+                }));
+        }
+    }
+
+    fn run_on_semantic(
+        &self,
+        semantic_result: &SemanticBuilderReturn,
+        _file_path: &str,
+    ) -> Vec<OxcDiagnostic> {
+        let semantic = &semantic_result.semantic;
+        let program = semantic.nodes().iter().find_map(|node| match node.kind() {
+            AstKind::Program(program) => Some(program),
+            _ => None,
+        });
+
+        match program {
+            Some(program) => analyze_program(semantic, program, &self.spec)
+                .iter()
+                .map(Self::create_diagnostic)
+                .collect(),
+            None => Vec::new(),
+        }
+    }
+}
@@ -0,0 +1,99 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::Expression;
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory, RuleExamples};
+
+/// Rule that detects focused tests left in spec files
+///
+/// `fdescribe`, `fit` and `.only` make the test runner skip every other test of the
+/// suite, so a focused test that gets committed silently disables the rest of the tests.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// fdescribe('UserService', () => {});
+/// fit('loads the user', () => {});
+/// it.only('loads the user', () => {});
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// describe('UserService', () => {});
+/// it('loads the user', () => {});
+/// ```
+pub struct TestFocusedTestsRule {}
+
+impl TestFocusedTestsRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn create_diagnostic(call: &str, span: Span) -> OxcDiagnostic {
+        OxcDiagnostic::error(format!("Focused test {} skips all other tests", call))
+            .with_help(
+                "Remove the focus before committing, e.g. use describe/it instead of fdescribe/fit",
+            )
+            .with_label(span.label("Focused test"))
+    }
+}
+
+impl Rule for TestFocusedTestsRule {
+    fn name(&self) -> &'static str {
+        "test-focused-tests"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects fdescribe, fit and .only calls left in test files"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::TestRules
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "fdescribe('UserService', () => {});",
+                "it.only('loads the user', () => {});",
+            ],
+            correct: &["describe('UserService', () => {});"],
+        }
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        let AstKind::CallExpression(call) = node else {
+            return Vec::new();
+        };
+
+        let focused = match &call.callee {
+            Expression::Identifier(ident) if matches!(ident.name.as_str(), "fdescribe" | "fit") => {
+                Some((ident.name.to_string(), ident.span))
+            }
+            Expression::StaticMemberExpression(member) if member.property.name == "only" => {
+                match &member.object {
+                    Expression::Identifier(ident)
+                        if matches!(ident.name.as_str(), "describe" | "it" | "test") =>
+                    {
+                        Some((format!("{}.only", ident.name), member.span))
+                    }
+                    _ => None,
+                }
+            }
+            _ => None,
+        };
+
+        focused
+            .map(|(name, span)| vec![Self::create_diagnostic(&format!("{}()", name), span)])
+            .unwrap_or_default()
+    }
+}
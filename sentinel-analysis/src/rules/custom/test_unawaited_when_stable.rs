@@ -0,0 +1,84 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::Expression;
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::Span;
+
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory, RuleExamples};
+
+/// Rule that detects `fixture.whenStable()` calls whose promise is dropped
+///
+/// `whenStable()` resolves once pending async work of the component is done. Calling it
+/// without `await` or `.then()` does not wait for anything, so the assertions that follow
+/// run against a component that is not stable yet and the test passes or fails by chance.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// fixture.whenStable();
+/// expect(component.user).toBeDefined();
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// await fixture.whenStable();
+/// expect(component.user).toBeDefined();
+/// ```
+pub struct TestUnawaitedWhenStableRule {}
+
+impl TestUnawaitedWhenStableRule {
+    pub fn new() -> Self {
+        Self {}
+    }
+
+    fn create_diagnostic(span: Span) -> OxcDiagnostic {
+        OxcDiagnostic::warn("The promise of whenStable() is not awaited")
+            .with_help("Await the call, or chain the assertions with .then(), so they run once the fixture is stable")
+            .with_label(span.label("Not awaited"))
+    }
+}
+
+impl Rule for TestUnawaitedWhenStableRule {
+    fn name(&self) -> &'static str {
+        "test-unawaited-when-stable"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects fixture.whenStable() calls that are neither awaited nor chained"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::TestRules
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &["fixture.whenStable();\nexpect(component.user).toBeDefined();"],
+            correct: &["await fixture.whenStable();\nexpect(component.user).toBeDefined();"],
+        }
+    }
+
+    fn run_on_node(&self, node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
+        // Only a call used as a statement drops its promise
+        let AstKind::ExpressionStatement(statement) = node else {
+            return Vec::new();
+        };
+        let Expression::CallExpression(call) = &statement.expression else {
+            return Vec::new();
+        };
+
+        match &call.callee {
+            Expression::StaticMemberExpression(member) if member.property.name == "whenStable" => {
+                vec![Self::create_diagnostic(call.span)]
+            }
+            _ => Vec::new(),
+        }
+    }
+}
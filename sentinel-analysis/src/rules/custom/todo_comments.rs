@@ -0,0 +1,115 @@
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_semantic::SemanticBuilderReturn;
+use serde_json::Value;
+
+use crate::rules::catalog::tags;
+use crate::rules::comments::{Comments, debt_marker};
+use crate::rules::{Rule, RuleCategory, RuleExamples};
+
+/// Rule that reports technical debt markers in comments
+///
+/// Comments starting with a marker such as `TODO` or `FIXME` are reported, so debt shows
+/// up in the findings and can be tracked per file and category.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// // TODO: remove once the new API is live
+/// const legacyUrl = '/api/v1/users';
+///
+/// /* FIXME handle the error case */
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// // See the TODO list in the README
+/// const legacyUrl = '/api/v1/users';
+/// ```
+///
+/// ## Rule Options
+///
+/// - `markers`: Markers to report (default: `["TODO", "FIXME", "HACK", "XXX"]`)
+pub struct TodoCommentsRule {
+    markers: Vec<String>,
+}
+
+impl TodoCommentsRule {
+    pub fn new() -> Self {
+        Self {
+            markers: ["TODO", "FIXME", "HACK", "XXX"]
+                .iter()
+                .map(|marker| marker.to_string())
+                .collect(),
+        }
+    }
+}
+
+impl Rule for TodoCommentsRule {
+    fn name(&self) -> &'static str {
+        "todo-comments"
+    }
+
+    fn description(&self) -> &'static str {
+        "Reports TODO, FIXME and similar technical debt markers in comments"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::BestPractices
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "// TODO: remove once the new API is live\nconst legacyUrl = '/api/v1/users';",
+                "/* FIXME handle the error case */",
+            ],
+            correct: &["// See the TODO list in the README\nconst legacyUrl = '/api/v1/users';"],
+        }
+    }
+
+    fn set_config(&mut self, config: Value) {
+        if let Some(markers) = config.get("markers").and_then(Value::as_array) {
+            let markers: Vec<String> = markers
+                .iter()
+                .filter_map(Value::as_str)
+                .filter(|marker| !marker.is_empty())
+                .map(str::to_string)
+                .collect();
+            if markers.is_empty() {
+                eprintln!("Warning: todo-comments needs at least one marker, keeping the defaults");
+            } else {
+                self.markers = markers;
+            }
+        }
+    }
+
+    fn run_on_semantic(
+        &self,
+        semantic_result: &SemanticBuilderReturn,
+        _file_path: &str,
+    ) -> Vec<OxcDiagnostic> {
+        Comments::from_semantic(&semantic_result.semantic)
+            .iter()
+            .filter_map(|comment| {
+                let (marker, text) = debt_marker(comment, &self.markers)?;
+                let message = if text.is_empty() {
+                    format!("{} comment", marker)
+                } else {
+                    format!("{} comment: {}", marker, text)
+                };
+                Some(
+                    OxcDiagnostic::warn(message)
+                        .with_help("Resolve the comment or track it in an issue")
+                        .with_label(comment.span.label(marker)),
+                )
+            })
+            .collect()
+    }
+}
@@ -0,0 +1,318 @@
+use oxc_ast::AstKind;
+use oxc_ast::ast::{
+    BindingPattern, BindingPatternKind, FormalParameters, Function, MethodDefinitionKind,
+};
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_semantic::{AstNode, Semantic, SemanticBuilderReturn};
+use oxc_span::Span;
+use serde_json::Value;
+
+use crate::rules::catalog::tags;
+use crate::rules::{DiagnosticData, Rule, RuleCategory, RuleExamples};
+use crate::utilities::glob::glob_match;
+
+/// The ways `any` enters the code
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum AnyKind {
+    /// An explicit `any` in a type annotation or type argument
+    Annotation,
+    /// A cast to `any` (`value as any`, `<any>value`)
+    Cast,
+    /// A parameter without a type annotation, which is implicitly `any`
+    Parameter,
+}
+
+impl AnyKind {
+    fn from_str(kind: &str) -> Option<Self> {
+        match kind {
+            "annotation" => Some(AnyKind::Annotation),
+            "cast" => Some(AnyKind::Cast),
+            "parameter" => Some(AnyKind::Parameter),
+            _ => None,
+        }
+    }
+
+    fn as_str(self) -> &'static str {
+        match self {
+            AnyKind::Annotation => "annotation",
+            AnyKind::Cast => "cast",
+            AnyKind::Parameter => "parameter",
+        }
+    }
+}
+
+/// Files in which some or all kinds of `any` are allowed
+#[derive(Debug, Clone)]
+struct AllowedPath {
+    /// Glob pattern of the file path
+    path: String,
+    /// Allowed kinds, all of them if empty
+    kinds: Vec<AnyKind>,
+}
+
+/// Rule that detects `any`, explicit and implicit
+///
+/// `any` switches off type checking for everything it touches and spreads through
+/// assignments and return values. The rule reports explicit `any` annotations and type
+/// arguments, casts to `any`, and parameters without a type annotation, which TypeScript
+/// types as `any` unless it can infer the type from the context.
+///
+/// Parameters are reported for function declarations, methods, and function expressions
+/// assigned to variables or properties without a type. Callbacks passed as arguments and
+/// parameters with a default value get their type from the context and are not reported.
+///
+/// Casts through `any` are also reported by `typescript-type-assertion`, which flags every
+/// assertion; this rule only looks at `any`, so it can be enabled on its own in code bases
+/// that accept other assertions.
+///
+/// ## Rule Details
+///
+/// Examples of **incorrect** code:
+///
+/// ```typescript
+/// let payload: any;
+/// const items: Array<any> = [];
+/// const user = response as any;
+/// function format(value) {
+///   return String(value);
+/// }
+/// ```
+///
+/// Examples of **correct** code:
+///
+/// ```typescript
+/// let payload: unknown;
+/// const items: Item[] = [];
+/// const user = parseUser(response);
+/// function format(value: number) {
+///   return String(value);
+/// }
+/// users.map(user => user.name);
+/// ```
+///
+/// ## Rule Options
+///
+/// - `checkParameters`: Set to `false` to not report untyped parameters (default: true)
+/// - `allow`: Globs of files in which `any` is allowed, either as strings or
+///   `{ "path": "...", "kinds": ["annotation", "cast", "parameter"] }` to allow some kinds only
+pub struct TypeScriptNoAnyRule {
+    check_parameters: bool,
+    allow: Vec<AllowedPath>,
+}
+
+impl TypeScriptNoAnyRule {
+    pub fn new() -> Self {
+        Self {
+            check_parameters: true,
+            allow: Vec::new(),
+        }
+    }
+
+    fn is_allowed(&self, kind: AnyKind, file_path: &str) -> bool {
+        self.allow.iter().any(|allowed| {
+            (allowed.kinds.is_empty() || allowed.kinds.contains(&kind))
+                && glob_match(&allowed.path, file_path)
+        })
+    }
+
+    fn check_any_keyword(span: Span, node: &AstNode, semantic: &Semantic) -> OxcDiagnostic {
+        match semantic.nodes().parent_kind(node.id()) {
+            Some(AstKind::TSAsExpression(cast)) => {
+                Self::create_diagnostic(AnyKind::Cast, cast.span)
+            }
+            Some(AstKind::TSTypeAssertion(cast)) => {
+                Self::create_diagnostic(AnyKind::Cast, cast.span)
+            }
+            _ => Self::create_diagnostic(AnyKind::Annotation, span),
+        }
+    }
+
+    /// Check if the parameters of a function get their types from the context
+    ///
+    /// Functions passed as arguments or assigned to typed variables and properties are
+    /// typed by their target; the others have to annotate their parameters.
+    fn is_contextually_typed(node: &AstNode, semantic: &Semantic) -> bool {
+        match semantic.nodes().parent_kind(node.id()) {
+            Some(AstKind::VariableDeclarator(declarator)) => {
+                declarator.id.type_annotation.is_some()
+            }
+            Some(AstKind::PropertyDefinition(property)) => property.type_annotation.is_some(),
+            // Setters take the type of their getter
+            Some(AstKind::MethodDefinition(method)) => method.kind == MethodDefinitionKind::Set,
+            _ => true,
+        }
+    }
+
+    fn check_parameters(params: &FormalParameters) -> Vec<OxcDiagnostic> {
+        let mut diagnostics: Vec<OxcDiagnostic> = params
+            .items
+            .iter()
+            .filter(|param| is_untyped(&param.pattern))
+            .map(|param| Self::create_diagnostic(AnyKind::Parameter, param.span))
+            .collect();
+        if let Some(rest) = &params.rest {
+            if rest.argument.type_annotation.is_none() {
+                diagnostics.push(Self::create_diagnostic(AnyKind::Parameter, rest.span));
+            }
+        }
+        diagnostics
+    }
+
+    fn check_function(
+        function: &Function,
+        node: &AstNode,
+        semantic: &Semantic,
+    ) -> Vec<OxcDiagnostic> {
+        if !function.is_declaration() && Self::is_contextually_typed(node, semantic) {
+            return Vec::new();
+        }
+        Self::check_parameters(&function.params)
+    }
+
+    fn create_diagnostic(kind: AnyKind, span: Span) -> OxcDiagnostic {
+        match kind {
+            AnyKind::Annotation => OxcDiagnostic::warn(ANNOTATION_MSG)
+                .with_help(ANNOTATION_HELP)
+                .with_label(span.label("Explicit any")),
+            AnyKind::Cast => OxcDiagnostic::warn(CAST_MSG)
+                .with_help(CAST_HELP)
+                .with_label(span.label("Cast to any")),
+            AnyKind::Parameter => OxcDiagnostic::warn(PARAMETER_MSG)
+                .with_help(PARAMETER_HELP)
+                .with_label(span.label("Implicitly any")),
+        }
+    }
+}
+
+/// Check if a parameter has neither a type annotation nor a default value to infer it from
+fn is_untyped(pattern: &BindingPattern) -> bool {
+    pattern.type_annotation.is_none()
+        && !matches!(pattern.kind, BindingPatternKind::AssignmentPattern(_))
+}
+
+impl Rule for TypeScriptNoAnyRule {
+    fn name(&self) -> &'static str {
+        "typescript-no-any"
+    }
+
+    fn description(&self) -> &'static str {
+        "Detects explicit any annotations, casts to any and untyped parameters"
+    }
+
+    fn category(&self) -> RuleCategory {
+        RuleCategory::TypeScript
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "let payload: any;",
+                "const user = response as any;",
+                "function format(value) {\n  return String(value);\n}",
+            ],
+            correct: &[
+                "let payload: unknown;",
+                "function format(value: number) {\n  return String(value);\n}",
+                "users.map(user => user.name);",
+            ],
+        }
+    }
+
+    fn set_config(&mut self, config: Value) {
+        if let Some(obj) = config.as_object() {
+            if let Some(check_parameters) = obj.get("checkParameters").and_then(Value::as_bool) {
+                self.check_parameters = check_parameters;
+            }
+            if let Some(allow) = obj.get("allow").and_then(Value::as_array) {
+                self.allow = allow
+                    .iter()
+                    .filter_map(|entry| match entry {
+                        Value::String(path) => Some(AllowedPath {
+                            path: path.clone(),
+                            kinds: Vec::new(),
+                        }),
+                        Value::Object(entry) => Some(AllowedPath {
+                            path: entry.get("path")?.as_str()?.to_string(),
+                            kinds: entry
+                                .get("kinds")
+                                .and_then(Value::as_array)
+                                .map(|kinds| {
+                                    kinds
+                                        .iter()
+                                        .filter_map(Value::as_str)
+                                        .filter_map(AnyKind::from_str)
+                                        .collect()
+                                })
+                                .unwrap_or_default(),
+                        }),
+                        _ => None,
+                    })
+                    .collect();
+            }
+        }
+    }
+
+    fn run_on_semantic(
+        &self,
+        semantic_result: &SemanticBuilderReturn,
+        file_path: &str,
+    ) -> Vec<OxcDiagnostic> {
+        let semantic = &semantic_result.semantic;
+        let mut diagnostics = Vec::new();
+        for node in semantic.nodes().iter() {
+            match node.kind() {
+                AstKind::TSAnyKeyword(any) => {
+                    diagnostics.push(Self::check_any_keyword(any.span, node, semantic));
+                }
+                AstKind::Function(function) if self.check_parameters => {
+                    diagnostics.extend(Self::check_function(function, node, semantic));
+                }
+                AstKind::ArrowFunctionExpression(arrow) if self.check_parameters => {
+                    if !Self::is_contextually_typed(node, semantic) {
+                        diagnostics.extend(Self::check_parameters(&arrow.params));
+                    }
+                }
+                _ => {}
+            }
+        }
+
+        diagnostics.retain(|diagnostic| {
+            !kind_of(diagnostic).is_some_and(|kind| self.is_allowed(kind, file_path))
+        });
+        diagnostics
+    }
+
+    fn diagnostic_data(&self, diagnostic: &OxcDiagnostic, _source_code: &str) -> DiagnosticData {
+        let mut data = DiagnosticData::default();
+        if let Some(kind) = kind_of(diagnostic) {
+            data.metadata
+                .insert("kind".to_string(), kind.as_str().into());
+        }
+        data
+    }
+}
+
+/// Get the kind of `any` a diagnostic of this rule reports
+fn kind_of(diagnostic: &OxcDiagnostic) -> Option<AnyKind> {
+    match diagnostic.message.as_ref() {
+        ANNOTATION_MSG => Some(AnyKind::Annotation),
+        CAST_MSG => Some(AnyKind::Cast),
+        PARAMETER_MSG => Some(AnyKind::Parameter),
+        _ => None,
+    }
+}
+
+const ANNOTATION_MSG: &str = "Explicit 'any' type";
+const ANNOTATION_HELP: &str =
+    "Use a specific type, or 'unknown' and narrow it with type guards before use";
+
+const CAST_MSG: &str = "Cast to 'any'";
+const CAST_HELP: &str = "Casting to 'any' switches off type checking for the value; validate the value or fix the types it is cast between";
+
+const PARAMETER_MSG: &str = "Parameter without a type is implicitly 'any'";
+const PARAMETER_HELP: &str =
+    "Annotate the parameter with its type, or give it a default value to infer the type from";
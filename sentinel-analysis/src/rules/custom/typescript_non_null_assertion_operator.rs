@@ -5,7 +5,8 @@ use oxc_diagnostics::OxcDiagnostic;
 use oxc_span::Span;
 use serde_json::Value;
 
-use crate::rules::Rule;
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
 
 /// Rule that detects usage of TypeScript's non-null assertion operator
 ///
@@ -104,6 +105,14 @@ impl Rule for TypeScriptNonNullAssertionRule {
         "Disallows TypeScript's non-null assertion operator"
     }
 
+    fn category(&self) -> RuleCategory {
+        RuleCategory::TypeScript
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
     fn set_config(&mut self, config: Value) {
         if let Some(obj) = config.as_object() {
             if let Some(skip_in_tests) = obj.get("skipInTests") {
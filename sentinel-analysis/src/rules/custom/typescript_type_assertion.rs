@@ -5,7 +5,9 @@ use oxc_diagnostics::OxcDiagnostic;
 use oxc_span::Span;
 use serde_json::Value;
 
-use crate::rules::Rule;
+use crate::rules::catalog::tags;
+use crate::rules::{DiagnosticData, Rule, RuleCategory, RuleExamples};
+use crate::utilities::source::{diagnostic_span, span_text};
 
 /// Rule that detects usage of TypeScript's type assertions and non-null assertion operator
 ///
@@ -300,6 +302,25 @@ impl Rule for TypeScriptAssertionRule {
         "Disallows unsafe TypeScript type assertions and non-null assertions"
     }
 
+    fn category(&self) -> RuleCategory {
+        RuleCategory::TypeScript
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
+    fn examples(&self) -> RuleExamples {
+        RuleExamples {
+            incorrect: &[
+                "const userInput = someValue as User;",
+                "const value = foo as any as SpecificType;",
+                "const element = document.querySelector('.foo')!;",
+            ],
+            correct: &["if (isUser(someValue)) {\n  const user = someValue;\n}"],
+        }
+    }
+
     fn set_config(&mut self, config: Value) {
         if let Some(obj) = config.as_object() {
             if let Some(skip_tests) = obj.get("skipInTests").and_then(Value::as_bool) {
@@ -325,6 +346,41 @@ impl Rule for TypeScriptAssertionRule {
         }
         visitor.diagnostics
     }
+
+    fn diagnostic_data(&self, diagnostic: &OxcDiagnostic, source_code: &str) -> DiagnosticData {
+        let mut data = DiagnosticData::default();
+        let Some(span) = diagnostic_span(diagnostic) else {
+            return data;
+        };
+        let code = span_text(source_code, span);
+
+        if diagnostic.message == NON_NULL_MSG {
+            data.metadata
+                .insert("assertion".to_string(), "non-null".into());
+            // `value!.prop` can become `value?.prop`
+            let followed_by_member = source_code
+                .get(span.end as usize..)
+                .is_some_and(|rest| rest.starts_with('.'));
+            if followed_by_member {
+                if let Some(expression) = code.strip_suffix('!') {
+                    data.suggestion = Some(format!("{}?", expression));
+                }
+            }
+        } else if let Some((_, asserted_type)) = code.rsplit_once(" as ") {
+            data.metadata.insert("assertion".to_string(), "as".into());
+            data.metadata
+                .insert("assertedType".to_string(), asserted_type.trim().into());
+        } else if let Some((asserted_type, _)) =
+            code.strip_prefix('<').and_then(|rest| rest.split_once('>'))
+        {
+            data.metadata
+                .insert("assertion".to_string(), "angle-bracket".into());
+            data.metadata
+                .insert("assertedType".to_string(), asserted_type.trim().into());
+        }
+
+        data
+    }
 }
 
 // Static diagnostic messages to avoid allocations
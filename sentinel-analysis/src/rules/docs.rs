@@ -0,0 +1,79 @@
+//! Reference documentation of the registered rules
+//!
+//! `scoper rules docs` renders the rules of the registry as markdown. Every finding links
+//! to the section of its rule through `Rule::docs_url`.
+
+use crate::rules_registry::RulesRegistry;
+
+/// Location of the generated rules reference, each rule is a section with its name as anchor
+pub const RULES_DOCS_URL: &str =
+    "https://github.com/rryter/sentinel/blob/main/sentinel-analysis/RULES.md";
+
+/// Code examples of a rule, see `Rule::examples`
+#[derive(Debug, Clone, Copy, Default)]
+pub struct RuleExamples {
+    /// Code the rule reports
+    pub incorrect: &'static [&'static str],
+    /// Code the rule accepts
+    pub correct: &'static [&'static str],
+}
+
+impl RuleExamples {
+    pub fn is_empty(&self) -> bool {
+        self.incorrect.is_empty() && self.correct.is_empty()
+    }
+}
+
+/// Render the rules reference of all registered rules as markdown, grouped by category
+pub fn generate_rules_reference(registry: &RulesRegistry) -> String {
+    let mut rules: Vec<_> = registry
+        .get_registered_rules()
+        .into_iter()
+        .filter_map(|name| registry.get_rule(name))
+        .collect();
+    rules.sort_by(|a, b| {
+        a.category()
+            .as_str()
+            .cmp(b.category().as_str())
+            .then_with(|| a.name().cmp(b.name()))
+    });
+
+    let mut markdown = String::from("# Rules Reference\n\n");
+    markdown.push_str("Generated by `scoper rules docs`, do not edit by hand.\n");
+
+    let mut current_category = None;
+    for rule in rules {
+        let category = rule.category().as_str();
+        if current_category != Some(category) {
+            markdown.push_str(&format!("\n## {}\n", category));
+            current_category = Some(category);
+        }
+
+        markdown.push_str(&format!("\n### {}\n\n", rule.name()));
+        markdown.push_str(&format!("{}\n\n", rule.description()));
+        markdown.push_str(&format!("- Version: {}\n", rule.version()));
+        if !rule.tags().is_empty() {
+            markdown.push_str(&format!("- Tags: {}\n", rule.tags().join(", ")));
+        }
+        if !rule.depends_on().is_empty() {
+            markdown.push_str(&format!("- Depends on: {}\n", rule.depends_on().join(", ")));
+        }
+
+        let examples = rule.examples();
+        push_examples(&mut markdown, "incorrect", examples.incorrect);
+        push_examples(&mut markdown, "correct", examples.correct);
+    }
+
+    markdown
+}
+
+fn push_examples(markdown: &mut String, kind: &str, examples: &[&str]) {
+    if examples.is_empty() {
+        return;
+    }
+
+    markdown.push_str(&format!("\nExamples of **{}** code:\n", kind));
+    for example in examples {
+        markdown.push_str(&format!("\n```typescript\n{}\n```\n", example.trim()));
+    }
+}
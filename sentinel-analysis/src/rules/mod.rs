@@ -1,6 +1,16 @@
 // Module declarations
+pub mod ancestry;
+pub mod boundaries;
+pub mod catalog;
+pub mod class_context;
+pub mod comments;
+pub mod context;
+pub mod docs;
 pub mod no_debugger;
 pub mod no_empty_pattern;
+pub mod presets;
+pub mod references;
+pub mod taint;
 
 // Try to import custom rules if they exist
 #[cfg(feature = "custom_rules")]
@@ -12,6 +22,34 @@ use oxc_diagnostics::OxcDiagnostic;
 use oxc_semantic::SemanticBuilderReturn;
 use oxc_span::Span;
 use serde_json::Value;
+use std::collections::HashMap;
+
+use crate::{FileAnalysisResult, RuleDiagnostic};
+
+pub use catalog::{RuleCategory, RuleSeverity, Taxonomy, TaxonomyMapping};
+pub use class_context::ClassContext;
+pub use context::{RuleContext, RuleDebug};
+pub use docs::RuleExamples;
+
+/// Structured data attached to a diagnostic, see `Rule::diagnostic_data`
+#[derive(Debug, Clone, Default)]
+pub struct DiagnosticData {
+    /// Values such as the asserted type or the names of assigned variables
+    pub metadata: HashMap<String, Value>,
+    /// Replacement for the code of the primary label that fixes the issue
+    pub suggestion: Option<String>,
+}
+
+/// Version of the `Rule` trait that custom rules are written against
+///
+/// Bumped when a change to the trait requires changes to existing rules.
+pub const RULE_API_VERSION: u32 = 1;
+
+/// Reserved rule ID of the syntax errors reported by the parser
+///
+/// Parse errors are findings like any other, so they can be suppressed or escalated by
+/// this ID. No rule can be registered under it.
+pub const PARSE_ERROR_RULE: &str = "sentinel/parse-error";
 
 /// Trait that all rules must implement
 pub trait Rule: Send + Sync {
@@ -22,6 +60,53 @@ pub trait Rule: Send + Sync {
     #[allow(dead_code)]
     fn description(&self) -> &'static str;
 
+    /// Get the category of the rule, used for selection and output grouping
+    fn category(&self) -> RuleCategory {
+        RuleCategory::BestPractices
+    }
+
+    /// Get the tags declared by the rule (maturity, performance cost, ...)
+    /// See `catalog::tags` for the well-known values.
+    fn tags(&self) -> &'static [&'static str] {
+        &[]
+    }
+
+    /// Get the entries of security standards the rule checks for, e.g. `(Taxonomy::Cwe, "CWE-79")`
+    /// Exported with the findings, so security teams can track the coverage of a standard.
+    fn taxonomies(&self) -> &'static [(Taxonomy, &'static str)] {
+        &[]
+    }
+
+    /// Whether the rule only counts its matches instead of reporting them (optional)
+    /// Matches of counting rules are summed up in the `counters` of the report, see
+    /// `counters::take_counters`. A match can name its counter in the `counter` metadata.
+    fn counts_only(&self) -> bool {
+        false
+    }
+
+    /// Get the names of the counters of a counting rule, reported even without matches
+    fn counter_names(&self) -> Vec<String> {
+        vec![self.name().to_string()]
+    }
+
+    /// Get the version of the rule
+    /// Bump it whenever a change makes the rule report different findings for the same
+    /// code, so baselines and the cache can tell a changed rule from changed code.
+    fn version(&self) -> &'static str {
+        "1"
+    }
+
+    /// Get the URL of the page explaining the rule, linked from every finding
+    /// Defaults to the section of the rule in the generated rules reference.
+    fn docs_url(&self) -> String {
+        format!("{}#{}", docs::RULES_DOCS_URL, self.name())
+    }
+
+    /// Get code examples the rule reports and accepts, shown in the rules reference
+    fn examples(&self) -> RuleExamples {
+        RuleExamples::default()
+    }
+
     /// Set configuration for this rule
     /// Default implementation does nothing - rules must override to use configuration
     fn set_config(&mut self, _config: Value) {}
@@ -45,6 +130,78 @@ pub trait Rule: Send + Sync {
     ) -> Vec<OxcDiagnostic> {
         Vec::new()
     }
+
+    /// Run the rule with the context of the file (optional)
+    /// Rules that log debug messages or write debug artifacts override this instead of
+    /// `run_on_semantic`; rules never write to the file system themselves, see `RuleContext`.
+    /// Default implementation calls `run_on_semantic`
+    fn run_with_context(
+        &self,
+        semantic_result: &SemanticBuilderReturn,
+        context: &RuleContext,
+    ) -> Vec<OxcDiagnostic> {
+        self.run_on_semantic(semantic_result, context.file_path())
+    }
+
+    /// Run the rule on the raw content of the file (optional)
+    /// Used by rules that do not need the AST, e.g. secret scanners.
+    /// Default implementation returns an empty Vec
+    fn run_on_source(&self, _source_code: &str, _file_path: &str) -> Vec<OxcDiagnostic> {
+        Vec::new()
+    }
+
+    /// Whether the rule needs the class-level context (optional)
+    /// The registry only collects `ClassContext`s if an enabled rule returns true here.
+    fn uses_class_context(&self) -> bool {
+        false
+    }
+
+    /// Run the rule on the context collected for a whole class (optional)
+    /// Default implementation returns an empty Vec
+    ///
+    /// @param class_context Fields, methods, subscriptions and lifecycle hooks of the class
+    /// @param file_path The path of the file being analyzed
+    fn run_on_class(&self, _class_context: &ClassContext, _file_path: &str) -> Vec<OxcDiagnostic> {
+        Vec::new()
+    }
+
+    /// Get the names of the rules whose results this rule builds on (optional)
+    /// Rules with dependencies run after all other rules of a file, see `run_on_results`.
+    fn depends_on(&self) -> &'static [&'static str] {
+        &[]
+    }
+
+    /// Run the rule on the results of the rules it depends on (optional)
+    /// Used by composite rules that combine the findings of other rules.
+    /// Default implementation returns an empty Vec
+    ///
+    /// @param results The diagnostics of each dependency for the current file
+    /// @param file_path The path of the file being analyzed
+    fn run_on_results(
+        &self,
+        _results: &HashMap<&str, Vec<&RuleDiagnostic>>,
+        _file_path: &str,
+    ) -> Vec<OxcDiagnostic> {
+        Vec::new()
+    }
+
+    /// Get structured data about a diagnostic produced by this rule (optional)
+    /// Called by the registry for every diagnostic with the source code of the file, so
+    /// the data can be derived from the code of the labeled span.
+    /// Default implementation returns no data
+    fn diagnostic_data(&self, _diagnostic: &OxcDiagnostic, _source_code: &str) -> DiagnosticData {
+        DiagnosticData::default()
+    }
+
+    /// Run the rule once on the results of all files (optional)
+    /// Used for project-level findings, e.g. a service injected by too many components.
+    /// The findings are reported under the `project` pseudo-file.
+    /// Default implementation returns an empty Vec
+    ///
+    /// @param results The analysis results of all files
+    fn run_on_project(&self, _results: &[FileAnalysisResult]) -> Vec<OxcDiagnostic> {
+        Vec::new()
+    }
 }
 
 // Re-export rules for easier access
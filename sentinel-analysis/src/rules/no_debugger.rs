@@ -2,7 +2,8 @@ use oxc_ast::AstKind;
 use oxc_diagnostics::OxcDiagnostic;
 use oxc_span::Span;
 
-use crate::rules::Rule;
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
 
 /// Rule that disallows debugger statements
 pub struct NoDebuggerRule;
@@ -16,6 +17,14 @@ impl Rule for NoDebuggerRule {
         "Disallow the use of debugger statements"
     }
 
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Correctness
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
     fn run_on_node(&self, node: &AstKind, span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
         match node {
             AstKind::DebuggerStatement(_) => {
@@ -2,7 +2,8 @@ use oxc_ast::AstKind;
 use oxc_diagnostics::OxcDiagnostic;
 use oxc_span::Span;
 
-use crate::rules::Rule;
+use crate::rules::catalog::tags;
+use crate::rules::{Rule, RuleCategory};
 
 /// Rule that disallows empty destructuring patterns
 pub struct NoEmptyPatternRule;
@@ -16,6 +17,14 @@ impl Rule for NoEmptyPatternRule {
         "Disallow empty destructuring patterns"
     }
 
+    fn category(&self) -> RuleCategory {
+        RuleCategory::Correctness
+    }
+
+    fn tags(&self) -> &'static [&'static str] {
+        &[tags::STABLE, tags::CHEAP]
+    }
+
     fn run_on_node(&self, _node: &AstKind, _span: Span, _file_path: &str) -> Vec<OxcDiagnostic> {
         match _node {
             AstKind::ArrayPattern(array) if array.elements.is_empty() => vec![
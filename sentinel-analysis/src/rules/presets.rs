@@ -0,0 +1,104 @@
+//! Named rule presets that can be referenced from the rules configuration
+//!
+//! A preset maps to a curated list of rules together with their severity, similar
+//! to `eslint:recommended`. Rules listed explicitly in the `rules` object of the
+//! configuration file override the preset entries.
+//!
+//! ```json
+//! {
+//!   "preset": "recommended",
+//!   "rules": {
+//!     "angular-input-count": ["warn", { "maxInputs": 8 }]
+//!   }
+//! }
+//! ```
+
+use serde_json::Value;
+
+/// Names of all available presets
+pub const PRESET_NAMES: &[&str] = &["recommended", "strict", "migration"];
+
+/// Rules that are stable and have a low false-positive rate
+const RECOMMENDED: &[(&str, &str)] = &[
+    ("no-debugger", "error"),
+    ("no-empty-pattern", "error"),
+    ("angular-component-class-suffix", "warn"),
+    ("angular-directive-class-suffix", "warn"),
+    ("angular-input-count", "warn"),
+    ("angular-obsolete-standalone-true", "warn"),
+    ("secrets-detection", "error"),
+    ("security-bypass-security-trust", "error"),
+    ("security-eval", "error"),
+    ("security-inner-html", "error"),
+    ("test-focused-tests", "error"),
+    ("typescript-non-null-assertion", "warn"),
+];
+
+/// Every rule that is part of recommended, reported as an error, plus the stricter checks
+const STRICT: &[(&str, &str)] = &[
+    ("no-debugger", "error"),
+    ("no-empty-pattern", "error"),
+    ("angular-bootstrap-module", "error"),
+    ("angular-common-module-import", "error"),
+    ("angular-component-class-suffix", "error"),
+    ("angular-component-styles", "error"),
+    ("angular-deprecated-api", "error"),
+    ("angular-directive-class-suffix", "error"),
+    ("angular-input-count", "error"),
+    ("angular-legacy-decorators", "error"),
+    ("angular-obsolete-standalone-true", "error"),
+    ("angular-on-push-change-detection", "error"),
+    ("angular-output-event-collision", "error"),
+    ("angular-prefer-inject", "error"),
+    ("angular-standalone-candidate", "error"),
+    ("policy-banned-imports", "error"),
+    ("rxjs-subscription-leak", "error"),
+    ("secrets-detection", "error"),
+    ("security-bypass-security-trust", "error"),
+    ("security-eval", "error"),
+    ("security-http-url-concatenation", "error"),
+    ("security-inner-html", "error"),
+    ("security-taint-flow", "error"),
+    ("test-focused-tests", "error"),
+    ("test-unawaited-when-stable", "error"),
+    ("typescript-no-any", "error"),
+    ("typescript-non-null-assertion", "error"),
+    ("typescript-type-assertion", "error"),
+];
+
+/// Rules that point out code to change when moving to modern Angular APIs
+const MIGRATION: &[(&str, &str)] = &[
+    ("angular-bootstrap-module", "warn"),
+    ("angular-common-module-import", "warn"),
+    ("angular-deprecated-api", "warn"),
+    ("angular-legacy-decorators", "warn"),
+    ("angular-obsolete-standalone-true", "warn"),
+    ("angular-prefer-inject", "warn"),
+    ("angular-standalone-candidate", "warn"),
+    ("rxjs-subscription-leak", "warn"),
+];
+
+/// Get the rules and severities of a preset by name
+pub fn get_preset(name: &str) -> Option<&'static [(&'static str, &'static str)]> {
+    match name.trim().to_lowercase().as_str() {
+        "recommended" => Some(RECOMMENDED),
+        "strict" => Some(STRICT),
+        "migration" => Some(MIGRATION),
+        _ => None,
+    }
+}
+
+/// Expand a preset into the rule configuration format used by the registry
+pub fn expand_preset(name: &str) -> Result<Vec<(String, Option<Value>, String)>, String> {
+    match get_preset(name) {
+        Some(rules) => Ok(rules
+            .iter()
+            .map(|(rule, severity)| (rule.to_string(), None, severity.to_string()))
+            .collect()),
+        None => Err(format!(
+            "Unknown preset '{}', expected one of: {}",
+            name,
+            PRESET_NAMES.join(", ")
+        )),
+    }
+}
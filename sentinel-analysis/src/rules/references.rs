@@ -0,0 +1,61 @@
+//! Scope-aware lookup of identifier references
+//!
+//! Resolves names through the scopes built by the semantic analysis instead of comparing
+//! strings, so a local `const eval = ...` or a parameter named like a class member is
+//! not confused with the global or the member it shadows.
+
+use oxc_ast::ast::IdentifierReference;
+use oxc_semantic::{ScopeId, Semantic, SymbolId};
+use oxc_span::{GetSpan, Span};
+
+/// A reference to a symbol
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct SymbolReference {
+    pub span: Span,
+    /// Whether the value is read, e.g. `foo(x)`
+    pub is_read: bool,
+    /// Whether the value is assigned, e.g. `x = 1`
+    pub is_write: bool,
+}
+
+/// Resolve a name to the binding visible in a scope, walking up to the enclosing scopes
+pub fn resolve_binding(semantic: &Semantic, name: &str, scope_id: ScopeId) -> Option<SymbolId> {
+    semantic.scoping().find_binding(scope_id, name)
+}
+
+/// Find all references to the binding a name resolves to in a scope
+///
+/// References to a different binding with the same name, e.g. a parameter shadowing the
+/// variable, are not included. Returns nothing if the name is not declared in the file.
+pub fn find_references(semantic: &Semantic, name: &str, scope_id: ScopeId) -> Vec<SymbolReference> {
+    let Some(symbol_id) = resolve_binding(semantic, name, scope_id) else {
+        return Vec::new();
+    };
+
+    semantic
+        .symbol_references(symbol_id)
+        .map(|reference| SymbolReference {
+            span: semantic.nodes().get_node(reference.node_id()).kind().span(),
+            is_read: reference.is_read(),
+            is_write: reference.is_write(),
+        })
+        .collect()
+}
+
+/// Get the span of the declaration of a symbol
+pub fn declaration_span(semantic: &Semantic, symbol_id: SymbolId) -> Span {
+    semantic.scoping().symbol_span(symbol_id)
+}
+
+/// Check if an identifier refers to a global, i.e. it is not declared anywhere in the file
+///
+/// Identifiers without reference information are treated as globals.
+pub fn is_global_reference(semantic: &Semantic, ident: &IdentifierReference) -> bool {
+    ident.reference_id.get().is_none_or(|reference_id| {
+        semantic
+            .scoping()
+            .get_reference(reference_id)
+            .symbol_id()
+            .is_none()
+    })
+}
@@ -0,0 +1,346 @@
+//! Intra-procedural taint tracking for security rules
+//!
+//! A rule declares a `TaintSpec` with the sources of user-controlled data, the sinks
+//! where such data is dangerous and the sanitizers that make it safe. The analysis walks
+//! every function body in source order, tracks which local variables (and `this` fields)
+//! hold tainted values and reports a `TaintFlow` whenever a tainted value reaches a sink.
+//!
+//! The analysis is flow-insensitive within straight-line code and does not follow calls
+//! into other functions. Variables are tracked by the symbol they resolve to, so a
+//! parameter or nested declaration shadowing a tainted variable is not tainted. Nested
+//! arrow functions see the taint of the enclosing function.
+
+use oxc_ast::ast::{
+    Argument, ArrowFunctionExpression, AssignmentExpression, AssignmentTarget, BindingPatternKind,
+    CallExpression, Expression, Function, IdentifierReference, NewExpression, Program,
+    VariableDeclarator,
+};
+use oxc_ast_visit::{Visit, walk};
+use oxc_semantic::{ScopeFlags, Semantic, SymbolId};
+use oxc_span::{GetSpan, Span};
+use std::collections::HashMap;
+
+/// Where tainted data becomes dangerous
+#[derive(Debug, Clone)]
+pub enum SinkKind {
+    /// Assigning to a property, e.g. `el.innerHTML = value`
+    Property,
+    /// Passing a value as the argument at the given index, e.g. `eval(value)`
+    Argument(usize),
+    /// Passing a value as the last argument, e.g. the body of `new Function('a', value)`
+    LastArgument,
+}
+
+/// A sink declared by a rule
+#[derive(Debug, Clone)]
+pub struct TaintSink {
+    /// Path pattern of the property or function, matched against the end of the
+    /// member chain (`document.write` matches `window.document.write`)
+    pub pattern: String,
+    pub kind: SinkKind,
+    /// CWE number reported for flows into this sink
+    pub cwe: &'static str,
+}
+
+/// Sources, sinks and sanitizers of a taint analysis
+#[derive(Debug, Clone, Default)]
+pub struct TaintSpec {
+    /// Path patterns of expressions that produce user-controlled data
+    pub sources: Vec<String>,
+    pub sinks: Vec<TaintSink>,
+    /// Path patterns of functions whose return value is safe
+    pub sanitizers: Vec<String>,
+}
+
+/// A tainted value that reaches a sink
+#[derive(Debug, Clone)]
+pub struct TaintFlow {
+    /// The source the value originates from
+    pub source: String,
+    /// The sink pattern the value reaches
+    pub sink: String,
+    pub cwe: &'static str,
+    pub span: Span,
+}
+
+/// Check if a member path matches a pattern, either exactly or as the end of the chain
+pub fn path_matches(path: &str, pattern: &str) -> bool {
+    path == pattern
+        || path
+            .strip_suffix(pattern)
+            .is_some_and(|prefix| prefix.ends_with('.'))
+}
+
+/// Render a member chain like `this.route.snapshot.queryParams` as a dotted path
+pub fn expression_path(expr: &Expression) -> Option<String> {
+    match expr {
+        Expression::Identifier(ident) => Some(ident.name.to_string()),
+        Expression::ThisExpression(_) => Some("this".to_string()),
+        Expression::StaticMemberExpression(member) => {
+            expression_path(&member.object)
+                .map(|object| format!("{}.{}", object, member.property.name))
+        }
+        Expression::ComputedMemberExpression(member) => expression_path(&member.object),
+        Expression::CallExpression(call) => expression_path(&call.callee),
+        Expression::ParenthesizedExpression(paren) => expression_path(&paren.expression),
+        _ => None,
+    }
+}
+
+/// Run the taint analysis over a whole program
+pub fn analyze_program<'a>(
+    semantic: &Semantic<'a>,
+    program: &Program<'a>,
+    spec: &TaintSpec,
+) -> Vec<TaintFlow> {
+    let mut analyzer = TaintAnalyzer {
+        semantic,
+        spec,
+        tainted: HashMap::new(),
+        saved: Vec::new(),
+        flows: Vec::new(),
+    };
+    analyzer.visit_program(program);
+    analyzer.flows
+}
+
+/// A tainted value holder
+#[derive(Debug, Clone, PartialEq, Eq, Hash)]
+enum TaintKey {
+    /// A declared variable or parameter
+    Symbol(SymbolId),
+    /// A `this.field` path, or a global that is not declared in the file
+    Path(String),
+}
+
+struct TaintAnalyzer<'s, 'a> {
+    semantic: &'s Semantic<'a>,
+    spec: &'s TaintSpec,
+    /// Tainted variables and fields and the source they come from
+    tainted: HashMap<TaintKey, String>,
+    /// Taint of the enclosing functions
+    saved: Vec<HashMap<TaintKey, String>>,
+    flows: Vec<TaintFlow>,
+}
+
+impl<'s, 'a> TaintAnalyzer<'s, 'a> {
+    /// Get the key of the variable an identifier refers to
+    fn reference_key(&self, ident: &IdentifierReference) -> TaintKey {
+        let symbol_id = ident.reference_id.get().and_then(|reference_id| {
+            self.semantic
+                .scoping()
+                .get_reference(reference_id)
+                .symbol_id()
+        });
+        match symbol_id {
+            Some(symbol_id) => TaintKey::Symbol(symbol_id),
+            None => TaintKey::Path(ident.name.to_string()),
+        }
+    }
+
+    fn is_source(&self, path: &str) -> bool {
+        self.spec.sources.iter().any(|source| path_matches(path, source))
+    }
+
+    fn is_sanitizer(&self, path: &str) -> bool {
+        self.spec
+            .sanitizers
+            .iter()
+            .any(|sanitizer| path_matches(path, sanitizer))
+    }
+
+    /// Get the source of the taint of an expression, if it is tainted
+    fn taint_of(&self, expr: &Expression) -> Option<String> {
+        match expr {
+            Expression::Identifier(ident) => self.tainted.get(&self.reference_key(ident)).cloned(),
+            Expression::StaticMemberExpression(_) | Expression::ComputedMemberExpression(_) => {
+                let path = expression_path(expr)?;
+                if self.is_source(&path) {
+                    return Some(path);
+                }
+                if let Some(source) = self.tainted.get(&TaintKey::Path(path)) {
+                    return Some(source.clone());
+                }
+                // A property of a tainted object is tainted as well
+                match expr {
+                    Expression::StaticMemberExpression(member) => self.taint_of(&member.object),
+                    Expression::ComputedMemberExpression(member) => self.taint_of(&member.object),
+                    _ => None,
+                }
+            }
+            Expression::CallExpression(call) => self.taint_of_call(call),
+            Expression::TemplateLiteral(template) => template
+                .expressions
+                .iter()
+                .find_map(|expr| self.taint_of(expr)),
+            Expression::BinaryExpression(binary) => self
+                .taint_of(&binary.left)
+                .or_else(|| self.taint_of(&binary.right)),
+            Expression::LogicalExpression(logical) => self
+                .taint_of(&logical.left)
+                .or_else(|| self.taint_of(&logical.right)),
+            Expression::ConditionalExpression(conditional) => self
+                .taint_of(&conditional.consequent)
+                .or_else(|| self.taint_of(&conditional.alternate)),
+            Expression::ParenthesizedExpression(paren) => self.taint_of(&paren.expression),
+            Expression::AwaitExpression(await_expr) => self.taint_of(&await_expr.argument),
+            Expression::TSAsExpression(as_expr) => self.taint_of(&as_expr.expression),
+            Expression::TSNonNullExpression(non_null) => self.taint_of(&non_null.expression),
+            Expression::AssignmentExpression(assignment) => self.taint_of(&assignment.right),
+            _ => None,
+        }
+    }
+
+    fn taint_of_call(&self, call: &CallExpression) -> Option<String> {
+        let path = expression_path(&call.callee);
+
+        if let Some(path) = &path {
+            if self.is_sanitizer(path) {
+                return None;
+            }
+            if self.is_source(path) {
+                return Some(path.clone());
+            }
+        }
+
+        // Methods called on tainted values (`value.trim()`) and functions called with
+        // tainted arguments propagate the taint
+        let receiver_taint = match &call.callee {
+            Expression::StaticMemberExpression(member) => self.taint_of(&member.object),
+            _ => None,
+        };
+        receiver_taint.or_else(|| {
+            call.arguments
+                .iter()
+                .filter_map(Argument::as_expression)
+                .find_map(|arg| self.taint_of(arg))
+        })
+    }
+
+    fn set_taint(&mut self, key: TaintKey, source: Option<String>) {
+        match source {
+            Some(source) => {
+                self.tainted.insert(key, source);
+            }
+            None => {
+                self.tainted.remove(&key);
+            }
+        }
+    }
+
+    /// Report tainted arguments of a call or `new` expression that reach argument sinks
+    fn check_arguments(&mut self, callee: &Expression, arguments: &[Argument]) {
+        let Some(path) = expression_path(callee) else {
+            return;
+        };
+        let flows: Vec<(String, TaintSink, Span)> = self
+            .spec
+            .sinks
+            .iter()
+            .filter(|sink| path_matches(&path, &sink.pattern))
+            .filter_map(|sink| {
+                let argument = match sink.kind {
+                    SinkKind::Property => return None,
+                    SinkKind::Argument(index) => arguments.get(index)?,
+                    SinkKind::LastArgument => arguments.last()?,
+                };
+                let source = self.taint_of(argument.as_expression()?)?;
+                Some((source, sink.clone(), argument.span()))
+            })
+            .collect();
+
+        for (source, sink, span) in flows {
+            self.report(source, &sink, span);
+        }
+    }
+
+    fn report(&mut self, source: String, sink: &TaintSink, span: Span) {
+        self.flows.push(TaintFlow {
+            source,
+            sink: sink.pattern.clone(),
+            cwe: sink.cwe,
+            span,
+        });
+    }
+}
+
+impl<'a, 's> Visit<'a> for TaintAnalyzer<'s, 'a> {
+    fn visit_function(&mut self, func: &Function<'a>, flags: ScopeFlags) {
+        // Functions start without taint, the analysis is intra-procedural
+        let outer = std::mem::take(&mut self.tainted);
+        self.saved.push(outer);
+        walk::walk_function(self, func, flags);
+        self.tainted = self.saved.pop().unwrap_or_default();
+    }
+
+    fn visit_arrow_function_expression(&mut self, arrow: &ArrowFunctionExpression<'a>) {
+        // Arrow functions close over the taint of the enclosing function
+        self.saved.push(self.tainted.clone());
+        walk::walk_arrow_function_expression(self, arrow);
+        self.tainted = self.saved.pop().unwrap_or_default();
+    }
+
+    fn visit_variable_declarator(&mut self, decl: &VariableDeclarator<'a>) {
+        if let BindingPatternKind::BindingIdentifier(id) = &decl.id.kind {
+            let source = decl.init.as_ref().and_then(|init| self.taint_of(init));
+            let key = match id.symbol_id.get() {
+                Some(symbol_id) => TaintKey::Symbol(symbol_id),
+                None => TaintKey::Path(id.name.to_string()),
+            };
+            self.set_taint(key, source);
+        }
+
+        walk::walk_variable_declarator(self, decl);
+    }
+
+    fn visit_assignment_expression(&mut self, assignment: &AssignmentExpression<'a>) {
+        let source = self.taint_of(&assignment.right);
+
+        match &assignment.left {
+            AssignmentTarget::AssignmentTargetIdentifier(ident) => {
+                let key = self.reference_key(ident);
+                self.set_taint(key, source);
+            }
+            AssignmentTarget::StaticMemberExpression(member) => {
+                let path = expression_path(&member.object)
+                    .map(|object| format!("{}.{}", object, member.property.name));
+
+                if let (Some(path), Some(source)) = (&path, &source) {
+                    let sink = self
+                        .spec
+                        .sinks
+                        .iter()
+                        .find(|sink| {
+                            matches!(sink.kind, SinkKind::Property)
+                                && path_matches(path, &sink.pattern)
+                        })
+                        .cloned();
+                    if let Some(sink) = sink {
+                        self.report(source.clone(), &sink, assignment.span);
+                    }
+                }
+
+                // Track tainted fields like `this.query = location.search`
+                if let Some(path) = path {
+                    if path.starts_with("this.") {
+                        self.set_taint(TaintKey::Path(path), source);
+                    }
+                }
+            }
+            _ => {}
+        }
+
+        walk::walk_assignment_expression(self, assignment);
+    }
+
+    fn visit_call_expression(&mut self, call: &CallExpression<'a>) {
+        self.check_arguments(&call.callee, &call.arguments);
+        walk::walk_call_expression(self, call);
+    }
+
+    fn visit_new_expression(&mut self, new_expr: &NewExpression<'a>) {
+        // `new Function(body)` evaluates its last argument like `eval`
+        self.check_arguments(&new_expr.callee, &new_expr.arguments);
+        walk::walk_new_expression(self, new_expr);
+    }
+}
@@ -1,15 +1,41 @@
-use oxc_diagnostics::Error;
+use oxc_ast::AstKind;
+use oxc_diagnostics::{Error, OxcDiagnostic};
 use oxc_diagnostics::reporter::Info;
 use oxc_semantic::SemanticBuilderReturn;
 use oxc_span::GetSpan;
-use std::collections::{HashMap, HashSet};
+use serde_json::Value;
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::sync::Arc;
 use std::time::Duration;
 use std::time::Instant;
 // Import the Rule trait and rule implementations
-use crate::RuleDiagnostic;
+use crate::cache::content_hash;
+use crate::utilities::glob::glob_match_any;
+use crate::utilities::source::{
+    ColumnUnit, diagnostic_span, has_generated_header, position_of_offset,
+};
+use crate::{FileAnalysisResult, RuleDiagnostic};
 pub use crate::rules::Rule;
+use crate::rules::presets::expand_preset;
+use crate::rules::{
+    ClassContext, PARSE_ERROR_RULE, RuleCategory, RuleContext, RuleDebug, RuleSeverity,
+    TaxonomyMapping,
+};
 pub use crate::rules::{NoDebuggerRule, NoEmptyPatternRule};
 
+/// Pseudo-file under which project-level findings are reported
+pub const PROJECT_FILE: &str = "project";
+
+/// Globs identifying spec, test and stories files, unless `test_patterns` is configured
+pub const DEFAULT_TEST_PATTERNS: &[&str] = &[
+    "**/*.spec.ts",
+    "**/*.spec.tsx",
+    "**/*.test.ts",
+    "**/*.test.tsx",
+    "**/*.stories.ts",
+    "**/*.stories.tsx",
+];
+
 /// The result of running a rule on a file
 pub struct RuleResult {
     #[allow(dead_code)]
@@ -22,6 +48,20 @@ pub struct RulesRegistry {
     rules: HashMap<&'static str, Box<dyn Rule>>,
     enabled_rules: HashSet<String>,
     rule_severity: HashMap<String, String>,
+    /// Configuration passed to each rule, part of the cache fingerprint
+    rule_configs: HashMap<String, String>,
+    /// Whether rules of the `test-rules` category run on test files
+    analyze_tests: bool,
+    /// Globs identifying test files
+    test_patterns: Vec<String>,
+    /// Globs identifying generated files in addition to their header
+    generated_patterns: Vec<String>,
+    /// Whether rules run on generated files
+    include_generated: bool,
+    /// Unit of the column numbers of diagnostics
+    column_unit: ColumnUnit,
+    /// Rules selected with `--rule-debug` and where their artifacts go
+    rule_debug: Option<RuleDebug>,
 }
 
 impl RulesRegistry {
@@ -31,12 +71,23 @@ impl RulesRegistry {
             rules: HashMap::new(),
             enabled_rules: HashSet::new(),
             rule_severity: HashMap::new(),
+            rule_configs: HashMap::new(),
+            analyze_tests: false,
+            test_patterns: DEFAULT_TEST_PATTERNS.iter().map(|p| p.to_string()).collect(),
+            generated_patterns: Vec::new(),
+            include_generated: false,
+            column_unit: ColumnUnit::default(),
+            rule_debug: None,
         }
     }
 
     /// Register a rule with the registry
     pub fn register_rule(&mut self, rule: Box<dyn Rule>) {
         let rule_name = rule.name();
+        // The ID of parse errors is reserved
+        if rule_name == PARSE_ERROR_RULE {
+            return;
+        }
         self.rules.insert(rule_name, rule);
     }
 
@@ -85,6 +136,94 @@ impl RulesRegistry {
         self.enabled_rules.iter().cloned().collect()
     }
 
+    /// Get the category of a registered rule
+    pub fn get_rule_category(&self, rule_name: &str) -> Option<RuleCategory> {
+        self.rules.get(rule_name).map(|rule| rule.category())
+    }
+
+    /// Get a registered rule
+    pub fn get_rule(&self, rule_name: &str) -> Option<&dyn Rule> {
+        self.rules.get(rule_name).map(|rule| &**rule)
+    }
+
+    /// Get the version of a registered rule
+    pub fn get_rule_version(&self, rule_name: &str) -> Option<&'static str> {
+        self.rules.get(rule_name).map(|rule| rule.version())
+    }
+
+    /// Get the tags declared by a registered rule
+    pub fn get_rule_tags(&self, rule_name: &str) -> &'static [&'static str] {
+        match self.rules.get(rule_name) {
+            Some(rule) => rule.tags(),
+            None => &[],
+        }
+    }
+
+    /// Get the security standard entries of the enabled rules that declare any
+    pub fn get_rule_taxonomies(&self) -> BTreeMap<String, Vec<TaxonomyMapping>> {
+        self.enabled_rules
+            .iter()
+            .filter_map(|name| {
+                let taxonomies = self.rules.get(name)?.taxonomies();
+                let mappings = taxonomies
+                    .iter()
+                    .map(|(taxonomy, id)| TaxonomyMapping {
+                        taxonomy: *taxonomy,
+                        id: id.to_string(),
+                    })
+                    .collect::<Vec<_>>();
+                (!mappings.is_empty()).then(|| (name.clone(), mappings))
+            })
+            .collect()
+    }
+
+    /// Check if a rule matches a selector, which can be the rule name,
+    /// its category (e.g. `angular`) or one of its tags (e.g. `experimental`)
+    pub fn rule_matches_selector(&self, rule_name: &str, selector: &str) -> bool {
+        let Some(rule) = self.rules.get(rule_name) else {
+            return false;
+        };
+
+        let selector = selector.trim().to_lowercase();
+        rule_name == selector
+            || rule.category().matches_selector(&selector)
+            || rule.tags().iter().any(|tag| *tag == selector)
+    }
+
+    /// Apply include and exclude selectors to the set of enabled rules
+    ///
+    /// If include selectors are given, every registered rule matching one of them is
+    /// enabled and all other rules are disabled. Exclude selectors are applied
+    /// afterwards and take precedence.
+    pub fn apply_rule_selectors(&mut self, include: &[String], exclude: &[String]) {
+        let rule_names = self.get_registered_rules();
+
+        if !include.is_empty() {
+            for rule_name in &rule_names {
+                if include
+                    .iter()
+                    .any(|selector| self.rule_matches_selector(rule_name, selector))
+                {
+                    self.enable_rule(rule_name);
+                    self.rule_severity
+                        .entry(rule_name.to_string())
+                        .or_insert_with(|| "error".to_string());
+                } else {
+                    self.disable_rule(rule_name);
+                }
+            }
+        }
+
+        for rule_name in &rule_names {
+            if exclude
+                .iter()
+                .any(|selector| self.rule_matches_selector(rule_name, selector))
+            {
+                self.disable_rule(rule_name);
+            }
+        }
+    }
+
     /// Run all enabled rules on a file's semantic analysis and get metrics by rule
     pub fn run_rules_with_metrics(
         &self,
@@ -92,29 +231,61 @@ impl RulesRegistry {
         file_path: &str,
         source_code: &str,
     ) -> (Vec<RuleDiagnostic>, HashMap<String, Duration>) {
-        let mut diagnostics = Vec::new();
+        self.run_rules_with_cache(
+            semantic_result,
+            file_path,
+            &Arc::from(source_code),
+            HashMap::new(),
+        )
+    }
+
+    /// Run the enabled rules that have no cached results for the file
+    ///
+    /// The cached diagnostics are part of the returned diagnostics, so composite rules
+    /// see the results of cached dependencies as well.
+    pub fn run_rules_with_cache(
+        &self,
+        semantic_result: &SemanticBuilderReturn,
+        file_path: &str,
+        source_code: &Arc<str>,
+        cached: HashMap<String, Vec<RuleDiagnostic>>,
+    ) -> (Vec<RuleDiagnostic>, HashMap<String, Duration>) {
+        let active_rules: Vec<&String> = self
+            .enabled_rules
+            .iter()
+            .filter(|rule_name| !cached.contains_key(*rule_name))
+            .filter(|rule_name| self.rule_applies_to(rule_name, file_path))
+            .collect();
+        let mut diagnostics: Vec<RuleDiagnostic> = cached.into_values().flatten().collect();
         let mut rule_durations = HashMap::new();
 
         // Only process if we have rules enabled
-        if !self.enabled_rules.is_empty() {
+        if !active_rules.is_empty() {
             // First, run visitor-based rules
-            for rule_name in &self.enabled_rules {
+            for rule_name in active_rules.iter().copied() {
                 if let Some(rule) = self.rules.get(rule_name.as_str()) {
                     // Time the rule execution
                     let rule_start = Instant::now();
 
-                    // Run visitor-based analysis
-                    let visitor_diagnostics = rule.run_on_semantic(semantic_result, file_path);
+                    // Run visitor-based and raw source analysis
+                    let context = RuleContext::new(rule_name, file_path, self.rule_debug.as_ref());
+                    let mut visitor_diagnostics = rule.run_with_context(semantic_result, &context);
+                    visitor_diagnostics.extend(rule.run_on_source(source_code, file_path));
+                    context.debug(&format!(
+                        "{} findings in {:?}",
+                        visitor_diagnostics.len(),
+                        rule_start.elapsed()
+                    ));
 
                     // Wrap each diagnostic with rule ID
                     for diagnostic in visitor_diagnostics {
-                        diagnostics.push(RuleDiagnostic {
-                            rule_id: rule_name.clone(),
+                        diagnostics.push(wrap_diagnostic(
+                            rule_name,
+                            &**rule,
                             diagnostic,
-                            source_code: source_code.to_string(),
-                            column_number: 0,
-                            line_number: 0,
-                        });
+                            source_code,
+                            self.column_unit,
+                        ));
                     }
 
                     // Record the time taken locally
@@ -129,7 +300,7 @@ impl RulesRegistry {
             // 1. Check if the rule implements run_on_node (requires modifying trait definition)
             // 2. Only traverse nodes if at least one rule implements run_on_node
             // 3. Only call run_on_node for rules that actually implement it (avoiding empty Vec allocations)
-            let has_node_based_rules = self.enabled_rules.iter().any(|rule_name| {
+            let has_node_based_rules = active_rules.iter().any(|rule_name| {
                 self.rules.get(rule_name.as_str()).map_or(false, |_rule| {
                     // For now, we assume all rules *might* use node-based processing
                     // In the future, we could have a trait method that returns whether
@@ -138,14 +309,50 @@ impl RulesRegistry {
                 })
             });
 
+            // Only collect class contexts if an enabled rule asks for them
+            let class_rules: Vec<(&String, &Box<dyn Rule>)> = active_rules
+                .iter()
+                .copied()
+                .filter_map(|rule_name| {
+                    self.rules
+                        .get(rule_name.as_str())
+                        .filter(|rule| rule.uses_class_context())
+                        .map(|rule| (rule_name, rule))
+                })
+                .collect();
+
             // >>> Section 2: Run traditional node-based rules (Conditionally) <<<
             if has_node_based_rules {
                 for node in semantic_result.semantic.nodes() {
                     let node_kind = node.kind();
                     let span = node.span();
 
+                    // Run class-level rules once per class
+                    if let AstKind::Class(class) = &node_kind {
+                        if !class_rules.is_empty() {
+                            let class_context = ClassContext::from_class(class);
+                            for (rule_name, rule) in &class_rules {
+                                let rule_start = Instant::now();
+                                let class_diagnostics = rule.run_on_class(&class_context, file_path);
+                                *rule_durations
+                                    .entry(rule_name.to_string())
+                                    .or_insert(Duration::default()) += rule_start.elapsed();
+
+                                for diagnostic in class_diagnostics {
+                                    diagnostics.push(wrap_diagnostic(
+                                        rule_name,
+                                        &***rule,
+                                        diagnostic,
+                                        source_code,
+                                        self.column_unit,
+                                    ));
+                                }
+                            }
+                        }
+                    }
+
                     // Run each enabled rule on this node
-                    for rule_name in &self.enabled_rules {
+                    for rule_name in active_rules.iter().copied() {
                         if let Some(rule) = self.rules.get(rule_name.as_str()) {
                             // Time the rule execution
                             let rule_start = Instant::now();
@@ -162,27 +369,325 @@ impl RulesRegistry {
 
                                 // Add all diagnostics from the Vec to your collection
                                 for diagnostic in diagnostics_vec {
-                                    let error = diagnostic
-                                        .clone()
-                                        .with_source_code(source_code.to_string());
-                                    let (line, column) = extract_position_info(&error);
-                                    diagnostics.push(RuleDiagnostic {
-                                        rule_id: rule_name.clone(),
+                                    diagnostics.push(wrap_diagnostic(
+                                        rule_name,
+                                        &**rule,
                                         diagnostic,
-                                        source_code: source_code.to_string(),
-                                        line_number: line,
-                                        column_number: column,
-                                    });
+                                        source_code,
+                                        self.column_unit,
+                                    ));
                                 }
                             }
                         }
                     }
                 }
             }
+
+            // Composite rules run last, once the results of their dependencies are known
+            self.run_composite_rules(
+                file_path,
+                source_code,
+                &mut diagnostics,
+                &mut rule_durations,
+            );
         }
 
         (diagnostics, rule_durations)
     }
+
+    /// Check if the cached results cover every rule that runs on the AST of a file
+    ///
+    /// Composite rules only read the results of other rules, so a file whose other rules
+    /// all hit the cache does not have to be parsed, see `run_cached_rules`.
+    pub fn is_fully_cached(
+        &self,
+        file_path: &str,
+        cached: &HashMap<String, Vec<RuleDiagnostic>>,
+    ) -> bool {
+        self.enabled_rules
+            .iter()
+            .filter(|rule_name| self.is_cacheable(rule_name))
+            .filter(|rule_name| self.rule_applies_to(rule_name, file_path))
+            .all(|rule_name| cached.contains_key(rule_name))
+    }
+
+    /// Get the results of a file whose rules all hit the cache, without its AST
+    ///
+    /// Only the composite rules run, on the cached diagnostics.
+    pub fn run_cached_rules(
+        &self,
+        file_path: &str,
+        source_code: &Arc<str>,
+        cached: HashMap<String, Vec<RuleDiagnostic>>,
+    ) -> (Vec<RuleDiagnostic>, HashMap<String, Duration>) {
+        let mut diagnostics: Vec<RuleDiagnostic> = cached.into_values().flatten().collect();
+        let mut rule_durations = HashMap::new();
+        self.run_composite_rules(
+            file_path,
+            source_code,
+            &mut diagnostics,
+            &mut rule_durations,
+        );
+        (diagnostics, rule_durations)
+    }
+
+    /// Run the composite rules on the diagnostics of their dependencies
+    fn run_composite_rules(
+        &self,
+        file_path: &str,
+        source_code: &Arc<str>,
+        diagnostics: &mut Vec<RuleDiagnostic>,
+        rule_durations: &mut HashMap<String, Duration>,
+    ) {
+        for rule_name in self.dependency_order() {
+            let rule = &self.rules[rule_name];
+            let rule_start = Instant::now();
+
+            let results: HashMap<&str, Vec<&RuleDiagnostic>> = rule
+                .depends_on()
+                .iter()
+                .map(|dependency| {
+                    let matches = diagnostics
+                        .iter()
+                        .filter(|d| d.rule_id == *dependency)
+                        .collect();
+                    (*dependency, matches)
+                })
+                .collect();
+            let composite_diagnostics = rule.run_on_results(&results, file_path);
+
+            *rule_durations
+                .entry(rule_name.to_string())
+                .or_insert(Duration::default()) += rule_start.elapsed();
+
+            for diagnostic in composite_diagnostics {
+                diagnostics.push(wrap_diagnostic(
+                    rule_name,
+                    &**rule,
+                    diagnostic,
+                    source_code,
+                    self.column_unit,
+                ));
+            }
+        }
+    }
+
+    /// Run the project-level hook of all enabled rules once all files are analyzed
+    ///
+    /// Returns the findings as the result of the `project` pseudo-file, or `None` if there
+    /// are none.
+    pub fn run_project_rules(&self, results: &[FileAnalysisResult]) -> Option<FileAnalysisResult> {
+        let project_start = Instant::now();
+        let mut diagnostics = Vec::new();
+        let mut rule_durations = HashMap::new();
+
+        let mut rule_names: Vec<&String> = self.enabled_rules.iter().collect();
+        rule_names.sort();
+        let no_source: Arc<str> = Arc::from("");
+
+        for rule_name in rule_names {
+            if let Some(rule) = self.rules.get(rule_name.as_str()) {
+                let rule_start = Instant::now();
+                let project_diagnostics = rule.run_on_project(results);
+                rule_durations.insert(rule_name.to_string(), rule_start.elapsed());
+
+                for diagnostic in project_diagnostics {
+                    diagnostics.push(wrap_diagnostic(
+                        rule_name,
+                        &**rule,
+                        diagnostic,
+                        &no_source,
+                        self.column_unit,
+                    ));
+                }
+            }
+        }
+
+        if diagnostics.is_empty() {
+            return None;
+        }
+
+        Some(FileAnalysisResult {
+            file_path: PROJECT_FILE.to_string(),
+            parse_duration: Duration::default(),
+            semantic_duration: Duration::default(),
+            rule_durations,
+            total_duration: project_start.elapsed(),
+            diagnostics,
+            angular_symbols: Vec::new(),
+            content_hash: None,
+            generated: false,
+            skipped: None,
+        })
+    }
+
+    /// Get the fingerprint of a rule under which its results are cached
+    ///
+    /// Changes with the version and with the configuration of the rule, so cached
+    /// results of a rule are not reused once either changes.
+    pub fn rule_fingerprint(&self, rule_name: &str) -> String {
+        let version = self.get_rule_version(rule_name).unwrap_or_default();
+        let config = self
+            .rule_configs
+            .get(rule_name)
+            .map_or("", String::as_str);
+        format!("{}:{:016x}", version, content_hash(config.as_bytes()))
+    }
+
+    /// Check if the results of a rule for a file can be cached
+    ///
+    /// Composite rules are not cached, since their results depend on other rules.
+    pub fn is_cacheable(&self, rule_name: &str) -> bool {
+        self.rules
+            .get(rule_name)
+            .is_some_and(|rule| rule.depends_on().is_empty())
+    }
+
+    /// Configure whether `test-rules` run and which files are test files
+    pub fn set_test_files(&mut self, analyze_tests: bool, test_patterns: Vec<String>) {
+        self.analyze_tests = analyze_tests;
+        self.test_patterns = test_patterns;
+    }
+
+    /// Check if a file is a spec, test or stories file
+    pub fn is_test_file(&self, file_path: &str) -> bool {
+        glob_match_any(&self.test_patterns, file_path)
+    }
+
+    /// Configure which files are generated and whether rules run on them
+    pub fn set_generated_files(
+        &mut self,
+        include_generated: bool,
+        generated_patterns: Vec<String>,
+    ) {
+        self.include_generated = include_generated;
+        self.generated_patterns = generated_patterns;
+    }
+
+    /// Set the unit of the column numbers of diagnostics
+    pub fn set_column_unit(&mut self, column_unit: ColumnUnit) {
+        self.column_unit = column_unit;
+    }
+
+    /// Get the unit of the column numbers of diagnostics
+    pub fn column_unit(&self) -> ColumnUnit {
+        self.column_unit
+    }
+
+    /// Select rules whose debug messages and artifacts are emitted, see `RuleContext`
+    pub fn set_rule_debug(&mut self, rule_debug: RuleDebug) {
+        self.rule_debug = Some(rule_debug);
+    }
+
+    /// Check if a file is generated, by its path or by an `@generated` / `DO NOT EDIT` header
+    pub fn is_generated_file(&self, file_path: &str, source_code: &str) -> bool {
+        glob_match_any(&self.generated_patterns, file_path) || has_generated_header(source_code)
+    }
+
+    /// Check if rules are skipped on generated files
+    pub fn skips_generated_files(&self) -> bool {
+        !self.include_generated
+    }
+
+    /// Check if a rule runs on a file
+    ///
+    /// Rules of the `test-rules` category only run on test files, and only if
+    /// `analyze_tests` is enabled; all other rules run on every file.
+    pub fn rule_applies_to(&self, rule_name: &str, file_path: &str) -> bool {
+        match self.get_rule_category(rule_name) {
+            Some(RuleCategory::TestRules) => self.analyze_tests && self.is_test_file(file_path),
+            _ => true,
+        }
+    }
+
+    /// Get the enabled rules with dependencies, ordered so that a rule comes after the
+    /// composite rules it depends on
+    ///
+    /// Rules that are part of a dependency cycle are left out.
+    pub fn dependency_order(&self) -> Vec<&'static str> {
+        let mut pending: Vec<&'static str> = self
+            .rules
+            .iter()
+            .filter(|(name, rule)| {
+                self.enabled_rules.contains(**name) && !rule.depends_on().is_empty()
+            })
+            .map(|(name, _)| *name)
+            .collect();
+        pending.sort();
+
+        let mut ordered: Vec<&'static str> = Vec::with_capacity(pending.len());
+        loop {
+            let (ready, waiting): (Vec<&'static str>, Vec<&'static str>) =
+                pending.iter().copied().partition(|name| {
+                    self.rules[*name].depends_on().iter().all(|dependency| {
+                        ordered.contains(dependency) || !pending.contains(dependency)
+                    })
+                });
+            if ready.is_empty() {
+                break;
+            }
+            ordered.extend(ready);
+            pending = waiting;
+        }
+
+        ordered
+    }
+
+    /// Get the dependencies of enabled rules that are not enabled themselves, as
+    /// `(rule, dependency)` pairs
+    pub fn missing_dependencies(&self) -> Vec<(&'static str, &'static str)> {
+        let mut missing: Vec<(&'static str, &'static str)> = self
+            .rules
+            .iter()
+            .filter(|(name, _)| self.enabled_rules.contains(**name))
+            .flat_map(|(name, rule)| {
+                rule.depends_on()
+                    .iter()
+                    .filter(|dependency| !self.enabled_rules.contains(**dependency))
+                    .map(move |dependency| (*name, *dependency))
+            })
+            .collect();
+        missing.sort();
+        missing
+    }
+}
+
+/// Associate a diagnostic with the rule that produced it and its position in the file
+fn wrap_diagnostic(
+    rule_name: &str,
+    rule: &dyn Rule,
+    diagnostic: OxcDiagnostic,
+    source_code: &Arc<str>,
+    column_unit: ColumnUnit,
+) -> RuleDiagnostic {
+    let (line, column) = match diagnostic_span(&diagnostic) {
+        Some(span) if !source_code.is_empty() => {
+            position_of_offset(source_code, span.start as usize, column_unit)
+        }
+        _ => {
+            let error = diagnostic.clone().with_source_code(source_code.to_string());
+            extract_position_info(&error)
+        }
+    };
+
+    // Data provided by the rule takes precedence over the error code
+    let data = rule.diagnostic_data(&diagnostic, source_code);
+    let mut metadata = diagnostic_metadata(&diagnostic);
+    metadata.extend(data.metadata);
+
+    RuleDiagnostic {
+        rule_id: rule_name.to_string(),
+        category: rule.category(),
+        rule_version: rule.version(),
+        docs_url: Some(rule.docs_url()),
+        metadata,
+        suggestion: data.suggestion,
+        diagnostic,
+        source_code: Arc::clone(source_code),
+        line_number: line,
+        column_number: column,
+        blame: None,
+    }
 }
 
 /// Create a registry with all default rules registered
@@ -219,15 +724,20 @@ pub fn load_rule_config(
         Err(err) => return Err(format!("Failed to parse config file: {}", err)),
     };
 
+    // Start from the preset, if one is referenced
+    let mut rule_config = match config.get("preset").and_then(|p| p.as_str()) {
+        Some(preset) => expand_preset(preset)?,
+        None => Vec::new(),
+    };
+    let has_preset = !rule_config.is_empty();
+
     if let Some(rules) = config.get("rules") {
         if let Some(rules_obj) = rules.as_object() {
-            let mut rule_config = Vec::new();
-
             for (rule_name, value) in rules_obj.iter() {
-                match value {
+                let entry = match value {
                     // Simple case: "rule-name": "error" or "rule-name": "warn"
                     serde_json::Value::String(severity) => {
-                        rule_config.push((rule_name.clone(), None, severity.clone()));
+                        (rule_name.clone(), None, severity.clone())
                     }
                     // Complex case: "rule-name": ["error", { config object }]
                     serde_json::Value::Array(arr) if !arr.is_empty() => {
@@ -243,18 +753,30 @@ pub fn load_rule_config(
                         } else {
                             None
                         };
-                        rule_config.push((rule_name.clone(), config, severity));
+                        (rule_name.clone(), config, severity)
                     }
                     // Invalid format
                     _ => {
                         return Err(format!("Invalid rule configuration for '{}'", rule_name));
                     }
-                }
+                };
+
+                // Explicit rule entries override the preset
+                rule_config.retain(|(name, _, _)| name != rule_name);
+                rule_config.push(entry);
             }
+
+            // Rules turned off explicitly are dropped from the enabled set
+            rule_config
+                .retain(|(_, _, severity)| RuleSeverity::from(severity.as_str()) != RuleSeverity::Off);
             return Ok(rule_config);
         }
     }
 
+    if has_preset {
+        return Ok(rule_config);
+    }
+
     Err("Config file does not contain a valid 'rules' object".to_string())
 }
 
@@ -277,6 +799,9 @@ pub fn configure_registry(
         if let Some(config) = rule_config {
             if let Some(rule) = registry.rules.get_mut(rule_name.as_str()) {
                 rule.set_config(config.clone());
+                registry
+                    .rule_configs
+                    .insert(rule_name.clone(), config.to_string());
             }
         }
     }
@@ -306,9 +831,15 @@ pub fn setup_rules_registry(
                 registry.get_enabled_rules()
             ),
         );
+    } else if let Some(preset) = super::utilities::config::get_preset_name(args) {
+        // A preset passed on the command line replaces the rules config file
+        apply_preset(&mut registry, &preset, debug_level);
     } else if let Some(rules_config_path) = &config.rules_config {
         // Config file comes next
         apply_rules_from_config(&mut registry, rules_config_path, debug_level);
+    } else if let Some(preset) = &config.preset {
+        // Preset from sentinel.json when no rules config file is available
+        apply_preset(&mut registry, preset, debug_level);
     } else {
         // Default rules as fallback
         log(
@@ -318,14 +849,121 @@ pub fn setup_rules_registry(
         );
     }
 
+    // Test rules only run on test files, and only if enabled
+    registry.set_test_files(
+        config.analyze_tests.unwrap_or(false),
+        config.test_patterns.clone().unwrap_or_else(|| {
+            DEFAULT_TEST_PATTERNS
+                .iter()
+                .map(|pattern| pattern.to_string())
+                .collect()
+        }),
+    );
+
+    // Generated files are skipped unless explicitly included
+    registry.set_generated_files(
+        config.include_generated.unwrap_or(false),
+        config.generated_patterns.clone().unwrap_or_default(),
+    );
+
+    match super::utilities::config::get_column_unit(config) {
+        Ok(column_unit) => registry.set_column_unit(column_unit),
+        Err(err) => log(DebugLevel::Error, debug_level, &err),
+    }
+
+    // Debug output of single rules, with the artifacts next to the reports
+    let debug_rules = super::utilities::config::get_rule_debug(args);
+    if !debug_rules.is_empty() {
+        for rule_name in &debug_rules {
+            if registry.get_rule(rule_name).is_none() {
+                log(
+                    DebugLevel::Warn,
+                    debug_level,
+                    &format!("--rule-debug: unknown rule '{}'", rule_name),
+                );
+            }
+        }
+        let output_dir = super::utilities::config::get_output_dir(config, args);
+        registry.set_rule_debug(RuleDebug::new(
+            debug_rules,
+            std::path::Path::new(&output_dir).join("rule-debug"),
+        ));
+    }
+
+    // Narrow down the enabled rules by category and tag selectors
+    let (include, exclude) = super::utilities::config::get_rule_selectors(args);
+    if !include.is_empty() || !exclude.is_empty() {
+        registry.apply_rule_selectors(&include, &exclude);
+        let mut rules = registry.get_enabled_rules();
+        rules.sort();
+        log(
+            DebugLevel::Info,
+            debug_level,
+            &format!(
+                "Rules after applying selectors (include: {:?}, exclude: {:?}): {:?}",
+                include, exclude, rules
+            ),
+        );
+    }
+
+    // Composite rules only see the results of dependencies that are enabled
+    for (rule_name, dependency) in registry.missing_dependencies() {
+        log(
+            DebugLevel::Warn,
+            debug_level,
+            &format!(
+                "Rule '{}' depends on '{}', which is not enabled; enable it to get complete results",
+                rule_name, dependency
+            ),
+        );
+    }
+
     registry
 }
 
+/// Extract structured metadata from a diagnostic
+///
+/// Rules attach identifiers through the diagnostic error code, e.g.
+/// `OxcDiagnostic::error(..).with_error_code("CWE", "79")` becomes `"cwe": "CWE-79"`.
+pub fn diagnostic_metadata(diagnostic: &OxcDiagnostic) -> HashMap<String, Value> {
+    let mut metadata = HashMap::new();
+
+    if let (Some(scope), Some(number)) = (&diagnostic.code.scope, &diagnostic.code.number) {
+        if scope.eq_ignore_ascii_case("cwe") {
+            metadata.insert("cwe".to_string(), Value::from(format!("CWE-{}", number)));
+        } else {
+            metadata.insert(
+                "code".to_string(),
+                Value::from(format!("{}({})", scope, number)),
+            );
+        }
+    }
+
+    metadata
+}
+
 fn extract_position_info(error: &Error) -> (usize, usize) {
     let info = Info::new(error);
     return (info.start.line, info.start.column);
 }
 
+/// Apply the rules of a named preset
+pub fn apply_preset(registry: &mut RulesRegistry, preset: &str, debug_level: DebugLevel) {
+    match expand_preset(preset) {
+        Ok(enabled_rules) => {
+            configure_registry(registry, &enabled_rules);
+            let mut rules = registry.get_enabled_rules();
+            rules.sort();
+            log(
+                DebugLevel::Info,
+                debug_level,
+                &format!("Using preset '{}': {:?}", preset, rules),
+            );
+        }
+        Err(err) => log(DebugLevel::Error, debug_level, &err),
+    }
+}
+
 /// Apply rules from configuration file
 pub fn apply_rules_from_config(
     registry: &mut RulesRegistry,
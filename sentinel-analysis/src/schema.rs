@@ -0,0 +1,230 @@
+//! Output schema version and capabilities of the analyzer
+//!
+//! Rules configurations and `findings.json` are exchanged between separately released
+//! parts: the binary, the `rules.json` fetched by the GitHub action and the API that
+//! receives the findings. A rules configuration can declare the schema version it was
+//! written for and the capabilities it needs:
+//!
+//! ```json
+//! { "schema_version": 1, "requires": ["policies", "presets"], "rules": { ... } }
+//! ```
+//!
+//! A mismatch fails the run before any file is analyzed, instead of silently ignoring
+//! unknown rules or options. `findings.json` reports the same information, so consumers
+//! can check it before reading the findings, and `scoper --capabilities` prints it.
+//!
+//! `scoper schema` prints the JSON Schema of `findings.json`. Readers of `findings.json`
+//! accept the current and the previous schema version through `upgrade_findings`, so a
+//! version bump does not break dashboards reading the reports of older runs.
+//!
+//! Every results file also embeds the build of the analyzer that wrote it, so a run can
+//! be reproduced with the same binary; `scoper version --json` prints the same block.
+
+use crate::cache::CACHE_VERSION;
+use crate::rules::RULE_API_VERSION;
+use serde::{Deserialize, Serialize};
+use serde_json::Value;
+use std::fs;
+
+/// Version of the rules configuration and findings.json format
+///
+/// Bumped on incompatible changes only; additions are announced as capabilities.
+pub const SCHEMA_VERSION: u32 = 1;
+
+/// Oldest version of findings.json that `upgrade_findings` can read
+///
+/// Version 0 is findings.json written before the `schema` block existed.
+pub const MIN_SCHEMA_VERSION: u32 = 0;
+
+/// JSON Schema of findings.json, printed by `scoper schema`
+pub const FINDINGS_JSON_SCHEMA: &str = include_str!("../schemas/findings.schema.json");
+
+/// Features of this analyzer that configurations and consumers can rely on
+pub const CAPABILITIES: &[&str] = &[
+    "loc-info",
+    "categories",
+    "metadata",
+    "rule-versions",
+    "fingerprints",
+    "by-directory",
+    "hotspots",
+    "templates",
+    "outputs",
+    "skipped-files",
+    "column-units",
+    "presets",
+    "rule-selectors",
+    "policies",
+    "architecture-boundaries",
+    "signal-migration",
+    "angular-graph",
+    "chunks",
+    "embeddings",
+    "ai-suggestions",
+    "escalations",
+    "truncation",
+    "editor-links",
+    "blame",
+    "quality-gates",
+    "message-keys",
+    "taxonomies",
+    "counters",
+    "counter-alerts",
+];
+
+/// Schema version and capabilities reported by the analyzer
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct SchemaInfo {
+    pub schema_version: u32,
+    pub analyzer_version: String,
+    pub capabilities: Vec<String>,
+}
+
+impl SchemaInfo {
+    /// Get the schema information of this binary
+    pub fn current() -> Self {
+        Self {
+            schema_version: SCHEMA_VERSION,
+            analyzer_version: env!("CARGO_PKG_VERSION").to_string(),
+            capabilities: CAPABILITIES.iter().map(|c| c.to_string()).collect(),
+        }
+    }
+}
+
+/// Build metadata and format versions of the analyzer
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+pub struct BuildInfo {
+    pub version: String,
+    /// Git commit the binary was built from, `unknown` outside of a checkout
+    pub commit: String,
+    /// Build time as RFC 3339, taken from `SOURCE_DATE_EPOCH` if set
+    pub build_date: String,
+    /// The parser is linked into the binary, so its crate version is its protocol
+    pub parser: String,
+    pub parser_version: String,
+    pub schema_version: u32,
+    pub cache_version: u32,
+    pub rule_api_version: u32,
+}
+
+impl BuildInfo {
+    /// Get the build information of this binary
+    pub fn current() -> Self {
+        let build_date = env!("SCOPER_BUILD_TIMESTAMP")
+            .parse::<i64>()
+            .ok()
+            .and_then(|secs| chrono::DateTime::from_timestamp(secs, 0))
+            .map(|date| date.to_rfc3339())
+            .unwrap_or_else(|| "unknown".to_string());
+        Self {
+            version: env!("CARGO_PKG_VERSION").to_string(),
+            commit: env!("SCOPER_GIT_COMMIT").to_string(),
+            build_date,
+            parser: "oxc_parser".to_string(),
+            parser_version: env!("SCOPER_PARSER_VERSION").to_string(),
+            schema_version: SCHEMA_VERSION,
+            cache_version: CACHE_VERSION,
+            rule_api_version: RULE_API_VERSION,
+        }
+    }
+}
+
+/// Check that a rules configuration can be handled by this binary
+///
+/// Configurations without `schema_version` are accepted for compatibility with files
+/// written before the field existed.
+pub fn check_compatibility(config: &Value) -> Result<(), String> {
+    if let Some(version) = config.get("schema_version") {
+        let version = version
+            .as_u64()
+            .ok_or_else(|| format!("Invalid schema_version {}, expected a number", version))?;
+        if version != SCHEMA_VERSION as u64 {
+            return Err(format!(
+                "The rules configuration uses schema version {}, but scoper {} supports version {}. \
+                 Use a scoper release matching the configuration or a configuration from the \
+                 scoper {} release.",
+                version,
+                env!("CARGO_PKG_VERSION"),
+                SCHEMA_VERSION,
+                env!("CARGO_PKG_VERSION")
+            ));
+        }
+    }
+
+    let missing: Vec<&str> = config
+        .get("requires")
+        .and_then(Value::as_array)
+        .map(|required| {
+            required
+                .iter()
+                .filter_map(Value::as_str)
+                .filter(|capability| !CAPABILITIES.contains(capability))
+                .collect()
+        })
+        .unwrap_or_default();
+    if !missing.is_empty() {
+        return Err(format!(
+            "The rules configuration requires capabilities not supported by scoper {}: {}. \
+             Update scoper or remove them from 'requires'.",
+            env!("CARGO_PKG_VERSION"),
+            missing.join(", ")
+        ));
+    }
+
+    Ok(())
+}
+
+/// Bring findings.json of a supported older schema version to the current version
+///
+/// Exports without a `schema` block are version 0; their missing fields are filled by
+/// the serde defaults of `FindingsExport` when deserialized. Exports newer than this
+/// binary are rejected instead of being misread.
+pub fn upgrade_findings(mut export: Value) -> Result<Value, String> {
+    let version = match export
+        .get("schema")
+        .and_then(|schema| schema.get("schema_version"))
+    {
+        None => 0,
+        Some(version) => version
+            .as_u64()
+            .ok_or_else(|| format!("Invalid schema_version {}, expected a number", version))?,
+    };
+    if !(MIN_SCHEMA_VERSION as u64..=SCHEMA_VERSION as u64).contains(&version) {
+        return Err(format!(
+            "The findings use schema version {}, but scoper {} reads versions {} to {}",
+            version,
+            env!("CARGO_PKG_VERSION"),
+            MIN_SCHEMA_VERSION,
+            SCHEMA_VERSION
+        ));
+    }
+
+    if version == 0 {
+        let object = export
+            .as_object_mut()
+            .ok_or_else(|| "The findings are not a JSON object".to_string())?;
+        object.insert(
+            "schema".to_string(),
+            serde_json::json!({
+                "schema_version": SCHEMA_VERSION,
+                "analyzer_version": "unknown",
+                "capabilities": [],
+            }),
+        );
+    }
+    Ok(export)
+}
+
+/// Check the rules configuration file at a path
+///
+/// Files that cannot be read or parsed pass, their errors are reported when the rules
+/// are loaded.
+pub fn check_rules_file(path: &str) -> Result<(), String> {
+    match fs::read_to_string(path)
+        .ok()
+        .and_then(|content| serde_json::from_str::<Value>(&content).ok())
+    {
+        Some(config) => check_compatibility(&config).map_err(|e| format!("{}: {}", path, e)),
+        None => Ok(()),
+    }
+}
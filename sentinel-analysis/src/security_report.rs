@@ -0,0 +1,108 @@
+//! Coverage of security standards
+//!
+//! `--format security` writes `security.json`: the entries of the security standards, CWE,
+//! OWASP ASVS and the OWASP Top 10, that the enabled rules check for, each with its rules
+//! and the findings of this run. Entries without findings are listed as well, so a security
+//! team can tell an entry that was checked and is clean from one no rule covers. Rules
+//! declare their entries with `Rule::taxonomies`.
+
+use crate::exporter::FindingsExport;
+use crate::output::{OutputSpec, OutputWriter};
+use crate::rules::Taxonomy;
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, BTreeSet};
+
+/// An entry of a standard with the rules checking for it
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct TaxonCoverage {
+    pub taxonomy: Taxonomy,
+    /// ID of the entry, e.g. `CWE-79` or `V5.3.3`
+    pub id: String,
+    pub rules: Vec<String>,
+    pub findings: usize,
+    pub findings_by_severity: BTreeMap<String, usize>,
+}
+
+/// The report written by `--format security`
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct SecurityReport {
+    pub analyzer_version: String,
+    pub timestamp: String,
+    /// Entries by standard and ID
+    pub coverage: Vec<TaxonCoverage>,
+}
+
+/// Map the findings of an export onto the standard entries of their rules
+pub fn build_security_report(export: &FindingsExport) -> SecurityReport {
+    let mut coverage: BTreeMap<(Taxonomy, &str), TaxonCoverage> = BTreeMap::new();
+    for (rule, mappings) in &export.summary.rule_taxonomies {
+        for mapping in mappings {
+            coverage
+                .entry((mapping.taxonomy, &mapping.id))
+                .or_insert_with(|| TaxonCoverage {
+                    taxonomy: mapping.taxonomy,
+                    id: mapping.id.clone(),
+                    rules: Vec::new(),
+                    findings: 0,
+                    findings_by_severity: BTreeMap::new(),
+                })
+                .rules
+                .push(rule.clone());
+        }
+    }
+
+    for finding in &export.findings {
+        let Some(mappings) = export.summary.rule_taxonomies.get(&finding.rule) else {
+            continue;
+        };
+        // A rule can map to an entry twice, e.g. by its own CWE and a broader one
+        let keys: BTreeSet<(Taxonomy, &str)> = mappings
+            .iter()
+            .map(|mapping| (mapping.taxonomy, mapping.id.as_str()))
+            .collect();
+        for key in keys {
+            if let Some(entry) = coverage.get_mut(&key) {
+                entry.findings += 1;
+                *entry
+                    .findings_by_severity
+                    .entry(finding.severity.clone())
+                    .or_insert(0) += 1;
+            }
+        }
+    }
+
+    let mut coverage: Vec<TaxonCoverage> = coverage.into_values().collect();
+    for entry in &mut coverage {
+        entry.rules.sort();
+        entry.rules.dedup();
+    }
+    SecurityReport {
+        analyzer_version: export.schema.analyzer_version.clone(),
+        timestamp: export.summary.timestamp.clone(),
+        coverage,
+    }
+}
+
+/// Writes the coverage of the security standards
+pub struct SecurityWriter;
+
+impl OutputWriter for SecurityWriter {
+    fn format(&self) -> &'static str {
+        "security"
+    }
+
+    fn default_path(&self, _spec: &OutputSpec, output_dir: &str) -> Result<String, String> {
+        Ok(format!("{}/security.json", output_dir))
+    }
+
+    fn render(&self, export: &FindingsExport, _spec: &OutputSpec) -> Result<String, String> {
+        if export.summary.rule_taxonomies.is_empty() {
+            return Err(
+                "No enabled rule maps to a security standard, enable them e.g. with --rules-include security,secrets"
+                    .to_string(),
+            );
+        }
+        serde_json::to_string_pretty(&build_security_report(export))
+            .map_err(|e| format!("Failed to serialize security report: {}", e))
+    }
+}
@@ -0,0 +1,359 @@
+//! Embedding the analyzer in other programs
+//!
+//! `Sentinel` runs a complete analysis from a `Config`: it checks the rules configuration,
+//! sets up the rules, finds the files, reuses and updates the rule cache, runs the
+//! project-level rules and normalizes the file paths, in the same order as the `scoper`
+//! binary. The result can be inspected directly or written with `Analysis::export`.
+//!
+//! ```no_run
+//! use scoper::Sentinel;
+//! use scoper::utilities::config::Config;
+//!
+//! let analysis = Sentinel::new(Config::load()).with_target("./src").run()?;
+//! println!("{} findings", analysis.findings());
+//! # Ok::<(), String>(())
+//! ```
+//!
+//! The size of the global thread pool is not changed; call
+//! `utilities::threading::configure_thread_pool` first to apply `threads`.
+//!
+//! `with_sources` runs the same pipeline on source text held in memory instead of the
+//! files of a directory, so tests can use fixtures without writing them to disk:
+//!
+//! ```no_run
+//! use scoper::Sentinel;
+//! use scoper::utilities::config::Config;
+//!
+//! let analysis = Sentinel::new(Config::default())
+//!     .with_sources(vec![("src/app.ts".to_string(), "debugger;".to_string())])
+//!     .run()?;
+//! # Ok::<(), String>(())
+//! ```
+
+use crate::FileAnalysisResult;
+use crate::analyzer::{BatchOptions, process_files_with_cache, process_sources};
+use crate::blame::{annotate_blame, apply_blame_policy};
+use crate::cache::{RESULTS_FILE, RuleCache};
+use crate::counters::{
+    CounterAlertOutcome, evaluate_counter_alerts, previous_counters, take_counters,
+};
+use crate::escalation::{Escalation, apply_escalations, previous_counts};
+use crate::filters::filter_results;
+use crate::inspect::{FileDetails, analyze_file_detailed};
+use crate::metrics::{Metrics, aggregate_metrics, export_results};
+use crate::new_code::keep_new_code;
+use crate::quality_gates::{QualityGateReport, add_gates, evaluate_quality_gates, previous_errors};
+use crate::rules_registry::{RulesRegistry, setup_rules_registry};
+use crate::schema::check_rules_file;
+use crate::suppressions::{Baseline, DEFAULT_SUPPRESSIONS_PATH, apply_suppressions};
+use crate::utilities::config::{
+    Config, get_blame, get_blame_policy, get_cache_path, get_changed_lines, get_finding_filter,
+    get_locale, get_output_dir, get_path_base, get_previous_results, get_target_path,
+};
+use crate::utilities::file_utils::find_files;
+use crate::utilities::paths::PathBase;
+use crate::utilities::{DebugLevel, log};
+use std::collections::BTreeMap;
+use std::path::Path;
+use std::sync::Arc;
+use std::time::Duration;
+
+/// An analyzer configured for one project
+pub struct Sentinel {
+    config: Config,
+    args: Vec<String>,
+    target: Option<String>,
+    /// Source text analyzed instead of the files of the target, by path
+    sources: Option<Vec<(String, String)>>,
+    debug_level: DebugLevel,
+}
+
+/// Results of an analysis run
+pub struct Analysis {
+    /// Results per file, with paths relative to `path_base`, followed by the project-level
+    /// findings if there are any
+    pub results: Vec<FileAnalysisResult>,
+    pub metrics: Metrics,
+    pub path_base: PathBase,
+    pub registry: Arc<RulesRegistry>,
+    /// Rules raised to a higher severity because their findings grew
+    pub escalations: Vec<Escalation>,
+    /// Outcome of the configured quality gates
+    pub quality_gate: Option<QualityGateReport>,
+    /// Matches of the count-only rules per counter, which are not findings
+    pub counters: BTreeMap<String, usize>,
+    /// Outcome of the configured counter alerts
+    pub counter_alerts: Vec<CounterAlertOutcome>,
+}
+
+impl Sentinel {
+    /// Create an analyzer for a configuration
+    pub fn new(config: Config) -> Self {
+        let debug_level = config.debug_level.unwrap_or(DebugLevel::Error);
+        Self {
+            config,
+            args: Vec::new(),
+            target: None,
+            sources: None,
+            debug_level,
+        }
+    }
+
+    /// Apply command line arguments on top of the configuration, e.g. `--enable-rule`
+    pub fn with_args(mut self, args: Vec<String>) -> Self {
+        self.args = args;
+        self
+    }
+
+    /// Analyze this directory instead of the configured `path`
+    pub fn with_target(mut self, target: &str) -> Self {
+        self.target = Some(target.to_string());
+        self
+    }
+
+    /// Analyze this source text instead of the files of the target directory
+    ///
+    /// The rule cache is not used, and paths are relative to the working directory unless
+    /// `path_base` is configured.
+    pub fn with_sources(mut self, sources: Vec<(String, String)>) -> Self {
+        self.sources = Some(sources);
+        self
+    }
+
+    pub fn with_debug_level(mut self, debug_level: DebugLevel) -> Self {
+        self.debug_level = debug_level;
+        self
+    }
+
+    /// Get the configuration the analyzer runs with
+    pub fn config(&self) -> &Config {
+        &self.config
+    }
+
+    /// Analyze a single file and keep the syntax tree, declarations, imports, exports and
+    /// timings along with its findings, e.g. for editor integrations
+    ///
+    /// The file is read from disk even if sources are set; suppressions and the rule cache
+    /// are not applied.
+    pub fn analyze_file_detailed(&self, file_path: &str) -> Result<FileDetails, String> {
+        if let Some(rules_config_path) = &self.config.rules_config {
+            check_rules_file(rules_config_path)?;
+        }
+        let registry = setup_rules_registry(&self.config, &self.args, self.debug_level);
+        analyze_file_detailed(file_path, &registry)
+    }
+
+    /// Analyze the target directory
+    ///
+    /// Fails if the rules configuration was written for another schema version.
+    pub fn run(&self) -> Result<Analysis, String> {
+        if let Some(rules_config_path) = &self.config.rules_config {
+            check_rules_file(rules_config_path)?;
+        }
+        let filter = get_finding_filter(&self.args);
+        filter.validate()?;
+        get_locale(&self.config, &self.args)?;
+
+        let registry = Arc::new(setup_rules_registry(
+            &self.config,
+            &self.args,
+            self.debug_level,
+        ));
+
+        let target = match &self.target {
+            Some(target) => target.clone(),
+            None => get_target_path(&self.config, &self.args),
+        };
+        let (mut files, scan_duration) = match &self.sources {
+            Some(sources) => (
+                sources.iter().map(|(path, _)| path.clone()).collect(),
+                Duration::default(),
+            ),
+            None => find_files(&target, self.debug_level),
+        };
+        if self.config.exclude_tests.unwrap_or(false) {
+            files.retain(|file| !registry.is_test_file(file));
+        }
+
+        let path_base = get_path_base(&self.config, &target);
+        let blame_policy = get_blame_policy(&self.args, &path_base)?;
+        let changed_lines = get_changed_lines(&self.config, &self.args, &path_base)?;
+        let cache_path =
+            get_cache_path(&self.config, &self.args).filter(|_| self.sources.is_none());
+        let previous_path =
+            get_previous_results(&self.config, &self.args).filter(|_| self.sources.is_none());
+        // The results of a previous run stand in for a missing rule cache, e.g. in fresh CI
+        let cache_source = cache_path
+            .as_deref()
+            .filter(|path| Path::new(path).exists())
+            .or(previous_path.as_deref())
+            .or(cache_path.as_deref());
+        let mut cache =
+            cache_source.map(|path| RuleCache::load(path, path_base.clone(), self.debug_level));
+        let (mut results, analysis_duration) = match &self.sources {
+            Some(sources) => {
+                let sources: Vec<(String, String)> = sources
+                    .iter()
+                    .filter(|(path, _)| files.contains(path))
+                    .cloned()
+                    .collect();
+                process_sources(&sources, &registry, self.debug_level)
+            }
+            None => process_files_with_cache(
+                &files,
+                &registry,
+                cache.as_ref(),
+                BatchOptions::from_config(&self.config),
+                self.debug_level,
+            ),
+        };
+
+        if let Some(cache) = cache.as_mut() {
+            cache.update(&results, &registry);
+            if let Some(path) = cache_path.as_deref() {
+                cache.save(path, self.debug_level);
+            }
+            let results_path =
+                Path::new(&get_output_dir(&self.config, &self.args)).join(RESULTS_FILE);
+            cache.save(&results_path.to_string_lossy(), self.debug_level);
+        }
+
+        let metrics = aggregate_metrics(&results, scan_duration, analysis_duration);
+
+        // Project-level findings are added after the metrics, which only cover real files
+        if let Some(project_result) = registry.run_project_rules(&results) {
+            results.push(project_result);
+        }
+
+        // Reports use paths relative to the path base, so they do not depend on the machine
+        path_base.normalize_results(&mut results);
+
+        // Count-only rules track metrics, so their matches are never reported as findings
+        let counters = take_counters(&mut results, &registry);
+
+        // Suppressed findings are dropped before anything counts them
+        let baseline = Baseline::load(
+            self.config
+                .suppressions
+                .as_deref()
+                .unwrap_or(DEFAULT_SUPPRESSIONS_PATH),
+        )?;
+        let suppressions = apply_suppressions(
+            &mut results,
+            &baseline,
+            chrono::Utc::now().date_naive(),
+            self.debug_level,
+        );
+        log(
+            DebugLevel::Info,
+            self.debug_level,
+            &format!(
+                "{} findings suppressed, {} reported again after their suppression expired",
+                suppressions.suppressed, suppressions.expired
+            ),
+        );
+
+        // Only the findings that are reported are blamed, which is one git call per file
+        if get_blame(&self.config, &self.args) {
+            annotate_blame(&mut results, &path_base, self.debug_level);
+        }
+
+        // Compared with the previous run before its findings.json is overwritten by export
+        let escalations = match &self.config.escalation {
+            Some(policies) => {
+                let output_dir = get_output_dir(&self.config, &self.args);
+                let previous = previous_counts(&output_dir).unwrap_or_default();
+                apply_escalations(&mut results, policies, &previous)?
+            }
+            None => Vec::new(),
+        };
+        let counter_alerts = match &self.config.counter_alerts {
+            Some(alerts) => {
+                let previous = previous_counters(&get_output_dir(&self.config, &self.args));
+                evaluate_counter_alerts(&counters, alerts, previous.as_ref())
+            }
+            None => Vec::new(),
+        };
+
+        // Filters only slice the reported findings, so escalations still see all of them
+        let mut filtered =
+            filter_results(&mut results, &filter) + apply_blame_policy(&mut results, &blame_policy);
+        if let Some(changed_lines) = &changed_lines {
+            filtered += keep_new_code(&mut results, changed_lines, &path_base);
+        }
+        if filtered > 0 {
+            log(
+                DebugLevel::Info,
+                self.debug_level,
+                &format!("{} findings left out by the filters", filtered),
+            );
+        }
+
+        // Gates see the reported findings; without --new-code-only, new errors are the ones
+        // beyond the previous run
+        let quality_gate = self.config.quality_gates.as_ref().map(|gates| {
+            let previous = match changed_lines {
+                Some(_) => 0,
+                None => previous_errors(&get_output_dir(&self.config, &self.args)).unwrap_or(0),
+            };
+            evaluate_quality_gates(&results, gates, previous)
+        });
+        let quality_gate = add_gates(
+            quality_gate,
+            counter_alerts
+                .iter()
+                .filter(|outcome| outcome.fail_quality_gate)
+                .map(CounterAlertOutcome::as_gate)
+                .collect(),
+        );
+
+        log(
+            DebugLevel::Info,
+            self.debug_level,
+            &format!("Analyzed {} files in {}", files.len(), target),
+        );
+        Ok(Analysis {
+            results,
+            metrics,
+            path_base,
+            registry,
+            escalations,
+            quality_gate,
+            counters,
+            counter_alerts,
+        })
+    }
+}
+
+impl Analysis {
+    /// Get the total number of findings
+    pub fn findings(&self) -> usize {
+        self.results
+            .iter()
+            .map(|result| result.diagnostics.len())
+            .sum()
+    }
+
+    /// Check if a quality gate is configured and failed
+    pub fn failed_quality_gate(&self) -> bool {
+        self.quality_gate
+            .as_ref()
+            .is_some_and(|quality_gate| !quality_gate.passed)
+    }
+
+    /// Write findings.json and the other configured reports to the output directory
+    pub fn export(&self, config: &Config, debug_level: DebugLevel) {
+        export_results(
+            config,
+            &self.metrics,
+            &self.results,
+            &self.path_base,
+            &self.registry,
+            &self.escalations,
+            self.quality_gate.as_ref(),
+            &self.counters,
+            &self.counter_alerts,
+            debug_level,
+        );
+    }
+}
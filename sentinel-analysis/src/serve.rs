@@ -0,0 +1,847 @@
+//! Read-only results API for browsing a run without the backend
+//!
+//! `scoper serve-results --dir <OUTPUT_DIR>` serves the reports of a run over HTTP. The
+//! `/api/v1` routes answer with the shapes the sentinel-frontend reads from the backend,
+//! presenting the run as a single completed analysis job of a single project:
+//!
+//! - `GET /api/v1/projects`
+//! - `GET /api/v1/analysis_jobs`
+//! - `GET /api/v1/violations`, filtered by `rule_id`, `rule_name` and `file_path`
+//! - `GET /api/v1/violations/time_series`
+//! - `GET /api/v1/files_with_violations`
+//!
+//! The contract of the routes is served as an OpenAPI 3 document under `/api/openapi.json`,
+//! generated from the same route table that answers the requests, and browsable with
+//! Swagger UI under `/api/docs`.
+//!
+//! The report files themselves (`findings.json`, `angular-graph.json`, ...) are served
+//! as-is under `/reports/<file>`. The reports are read on every request, so a new run
+//! in the same directory shows up without restarting the server.
+//!
+//! Every request passes the same middleware:
+//!
+//! - request ids: the `X-Request-Id` of the request, or a new one, is echoed in the response
+//! - CORS: browsers may call the API from the origins of `serve.cors_origins` (or
+//!   `--cors-origin`), only from the same origin if none is configured
+//! - panic recovery: a route that panics answers 500 instead of stopping the server
+//! - access log: one JSON line per request on stdout, unless `serve.access_log` is false
+
+use crate::exporter::{FindingEntry, FindingsExport};
+use crate::schema::upgrade_findings;
+use crate::utilities::{DebugLevel, log};
+use serde_json::{Value, json};
+use std::collections::BTreeMap;
+use std::fs;
+use std::io::{BufRead, BufReader, Read, Write};
+use std::net::{TcpListener, TcpStream};
+use std::panic::{AssertUnwindSafe, catch_unwind};
+use std::path::{Path, PathBuf};
+use std::sync::atomic::{AtomicU64, Ordering};
+use std::sync::{Arc, Mutex, mpsc};
+use std::thread;
+use std::time::{Duration, Instant};
+
+/// Port the server listens on by default
+pub const DEFAULT_PORT: u16 = 3001;
+
+/// Page size of paginated routes without `per_page`
+const DEFAULT_PER_PAGE: usize = 25;
+
+/// Largest page size of paginated routes, as in the backend
+const MAX_PER_PAGE: usize = 100;
+
+/// Id of the project and analysis job the run is presented as
+const RUN_ID: u64 = 1;
+
+/// Longest request id taken over from a request
+const MAX_REQUEST_ID_LENGTH: usize = 128;
+
+/// Most bytes read of the request line and the headers of a request
+const MAX_REQUEST_HEAD_BYTES: u64 = 16 * 1024;
+
+/// Most headers read of a request
+const MAX_HEADERS: usize = 64;
+
+/// Time a client has to send its request or read the response
+const IO_TIMEOUT: Duration = Duration::from_secs(10);
+
+/// Number of threads answering connections
+const WORKERS: usize = 8;
+
+/// Number of requests answered, part of the generated request ids
+static REQUEST_COUNTER: AtomicU64 = AtomicU64::new(0);
+
+/// Options of the results server
+#[derive(Debug, Clone)]
+pub struct ServeOptions {
+    /// Origins allowed to call the API from a browser, none if empty
+    pub cors_origins: Vec<String>,
+    /// Print a JSON line per request
+    pub access_log: bool,
+}
+
+impl Default for ServeOptions {
+    fn default() -> Self {
+        Self {
+            cors_origins: Vec::new(),
+            access_log: true,
+        }
+    }
+}
+
+/// A request, with lowercase header names
+struct Request {
+    method: String,
+    target: String,
+    headers: BTreeMap<String, String>,
+}
+
+/// An HTTP response
+struct Response {
+    status: u16,
+    content_type: &'static str,
+    body: Vec<u8>,
+}
+
+impl Response {
+    fn json(status: u16, value: &Value) -> Self {
+        Self {
+            status,
+            content_type: "application/json",
+            body: value.to_string().into_bytes(),
+        }
+    }
+
+    fn error(status: u16, message: &str) -> Self {
+        Self::json(status, &json!({ "error": message }))
+    }
+}
+
+/// Decode a percent-encoded query component
+fn decode_component(value: &str) -> String {
+    let bytes = value.as_bytes();
+    let mut decoded = Vec::with_capacity(bytes.len());
+    let mut i = 0;
+    while i < bytes.len() {
+        match bytes[i] {
+            b'+' => decoded.push(b' '),
+            b'%' if i + 2 < bytes.len() => {
+                let hex = std::str::from_utf8(&bytes[i + 1..i + 3]).ok();
+                match hex.and_then(|hex| u8::from_str_radix(hex, 16).ok()) {
+                    Some(byte) => {
+                        decoded.push(byte);
+                        i += 2;
+                    }
+                    None => decoded.push(b'%'),
+                }
+            }
+            byte => decoded.push(byte),
+        }
+        i += 1;
+    }
+    String::from_utf8_lossy(&decoded).into_owned()
+}
+
+/// Split a request target into its path and query parameters
+fn parse_target(target: &str) -> (&str, BTreeMap<String, String>) {
+    let (path, query) = target.split_once('?').unwrap_or((target, ""));
+    let params = query
+        .split('&')
+        .filter(|pair| !pair.is_empty())
+        .map(|pair| {
+            let (key, value) = pair.split_once('=').unwrap_or((pair, ""));
+            (decode_component(key), decode_component(value))
+        })
+        .collect();
+    (path, params)
+}
+
+/// Read findings.json of the output directory
+fn load_findings(dir: &Path) -> Result<FindingsExport, String> {
+    let path = dir.join("findings.json");
+    let content = fs::read_to_string(&path)
+        .map_err(|e| format!("Failed to read {}: {}", path.display(), e))?;
+    serde_json::from_str::<Value>(&content)
+        .map_err(|e| e.to_string())
+        .and_then(upgrade_findings)
+        .and_then(|export| serde_json::from_value(export).map_err(|e| e.to_string()))
+        .map_err(|e| format!("Failed to parse {}: {}", path.display(), e))
+}
+
+/// Get a page of items and the pagination metadata
+fn paginate<T>(items: Vec<T>, params: &BTreeMap<String, String>) -> (Vec<T>, Value) {
+    let per_page = params
+        .get("per_page")
+        .and_then(|n| n.parse::<usize>().ok())
+        .filter(|n| *n > 0)
+        .unwrap_or(DEFAULT_PER_PAGE)
+        .min(MAX_PER_PAGE);
+    let page = params
+        .get("page")
+        .and_then(|n| n.parse::<usize>().ok())
+        .filter(|n| *n > 0)
+        .unwrap_or(1);
+    let total_count = items.len();
+    let page_items = items
+        .into_iter()
+        .skip((page - 1) * per_page)
+        .take(per_page)
+        .collect();
+    let meta = json!({
+        "total_count": total_count,
+        "current_page": page,
+        "total_pages": total_count.div_ceil(per_page),
+    });
+    (page_items, meta)
+}
+
+/// The run as a project
+fn project(dir: &Path, export: &FindingsExport) -> Value {
+    let name = fs::canonicalize(dir)
+        .ok()
+        .and_then(|dir| {
+            dir.file_name()
+                .map(|name| name.to_string_lossy().into_owned())
+        })
+        .unwrap_or_else(|| dir.display().to_string());
+    json!({
+        "id": RUN_ID,
+        "name": name,
+        "repository_url": "",
+        "created_at": export.summary.timestamp,
+        "updated_at": export.summary.timestamp,
+    })
+}
+
+fn projects_route(dir: &Path, export: &FindingsExport) -> Value {
+    json!({ "data": { "projects": [project(dir, export)] }, "meta": null })
+}
+
+fn analysis_jobs_route(dir: &Path, export: &FindingsExport) -> Value {
+    let summary = &export.summary;
+    let files = summary.files_processed.max(1) as f64;
+    json!({
+        "data": [{
+            "id": RUN_ID,
+            "project_id": RUN_ID,
+            "status": "completed",
+            "total_files": summary.files_processed,
+            "total_matches": summary.total_findings,
+            "rules_matched": summary.findings_by_rule.len(),
+            "created_at": summary.timestamp,
+            "updated_at": summary.timestamp,
+            "duration": summary.total_duration_ms,
+            "files_per_second_wall_time": summary.files_per_second_wall_time,
+            "cumulative_processing_time_ms": summary.analysis_duration_ms,
+            "avg_time_per_file_ms": summary.analysis_duration_ms as f64 / files,
+            "files_per_second_cpu_time": summary.files_per_second_wall_time,
+            "parallel_cores_used": summary.parallel_cores_used,
+            "parallel_speedup_factor": summary.parallel_cores_used as f64
+                * summary.parallel_efficiency_percent
+                / 100.0,
+            "parallel_efficiency_percent": summary.parallel_efficiency_percent,
+            "project": project(dir, export),
+        }],
+        "meta": { "current_page": 1, "total_pages": 1, "total_count": 1 },
+    })
+}
+
+/// Whether a finding passes the filters of the violations route
+fn matches_filters(finding: &FindingEntry, params: &BTreeMap<String, String>) -> bool {
+    params
+        .get("rule_id")
+        .is_none_or(|rule| &finding.rule == rule)
+        && params
+            .get("rule_name")
+            .is_none_or(|rule| &finding.rule == rule)
+        && params
+            .get("file_path")
+            .is_none_or(|file| finding.file.contains(file.as_str()))
+}
+
+fn violations_route(export: &FindingsExport, params: &BTreeMap<String, String>) -> Value {
+    let violations = export
+        .findings
+        .iter()
+        .enumerate()
+        .filter(|(_, finding)| matches_filters(finding, params))
+        .map(|(i, finding)| {
+            json!({
+                "id": i + 1,
+                "rule_id": finding.rule,
+                "rule_name": finding.rule,
+                "line_number": finding.line,
+                "column": finding.column,
+                "match_text": finding.message,
+                "file_with_violations": { "file_path": finding.file },
+            })
+        })
+        .collect();
+    let (data, mut meta) = paginate(violations, params);
+    meta["analysis_job_id"] = json!(RUN_ID);
+    json!({ "data": data, "meta": meta })
+}
+
+/// A single point, the date of the run and its number of findings
+fn time_series_route(export: &FindingsExport) -> Value {
+    let date = export
+        .summary
+        .timestamp
+        .get(..10)
+        .unwrap_or(export.summary.timestamp.as_str());
+    json!({ "data": [{ "date": date, "count": export.summary.total_findings }] })
+}
+
+fn files_with_violations_route(
+    export: &FindingsExport,
+    params: &BTreeMap<String, String>,
+) -> Value {
+    let mut files: Vec<&str> = export.findings.iter().map(|f| f.file.as_str()).collect();
+    files.sort();
+    files.dedup();
+    let files = files
+        .into_iter()
+        .enumerate()
+        .map(|(i, file)| {
+            json!({
+                "id": i + 1,
+                "file_path": file,
+                "analysis_job_id": RUN_ID,
+                "display_path": file,
+                "job_status": "completed",
+            })
+        })
+        .collect();
+    let (data, mut meta) = paginate(files, params);
+    meta["sort"] = json!("file_path");
+    meta["direction"] = json!("asc");
+    json!({ "data": data, "meta": meta })
+}
+
+/// Query parameters of the paginated routes
+const PAGE_PARAMS: &[QueryParam] = &[
+    ("page", "integer", "Page to get, starting at 1"),
+    ("per_page", "integer", "Items per page (default: 25, at most 100)"),
+];
+
+/// A query parameter: name, JSON schema type and description
+type QueryParam = (&'static str, &'static str, &'static str);
+
+/// A GET route of the API, which is also documented in the OpenAPI document
+struct ApiRoute {
+    path: &'static str,
+    summary: &'static str,
+    params: &'static [&'static [QueryParam]],
+    /// JSON schema of the response
+    schema: fn() -> Value,
+    handler: fn(&Path, &FindingsExport, &BTreeMap<String, String>) -> Value,
+}
+
+/// JSON schema of a list response with pagination metadata
+fn page_schema(item: Value) -> Value {
+    json!({
+        "type": "object",
+        "properties": {
+            "data": { "type": "array", "items": item },
+            "meta": {
+                "type": "object",
+                "properties": {
+                    "total_count": { "type": "integer" },
+                    "current_page": { "type": "integer" },
+                    "total_pages": { "type": "integer" },
+                },
+            },
+        },
+    })
+}
+
+/// JSON schema of the run presented as a project
+fn project_schema() -> Value {
+    json!({
+        "type": "object",
+        "properties": {
+            "id": { "type": "integer" },
+            "name": { "type": "string" },
+            "repository_url": { "type": "string" },
+            "created_at": { "type": "string", "format": "date-time" },
+            "updated_at": { "type": "string", "format": "date-time" },
+        },
+    })
+}
+
+/// The routes answered with the findings of the run
+const API_ROUTES: &[ApiRoute] = &[
+    ApiRoute {
+        path: "/api/v1/projects",
+        summary: "List the projects, the analyzed directory as the only one",
+        params: &[],
+        schema: || {
+            json!({
+                "type": "object",
+                "properties": {
+                    "data": {
+                        "type": "object",
+                        "properties": {
+                            "projects": { "type": "array", "items": project_schema() },
+                        },
+                    },
+                },
+            })
+        },
+        handler: |dir, export, _| projects_route(dir, export),
+    },
+    ApiRoute {
+        path: "/api/v1/analysis_jobs",
+        summary: "List the analysis jobs, the run as the only completed one",
+        params: &[],
+        schema: || {
+            page_schema(json!({
+                "type": "object",
+                "properties": {
+                    "id": { "type": "integer" },
+                    "project_id": { "type": "integer" },
+                    "status": { "type": "string", "enum": ["completed"] },
+                    "total_files": { "type": "integer" },
+                    "total_matches": { "type": "integer" },
+                    "rules_matched": { "type": "integer" },
+                    "duration": { "type": "integer" },
+                    "project": project_schema(),
+                },
+            }))
+        },
+        handler: |dir, export, _| analysis_jobs_route(dir, export),
+    },
+    ApiRoute {
+        path: "/api/v1/violations",
+        summary: "List the findings of the run",
+        params: &[
+            &[
+                ("rule_id", "string", "Only findings of this rule"),
+                ("rule_name", "string", "Only findings of this rule"),
+                (
+                    "file_path",
+                    "string",
+                    "Only findings in files containing this text",
+                ),
+            ],
+            PAGE_PARAMS,
+        ],
+        schema: || {
+            page_schema(json!({
+                "type": "object",
+                "properties": {
+                    "id": { "type": "integer" },
+                    "rule_id": { "type": "string" },
+                    "rule_name": { "type": "string" },
+                    "line_number": { "type": "integer" },
+                    "column": { "type": "integer" },
+                    "match_text": { "type": "string" },
+                    "file_with_violations": {
+                        "type": "object",
+                        "properties": { "file_path": { "type": "string" } },
+                    },
+                },
+            }))
+        },
+        handler: |_, export, params| violations_route(export, params),
+    },
+    ApiRoute {
+        path: "/api/v1/violations/time_series",
+        summary: "Count the findings per day, the day of the run as the only point",
+        params: &[],
+        schema: || {
+            json!({
+                "type": "object",
+                "properties": {
+                    "data": {
+                        "type": "array",
+                        "items": {
+                            "type": "object",
+                            "properties": {
+                                "date": { "type": "string", "format": "date" },
+                                "count": { "type": "integer" },
+                            },
+                        },
+                    },
+                },
+            })
+        },
+        handler: |_, export, _| time_series_route(export),
+    },
+    ApiRoute {
+        path: "/api/v1/files_with_violations",
+        summary: "List the files with findings, sorted by path",
+        params: &[PAGE_PARAMS],
+        schema: || {
+            page_schema(json!({
+                "type": "object",
+                "properties": {
+                    "id": { "type": "integer" },
+                    "file_path": { "type": "string" },
+                    "analysis_job_id": { "type": "integer" },
+                    "display_path": { "type": "string" },
+                    "job_status": { "type": "string" },
+                },
+            }))
+        },
+        handler: |_, export, params| files_with_violations_route(export, params),
+    },
+];
+
+/// Get the OpenAPI 3 document of the API, generated from the route table
+pub fn openapi_document() -> Value {
+    let error = json!({
+        "description": "Error",
+        "content": { "application/json": { "schema": {
+            "type": "object",
+            "properties": { "error": { "type": "string" } },
+        } } },
+    });
+
+    let mut paths = serde_json::Map::new();
+    for route in API_ROUTES {
+        let parameters: Vec<Value> = route
+            .params
+            .iter()
+            .flat_map(|params| params.iter())
+            .map(|(name, kind, description)| {
+                json!({
+                    "name": name,
+                    "in": "query",
+                    "required": false,
+                    "description": description,
+                    "schema": { "type": kind },
+                })
+            })
+            .collect();
+        paths.insert(
+            route.path.to_string(),
+            json!({ "get": {
+                "summary": route.summary,
+                "parameters": parameters,
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "content": { "application/json": { "schema": (route.schema)() } },
+                    },
+                    "503": {
+                        "description": "findings.json is missing or unreadable",
+                        "content": error["content"],
+                    },
+                },
+            } }),
+        );
+    }
+    paths.insert(
+        "/reports/{file}".to_string(),
+        json!({ "get": {
+            "summary": "Get a report file of the output directory as-is",
+            "parameters": [{
+                "name": "file",
+                "in": "path",
+                "required": true,
+                "description": "Name of the file, e.g. findings.json or angular-graph.json",
+                "schema": { "type": "string" },
+            }],
+            "responses": {
+                "200": { "description": "The report file" },
+                "404": error,
+            },
+        } }),
+    );
+
+    json!({
+        "openapi": "3.0.3",
+        "info": {
+            "title": "Sentinel results API",
+            "description": "Read-only API serving the reports of one run of scoper",
+            "version": env!("CARGO_PKG_VERSION"),
+        },
+        "paths": paths,
+    })
+}
+
+/// Swagger UI for the OpenAPI document, loaded from a CDN
+const SWAGGER_UI: &str = r##"<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Sentinel results API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#swagger-ui" });</script>
+</body>
+</html>
+"##;
+
+/// Serve a report file of the output directory
+fn report_route(dir: &Path, name: &str) -> Response {
+    // Only plain file names, so nothing outside of the output directory can be read
+    if name.is_empty() || name.contains(['/', '\\']) || name.starts_with('.') {
+        return Response::error(404, "Not found");
+    }
+    match fs::read(dir.join(name)) {
+        Ok(body) => Response {
+            status: 200,
+            content_type: match Path::new(name).extension().and_then(|e| e.to_str()) {
+                Some("json" | "sarif") => "application/json",
+                Some("html") => "text/html; charset=utf-8",
+                Some("csv") => "text/csv",
+                _ => "text/plain; charset=utf-8",
+            },
+            body,
+        },
+        Err(_) => Response::error(404, "Not found"),
+    }
+}
+
+/// Answer a GET request
+fn route(dir: &Path, target: &str) -> Response {
+    let (path, params) = parse_target(target);
+    let path = path.trim_end_matches('/');
+    if let Some(name) = path.strip_prefix("/reports/") {
+        return report_route(dir, name);
+    }
+    match path {
+        "/api/openapi.json" => return Response::json(200, &openapi_document()),
+        "/api/docs" => {
+            return Response {
+                status: 200,
+                content_type: "text/html; charset=utf-8",
+                body: SWAGGER_UI.as_bytes().to_vec(),
+            };
+        }
+        _ => {}
+    }
+
+    let Some(api_route) = API_ROUTES.iter().find(|api_route| api_route.path == path) else {
+        return Response::error(404, "Not found");
+    };
+    match load_findings(dir) {
+        Ok(export) => Response::json(200, &(api_route.handler)(dir, &export, &params)),
+        Err(e) => Response::error(503, &e),
+    }
+}
+
+/// Read the request line and the headers of a request
+///
+/// The body of requests is never needed, so it is not read. A request whose head is
+/// longer than `MAX_REQUEST_HEAD_BYTES`, has more than `MAX_HEADERS` headers or ends
+/// early is a bad request.
+fn read_request(stream: &TcpStream) -> std::io::Result<Option<Request>> {
+    let mut reader = BufReader::new(stream.take(MAX_REQUEST_HEAD_BYTES));
+    let mut request_line = String::new();
+    reader.read_line(&mut request_line)?;
+    if !request_line.ends_with('\n') {
+        return Ok(None);
+    }
+
+    let mut headers = BTreeMap::new();
+    let mut header = String::new();
+    loop {
+        reader.read_line(&mut header)?;
+        if !header.ends_with('\n') || headers.len() >= MAX_HEADERS {
+            return Ok(None);
+        }
+        if header.trim_end().is_empty() {
+            break;
+        }
+        if let Some((name, value)) = header.split_once(':') {
+            headers.insert(name.trim().to_ascii_lowercase(), value.trim().to_string());
+        }
+        header.clear();
+    }
+
+    let mut parts = request_line.split_whitespace();
+    Ok(match (parts.next(), parts.next()) {
+        (Some(method), Some(target)) => Some(Request {
+            method: method.to_string(),
+            target: target.to_string(),
+            headers,
+        }),
+        _ => None,
+    })
+}
+
+/// Get the id of a request, taken over from `X-Request-Id` if it is safe to echo
+fn request_id(request: Option<&Request>) -> String {
+    let given = request
+        .and_then(|request| request.headers.get("x-request-id"))
+        .filter(|id| {
+            !id.is_empty()
+                && id.len() <= MAX_REQUEST_ID_LENGTH
+                && id.bytes().all(|byte| byte.is_ascii_graphic())
+        });
+    match given {
+        Some(id) => id.clone(),
+        None => format!(
+            "{:x}-{:x}",
+            chrono::Utc::now().timestamp_micros(),
+            REQUEST_COUNTER.fetch_add(1, Ordering::Relaxed)
+        ),
+    }
+}
+
+/// Get the CORS headers of a response to a request from an origin
+fn cors_headers(origin: Option<&str>, options: &ServeOptions) -> String {
+    let methods = "Access-Control-Allow-Methods: GET, OPTIONS\r\n\
+                   Access-Control-Allow-Headers: *\r\n\
+                   Access-Control-Expose-Headers: X-Request-Id\r\n";
+    // Without configured origins, and for other origins, no CORS headers are sent, so
+    // browsers block cross-origin requests
+    if options.cors_origins.is_empty() {
+        return String::new();
+    }
+    match origin.filter(|origin| options.cors_origins.iter().any(|allowed| allowed == origin)) {
+        Some(origin) => format!(
+            "Access-Control-Allow-Origin: {}\r\nVary: Origin\r\n{}",
+            origin, methods
+        ),
+        None => "Vary: Origin\r\n".to_string(),
+    }
+}
+
+/// Answer a request, a panicking route answers 500
+fn respond(request: Option<&Request>, dir: &Path) -> Response {
+    let Some(request) = request else {
+        return Response::error(400, "Bad request");
+    };
+    match request.method.as_str() {
+        "GET" => catch_unwind(AssertUnwindSafe(|| route(dir, &request.target)))
+            .unwrap_or_else(|_| Response::error(500, "Internal server error")),
+        "OPTIONS" => Response {
+            status: 204,
+            content_type: "text/plain",
+            body: Vec::new(),
+        },
+        _ => Response::error(405, "The results API is read-only"),
+    }
+}
+
+/// Read a request and write the response
+fn handle(stream: TcpStream, dir: &Path, options: &ServeOptions) -> std::io::Result<()> {
+    let started = Instant::now();
+    stream.set_read_timeout(Some(IO_TIMEOUT))?;
+    stream.set_write_timeout(Some(IO_TIMEOUT))?;
+    let request = read_request(&stream)?;
+    let request_id = request_id(request.as_ref());
+    let response = respond(request.as_ref(), dir);
+
+    let reason = match response.status {
+        200 => "OK",
+        204 => "No Content",
+        400 => "Bad Request",
+        404 => "Not Found",
+        405 => "Method Not Allowed",
+        500 => "Internal Server Error",
+        _ => "Service Unavailable",
+    };
+    let origin = request
+        .as_ref()
+        .and_then(|request| request.headers.get("origin"))
+        .map(String::as_str);
+    let mut stream = &stream;
+    write!(
+        stream,
+        "HTTP/1.1 {} {}\r\nContent-Type: {}\r\nContent-Length: {}\r\nX-Request-Id: {}\r\n\
+         {}Connection: close\r\n\r\n",
+        response.status,
+        reason,
+        response.content_type,
+        response.body.len(),
+        request_id,
+        cors_headers(origin, options)
+    )?;
+    stream.write_all(&response.body)?;
+    stream.flush()?;
+
+    if options.access_log {
+        let entry = json!({
+            "timestamp": chrono::Utc::now().to_rfc3339(),
+            "request_id": request_id,
+            "remote": stream.peer_addr().map(|addr| addr.to_string()).unwrap_or_default(),
+            "method": request.as_ref().map(|request| request.method.as_str()),
+            "target": request.as_ref().map(|request| request.target.as_str()),
+            "status": response.status,
+            "bytes": response.body.len(),
+            "duration_ms": started.elapsed().as_secs_f64() * 1000.0,
+        });
+        println!("{}", entry);
+    }
+    Ok(())
+}
+
+/// Serve the reports of an output directory until the process is stopped
+///
+/// Connections are answered by a fixed pool of worker threads, so a slow client doesn't
+/// hold up the others. While every worker is busy and the queue is full, no further
+/// connections are accepted.
+pub fn serve_results(
+    dir: &str,
+    port: u16,
+    options: &ServeOptions,
+    debug_level: DebugLevel,
+) -> Result<(), String> {
+    let dir = Path::new(dir);
+    if !dir.is_dir() {
+        return Err(format!(
+            "{} is not a directory, run the analysis first",
+            dir.display()
+        ));
+    }
+
+    let listener = TcpListener::bind(("127.0.0.1", port))
+        .map_err(|e| format!("Failed to listen on port {}: {}", port, e))?;
+    println!(
+        "Serving the results in {} on http://127.0.0.1:{}",
+        dir.display(),
+        port
+    );
+
+    let (sender, receiver) = mpsc::sync_channel::<TcpStream>(WORKERS);
+    let receiver = Arc::new(Mutex::new(receiver));
+    for _ in 0..WORKERS {
+        let receiver = Arc::clone(&receiver);
+        let dir = dir.to_path_buf();
+        let options = options.clone();
+        thread::spawn(move || {
+            loop {
+                let stream = match receiver.lock() {
+                    Ok(receiver) => receiver.recv(),
+                    Err(_) => return,
+                };
+                let Ok(stream) = stream else {
+                    return;
+                };
+                if let Err(e) = handle(stream, &dir, &options) {
+                    log(
+                        DebugLevel::Warn,
+                        debug_level,
+                        &format!("Failed to answer a request: {}", e),
+                    );
+                }
+            }
+        });
+    }
+
+    for stream in listener.incoming() {
+        let stream = match stream {
+            Ok(stream) => stream,
+            Err(e) => {
+                log(
+                    DebugLevel::Warn,
+                    debug_level,
+                    &format!("Failed to accept a connection: {}", e),
+                );
+                continue;
+            }
+        };
+        if sender.send(stream).is_err() {
+            return Err("The workers answering requests have stopped".to_string());
+        }
+    }
+    Ok(())
+}
@@ -0,0 +1,201 @@
+use crate::FileAnalysisResult;
+use crate::utilities::source::diagnostic_span;
+use serde::{Deserialize, Serialize};
+use std::collections::HashMap;
+use std::path::Path;
+use tabled::{
+    builder::Builder,
+    settings::{Alignment, Style, object::Columns},
+};
+
+/// Rules whose findings block a migration to signals, with the weight of a single finding
+///
+/// Rules that are not enabled simply do not contribute to the score.
+const SIGNAL_MIGRATION_RULES: &[(&str, u32)] = &[
+    ("angular-legacy-decorators", 10),
+    ("rxjs-subscription-leak", 5),
+    ("angular-obsolete-standalone-true", 2),
+];
+
+/// Score at or above which a component is considered ready for migration
+const READY_THRESHOLD: u32 = 80;
+/// Score at or above which a component needs some work before migrating
+const NEEDS_WORK_THRESHOLD: u32 = 50;
+
+/// Signal migration readiness of a single component
+#[derive(Serialize, Deserialize, Clone)]
+pub struct ComponentReadiness {
+    pub component: String,
+    pub file: String,
+    /// Readiness score from 0 (blocked) to 100 (ready)
+    pub score: u32,
+    /// One of `ready`, `needs-work` or `blocked`
+    pub status: String,
+    /// Number of blocking findings per rule
+    pub blockers: HashMap<String, usize>,
+}
+
+/// Signal migration readiness report for the whole project
+#[derive(Serialize, Deserialize, Clone)]
+pub struct SignalMigrationReport {
+    pub components: Vec<ComponentReadiness>,
+    pub average_score: f64,
+    pub ready_count: usize,
+    pub needs_work_count: usize,
+    pub blocked_count: usize,
+}
+
+fn rule_weight(rule_id: &str) -> Option<u32> {
+    SIGNAL_MIGRATION_RULES
+        .iter()
+        .find(|(rule, _)| *rule == rule_id)
+        .map(|(_, weight)| *weight)
+}
+
+/// Get the name of the entry of the findings of a file outside of its components, the
+/// file name like `user.service.ts`
+fn file_entry_name(file_path: &str) -> String {
+    Path::new(file_path)
+        .file_name()
+        .map(|name| name.to_string_lossy().to_string())
+        .unwrap_or_else(|| file_path.to_string())
+}
+
+fn status_for_score(score: u32) -> &'static str {
+    if score >= READY_THRESHOLD {
+        "ready"
+    } else if score >= NEEDS_WORK_THRESHOLD {
+        "needs-work"
+    } else {
+        "blocked"
+    }
+}
+
+/// Build the readiness score of a component from its blocking findings
+fn readiness(
+    component: String,
+    file: &str,
+    blockers: HashMap<String, usize>,
+) -> ComponentReadiness {
+    let penalty: u32 = blockers
+        .iter()
+        .map(|(rule, count)| rule_weight(rule).unwrap_or(0) * *count as u32)
+        .sum();
+    let score = 100u32.saturating_sub(penalty);
+    ComponentReadiness {
+        component,
+        file: file.to_string(),
+        score,
+        status: status_for_score(score).to_string(),
+        blockers,
+    }
+}
+
+/// Build the readiness report from the analysis results
+///
+/// Every `@Component` class gets an entry with the blocking findings inside of it. The
+/// blocking findings of a file outside of its components are grouped in an entry named
+/// after the file. Returns `None` if the project contains neither.
+pub fn build_signal_migration_report(
+    results: &[FileAnalysisResult],
+) -> Option<SignalMigrationReport> {
+    let mut components = Vec::new();
+
+    for result in results {
+        let classes: Vec<_> = result
+            .angular_symbols
+            .iter()
+            .filter(|symbol| symbol.kind == "component")
+            .collect();
+        let mut class_blockers: Vec<HashMap<String, usize>> = vec![HashMap::new(); classes.len()];
+        let mut file_blockers: HashMap<String, usize> = HashMap::new();
+
+        for rule_diagnostic in &result.diagnostics {
+            if rule_weight(&rule_diagnostic.rule_id).is_none() {
+                continue;
+            }
+            let class = diagnostic_span(&rule_diagnostic.diagnostic).and_then(|span| {
+                classes
+                    .iter()
+                    .position(|class| class.span.start <= span.start && span.start < class.span.end)
+            });
+            let blockers = match class {
+                Some(index) => &mut class_blockers[index],
+                None => &mut file_blockers,
+            };
+            *blockers.entry(rule_diagnostic.rule_id.clone()).or_insert(0) += 1;
+        }
+
+        for (class, blockers) in classes.iter().zip(class_blockers) {
+            components.push(readiness(class.name.clone(), &result.file_path, blockers));
+        }
+        if !file_blockers.is_empty() {
+            components.push(readiness(
+                file_entry_name(&result.file_path),
+                &result.file_path,
+                file_blockers,
+            ));
+        }
+    }
+
+    if components.is_empty() {
+        return None;
+    }
+
+    // Least ready components first
+    components.sort_by(|a, b| {
+        a.score
+            .cmp(&b.score)
+            .then_with(|| a.file.cmp(&b.file))
+            .then_with(|| a.component.cmp(&b.component))
+    });
+
+    let count_status = |status: &str| components.iter().filter(|c| c.status == status).count();
+    let average_score =
+        components.iter().map(|c| c.score as f64).sum::<f64>() / components.len() as f64;
+
+    Some(SignalMigrationReport {
+        average_score,
+        ready_count: count_status("ready"),
+        needs_work_count: count_status("needs-work"),
+        blocked_count: count_status("blocked"),
+        components,
+    })
+}
+
+/// Print the readiness report section, listing components that are not ready yet
+pub fn print_signal_migration_report(report: &SignalMigrationReport) {
+    println!("\nSignal migration readiness:");
+    println!("----------------");
+
+    let mut builder = Builder::new();
+    builder.push_record(["Component", "Score", "Status", "Blockers"]);
+
+    for component in report.components.iter().filter(|c| c.status != "ready") {
+        let mut blockers: Vec<String> = component
+            .blockers
+            .iter()
+            .map(|(rule, count)| format!("{} ({})", rule, count))
+            .collect();
+        blockers.sort();
+
+        builder.push_record([
+            component.component.as_str(),
+            &component.score.to_string(),
+            component.status.as_str(),
+            &blockers.join(", "),
+        ]);
+    }
+
+    let mut table = builder.build();
+    table
+        .with(Style::ascii_rounded())
+        .modify(Columns::single(1), Alignment::right());
+
+    println!("{}", table);
+    println!("----------------");
+    println!(
+        "Average score: {:.1} ({} ready, {} need work, {} blocked)\n",
+        report.average_score, report.ready_count, report.needs_work_count, report.blocked_count
+    );
+}
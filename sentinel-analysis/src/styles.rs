@@ -0,0 +1,226 @@
+//! A lightweight parser for the CSS and SCSS of component styles
+//!
+//! Rules only need the selectors of a stylesheet and where they are, not the values of
+//! the declarations, so the parser does not build a full syntax tree. It resolves SCSS
+//! nesting (`.card { &:hover { ... } .title { ... } }`) into complete selectors, looks
+//! through `@media`, `@supports` and control flow blocks, and skips the bodies of
+//! `@mixin`, `@function`, `@keyframes` and `@font-face`, which contain no selectors of the
+//! component. Comments, strings and `#{...}` interpolations are handled; everything else
+//! is kept as written.
+//!
+//! ```rust
+//! use scoper::styles::parse_stylesheet;
+//!
+//! let stylesheet = parse_stylesheet(".card {\n  .title { color: red; }\n}");
+//! assert_eq!(stylesheet.rules[1].selectors, vec![".card .title"]);
+//! assert_eq!(stylesheet.rules[1].depth(), 2);
+//! ```
+
+/// At-rules whose blocks contain declarations or keyframes instead of style rules
+const OPAQUE_AT_RULES: &[&str] = &["@font-face", "@function", "@keyframes", "@mixin", "@page"];
+
+/// A style rule with its selectors resolved against the enclosing rules
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct StyleRule {
+    /// Complete selectors, one per entry of the selector list
+    pub selectors: Vec<String>,
+    /// 1-based line of the selector in the stylesheet
+    pub line: usize,
+}
+
+impl StyleRule {
+    /// Get the number of compound selectors of the deepest selector, e.g. 3 for `.a .b > .c`
+    pub fn depth(&self) -> usize {
+        self.selectors
+            .iter()
+            .map(|selector| selector_depth(selector))
+            .max()
+            .unwrap_or(0)
+    }
+}
+
+/// The style rules of a stylesheet
+#[derive(Debug, Clone, Default)]
+pub struct Stylesheet {
+    pub rules: Vec<StyleRule>,
+}
+
+impl Stylesheet {
+    /// Check if the stylesheet contains no style rules, e.g. a generated placeholder file
+    pub fn is_empty(&self) -> bool {
+        self.rules.is_empty()
+    }
+}
+
+/// An open block while parsing
+enum Block {
+    /// A style rule, with its resolved selectors
+    Rule(Vec<String>),
+    /// `@media` and the like, whose rules nest in the enclosing rule
+    Transparent,
+    /// A block without style rules of the component
+    Opaque,
+}
+
+/// Count the compound selectors of a selector
+fn selector_depth(selector: &str) -> usize {
+    selector
+        .split(|c: char| c.is_whitespace() || matches!(c, '>' | '+' | '~'))
+        .filter(|part| !part.is_empty())
+        .count()
+}
+
+/// Split a selector list at the commas outside of parentheses, e.g. `:is(a, b), c`
+fn split_selector_list(prelude: &str) -> Vec<String> {
+    let mut selectors = Vec::new();
+    let mut depth = 0;
+    let mut start = 0;
+    for (index, c) in prelude.char_indices() {
+        match c {
+            '(' => depth += 1,
+            ')' => depth -= 1,
+            ',' if depth == 0 => {
+                selectors.push(prelude[start..index].to_string());
+                start = index + 1;
+            }
+            _ => {}
+        }
+    }
+    selectors.push(prelude[start..].to_string());
+    selectors
+        .into_iter()
+        .map(|selector| selector.split_whitespace().collect::<Vec<_>>().join(" "))
+        .filter(|selector| !selector.is_empty())
+        .collect()
+}
+
+/// Resolve the selectors of a nested rule against the selectors of its parent rule
+fn resolve_selectors(parents: &[String], own: Vec<String>) -> Vec<String> {
+    if parents.is_empty() {
+        return own;
+    }
+    own.iter()
+        .flat_map(|selector| {
+            parents.iter().map(move |parent| {
+                if selector.contains('&') {
+                    selector.replace('&', parent)
+                } else {
+                    format!("{} {}", parent, selector)
+                }
+            })
+        })
+        .collect()
+}
+
+/// Parse a CSS or SCSS stylesheet into its style rules
+///
+/// The parser never fails; unbalanced braces close the open blocks early or are ignored.
+pub fn parse_stylesheet(source: &str) -> Stylesheet {
+    let mut rules = Vec::new();
+    let mut blocks: Vec<Block> = Vec::new();
+    let mut prelude = String::new();
+    let mut prelude_line = 1;
+    let mut line = 1;
+    // Depth of `#{...}` interpolations, whose braces do not open blocks
+    let mut interpolation = 0;
+
+    let mut chars = source.chars().peekable();
+    while let Some(c) = chars.next() {
+        match c {
+            '/' if chars.peek() == Some(&'/') => {
+                // Line comment
+                for c in chars.by_ref() {
+                    if c == '\n' {
+                        line += 1;
+                        break;
+                    }
+                }
+                prelude.push(' ');
+            }
+            '/' if chars.peek() == Some(&'*') => {
+                chars.next();
+                let mut previous = ' ';
+                for c in chars.by_ref() {
+                    if c == '\n' {
+                        line += 1;
+                    }
+                    if previous == '*' && c == '/' {
+                        break;
+                    }
+                    previous = c;
+                }
+                prelude.push(' ');
+            }
+            '"' | '\'' => {
+                prelude.push(c);
+                while let Some(s) = chars.next() {
+                    prelude.push(s);
+                    match s {
+                        '\\' => prelude.extend(chars.next()),
+                        '\n' => line += 1,
+                        _ if s == c => break,
+                        _ => {}
+                    }
+                }
+            }
+            '#' if chars.peek() == Some(&'{') => {
+                chars.next();
+                interpolation += 1;
+                prelude.push_str("#{");
+            }
+            '}' if interpolation > 0 => {
+                interpolation -= 1;
+                prelude.push('}');
+            }
+            '{' => {
+                let text = prelude.trim();
+                let parents = blocks.iter().rev().find_map(|block| match block {
+                    Block::Rule(selectors) => Some(selectors.as_slice()),
+                    _ => None,
+                });
+                let in_opaque = blocks.iter().any(|block| matches!(block, Block::Opaque));
+
+                let block = if in_opaque {
+                    Block::Opaque
+                } else if text.starts_with('@') {
+                    let name = text.split(|c: char| c.is_whitespace() || c == '(').next();
+                    if name.is_some_and(|name| OPAQUE_AT_RULES.contains(&name)) {
+                        Block::Opaque
+                    } else {
+                        Block::Transparent
+                    }
+                } else if text.ends_with(':') {
+                    // Nested properties like `font: { family: serif; }`
+                    Block::Opaque
+                } else {
+                    let selectors =
+                        resolve_selectors(parents.unwrap_or_default(), split_selector_list(text));
+                    rules.push(StyleRule {
+                        selectors: selectors.clone(),
+                        line: prelude_line,
+                    });
+                    Block::Rule(selectors)
+                };
+                blocks.push(block);
+                prelude.clear();
+            }
+            '}' => {
+                blocks.pop();
+                prelude.clear();
+            }
+            ';' => prelude.clear(),
+            '\n' => {
+                line += 1;
+                prelude.push(' ');
+            }
+            _ => {
+                if prelude.trim().is_empty() && !c.is_whitespace() {
+                    prelude_line = line;
+                }
+                prelude.push(c);
+            }
+        }
+    }
+
+    Stylesheet { rules }
+}
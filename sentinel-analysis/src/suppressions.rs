@@ -0,0 +1,380 @@
+//! Suppressions of findings, with expiry dates and owners
+//!
+//! A finding is suppressed inline by a comment on its line or the line above it:
+//!
+//! ```ts
+//! // sentinel-disable-next-line no-console expires=2026-12-31 owner=@web-team -- debugging
+//! console.log(order);
+//! legacyCall(); // sentinel-disable-line deprecated-api owner=@platform
+//! ```
+//!
+//! or in the baseline file, `sentinel-suppressions.json` unless `suppressions` points
+//! elsewhere, by fingerprint or by rule and file:
+//!
+//! ```json
+//! { "suppressions": [
+//!   { "rule": "no-console", "file": "src/app/debug.ts", "owner": "@web-team",
+//!     "created": "2026-01-15", "expires": "2026-06-30", "reason": "until the logger lands" }
+//! ] }
+//! ```
+//!
+//! Several rules are separated by commas. A suppression without `expires` never expires;
+//! an expired one no longer applies, so its findings are reported again. `scoper
+//! suppressions list` audits all suppressions with their owner, age and status.
+
+use crate::FileAnalysisResult;
+use crate::exporter::finding_fingerprint;
+use crate::hotspots::git;
+use crate::utilities::file_utils::find_files;
+use crate::utilities::source::decode_source;
+use crate::utilities::{DebugLevel, log};
+use chrono::{NaiveDate, Utc};
+use serde::{Deserialize, Serialize};
+use std::fs;
+use std::path::Path;
+use tabled::{
+    builder::Builder,
+    settings::{Alignment, Style, object::Columns},
+};
+
+/// Default location of the baseline file
+pub const DEFAULT_SUPPRESSIONS_PATH: &str = "sentinel-suppressions.json";
+
+/// Comment suppressing the findings on the following line
+const DISABLE_NEXT_LINE: &str = "sentinel-disable-next-line";
+
+/// Comment suppressing the findings on its own line
+const DISABLE_LINE: &str = "sentinel-disable-line";
+
+/// A suppression of the baseline file
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+pub struct BaselineEntry {
+    /// Fingerprint of the suppressed finding; without it, all findings of the rule in
+    /// the file are suppressed
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub fingerprint: Option<String>,
+    pub rule: String,
+    #[serde(default)]
+    pub file: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub owner: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub reason: Option<String>,
+    /// Date the suppression was added, as YYYY-MM-DD
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub created: Option<String>,
+    /// Last day the suppression applies, as YYYY-MM-DD
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub expires: Option<String>,
+}
+
+/// The baseline file
+#[derive(Serialize, Deserialize, Debug, Clone, Default)]
+pub struct Baseline {
+    #[serde(default)]
+    pub suppressions: Vec<BaselineEntry>,
+}
+
+impl Baseline {
+    /// Load the baseline file, an empty baseline if it does not exist
+    pub fn load(path: &str) -> Result<Self, String> {
+        match fs::read_to_string(path) {
+            Ok(content) => {
+                serde_json::from_str(&content).map_err(|e| format!("Invalid {}: {}", path, e))
+            }
+            Err(_) => Ok(Self::default()),
+        }
+    }
+}
+
+/// A suppression comment in a source file
+#[derive(Serialize, Deserialize, Debug, Clone, PartialEq)]
+pub struct InlineSuppression {
+    pub rules: Vec<String>,
+    /// 1-based line of the comment
+    pub comment_line: usize,
+    /// 1-based line whose findings are suppressed
+    pub target_line: usize,
+    pub owner: Option<String>,
+    pub reason: Option<String>,
+    pub expires: Option<String>,
+}
+
+impl InlineSuppression {
+    /// Parse the suppression comment of a line, if it has one
+    fn parse(line: &str, line_number: usize) -> Option<Self> {
+        let (directive, target_line) = if let Some(index) = line.find(DISABLE_NEXT_LINE) {
+            (&line[index + DISABLE_NEXT_LINE.len()..], line_number + 1)
+        } else if let Some(index) = line.find(DISABLE_LINE) {
+            (&line[index + DISABLE_LINE.len()..], line_number)
+        } else {
+            return None;
+        };
+
+        let directive = directive.trim_end().trim_end_matches("*/");
+        let (directive, reason) = match directive.split_once("--") {
+            Some((directive, reason)) => (directive, Some(reason.trim().to_string())),
+            None => (directive, None),
+        };
+
+        let mut suppression = Self {
+            rules: Vec::new(),
+            comment_line: line_number,
+            target_line,
+            owner: None,
+            reason: reason.filter(|reason| !reason.is_empty()),
+            expires: None,
+        };
+        for token in directive.split_whitespace() {
+            if let Some(owner) = token.strip_prefix("owner=") {
+                suppression.owner = Some(owner.to_string());
+            } else if let Some(expires) = token.strip_prefix("expires=") {
+                suppression.expires = Some(expires.to_string());
+            } else {
+                suppression.rules.extend(
+                    token
+                        .split(',')
+                        .filter(|rule| !rule.is_empty())
+                        .map(str::to_string),
+                );
+            }
+        }
+        (!suppression.rules.is_empty()).then_some(suppression)
+    }
+
+    fn matches(&self, rule: &str, line: usize) -> bool {
+        self.target_line == line && self.rules.iter().any(|r| r == rule)
+    }
+}
+
+/// Find the suppression comments of a source file
+pub fn parse_inline_suppressions(source: &str) -> Vec<InlineSuppression> {
+    source
+        .lines()
+        .enumerate()
+        .filter_map(|(i, line)| InlineSuppression::parse(line, i + 1))
+        .collect()
+}
+
+/// Check if a suppression has expired; invalid dates count as expired, so a typo does
+/// not suppress a finding forever
+fn is_expired(expires: Option<&str>, today: NaiveDate) -> bool {
+    expires.is_some_and(|date| {
+        NaiveDate::parse_from_str(date, "%Y-%m-%d").map_or(true, |date| date < today)
+    })
+}
+
+/// Numbers of findings affected by suppressions in a run
+#[derive(Debug, Clone, Copy, Default)]
+pub struct SuppressionOutcome {
+    pub suppressed: usize,
+    /// Findings reported again because their suppression expired
+    pub expired: usize,
+}
+
+/// Remove the findings with an active suppression from the results
+///
+/// The file paths of the results must already be relative to the path base, like the
+/// files of the baseline.
+pub fn apply_suppressions(
+    results: &mut [FileAnalysisResult],
+    baseline: &Baseline,
+    today: NaiveDate,
+    debug_level: DebugLevel,
+) -> SuppressionOutcome {
+    let mut outcome = SuppressionOutcome::default();
+    for result in results.iter_mut() {
+        let Some(first) = result.diagnostics.first() else {
+            continue;
+        };
+        let inline = parse_inline_suppressions(&first.source_code);
+        let file_path = result.file_path.clone();
+
+        result.diagnostics.retain(|diagnostic| {
+            let fingerprint = finding_fingerprint(diagnostic, &file_path);
+            let inline_expiry = inline
+                .iter()
+                .filter(|s| s.matches(&diagnostic.rule_id, diagnostic.line_number))
+                .map(|s| (s.expires.as_deref(), s.owner.as_deref()));
+            let baseline_expiry = baseline
+                .suppressions
+                .iter()
+                .filter(|entry| entry.rule == diagnostic.rule_id)
+                .filter(|entry| match &entry.fingerprint {
+                    Some(suppressed) => *suppressed == fingerprint,
+                    None => entry.file == file_path,
+                })
+                .map(|entry| (entry.expires.as_deref(), entry.owner.as_deref()));
+            let matching: Vec<_> = inline_expiry.chain(baseline_expiry).collect();
+
+            if matching.is_empty() {
+                return true;
+            }
+            if matching
+                .iter()
+                .any(|(expires, _)| !is_expired(*expires, today))
+            {
+                outcome.suppressed += 1;
+                return false;
+            }
+
+            outcome.expired += 1;
+            let (expires, owner) = matching[0];
+            log(
+                DebugLevel::Warn,
+                debug_level,
+                &format!(
+                    "Suppression of {} at {}:{} expired on {} (owner: {}), reporting it again",
+                    diagnostic.rule_id,
+                    file_path,
+                    diagnostic.line_number,
+                    expires.unwrap_or("-"),
+                    owner.unwrap_or("-")
+                ),
+            );
+            true
+        });
+    }
+    outcome
+}
+
+/// A suppression listed by the audit
+#[derive(Serialize, Deserialize, Debug, Clone)]
+pub struct SuppressionListing {
+    /// `inline` or `baseline`
+    pub kind: String,
+    pub rules: Vec<String>,
+    /// File, with the line of the comment for inline suppressions
+    pub location: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub owner: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub reason: Option<String>,
+    /// Days since the suppression was added, from git blame for inline suppressions
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub age_days: Option<i64>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub expires: Option<String>,
+    pub expired: bool,
+}
+
+/// Get the date a line was last changed, from git blame
+fn line_date(file: &Path, line: usize) -> Option<NaiveDate> {
+    let dir = file.parent()?;
+    let name = file.file_name()?.to_string_lossy().into_owned();
+    let range = format!("{},{}", line, line);
+    let output = git(
+        dir,
+        &[
+            "blame",
+            "--porcelain",
+            "-L",
+            range.as_str(),
+            "--",
+            name.as_str(),
+        ],
+    )
+    .ok()?;
+    let timestamp = output
+        .lines()
+        .find_map(|line| line.strip_prefix("author-time "))?
+        .trim()
+        .parse::<i64>()
+        .ok()?;
+    chrono::DateTime::from_timestamp(timestamp, 0).map(|date| date.date_naive())
+}
+
+/// List the inline suppressions under a directory and the suppressions of the baseline
+pub fn list_suppressions(
+    target: &str,
+    baseline: &Baseline,
+    debug_level: DebugLevel,
+) -> Vec<SuppressionListing> {
+    let today = Utc::now().date_naive();
+    let (files, _) = find_files(target, debug_level);
+    let mut listings = Vec::new();
+
+    for file in files {
+        let Ok(source) = fs::read(&file)
+            .map_err(|e| e.to_string())
+            .and_then(decode_source)
+        else {
+            continue;
+        };
+        for suppression in parse_inline_suppressions(&source.content) {
+            let added = line_date(Path::new(&file), suppression.comment_line);
+            listings.push(SuppressionListing {
+                kind: "inline".to_string(),
+                location: format!("{}:{}", file, suppression.comment_line),
+                owner: suppression.owner,
+                reason: suppression.reason,
+                age_days: added.map(|added| (today - added).num_days()),
+                expired: is_expired(suppression.expires.as_deref(), today),
+                expires: suppression.expires,
+                rules: suppression.rules,
+            });
+        }
+    }
+
+    for entry in &baseline.suppressions {
+        let added = entry
+            .created
+            .as_deref()
+            .and_then(|date| NaiveDate::parse_from_str(date, "%Y-%m-%d").ok());
+        listings.push(SuppressionListing {
+            kind: "baseline".to_string(),
+            rules: vec![entry.rule.clone()],
+            location: match &entry.fingerprint {
+                Some(fingerprint) => format!("{} ({})", entry.file, fingerprint),
+                None => entry.file.clone(),
+            },
+            owner: entry.owner.clone(),
+            reason: entry.reason.clone(),
+            age_days: added.map(|added| (today - added).num_days()),
+            expires: entry.expires.clone(),
+            expired: is_expired(entry.expires.as_deref(), today),
+        });
+    }
+    listings
+}
+
+/// Print the suppressions as a table
+pub fn print_suppressions(listings: &[SuppressionListing]) {
+    println!("\nSuppressions:");
+    println!("----------------");
+
+    let mut builder = Builder::new();
+    builder.push_record([
+        "Kind", "Rules", "Location", "Owner", "Age", "Expires", "Status",
+    ]);
+    for listing in listings {
+        builder.push_record([
+            listing.kind.clone(),
+            listing.rules.join(", "),
+            listing.location.clone(),
+            listing.owner.clone().unwrap_or_else(|| "-".to_string()),
+            listing
+                .age_days
+                .map_or("-".to_string(), |days| format!("{}d", days)),
+            listing
+                .expires
+                .clone()
+                .unwrap_or_else(|| "never".to_string()),
+            if listing.expired { "EXPIRED" } else { "active" }.to_string(),
+        ]);
+    }
+
+    let mut table = builder.build();
+    table
+        .with(Style::ascii_rounded())
+        .modify(Columns::single(4), Alignment::right());
+
+    println!("{}", table);
+    let active = listings.iter().filter(|listing| !listing.expired).count();
+    println!(
+        "Total: {} active, {} expired",
+        active,
+        listings.len() - active
+    );
+    println!("----------------");
+}
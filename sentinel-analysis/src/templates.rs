@@ -0,0 +1,87 @@
+//! Custom reports rendered from templates
+//!
+//! `--format template --template report.md.tmpl` renders the findings export through a
+//! [minijinja](https://docs.rs/minijinja) template, so teams can produce their own reports
+//! without changing the analyzer. The template sees the same fields as `findings.json`
+//! (`findings`, `summary`, `by_directory`, `hotspots`, ...) and can use these filters in
+//! addition to the built-in ones such as `groupby`:
+//!
+//! - `severity_color`: wraps a severity in the ANSI color used by the console output
+//! - `relpath`: makes a path relative to the working directory, or to the given base
+//! - `hyperlink`: makes a text a terminal hyperlink to a URL such as `finding.editor_url`
+//!
+//! Unless the output has a path, the report is written to the output directory, named after
+//! the template without its `.tmpl` extension.
+
+use crate::exporter::FindingsExport;
+use minijinja::Environment;
+use std::env;
+use std::fs;
+use std::path::Path;
+
+/// Wrap a severity in its ANSI color
+fn severity_color(severity: String) -> String {
+    let color = match severity.as_str() {
+        "error" => "31",
+        "warning" => "33",
+        _ => "36",
+    };
+    format!("\x1b[{}m{}\x1b[0m", color, severity)
+}
+
+/// Make a path relative to a base directory, the working directory by default
+fn relpath(path: String, base: Option<String>) -> String {
+    let base = match base {
+        Some(base) => base.into(),
+        None => env::current_dir().unwrap_or_default(),
+    };
+    let relative = Path::new(&path)
+        .strip_prefix(&base)
+        .ok()
+        .or_else(|| Path::new(&path).strip_prefix(".").ok());
+
+    relative.map_or(path.clone(), |relative| {
+        relative.to_string_lossy().to_string()
+    })
+}
+
+/// Wrap a text in an OSC 8 hyperlink, which terminals render as clickable text
+///
+/// Without a URL, e.g. a finding without `editor_url`, the text is returned as-is.
+fn hyperlink(text: String, url: Option<String>) -> String {
+    match url {
+        Some(url) if !url.is_empty() => format!("\x1b]8;;{}\x1b\\{}\x1b]8;;\x1b\\", url, text),
+        _ => text,
+    }
+}
+
+/// Render a findings export through a template
+pub fn render_template(export: &FindingsExport, template_path: &str) -> Result<String, String> {
+    let source = fs::read_to_string(template_path)
+        .map_err(|e| format!("Failed to read template {}: {}", template_path, e))?;
+
+    let mut environment = Environment::new();
+    environment.add_filter("severity_color", severity_color);
+    environment.add_filter("relpath", relpath);
+    environment.add_filter("hyperlink", hyperlink);
+    environment
+        .add_template("report", &source)
+        .map_err(|e| format!("Invalid template {}: {}", template_path, e))?;
+
+    environment
+        .get_template("report")
+        .and_then(|template| template.render(export))
+        .map_err(|e| format!("Failed to render template {}: {}", template_path, e))
+}
+
+/// Get the path of the rendered report in the output directory
+pub fn report_path(template_path: &str, output_dir: &str) -> String {
+    let name = Path::new(template_path)
+        .file_name()
+        .map_or("report".to_string(), |name| {
+            name.to_string_lossy().to_string()
+        });
+    let name = name.strip_suffix(".tmpl").unwrap_or(&name);
+
+    format!("{}/{}", output_dir, name)
+}
@@ -0,0 +1,262 @@
+//! Token counting for chunk size limits
+//!
+//! `BpeTokenizer` is a byte-level BPE tokenizer compatible with OpenAI's tiktoken. It
+//! loads the merge ranks of an encoding (`cl100k_base`, `o200k_base`) from a `.tiktoken`
+//! file, which is looked up in the configured path or in the Sentinel cache directory.
+//! When no rank file is available, `ApproximateTokenizer` estimates the count from the
+//! same pre-tokenization, which stays close for symbol-heavy and minified code where
+//! word-based heuristics are far off.
+
+use base64::Engine;
+use base64::engine::general_purpose::STANDARD;
+use regex::Regex;
+use std::collections::HashMap;
+use std::fs;
+use std::path::PathBuf;
+
+use crate::utilities::config::TokenizerConfig;
+use crate::utilities::{DebugLevel, log};
+
+/// Pre-tokenization of cl100k_base, without the `\s+(?!\S)` lookahead which the regex
+/// crate does not support; the trailing whitespace group is handled in `split_pieces`
+const CL100K_PATTERN: &str = r"'(?i:[sdmt]|ll|ve|re)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|(\s+)";
+
+/// Pre-tokenization of o200k_base, see `CL100K_PATTERN`
+const O200K_PATTERN: &str = r"[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|(\s+)";
+
+/// Average number of bytes per token of the BPE encodings for source code
+const APPROXIMATE_BYTES_PER_TOKEN: usize = 4;
+
+/// Counts the tokens of a text for a target model
+pub trait Tokenizer: Send + Sync {
+    /// Get the name of the encoding, e.g. `cl100k_base`
+    fn name(&self) -> &str;
+
+    /// Count the tokens of a text
+    fn count_tokens(&self, text: &str) -> usize;
+}
+
+/// Get the encoding used by a model
+pub fn encoding_for_model(model: &str) -> &'static str {
+    let model = model.to_lowercase();
+    let o200k_prefixes = ["gpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "o1", "o3", "o4"];
+    if o200k_prefixes
+        .iter()
+        .any(|prefix| model.starts_with(prefix))
+    {
+        "o200k_base"
+    } else {
+        "cl100k_base"
+    }
+}
+
+fn pattern_for_encoding(encoding: &str) -> Result<&'static str, String> {
+    match encoding {
+        "cl100k_base" => Ok(CL100K_PATTERN),
+        "o200k_base" => Ok(O200K_PATTERN),
+        other => Err(format!(
+            "Unknown encoding '{}', expected cl100k_base or o200k_base",
+            other
+        )),
+    }
+}
+
+/// Split a text into the pieces that are encoded independently
+///
+/// Emulates `\s+(?!\S)`: a whitespace run followed by a non-whitespace character leaves
+/// its last character to the next piece, so ` foo` stays one piece.
+fn split_pieces<'t>(pattern: &Regex, text: &'t str) -> Vec<&'t str> {
+    let mut pieces = Vec::new();
+    let mut position = 0;
+
+    while let Some(captures) = pattern.captures_at(text, position) {
+        let piece = captures.get(0).expect("Match without group 0");
+        if piece.is_empty() {
+            break;
+        }
+
+        let mut end = piece.end();
+        if captures.get(1).is_some() && end < text.len() {
+            let last_char = text[piece.start()..end].chars().next_back();
+            if let Some(last_char) = last_char {
+                if end - piece.start() > last_char.len_utf8() {
+                    end -= last_char.len_utf8();
+                }
+            }
+        }
+
+        pieces.push(&text[piece.start()..end]);
+        position = end;
+    }
+
+    pieces
+}
+
+/// Byte-level BPE tokenizer compatible with tiktoken
+pub struct BpeTokenizer {
+    name: String,
+    ranks: HashMap<Vec<u8>, u32>,
+    pattern: Regex,
+}
+
+impl BpeTokenizer {
+    /// Load an encoding from a `.tiktoken` file with one `<base64 token> <rank>` per line
+    pub fn from_tiktoken_file(encoding: &str, path: &PathBuf) -> Result<Self, String> {
+        let content = fs::read_to_string(path)
+            .map_err(|e| format!("Failed to read {}: {}", path.display(), e))?;
+
+        let mut ranks = HashMap::new();
+        for (line_number, line) in content.lines().enumerate() {
+            if line.trim().is_empty() {
+                continue;
+            }
+            let (token, rank) = line
+                .split_once(' ')
+                .ok_or_else(|| format!("Invalid line {} in {}", line_number + 1, path.display()))?;
+            let token = STANDARD
+                .decode(token)
+                .map_err(|e| format!("Invalid token on line {}: {}", line_number + 1, e))?;
+            let rank = rank
+                .trim()
+                .parse()
+                .map_err(|e| format!("Invalid rank on line {}: {}", line_number + 1, e))?;
+            ranks.insert(token, rank);
+        }
+
+        let pattern = Regex::new(pattern_for_encoding(encoding)?)
+            .map_err(|e| format!("Invalid pre-tokenization pattern: {}", e))?;
+
+        Ok(Self {
+            name: encoding.to_string(),
+            ranks,
+            pattern,
+        })
+    }
+
+    /// Merge the bytes of a piece into tokens, always merging the pair with the lowest
+    /// rank first, and return the ranks of the resulting tokens
+    fn encode_piece(&self, piece: &[u8]) -> Vec<u32> {
+        if let Some(&rank) = self.ranks.get(piece) {
+            return vec![rank];
+        }
+
+        // Start and end offsets of the current parts
+        let mut parts: Vec<(usize, usize)> = (0..piece.len()).map(|i| (i, i + 1)).collect();
+
+        loop {
+            let best = parts
+                .windows(2)
+                .enumerate()
+                .filter_map(|(i, pair)| {
+                    self.ranks
+                        .get(&piece[pair[0].0..pair[1].1])
+                        .map(|&rank| (rank, i))
+                })
+                .min();
+
+            match best {
+                Some((_, i)) => {
+                    parts[i].1 = parts[i + 1].1;
+                    parts.remove(i + 1);
+                }
+                None => break,
+            }
+        }
+
+        // Every single byte has a rank in the byte-level encodings
+        parts
+            .iter()
+            .filter_map(|&(start, end)| self.ranks.get(&piece[start..end]).copied())
+            .collect()
+    }
+
+    /// Encode a text into token ranks
+    pub fn encode(&self, text: &str) -> Vec<u32> {
+        split_pieces(&self.pattern, text)
+            .into_iter()
+            .flat_map(|piece| self.encode_piece(piece.as_bytes()))
+            .collect()
+    }
+}
+
+impl Tokenizer for BpeTokenizer {
+    fn name(&self) -> &str {
+        &self.name
+    }
+
+    fn count_tokens(&self, text: &str) -> usize {
+        self.encode(text).len()
+    }
+}
+
+/// Estimates token counts from the pre-tokenization of an encoding
+///
+/// Every piece counts as at least one token, longer pieces as one token per four bytes.
+pub struct ApproximateTokenizer {
+    name: String,
+    pattern: Regex,
+}
+
+impl ApproximateTokenizer {
+    pub fn new(encoding: &str) -> Result<Self, String> {
+        Ok(Self {
+            name: format!("{} (approximate)", encoding),
+            pattern: Regex::new(pattern_for_encoding(encoding)?)
+                .map_err(|e| format!("Invalid pre-tokenization pattern: {}", e))?,
+        })
+    }
+}
+
+impl Tokenizer for ApproximateTokenizer {
+    fn name(&self) -> &str {
+        &self.name
+    }
+
+    fn count_tokens(&self, text: &str) -> usize {
+        split_pieces(&self.pattern, text)
+            .iter()
+            .map(|piece| piece.len().div_ceil(APPROXIMATE_BYTES_PER_TOKEN).max(1))
+            .sum()
+    }
+}
+
+/// Get the default location of the rank file of an encoding
+fn default_ranks_path(encoding: &str) -> Option<PathBuf> {
+    dirs::cache_dir().map(|dir| dir.join("sentinel").join(format!("{}.tiktoken", encoding)))
+}
+
+/// Create the tokenizer for the configured model or encoding
+///
+/// Falls back to `ApproximateTokenizer` if the rank file of the encoding is not found.
+pub fn create_tokenizer(
+    config: &TokenizerConfig,
+    debug_level: DebugLevel,
+) -> Result<Box<dyn Tokenizer>, String> {
+    let encoding = match (&config.encoding, &config.model) {
+        (Some(encoding), _) => encoding.clone(),
+        (None, Some(model)) => encoding_for_model(model).to_string(),
+        (None, None) => "cl100k_base".to_string(),
+    };
+
+    let ranks_path = config
+        .ranks_path
+        .as_ref()
+        .map(PathBuf::from)
+        .or_else(|| default_ranks_path(&encoding));
+
+    if let Some(path) = ranks_path.filter(|path| path.exists()) {
+        return Ok(Box::new(BpeTokenizer::from_tiktoken_file(
+            &encoding, &path,
+        )?));
+    }
+
+    log(
+        DebugLevel::Warn,
+        debug_level,
+        &format!(
+            "No rank file for {} found, token counts are approximate (set tokenizer.ranks_path)",
+            encoding
+        ),
+    );
+    Ok(Box::new(ApproximateTokenizer::new(&encoding)?))
+}
@@ -49,8 +49,9 @@ pub fn parse_args() -> Command {
         .arg(
             Arg::new("rule-debug")
                 .long("rule-debug")
-                .help("Enable verbose rule debugging output")
-                .action(ArgAction::SetTrue),
+                .help("Print the debug messages of these rules and write their debug artifacts to <output>/rule-debug (comma-separated)")
+                .value_name("RULES")
+                .action(ArgAction::Append),
         )
         .arg(
             Arg::new("severity")
@@ -87,12 +88,175 @@ pub fn parse_args() -> Command {
                 .value_name("TAG")
                 .action(ArgAction::Append),
         )
+        .arg(
+            Arg::new("preset")
+                .long("preset")
+                .help("Use a named rule preset (recommended, strict, migration)")
+                .value_name("PRESET"),
+        )
+        .arg(
+            Arg::new("rules-include")
+                .long("rules-include")
+                .help("Only run rules matching these categories, tags or names (comma-separated)")
+                .value_name("SELECTORS"),
+        )
+        .arg(
+            Arg::new("rules-exclude")
+                .long("rules-exclude")
+                .help("Skip rules matching these categories, tags or names (comma-separated)")
+                .value_name("SELECTORS"),
+        )
+        .arg(
+            Arg::new("history")
+                .long("history")
+                .help("Append the findings per rule of this run to history.jsonl")
+                .action(ArgAction::SetTrue),
+        )
+        .arg(
+            Arg::new("max-findings")
+                .long("max-findings")
+                .help("Maximum number of findings written to the reports")
+                .value_name("NUM")
+                .value_parser(clap::value_parser!(usize)),
+        )
+        .arg(
+            Arg::new("max-findings-per-rule")
+                .long("max-findings-per-rule")
+                .help("Maximum number of findings of each rule written to the reports")
+                .value_name("NUM")
+                .value_parser(clap::value_parser!(usize)),
+        )
+        .arg(
+            Arg::new("filter-rule")
+                .long("filter-rule")
+                .help("Only report findings of rules matching these globs (comma-separated, repeatable)")
+                .value_name("GLOBS")
+                .action(ArgAction::Append),
+        )
+        .arg(
+            Arg::new("filter-severity")
+                .long("filter-severity")
+                .help("Only report findings of these severities: error, warning, info (comma-separated, repeatable)")
+                .value_name("SEVERITIES")
+                .action(ArgAction::Append),
+        )
+        .arg(
+            Arg::new("filter-path")
+                .long("filter-path")
+                .help("Only report findings in files matching these globs (comma-separated, repeatable)")
+                .value_name("GLOBS")
+                .action(ArgAction::Append),
+        )
+        .arg(
+            Arg::new("blame")
+                .long("blame")
+                .help("Attach the author, commit and date of the last change of their line to findings")
+                .action(ArgAction::SetTrue),
+        )
+        .arg(
+            Arg::new("only-mine")
+                .long("only-mine")
+                .help("Only report findings on lines last changed by the current git user (implies --blame)")
+                .action(ArgAction::SetTrue),
+        )
+        .arg(
+            Arg::new("since")
+                .long("since")
+                .help("Only report findings on lines changed on or after this date (YYYY-MM-DD, implies --blame)")
+                .value_name("DATE"),
+        )
+        .arg(
+            Arg::new("new-code-only")
+                .long("new-code-only")
+                .help("Only report findings on lines changed on the current branch")
+                .action(ArgAction::SetTrue),
+        )
+        .arg(
+            Arg::new("new-code-base")
+                .long("new-code-base")
+                .help("Branch compared with by --new-code-only (default: origin/HEAD, main or master)")
+                .value_name("REF"),
+        )
+        .arg(
+            Arg::new("emit-chunks")
+                .long("emit-chunks")
+                .help("Write LLM-ready code chunks with their findings to chunks.jsonl")
+                .action(ArgAction::SetTrue),
+        )
+        .arg(
+            Arg::new("tree")
+                .long("tree")
+                .help("Print the findings rolled up per directory as a tree")
+                .action(ArgAction::SetTrue),
+        )
+        .arg(
+            Arg::new("churn")
+                .long("churn")
+                .help("Weight the hotspots by the number of commits touching each file")
+                .action(ArgAction::SetTrue),
+        )
+        .arg(
+            Arg::new("cache")
+                .long("cache")
+                .help("Reuse rule results of unchanged files from .sentinel-cache")
+                .action(ArgAction::SetTrue),
+        )
+        .arg(
+            Arg::new("previous")
+                .long("previous")
+                .help("Reuse rule results of unchanged files from the analysis_results.json of a previous run, if the rule cache is missing")
+                .value_name("FILE"),
+        )
+        .arg(
+            Arg::new("embed")
+                .long("embed")
+                .help("Build a semantic search index of the code chunks in embeddings.json")
+                .action(ArgAction::SetTrue),
+        )
+        .arg(
+            Arg::new("ai-suggestions")
+                .long("ai-suggestions")
+                .help("Attach AI-generated fix suggestions to findings of the configured rules")
+                .action(ArgAction::SetTrue),
+        )
         .arg(
             Arg::new("export-json")
                 .long("export-json")
                 .help("Export rule findings to a JSON file")
                 .value_name("FILE"),
         )
+        .arg(
+            Arg::new("format")
+                .long("format")
+                .help("Format of the findings report")
+                .value_name("FORMAT")
+                .value_parser(["json", "sarif", "template", "backstage", "security"]),
+        )
+        .arg(
+            Arg::new("output")
+                .long("output")
+                .help("Also write the report as format=FORMAT[,path=FILE][,template=FILE] (repeatable)")
+                .value_name("SPEC")
+                .action(ArgAction::Append),
+        )
+        .arg(
+            Arg::new("editor")
+                .long("editor")
+                .help("Editor opened by the links of findings: vscode, cursor, jetbrains, sublime or a URL template")
+                .value_name("EDITOR"),
+        )
+        .arg(
+            Arg::new("locale")
+                .long("locale")
+                .help("Language of the messages in the reports: en or de")
+                .value_name("LOCALE"),
+        )
+        .arg(
+            Arg::new("template")
+                .long("template")
+                .help("Template rendering the findings report with --format template")
+                .value_name("FILE"),
+        )
         .arg(
             Arg::new("rules")
                 .short('r')
@@ -106,12 +270,303 @@ pub fn parse_args() -> Command {
                 .help("Path to rules configuration file")
                 .value_name("FILE"),
         )
+        .arg(
+            Arg::new("capabilities")
+                .long("capabilities")
+                .help("Print the schema version and capabilities of this binary as JSON and exit")
+                .action(ArgAction::SetTrue),
+        )
+        .arg(
+            Arg::new("report-fp")
+                .long("report-fp")
+                .help("Report the finding with this fingerprint of the last run as false positive")
+                .value_name("FINGERPRINT"),
+        )
+        .arg(
+            Arg::new("comment")
+                .long("comment")
+                .help("Explanation added to a false-positive report")
+                .value_name("TEXT")
+                .requires("report-fp"),
+        )
         .arg(
             Arg::new("threads")
                 .long("threads")
                 .help("Number of threads to use for parallel processing")
                 .value_name("NUM"),
         )
+        .subcommand(
+            Command::new("search")
+                .about("Search the code indexed by a previous run with --embed")
+                .arg(
+                    Arg::new("QUERY")
+                        .help("Natural language or code query")
+                        .required(true)
+                        .index(1),
+                )
+                .arg(
+                    Arg::new("limit")
+                        .short('n')
+                        .long("limit")
+                        .help("Number of results to show (default: 10)")
+                        .value_name("NUM")
+                        .value_parser(clap::value_parser!(usize)),
+                )
+                .arg(
+                    Arg::new("output-dir")
+                        .short('o')
+                        .long("output-dir")
+                        .help("Directory of the run that built the index")
+                        .value_name("DIR"),
+                ),
+        )
+        .subcommand(
+            Command::new("docker")
+                .about("Analyze /workspace and write the reports to /out, for use in containers"),
+        )
+        .subcommand(
+            Command::new("version")
+                .about("Print the version, build and format versions of the analyzer")
+                .arg(
+                    Arg::new("json")
+                        .long("json")
+                        .help("Print the build information as JSON")
+                        .action(ArgAction::SetTrue),
+                ),
+        )
+        .subcommand(
+            Command::new("schema")
+                .about("Print the JSON Schema of findings.json")
+                .arg(
+                    Arg::new("output")
+                        .short('o')
+                        .long("output")
+                        .help("Write the schema to a file instead of stdout")
+                        .value_name("FILE"),
+                ),
+        )
+        .subcommand(
+            Command::new("suppressions")
+                .about("Audit the suppressed findings")
+                .subcommand(
+                    Command::new("list")
+                        .about("List inline and baseline suppressions with owner, age and expiry")
+                        .arg(
+                            Arg::new("PATH")
+                                .help("Directory searched for inline suppressions (default: the configured path)")
+                                .index(1),
+                        )
+                        .arg(
+                            Arg::new("json")
+                                .long("json")
+                                .help("Print the suppressions as JSON")
+                                .action(ArgAction::SetTrue),
+                        ),
+                ),
+        )
+        .subcommand(
+            Command::new("org")
+                .about("Analyze the repositories of an organization")
+                .subcommand(
+                    Command::new("scan")
+                        .about("Clone or update every listed repository, analyze it and write a combined report")
+                        .arg(
+                            Arg::new("config")
+                                .long("config")
+                                .help("YAML or JSON file listing the repositories (default: repos.yaml)")
+                                .value_name("FILE"),
+                        ),
+                ),
+        )
+        .subcommand(
+            Command::new("publish")
+                .about("Upload the findings of the last run to the sentinel-backend")
+                .arg(
+                    Arg::new("dir")
+                        .long("dir")
+                        .help("Directory with findings.json (default: the output directory)")
+                        .value_name("DIR"),
+                ),
+        )
+        .subcommand(
+            Command::new("serve-results")
+                .about("Serve the reports of a run over a read-only HTTP API for the frontend")
+                .arg(
+                    Arg::new("dir")
+                        .long("dir")
+                        .help("Directory with the reports (default: the output directory)")
+                        .value_name("DIR"),
+                )
+                .arg(
+                    Arg::new("port")
+                        .short('p')
+                        .long("port")
+                        .help("Port to listen on (default: 3001)")
+                        .value_name("PORT")
+                        .value_parser(clap::value_parser!(u16)),
+                )
+                .arg(
+                    Arg::new("cors-origin")
+                        .long("cors-origin")
+                        .help("Origin allowed to call the API from a browser (repeatable, default: any)")
+                        .value_name("ORIGIN")
+                        .action(ArgAction::Append),
+                ),
+        )
+        .subcommand(
+            Command::new("ast")
+                .about("Capture syntax trees and run rules on them, for offline rule development")
+                .subcommand(
+                    Command::new("dump")
+                        .about("Write the syntax tree of a file as a JSON snapshot")
+                        .arg(
+                            Arg::new("FILE")
+                                .help("File to capture")
+                                .required(true)
+                                .index(1),
+                        )
+                        .arg(
+                            Arg::new("output")
+                                .short('o')
+                                .long("output")
+                                .help("Write the snapshot to a file instead of stdout")
+                                .value_name("FILE"),
+                        ),
+                )
+                .subcommand(
+                    Command::new("run-rule")
+                        .about("Run a single rule on a snapshot written by ast dump")
+                        .arg(
+                            Arg::new("ast")
+                                .long("ast")
+                                .help("Snapshot to run the rule on")
+                                .value_name("FILE")
+                                .required(true),
+                        )
+                        .arg(
+                            Arg::new("rule")
+                                .long("rule")
+                                .help("ID of the rule")
+                                .value_name("ID")
+                                .required(true),
+                        )
+                        .arg(
+                            Arg::new("options")
+                                .long("options")
+                                .help("Options of the rule as JSON, e.g. '{\"maxInputs\": 3}'")
+                                .value_name("JSON"),
+                        )
+                        .arg(
+                            Arg::new("force")
+                                .long("force")
+                                .help("Run the rule even if this parser builds another tree than the snapshot")
+                                .action(ArgAction::SetTrue),
+                        )
+                        .arg(
+                            Arg::new("json")
+                                .long("json")
+                                .help("Print the findings as JSON")
+                                .action(ArgAction::SetTrue),
+                        ),
+                )
+                .subcommand(
+                    Command::new("inspect")
+                        .about("Print the path of nodes to a position of a file and the node there")
+                        .arg(
+                            Arg::new("FILE")
+                                .help("File to inspect")
+                                .required(true)
+                                .index(1),
+                        )
+                        .arg(
+                            Arg::new("line")
+                                .long("line")
+                                .help("1-based line of the position")
+                                .value_name("LINE")
+                                .required(true)
+                                .value_parser(clap::value_parser!(usize)),
+                        )
+                        .arg(
+                            Arg::new("col")
+                                .long("col")
+                                .help("1-based column of the position, in the configured column unit")
+                                .value_name("COLUMN")
+                                .default_value("1")
+                                .value_parser(clap::value_parser!(usize)),
+                        )
+                        .arg(
+                            Arg::new("json")
+                                .long("json")
+                                .help("Print the path, node and children as JSON")
+                                .action(ArgAction::SetTrue),
+                        ),
+                ),
+        )
+        .subcommand(
+            Command::new("cache")
+                .about("Move the rule cache between machines, e.g. as a CI artifact")
+                .subcommand(
+                    Command::new("export")
+                        .about("Bundle the rule cache into a zstd-compressed tar archive")
+                        .arg(
+                            Arg::new("ARCHIVE")
+                                .help("Archive to write, e.g. cache.tar.zst")
+                                .required(true)
+                                .index(1),
+                        ),
+                )
+                .subcommand(
+                    Command::new("import")
+                        .about("Restore the rule cache from an archive, dropping files that are not in the checkout")
+                        .arg(
+                            Arg::new("ARCHIVE")
+                                .help("Archive written by cache export")
+                                .required(true)
+                                .index(1),
+                        )
+                        .arg(
+                            Arg::new("PATH")
+                                .help("Checkout the cached paths are relative to (default: the configured path)")
+                                .index(2),
+                        ),
+                ),
+        )
+        .subcommand(
+            Command::new("doctor")
+                .about("Check the configuration, rules, parser, cache and git, and print a pass/fail table"),
+        )
+        .subcommand(
+            Command::new("rules")
+                .about("Inspect the registered rules")
+                .subcommand(
+                    Command::new("stats")
+                        .about("Show matches, fix and suppression rates per rule from the run history")
+                        .arg(
+                            Arg::new("json")
+                                .long("json")
+                                .help("Print the statistics as JSON")
+                                .action(ArgAction::SetTrue),
+                        )
+                        .arg(
+                            Arg::new("output-dir")
+                                .short('o')
+                                .long("output-dir")
+                                .help("Directory of the runs with history.jsonl")
+                                .value_name("DIR"),
+                        ),
+                )
+                .subcommand(
+                    Command::new("docs")
+                        .about("Generate the rules reference as markdown")
+                        .arg(
+                            Arg::new("output")
+                                .short('o')
+                                .long("output")
+                                .help("Write the reference to a file instead of stdout")
+                                .value_name("FILE"),
+                        ),
+                ),
+        )
 }
 
 /// Get debug level from parsed arguments
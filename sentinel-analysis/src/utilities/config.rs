@@ -1,5 +1,18 @@
+use crate::blame::{BlamePolicy, current_user_email};
+use crate::cache::DEFAULT_CACHE_PATH;
+use crate::editor_links::EditorLinks;
+use crate::filters::FindingFilter;
+use crate::limits::FindingLimits;
+use crate::messages::Locale;
+use crate::new_code::ChangedLines;
+use crate::output::OutputSpec;
+use crate::serve::ServeOptions;
 use crate::utilities::DebugLevel;
+use crate::utilities::paths::PathBase;
+use crate::utilities::source::ColumnUnit;
+use chrono::NaiveDate;
 use serde::{Deserialize, Serialize};
+use std::collections::HashMap;
 use std::fs;
 use std::io::Read;
 
@@ -11,6 +24,16 @@ pub struct Config {
     pub export_metrics_csv: Option<String>,
     /// Number of threads to use for parallel processing (default: all available)
     pub threads: Option<usize>,
+    /// Maximum number of files preloaded and analyzed together (default: twice the cores)
+    pub batch_size: Option<usize>,
+    /// Maximum size in bytes of the files preloaded together (default: 16 MiB)
+    pub batch_bytes: Option<u64>,
+    /// Resident memory in MiB above which the analysis streams, e.g. the limit of a CI runner
+    pub memory_limit_mb: Option<u64>,
+    /// Number of threads reading files (default: the analysis threads)
+    pub read_threads: Option<usize>,
+    /// Size in bytes from which files are memory-mapped instead of read (default: never)
+    pub mmap_threshold: Option<u64>,
     /// Path to rules configuration file
     pub rules_config: Option<String>,
     /// Debug level for controlling output verbosity
@@ -19,6 +42,112 @@ pub struct Config {
     pub output_dir: Option<String>,
     /// API URL for submitting analysis results
     pub api_url: Option<String>,
+    /// Named rule preset (recommended, strict, migration) used when no rules config is found
+    pub preset: Option<String>,
+    /// Write LLM-ready code chunks with their findings to chunks.jsonl
+    pub emit_chunks: Option<bool>,
+    /// Build the semantic search index from the chunks
+    pub embed: Option<bool>,
+    /// Embedding provider used for the semantic search index
+    pub embeddings: Option<EmbeddingsConfig>,
+    /// LLM endpoint used for fix suggestions with --ai-suggestions
+    pub ai_suggestions: Option<AiSuggestionsConfig>,
+    /// Backend the findings are uploaded to by `scoper publish`
+    pub publish: Option<PublishConfig>,
+    /// Notifications sent after a run, see `notifications`
+    pub notifications: Option<NotificationsConfig>,
+    /// Options of `scoper serve-results`
+    pub serve: Option<ServeConfig>,
+    /// Tokenizer used to measure chunks
+    pub tokenizer: Option<TokenizerConfig>,
+    /// Token limit of a chunk (default: 2048)
+    pub max_chunk_tokens: Option<usize>,
+    /// Reuse the rule results of unchanged files from the previous run
+    pub cache: Option<bool>,
+    /// Append the findings per rule of every run to history.jsonl
+    pub history: Option<bool>,
+    /// Path of the rule cache (default: .sentinel-cache/rule-results.json)
+    pub cache_path: Option<String>,
+    /// Results of a previous run to reuse if the rule cache is missing, e.g.
+    /// out/analysis_results.json from a CI artifact
+    pub previous: Option<String>,
+    /// Lowest severity of findings that fails a `docker` run: error (default), warning, never
+    pub fail_on: Option<String>,
+    /// Baseline file of suppressed findings (default: sentinel-suppressions.json)
+    pub suppressions: Option<String>,
+    /// Maximum number of findings written to the reports, see `limits`
+    pub max_findings: Option<usize>,
+    /// Maximum number of findings of every rule written to the reports
+    pub max_findings_per_rule: Option<usize>,
+    /// Maximum number of findings of single rules, overriding `max_findings_per_rule`
+    pub max_findings_by_rule: Option<HashMap<String, usize>>,
+    /// Rules raised to a higher severity when their findings grow, see `escalation`
+    pub escalation: Option<HashMap<String, EscalationPolicy>>,
+    /// Conditions the reported findings must meet, see `quality_gates`
+    pub quality_gates: Option<QualityGates>,
+    /// Alert thresholds on the counters of count-only rules, by counter, see `counters`
+    pub counter_alerts: Option<HashMap<String, CounterAlert>>,
+    /// Run the rules of the `test-rules` category on test files
+    pub analyze_tests: Option<bool>,
+    /// Skip test files entirely when scanning
+    pub exclude_tests: Option<bool>,
+    /// Globs identifying test files (default: spec, test and stories files)
+    pub test_patterns: Option<Vec<String>>,
+    /// Globs identifying generated files, in addition to `@generated` / `DO NOT EDIT` headers
+    pub generated_patterns: Option<Vec<String>>,
+    /// Run the rules on generated files as well (default: false)
+    pub include_generated: Option<bool>,
+    /// Directory levels below the project root rolled up in `by_directory` (default: 2)
+    pub directory_depth: Option<usize>,
+    /// Weight the hotspots by the number of commits touching each file
+    pub churn: Option<bool>,
+    /// Only count commits after this `git log --since` date, e.g. `6 months ago`
+    pub churn_since: Option<String>,
+    /// Branch compared with by `--new-code-only` (default: origin/HEAD, main or master)
+    pub new_code_base: Option<String>,
+    /// Number of files reported as hotspots (default: 10)
+    pub hotspot_limit: Option<usize>,
+    /// Format of the findings report: json (default), sarif, template, backstage or security
+    pub format: Option<String>,
+    /// Template rendering the report with `"format": "template"`
+    pub template: Option<String>,
+    /// Reports written in addition to findings.json, see `OutputSpec`
+    pub outputs: Option<Vec<OutputSpec>>,
+    /// Directory the file paths in reports are relative to (default: the analyzed directory)
+    pub path_base: Option<String>,
+    /// Unit of column numbers: utf-16 (default, like editors), char or byte
+    pub column_unit: Option<String>,
+    /// Endpoint receiving false-positive reports from --report-fp, instead of feedback.jsonl
+    pub feedback_url: Option<String>,
+    /// Editor the `editor_url` of findings opens: a name like `vscode` or a URL template
+    pub editor: Option<String>,
+    /// Language of the messages in the reports: en (default) or de
+    pub locale: Option<String>,
+    /// Attach the author, commit and date of the last change of their line to findings
+    pub blame: Option<bool>,
+}
+
+/// Configuration of the tokenizer used for chunk sizes
+#[derive(Serialize, Deserialize, Debug, Default, Clone)]
+pub struct TokenizerConfig {
+    /// Target model, used to pick the encoding, e.g. `gpt-4o`
+    pub model: Option<String>,
+    /// Encoding, `cl100k_base` or `o200k_base`; overrides the model
+    pub encoding: Option<String>,
+    /// Path of the `.tiktoken` rank file of the encoding
+    pub ranks_path: Option<String>,
+}
+
+/// Configuration of the embedding provider
+#[derive(Serialize, Deserialize, Debug, Default, Clone)]
+pub struct EmbeddingsConfig {
+    /// One of `hashing` (default), `openai` or `local`
+    pub provider: Option<String>,
+    pub model: Option<String>,
+    /// Embeddings endpoint, for `local` providers or OpenAI-compatible proxies
+    pub url: Option<String>,
+    /// API key, defaults to the OPENAI_API_KEY environment variable for `openai`
+    pub api_key: Option<String>,
 }
 
 impl Config {
@@ -75,8 +204,61 @@ impl Config {
         Config::default()
     }
 
+    /// Override settings with `SENTINEL_*` environment variables
+    ///
+    /// Supported are `SENTINEL_PRESET`, `SENTINEL_RULES_CONFIG`, `SENTINEL_DEBUG_LEVEL`,
+    /// `SENTINEL_THREADS`, `SENTINEL_MEMORY_LIMIT_MB`, `SENTINEL_EMIT_CHUNKS`, `SENTINEL_CACHE`
+    /// and `SENTINEL_FAIL_ON`.
+    pub fn apply_env(&mut self) -> Result<(), String> {
+        let var = |name: &str| std::env::var(name).ok().filter(|value| !value.is_empty());
+        let flag = |name: &str| -> Result<Option<bool>, String> {
+            match var(name).as_deref() {
+                None => Ok(None),
+                Some("1" | "true") => Ok(Some(true)),
+                Some("0" | "false") => Ok(Some(false)),
+                Some(value) => Err(format!("Invalid value for {}: {}", name, value)),
+            }
+        };
+
+        if let Some(preset) = var("SENTINEL_PRESET") {
+            self.preset = Some(preset);
+        }
+        if let Some(rules_config) = var("SENTINEL_RULES_CONFIG") {
+            self.rules_config = Some(rules_config);
+        }
+        if let Some(level) = var("SENTINEL_DEBUG_LEVEL") {
+            self.debug_level = Some(level.parse()?);
+        }
+        if let Some(threads) = var("SENTINEL_THREADS") {
+            self.threads = Some(
+                threads
+                    .parse()
+                    .map_err(|_| format!("Invalid value for SENTINEL_THREADS: {}", threads))?,
+            );
+        }
+        if let Some(limit) = var("SENTINEL_MEMORY_LIMIT_MB") {
+            self.memory_limit_mb =
+                Some(limit.parse().map_err(|_| {
+                    format!("Invalid value for SENTINEL_MEMORY_LIMIT_MB: {}", limit)
+                })?);
+        }
+        if let Some(emit_chunks) = flag("SENTINEL_EMIT_CHUNKS")? {
+            self.emit_chunks = Some(emit_chunks);
+        }
+        if let Some(cache) = flag("SENTINEL_CACHE")? {
+            self.cache = Some(cache);
+        }
+        if let Some(fail_on) = var("SENTINEL_FAIL_ON") {
+            if !matches!(fail_on.as_str(), "error" | "warning" | "never") {
+                return Err(format!("Invalid value for SENTINEL_FAIL_ON: {}", fail_on));
+            }
+            self.fail_on = Some(fail_on);
+        }
+        Ok(())
+    }
+
     /// Try to load config from a specific path
-    fn try_load_from_path(path: &str) -> Option<Self> {
+    pub fn try_load_from_path(path: &str) -> Option<Self> {
         match fs::File::open(path) {
             Ok(mut file) => {
                 let mut contents = String::new();
@@ -98,6 +280,104 @@ impl Config {
     }
 }
 
+/// Configuration of the LLM-assisted fix suggestions
+#[derive(Serialize, Deserialize, Debug, Default, Clone)]
+pub struct AiSuggestionsConfig {
+    /// OpenAI-compatible chat completions endpoint
+    pub url: Option<String>,
+    pub model: Option<String>,
+    pub api_key: Option<String>,
+    /// Rules whose findings get a suggestion
+    #[serde(default)]
+    pub rules: Vec<String>,
+    /// Maximum number of suggestions per run (default: 20)
+    pub max_suggestions: Option<usize>,
+}
+
+/// Escalation of a rule whose findings grow run-over-run
+#[derive(Serialize, Deserialize, Debug, Default, Clone)]
+pub struct EscalationPolicy {
+    /// Increase of findings over the previous run that is still tolerated
+    #[serde(default)]
+    pub threshold: usize,
+    /// Severity the findings are raised to, error (default) or warning
+    pub severity: Option<String>,
+}
+
+/// Alert thresholds of a counter of count-only rules
+#[derive(Serialize, Deserialize, Debug, Default, Clone)]
+pub struct CounterAlert {
+    /// Highest count that is still tolerated
+    pub max: Option<usize>,
+    /// Increase over the previous run that is still tolerated
+    pub max_increase: Option<usize>,
+    /// Whether a breach fails the quality gate, otherwise it is only reported
+    #[serde(default)]
+    pub fail_quality_gate: bool,
+}
+
+/// Conditions a run must meet, a failed gate fails the run
+#[derive(Serialize, Deserialize, Debug, Default, Clone)]
+pub struct QualityGates {
+    /// Maximum number of errors that were not in the previous run
+    pub max_new_errors: Option<usize>,
+    /// Maximum number of warnings per category, e.g. `{ "security": 0 }`
+    #[serde(default)]
+    pub max_warnings_by_category: HashMap<String, usize>,
+    /// Minimum average signal migration readiness score, from 0 to 100
+    pub min_migration_readiness: Option<f64>,
+}
+
+/// Configuration of the upload of findings to the sentinel-backend
+#[derive(Serialize, Deserialize, Debug, Default, Clone)]
+pub struct PublishConfig {
+    /// Base URL of the backend, e.g. `https://sentinel.example.com`
+    pub url: Option<String>,
+    /// Id of the project in the backend the findings belong to
+    pub project_id: Option<u64>,
+    /// Bearer token, defaults to the SENTINEL_PUBLISH_TOKEN environment variable
+    pub token: Option<String>,
+    /// Retries of failed uploads, with exponential backoff (default: 3)
+    pub retries: Option<u32>,
+}
+
+/// Options of the results server
+#[derive(Serialize, Deserialize, Debug, Default, Clone)]
+pub struct ServeConfig {
+    /// Origins allowed to call the API from a browser (default: any origin)
+    pub cors_origins: Option<Vec<String>>,
+    /// Print a JSON line per request (default: true)
+    pub access_log: Option<bool>,
+}
+
+/// Notifications sent after a run
+#[derive(Serialize, Deserialize, Debug, Default, Clone)]
+pub struct NotificationsConfig {
+    /// Email the summary of every run over SMTP
+    pub email: Option<EmailConfig>,
+}
+
+/// SMTP delivery of the report of a run
+#[derive(Serialize, Deserialize, Debug, Default, Clone)]
+pub struct EmailConfig {
+    pub smtp_host: String,
+    /// Port of the SMTP server (default: 587 with starttls, 465 with tls, 25 without)
+    pub smtp_port: Option<u16>,
+    /// Encryption of the connection: starttls (default), tls or none
+    pub tls: Option<String>,
+    /// User to log in as, no login if not set
+    pub username: Option<String>,
+    /// Password, defaults to the SENTINEL_SMTP_PASSWORD environment variable
+    pub password: Option<String>,
+    /// Sender, e.g. `Sentinel <sentinel@example.com>`
+    pub from: String,
+    pub to: Vec<String>,
+    /// Subject of the mail (default: the number of findings and errors)
+    pub subject: Option<String>,
+    /// Only send the report if the run has findings
+    pub only_on_findings: Option<bool>,
+}
+
 /// Helper function to get debug level
 pub fn get_debug_level(config: &Config, args: &[String]) -> DebugLevel {
     // Check for command line argument first
@@ -151,6 +431,82 @@ pub fn get_enabled_rules(
     None
 }
 
+/// Helper function to get the rule preset name from command line
+pub fn get_preset_name(args: &[String]) -> Option<String> {
+    for (i, arg) in args.iter().enumerate() {
+        if let Some(value) = arg.strip_prefix("--preset=") {
+            return Some(value.to_string());
+        }
+        if arg == "--preset" {
+            return args.get(i + 1).cloned();
+        }
+    }
+
+    None
+}
+
+/// Helper function to get the rule include and exclude selectors from command line
+///
+/// Supports `--rules-include=angular,rxjs` as well as `--rules-include angular,rxjs`.
+/// `--enable-tag` and `--disable-tag` are accepted as single-value aliases.
+pub fn get_rule_selectors(args: &[String]) -> (Vec<String>, Vec<String>) {
+    let mut include = Vec::new();
+    let mut exclude = Vec::new();
+
+    for (i, arg) in args.iter().enumerate() {
+        let (flag, inline_value) = match arg.split_once('=') {
+            Some((flag, value)) => (flag, Some(value.to_string())),
+            None => (arg.as_str(), None),
+        };
+
+        let target = match flag {
+            "--rules-include" | "--enable-tag" => &mut include,
+            "--rules-exclude" | "--disable-tag" => &mut exclude,
+            _ => continue,
+        };
+
+        let value = match inline_value.or_else(|| args.get(i + 1).cloned()) {
+            Some(value) => value,
+            None => continue,
+        };
+
+        target.extend(
+            value
+                .split(',')
+                .map(|s| s.trim().to_lowercase())
+                .filter(|s| !s.is_empty()),
+        );
+    }
+
+    (include, exclude)
+}
+
+/// Get the rules selected with `--rule-debug`
+///
+/// Supports `--rule-debug=rxjs-subscription-leak` as well as a comma-separated list, and
+/// can be repeated.
+pub fn get_rule_debug(args: &[String]) -> Vec<String> {
+    let mut rules = Vec::new();
+
+    for (i, arg) in args.iter().enumerate() {
+        let value = match arg.split_once('=') {
+            Some(("--rule-debug", value)) => Some(value.to_string()),
+            None if arg == "--rule-debug" => args.get(i + 1).cloned(),
+            _ => None,
+        };
+        if let Some(value) = value {
+            rules.extend(
+                value
+                    .split(',')
+                    .map(|s| s.trim().to_string())
+                    .filter(|s| !s.is_empty()),
+            );
+        }
+    }
+
+    rules
+}
+
 /// Helper function to get the target directory path
 pub fn get_target_path(config: &Config, args: &[String]) -> String {
     // Command line argument takes precedence over config file
@@ -164,6 +520,19 @@ pub fn get_target_path(config: &Config, args: &[String]) -> String {
     }
 }
 
+/// Helper function to get the directory the file paths in reports are relative to
+pub fn get_path_base(config: &Config, target_path: &str) -> PathBase {
+    PathBase::new(config.path_base.as_deref().unwrap_or(target_path))
+}
+
+/// Helper function to get the unit of the column numbers in reports
+pub fn get_column_unit(config: &Config) -> Result<ColumnUnit, String> {
+    config
+        .column_unit
+        .as_deref()
+        .map_or(Ok(ColumnUnit::default()), ColumnUnit::parse)
+}
+
 /// Helper function to get the output directory from command line
 pub fn get_output_dir(config: &Config, args: &[String]) -> String {
     // Check for command line argument first
@@ -180,6 +549,268 @@ pub fn get_output_dir(config: &Config, args: &[String]) -> String {
         .unwrap_or_else(|| "findings".to_string())
 }
 
+/// Helper function to check if the run should be appended to the history
+pub fn get_history(config: &Config, args: &[String]) -> bool {
+    // Command line flag takes precedence over config file
+    if args.iter().any(|arg| arg == "--history") {
+        return true;
+    }
+
+    config.history.unwrap_or(false)
+}
+
+/// Helper function to check if chunks should be emitted
+pub fn get_emit_chunks(config: &Config, args: &[String]) -> bool {
+    // Command line flag takes precedence over config file
+    if args.iter().any(|arg| arg == "--emit-chunks") {
+        return true;
+    }
+
+    config.emit_chunks.unwrap_or(false)
+}
+
+/// Helper function to check if the findings should be printed as a directory tree
+pub fn get_tree(args: &[String]) -> bool {
+    args.iter().any(|arg| arg == "--tree")
+}
+
+/// Helper function to check if the hotspots should be weighted by git churn
+pub fn get_churn(config: &Config, args: &[String]) -> bool {
+    // Command line flag takes precedence over config file
+    if args.iter().any(|arg| arg == "--churn") {
+        return true;
+    }
+
+    config.churn.unwrap_or(false)
+}
+
+/// Helper function to get all values of an option given as `--name value` or `--name=value`
+fn get_arg_values(args: &[String], name: &str) -> Vec<String> {
+    let prefix = format!("{}=", name);
+    let mut values = Vec::new();
+    for i in 0..args.len() {
+        if let Some(value) = args[i].strip_prefix(&prefix) {
+            values.push(value.to_string());
+        } else if args[i] == name && i + 1 < args.len() {
+            values.push(args[i + 1].clone());
+        }
+    }
+    values
+}
+
+/// Helper function to get the first value of an option given as `--name value` or `--name=value`
+fn get_arg_value(args: &[String], name: &str) -> Option<String> {
+    get_arg_values(args, name).into_iter().next()
+}
+
+/// Helper function to get the format of the findings report
+pub fn get_format(config: &Config, args: &[String]) -> String {
+    // Command line argument takes precedence over config file
+    get_arg_value(args, "--format")
+        .or_else(|| config.format.clone())
+        .unwrap_or_else(|| "json".to_string())
+}
+
+/// Helper function to get the template of the findings report
+pub fn get_template(config: &Config, args: &[String]) -> Option<String> {
+    // Command line argument takes precedence over config file
+    get_arg_value(args, "--template").or_else(|| config.template.clone())
+}
+
+/// Helper function to get the reports written in addition to findings.json
+///
+/// `--output` arguments replace the configured `outputs`. A `--format` other than json adds
+/// an output of that format.
+pub fn get_outputs(config: &Config, args: &[String]) -> Result<Vec<OutputSpec>, String> {
+    // Command line arguments take precedence over config file
+    let specs = get_arg_values(args, "--output");
+    let mut outputs = if specs.is_empty() {
+        config.outputs.clone().unwrap_or_default()
+    } else {
+        specs
+            .iter()
+            .map(|spec| OutputSpec::parse(spec))
+            .collect::<Result<Vec<_>, _>>()?
+    };
+
+    let format = get_format(config, args);
+    if format != "json" {
+        let mut output = OutputSpec {
+            format,
+            ..Default::default()
+        };
+        if let Some(template) = get_template(config, args) {
+            output.options.insert("template".to_string(), template);
+        }
+        outputs.push(output);
+    }
+    Ok(outputs)
+}
+
+/// Helper function to get the caps on the findings written to the reports
+pub fn get_finding_limits(config: &Config, args: &[String]) -> FindingLimits {
+    // Command line arguments take precedence over config file
+    let arg = |name: &str| get_arg_value(args, name).and_then(|value| value.parse().ok());
+    FindingLimits {
+        max_findings: arg("--max-findings").or(config.max_findings),
+        max_findings_per_rule: arg("--max-findings-per-rule").or(config.max_findings_per_rule),
+        max_findings_by_rule: config.max_findings_by_rule.clone().unwrap_or_default(),
+    }
+}
+
+/// Helper function to get the filters slicing the findings of the run
+pub fn get_finding_filter(args: &[String]) -> FindingFilter {
+    // Every flag can be repeated and take a comma-separated list
+    let values = |name: &str| -> Vec<String> {
+        get_arg_values(args, name)
+            .iter()
+            .flat_map(|value| value.split(','))
+            .map(str::trim)
+            .filter(|value| !value.is_empty())
+            .map(str::to_string)
+            .collect()
+    };
+    FindingFilter {
+        rules: values("--filter-rule"),
+        severities: values("--filter-severity"),
+        paths: values("--filter-path"),
+    }
+}
+
+/// Helper function to get the editor links of the findings, `None` if no editor is set
+pub fn get_editor_links(
+    config: &Config,
+    args: &[String],
+    path_base: &PathBase,
+) -> Result<Option<EditorLinks>, String> {
+    // Command line argument takes precedence over config file
+    get_arg_value(args, "--editor")
+        .or_else(|| config.editor.clone())
+        .map(|editor| EditorLinks::new(&editor, path_base.clone()))
+        .transpose()
+}
+
+/// Helper function to get the language of the messages in the reports
+pub fn get_locale(config: &Config, args: &[String]) -> Result<Locale, String> {
+    // Command line argument takes precedence over config file
+    get_arg_value(args, "--locale")
+        .or_else(|| config.locale.clone())
+        .map_or(Ok(Locale::default()), |locale| Locale::parse(&locale))
+}
+
+/// Helper function to check if findings are annotated with git blame
+pub fn get_blame(config: &Config, args: &[String]) -> bool {
+    // Command line flags take precedence over config file; the blame policies need it
+    args.iter()
+        .any(|arg| arg == "--blame" || arg == "--only-mine")
+        || get_arg_value(args, "--since").is_some()
+        || config.blame.unwrap_or(false)
+}
+
+/// Helper function to get the "new code only" policy on the authorship of findings
+pub fn get_blame_policy(args: &[String], path_base: &PathBase) -> Result<BlamePolicy, String> {
+    let author_email = match args.iter().any(|arg| arg == "--only-mine") {
+        true => Some(current_user_email(&path_base.resolve("."))?),
+        false => None,
+    };
+    let since = get_arg_value(args, "--since")
+        .map(|date| {
+            NaiveDate::parse_from_str(&date, "%Y-%m-%d")
+                .map_err(|_| format!("Invalid date for --since: {}, expected YYYY-MM-DD", date))
+        })
+        .transpose()?;
+    Ok(BlamePolicy {
+        author_email,
+        since,
+    })
+}
+
+/// Helper function to get the lines changed on the current branch with `--new-code-only`
+pub fn get_changed_lines(
+    config: &Config,
+    args: &[String],
+    path_base: &PathBase,
+) -> Result<Option<ChangedLines>, String> {
+    if !args.iter().any(|arg| arg == "--new-code-only") {
+        return Ok(None);
+    }
+
+    // Command line argument takes precedence over config file
+    let base = get_arg_value(args, "--new-code-base").or_else(|| config.new_code_base.clone());
+    ChangedLines::load(&path_base.resolve("."), base.as_deref()).map(Some)
+}
+
+/// Helper function to get the options of the results server
+pub fn get_serve_options(config: &Config, args: &[String]) -> ServeOptions {
+    let serve = config.serve.clone().unwrap_or_default();
+    // Command line arguments take precedence over config file
+    let origins = get_arg_values(args, "--cors-origin");
+    ServeOptions {
+        cors_origins: match origins.is_empty() {
+            true => serve.cors_origins.unwrap_or_default(),
+            false => origins,
+        },
+        access_log: serve.access_log.unwrap_or(true),
+    }
+}
+
+/// Helper function to get the path of the rule cache, `None` if caching is disabled
+pub fn get_cache_path(config: &Config, args: &[String]) -> Option<String> {
+    // Command line flag takes precedence over config file
+    let enabled = args.iter().any(|arg| arg == "--cache") || config.cache.unwrap_or(false);
+    if !enabled {
+        return None;
+    }
+
+    Some(
+        config
+            .cache_path
+            .clone()
+            .unwrap_or_else(|| DEFAULT_CACHE_PATH.to_string()),
+    )
+}
+
+/// Helper function to get the results file of a previous run, `None` if not set
+///
+/// Supports `--previous=out/analysis_results.json` as well as `--previous out/...`.
+pub fn get_previous_results(config: &Config, args: &[String]) -> Option<String> {
+    // Command line argument takes precedence over config file
+    for (i, arg) in args.iter().enumerate() {
+        match arg.split_once('=') {
+            Some(("--previous", value)) => return Some(value.to_string()),
+            None if arg == "--previous" => return args.get(i + 1).cloned(),
+            _ => {}
+        }
+    }
+
+    config.previous.clone()
+}
+
+/// Helper function to check if the semantic search index should be built
+pub fn get_embed(config: &Config, args: &[String]) -> bool {
+    // Command line flag takes precedence over config file
+    if args.iter().any(|arg| arg == "--embed") {
+        return true;
+    }
+
+    config.embed.unwrap_or(false)
+}
+
+/// Helper function to get the AI suggestion settings if --ai-suggestions is set
+pub fn get_ai_suggestions<'c>(
+    config: &'c Config,
+    args: &[String],
+) -> Option<&'c AiSuggestionsConfig> {
+    if !args.iter().any(|arg| arg == "--ai-suggestions") {
+        return None;
+    }
+
+    if config.ai_suggestions.is_none() {
+        eprintln!("Warning: --ai-suggestions requires an ai_suggestions section in sentinel.json");
+    }
+    config.ai_suggestions.as_ref()
+}
+
 /// Helper function to get metrics JSON path based on output directory
 pub fn get_metrics_json_path(config: &Config, output_dir: Option<&String>) -> Option<String> {
     if let Some(path) = &config.export_metrics_json {
@@ -0,0 +1,51 @@
+/// Match a path or module specifier against a glob pattern
+///
+/// Supported syntax:
+/// - `*` matches any sequence of characters except `/`
+/// - `**` matches any sequence of characters including `/`
+/// - `?` matches a single character except `/`
+///
+/// A trailing `/**` also matches the directory itself, so `src/app/**` matches `src/app`.
+pub fn glob_match(pattern: &str, text: &str) -> bool {
+    if let Some(prefix) = pattern.strip_suffix("/**") {
+        if text == prefix {
+            return true;
+        }
+    }
+    match_bytes(pattern.as_bytes(), text.as_bytes())
+}
+
+fn match_bytes(pattern: &[u8], text: &[u8]) -> bool {
+    match pattern.first() {
+        None => text.is_empty(),
+        Some(b'*') if pattern.get(1) == Some(&b'*') => {
+            // `**/` may also match zero directories
+            let rest = &pattern[2..];
+            if rest.first() == Some(&b'/') && match_bytes(&rest[1..], text) {
+                return true;
+            }
+            (0..=text.len()).any(|i| match_bytes(rest, &text[i..]))
+        }
+        Some(b'*') => {
+            let rest = &pattern[1..];
+            for i in 0..=text.len() {
+                if match_bytes(rest, &text[i..]) {
+                    return true;
+                }
+                if i < text.len() && text[i] == b'/' {
+                    break;
+                }
+            }
+            false
+        }
+        Some(b'?') => !text.is_empty() && text[0] != b'/' && match_bytes(&pattern[1..], &text[1..]),
+        Some(&c) => !text.is_empty() && text[0] == c && match_bytes(&pattern[1..], &text[1..]),
+    }
+}
+
+/// Check if any of the patterns matches the text
+pub fn glob_match_any<S: AsRef<str>>(patterns: &[S], text: &str) -> bool {
+    patterns
+        .iter()
+        .any(|pattern| glob_match(pattern.as_ref(), text))
+}
@@ -0,0 +1,17 @@
+use std::fs;
+
+/// Get the resident memory of the process in bytes
+///
+/// Read from `/proc/self/status`, so only available on Linux; `None` elsewhere.
+pub fn resident_bytes() -> Option<u64> {
+    let status = fs::read_to_string("/proc/self/status").ok()?;
+    let line = status.lines().find(|line| line.starts_with("VmRSS:"))?;
+    let kilobytes: u64 = line
+        .trim_start_matches("VmRSS:")
+        .trim()
+        .trim_end_matches("kB")
+        .trim()
+        .parse()
+        .ok()?;
+    Some(kilobytes * 1024)
+}
@@ -1,7 +1,11 @@
 pub mod cli;
 pub mod config;
 pub mod file_utils;
+pub mod glob;
 pub mod logging;
+pub mod memory;
+pub mod paths;
+pub mod source;
 pub mod threading;
 
 // Re-export the DebugLevel enum directly from the logging module
@@ -0,0 +1,73 @@
+//! Normalization of the file paths in reports
+//!
+//! Reports and the rule cache use paths relative to a base directory, the analyzed
+//! directory unless `path_base` is configured, with forward slashes on every platform.
+//! This keeps the results independent of the machine and the checkout location. Files
+//! outside of the base keep their absolute path.
+
+use crate::FileAnalysisResult;
+use crate::rules_registry::PROJECT_FILE;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+/// Base directory of the paths in reports
+#[derive(Debug, Clone, PartialEq)]
+pub struct PathBase {
+    base: PathBuf,
+}
+
+/// Get the absolute form of a path, resolving symlinks if the path exists
+fn absolute_path(path: &Path) -> PathBuf {
+    fs::canonicalize(path)
+        .or_else(|_| std::path::absolute(path))
+        .unwrap_or_else(|_| path.to_path_buf())
+}
+
+impl PathBase {
+    /// Create a base from a directory, or from the directory of a file
+    pub fn new(base: &str) -> Self {
+        let base = absolute_path(Path::new(base));
+        let base = match base.is_file() {
+            true => base.parent().map(Path::to_path_buf).unwrap_or(base),
+            false => base,
+        };
+        Self { base }
+    }
+
+    /// Get the path of a file relative to the base, with forward slashes
+    pub fn relative(&self, path: &str) -> String {
+        if path == PROJECT_FILE {
+            return path.to_string();
+        }
+
+        let absolute = absolute_path(Path::new(path));
+        let relative = absolute.strip_prefix(&self.base).unwrap_or(&absolute);
+        let relative = relative.to_string_lossy().replace('\\', "/");
+        match relative.is_empty() {
+            true => ".".to_string(),
+            false => relative,
+        }
+    }
+
+    /// Get the path to read a file given relative to the base
+    pub fn resolve(&self, path: &str) -> PathBuf {
+        self.base.join(path)
+    }
+
+    /// Replace the file paths of analysis results with their relative form
+    pub fn normalize_results(&self, results: &mut [FileAnalysisResult]) {
+        for result in results {
+            result.file_path = self.relative(&result.file_path);
+            for symbol in &mut result.angular_symbols {
+                symbol.file = self.relative(&symbol.file);
+            }
+        }
+    }
+}
+
+impl Default for PathBase {
+    /// The working directory
+    fn default() -> Self {
+        Self::new(".")
+    }
+}
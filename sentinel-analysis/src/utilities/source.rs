@@ -0,0 +1,222 @@
+use oxc_diagnostics::OxcDiagnostic;
+use oxc_span::{GetSpan, Span};
+
+/// Move a byte offset back to the start of the character it points into
+fn floor_char_boundary(source: &str, offset: usize) -> usize {
+    let mut offset = offset.min(source.len());
+    while !source.is_char_boundary(offset) {
+        offset -= 1;
+    }
+    offset
+}
+
+/// Move a byte offset forward to the end of the character it points into
+fn ceil_char_boundary(source: &str, offset: usize) -> usize {
+    let mut offset = offset.min(source.len());
+    while !source.is_char_boundary(offset) {
+        offset += 1;
+    }
+    offset
+}
+
+/// Get the text of a span without panicking
+///
+/// Offsets beyond the end of the source are clamped and offsets inside a multi-byte
+/// character are widened to the whole character, so a span from another version of the
+/// file yields a best-effort slice instead of a panic. An inverted span yields `""`.
+pub fn span_text(source: &str, span: Span) -> &str {
+    let start = floor_char_boundary(source, span.start as usize);
+    let end = ceil_char_boundary(source, span.end as usize);
+    if start >= end {
+        ""
+    } else {
+        &source[start..end]
+    }
+}
+
+/// Get the source code of an AST node
+pub fn node_source<'s, T: GetSpan>(node: &T, source: &'s str) -> &'s str {
+    span_text(source, node.span())
+}
+
+/// Get the span of the primary label of a diagnostic, or of its first label
+pub fn diagnostic_span(diagnostic: &OxcDiagnostic) -> Option<Span> {
+    let labels = diagnostic.labels.as_ref()?;
+    let label = labels
+        .iter()
+        .find(|label| label.primary())
+        .or_else(|| labels.first())?;
+    Some(Span::new(
+        label.offset() as u32,
+        (label.offset() + label.len()) as u32,
+    ))
+}
+
+/// Get the code of a span for use in a diagnostic message
+///
+/// Whitespace runs, including line breaks, are collapsed to a single space and the
+/// result is cut after `max_chars` characters with a trailing `…`.
+pub fn snippet(source: &str, span: Span, max_chars: usize) -> String {
+    let collapsed = span_text(source, span)
+        .split_whitespace()
+        .collect::<Vec<_>>()
+        .join(" ");
+
+    match collapsed.char_indices().nth(max_chars) {
+        Some((cut, _)) => format!("{}…", &collapsed[..cut]),
+        None => collapsed,
+    }
+}
+
+/// Get the 1-based line number of a byte offset
+pub fn line_of_offset(source: &str, offset: usize) -> usize {
+    source.as_bytes()[..offset.min(source.len())]
+        .iter()
+        .filter(|&&b| b == b'\n')
+        .count()
+        + 1
+}
+
+/// Unit of the column numbers in reports
+///
+/// Offsets are bytes, while editors count characters or, like VS Code and SARIF consumers,
+/// UTF-16 code units. The units only differ on lines with non-ASCII characters.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub enum ColumnUnit {
+    Byte,
+    Char,
+    #[default]
+    Utf16,
+}
+
+impl ColumnUnit {
+    /// Parse a unit name: `byte`, `char` (or `rune`) or `utf-16`
+    pub fn parse(name: &str) -> Result<Self, String> {
+        match name.to_lowercase().as_str() {
+            "byte" => Ok(Self::Byte),
+            "char" | "rune" => Ok(Self::Char),
+            "utf-16" | "utf16" => Ok(Self::Utf16),
+            _ => Err(format!(
+                "Unknown column unit {}, expected byte, char or utf-16",
+                name
+            )),
+        }
+    }
+
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            Self::Byte => "byte",
+            Self::Char => "char",
+            Self::Utf16 => "utf-16",
+        }
+    }
+}
+
+/// Get the 1-based line and column of a byte offset, counting the column in `unit`
+pub fn position_of_offset(source: &str, offset: usize, unit: ColumnUnit) -> (usize, usize) {
+    let offset = floor_char_boundary(source, offset);
+    let line_start = source[..offset]
+        .rfind('\n')
+        .map_or(0, |newline| newline + 1);
+    let prefix = &source[line_start..offset];
+    let column = match unit {
+        ColumnUnit::Byte => prefix.len(),
+        ColumnUnit::Char => prefix.chars().count(),
+        ColumnUnit::Utf16 => prefix.encode_utf16().count(),
+    };
+    (line_of_offset(source, offset), column + 1)
+}
+
+/// Get the byte offset of a 1-based line and column, counting the column in `unit`
+///
+/// Returns `None` if the file has no such line; a column beyond the end of the line is
+/// clamped to the end of the line.
+pub fn offset_of_position(
+    source: &str,
+    line: usize,
+    column: usize,
+    unit: ColumnUnit,
+) -> Option<usize> {
+    let line_start = match line {
+        0 => return None,
+        1 => 0,
+        line => source.match_indices('\n').nth(line - 2)?.0 + 1,
+    };
+    let line_text = source[line_start..].split('\n').next().unwrap_or("");
+
+    let mut counted = 0;
+    for (index, ch) in line_text.char_indices() {
+        if counted >= column.saturating_sub(1) {
+            return Some(line_start + index);
+        }
+        counted += match unit {
+            ColumnUnit::Byte => ch.len_utf8(),
+            ColumnUnit::Char => 1,
+            ColumnUnit::Utf16 => ch.len_utf16(),
+        };
+    }
+    Some(line_start + line_text.len())
+}
+
+/// Number of leading lines searched for a generated-file marker
+const GENERATED_HEADER_LINES: usize = 5;
+
+/// Check if the header of a file marks it as generated
+///
+/// Code generators for protobuf, GraphQL or OpenAPI clients put `@generated` or
+/// `DO NOT EDIT` in a comment at the top of the file.
+pub fn has_generated_header(source: &str) -> bool {
+    source
+        .lines()
+        .take(GENERATED_HEADER_LINES)
+        .any(|line| line.contains("@generated") || line.to_uppercase().contains("DO NOT EDIT"))
+}
+
+/// Source text decoded from the bytes of a file
+#[derive(Debug, Clone, PartialEq)]
+pub struct DecodedSource {
+    /// The text without byte order mark
+    pub content: String,
+    /// Encoding the file was decoded from, e.g. `utf-8` or `latin-1`
+    pub encoding: &'static str,
+}
+
+/// Decode the bytes of a source file
+///
+/// A UTF-8 byte order mark is stripped, so offsets and columns match what editors show.
+/// Files with a UTF-16 byte order mark are transcoded, and files that are not valid UTF-8
+/// are read as Latin-1. Files containing NUL bytes are binary and cannot be decoded.
+pub fn decode_source(bytes: Vec<u8>) -> Result<DecodedSource, String> {
+    let utf16 = |bytes: &[u8], from: fn([u8; 2]) -> u16, encoding| {
+        let units: Vec<u16> = bytes
+            .chunks_exact(2)
+            .map(|pair| from([pair[0], pair[1]]))
+            .collect();
+        String::from_utf16(&units)
+            .map(|content| DecodedSource { content, encoding })
+            .map_err(|_| format!("Invalid {} content", encoding))
+    };
+
+    match bytes.as_slice() {
+        [0xEF, 0xBB, 0xBF, rest @ ..] => String::from_utf8(rest.to_vec())
+            .map(|content| DecodedSource {
+                content,
+                encoding: "utf-8-bom",
+            })
+            .map_err(|_| "Invalid UTF-8 content after byte order mark".to_string()),
+        [0xFF, 0xFE, rest @ ..] => utf16(rest, u16::from_le_bytes, "utf-16le"),
+        [0xFE, 0xFF, rest @ ..] => utf16(rest, u16::from_be_bytes, "utf-16be"),
+        _ if bytes.contains(&0) => Err("Binary content, not a text file".to_string()),
+        _ => match String::from_utf8(bytes) {
+            Ok(content) => Ok(DecodedSource {
+                content,
+                encoding: "utf-8",
+            }),
+            // Every byte is a Latin-1 character
+            Err(err) => Ok(DecodedSource {
+                content: err.into_bytes().into_iter().map(char::from).collect(),
+                encoding: "latin-1",
+            }),
+        },
+    }
+}
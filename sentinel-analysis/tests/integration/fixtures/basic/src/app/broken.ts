@@ -0,0 +1,3 @@
+const user = { name: 'Ada' };
+const name;
+debugger;
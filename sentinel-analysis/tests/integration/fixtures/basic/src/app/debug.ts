@@ -0,0 +1,4 @@
+export function load(id: string) {
+  debugger;
+  return fetch(`/api/users/${id}`);
+}
@@ -0,0 +1,7 @@
+export function first({}: { id: string }) {
+  return null;
+}
+
+export function second([]: string[]) {
+  return null;
+}
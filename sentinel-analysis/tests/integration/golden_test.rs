@@ -0,0 +1,102 @@
+use serde_json::Value;
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+/// Keys whose values change from run to run, removed before comparing
+const VOLATILE_KEYS: &[&str] = &[
+    "build",
+    "analyzer_version",
+    "timestamp",
+    "total_duration_ms",
+    "files_per_second_wall_time",
+    "parallel_cores_used",
+    "parallel_efficiency_percent",
+    "scan_duration_ms",
+    "analysis_duration_ms",
+];
+
+/// Reports compared with their golden file
+const REPORTS: &[&str] = &["findings.json", "findings.sarif"];
+
+// Test utilities
+fn integration_dir() -> PathBuf {
+    Path::new(env!("CARGO_MANIFEST_DIR")).join("tests/integration")
+}
+
+/// Remove the volatile values and the version of the SARIF driver
+fn normalize(value: &mut Value) {
+    match value {
+        Value::Object(map) => {
+            map.retain(|key, _| !VOLATILE_KEYS.contains(&key.as_str()));
+            if map.get("name").and_then(Value::as_str) == Some("scoper") {
+                map.remove("version");
+            }
+            map.values_mut().for_each(normalize);
+        }
+        Value::Array(items) => items.iter_mut().for_each(normalize),
+        _ => {}
+    }
+}
+
+/// Run the CLI on a fixture repository and compare its reports with the golden files
+///
+/// Set `UPDATE_GOLDEN=1` to write the golden files instead, and review them before
+/// committing. Without it a missing golden file fails the test like a differing one.
+fn check_fixture(name: &str) {
+    let fixture = integration_dir().join("fixtures").join(name);
+    let golden = integration_dir().join("golden").join(name);
+    let output = tempfile::tempdir().unwrap();
+
+    let status = Command::new(env!("CARGO_BIN_EXE_scoper"))
+        .current_dir(&fixture)
+        .env("SENTINEL_CONFIG", fixture.join("sentinel.json"))
+        .args([
+            "src",
+            "--rules",
+            "no-debugger,no-empty-pattern",
+            "--output-dir",
+        ])
+        .arg(output.path())
+        .status()
+        .expect("Failed to run scoper");
+    assert!(status.success(), "scoper failed on fixture {}", name);
+
+    let update = std::env::var("UPDATE_GOLDEN").is_ok_and(|value| value == "1");
+    for report in REPORTS {
+        let content = fs::read_to_string(output.path().join(report))
+            .unwrap_or_else(|e| panic!("{} of fixture {} not written: {}", report, name, e));
+        let mut actual: Value = serde_json::from_str(&content).unwrap();
+        normalize(&mut actual);
+        let actual = serde_json::to_string_pretty(&actual).unwrap() + "\n";
+
+        let golden_path = golden.join(report);
+        if update {
+            fs::create_dir_all(&golden).unwrap();
+            fs::write(&golden_path, &actual).unwrap();
+            eprintln!("Wrote {}", golden_path.display());
+            continue;
+        }
+        let expected = fs::read_to_string(&golden_path).unwrap_or_else(|e| {
+            panic!(
+                "Golden file {} of fixture {} is missing ({}), run with UPDATE_GOLDEN=1 and commit it",
+                golden_path.display(),
+                name,
+                e
+            )
+        });
+        assert_eq!(
+            expected,
+            actual,
+            "{} of fixture {} differs from {}, rerun with UPDATE_GOLDEN=1 if the change is intended",
+            report,
+            name,
+            golden_path.display()
+        );
+    }
+}
+
+#[test]
+fn test_basic_fixture_matches_golden_files() {
+    check_fixture("basic");
+}
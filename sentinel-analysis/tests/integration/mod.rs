@@ -0,0 +1,2 @@
+// Golden-file tests of the complete CLI
+mod golden_test;
@@ -1,2 +1,6 @@
 // Test modules
 mod rules;
+// Pipeline tests on in-memory sources
+mod pipeline;
+// Golden-file tests of the CLI on fixture repositories
+mod integration;
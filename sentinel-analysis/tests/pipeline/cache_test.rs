@@ -0,0 +1,101 @@
+use scoper::utilities::config::Config;
+use scoper::{Analysis, Sentinel};
+use std::path::Path;
+use std::time::Duration;
+
+// Test utilities
+fn run(dir: &Path) -> Analysis {
+    Sentinel::new(Config {
+        cache: Some(true),
+        cache_path: Some(dir.join("cache.json").to_string_lossy().into_owned()),
+        rules_config: Some(dir.join("rules.json").to_string_lossy().into_owned()),
+        output_dir: Some(dir.join("out").to_string_lossy().into_owned()),
+        ..Config::default()
+    })
+    .with_args(vec!["scoper".to_string()])
+    .with_target(dir.join("project").to_str().unwrap())
+    .run()
+    .expect("analysis failed")
+}
+
+/// Mark the cached findings, so a cache hit can be told from a fresh result
+fn mark_cached_findings(dir: &Path) {
+    let path = dir.join("cache.json");
+    let mut cache: serde_json::Value =
+        serde_json::from_str(&std::fs::read_to_string(&path).unwrap()).unwrap();
+    for file in cache["files"].as_object_mut().unwrap().values_mut() {
+        file["rules"]["no-debugger"]["diagnostics"][0]["message"] = "From the cache".into();
+    }
+    std::fs::write(&path, cache.to_string()).unwrap();
+}
+
+fn setup() -> tempfile::TempDir {
+    let dir = tempfile::tempdir().unwrap();
+    std::fs::create_dir(dir.path().join("project")).unwrap();
+    std::fs::write(dir.path().join("project").join("app.ts"), "debugger;\n").unwrap();
+    std::fs::write(
+        dir.path().join("rules.json"),
+        r#"{ "rules": { "no-debugger": "error" } }"#,
+    )
+    .unwrap();
+    run(dir.path());
+    mark_cached_findings(dir.path());
+    dir
+}
+
+fn messages(analysis: &Analysis) -> Vec<(String, usize)> {
+    analysis
+        .results
+        .iter()
+        .flat_map(|result| &result.diagnostics)
+        .map(|diagnostic| {
+            (
+                diagnostic.diagnostic.message.to_string(),
+                diagnostic.line_number,
+            )
+        })
+        .collect()
+}
+
+#[test]
+fn test_unchanged_files_are_not_parsed_again() {
+    let dir = setup();
+
+    let analysis = run(dir.path());
+
+    assert_eq!(messages(&analysis), vec![("From the cache".to_string(), 1)]);
+    assert_eq!(analysis.results[0].parse_duration, Duration::ZERO);
+}
+
+#[test]
+fn test_changed_content_invalidates_the_cache() {
+    let dir = setup();
+    std::fs::write(
+        dir.path().join("project").join("app.ts"),
+        "const id = 1;\ndebugger;\n",
+    )
+    .unwrap();
+
+    let analysis = run(dir.path());
+
+    let findings = messages(&analysis);
+    assert_eq!(findings.len(), 1);
+    assert_ne!(findings[0].0, "From the cache");
+    assert_eq!(findings[0].1, 2);
+}
+
+#[test]
+fn test_changed_rule_configuration_invalidates_the_cache() {
+    let dir = setup();
+    std::fs::write(
+        dir.path().join("rules.json"),
+        r#"{ "rules": { "no-debugger": ["error", { "reason": "changed" }] } }"#,
+    )
+    .unwrap();
+
+    let analysis = run(dir.path());
+
+    let findings = messages(&analysis);
+    assert_eq!(findings.len(), 1);
+    assert_ne!(findings[0].0, "From the cache");
+}
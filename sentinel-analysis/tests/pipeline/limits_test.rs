@@ -0,0 +1,123 @@
+use scoper::exporter::{FindingEntry, FindingsExport};
+use scoper::limits::{FindingLimits, truncate_findings};
+use scoper::utilities::config::Config;
+use scoper::{DebugLevel, Sentinel};
+use serde_json::json;
+use std::collections::{BTreeMap, HashMap};
+
+// Test utilities
+fn finding(rule: &str, line: usize, severity: &str) -> FindingEntry {
+    serde_json::from_value(json!({
+        "rule": rule,
+        "category": "correctness",
+        "message": format!("{} at line {}", rule, line),
+        "file": "src/app.ts",
+        "line": line,
+        "column": 1,
+        "severity": severity,
+    }))
+    .unwrap()
+}
+
+fn kept(findings: &[FindingEntry]) -> Vec<(&str, usize)> {
+    findings
+        .iter()
+        .map(|finding| (finding.rule.as_str(), finding.line))
+        .collect()
+}
+
+#[test]
+fn test_rule_caps_keep_the_first_findings_of_each_rule() {
+    let mut findings: Vec<FindingEntry> = (1..=3)
+        .map(|line| finding("no-debugger", line, "error"))
+        .chain((4..=6).map(|line| finding("todo-comments", line, "warning")))
+        .collect();
+    let limits = FindingLimits {
+        max_findings_per_rule: Some(2),
+        max_findings_by_rule: HashMap::from([("todo-comments".to_string(), 1)]),
+        ..FindingLimits::default()
+    };
+
+    let truncation = truncate_findings(&mut findings, &limits).unwrap();
+
+    assert_eq!(
+        kept(&findings),
+        vec![("no-debugger", 1), ("no-debugger", 2), ("todo-comments", 4)]
+    );
+    assert_eq!(truncation.omitted, 3);
+    assert_eq!(
+        truncation.omitted_by_rule,
+        BTreeMap::from([
+            ("no-debugger".to_string(), 1),
+            ("todo-comments".to_string(), 2)
+        ])
+    );
+}
+
+#[test]
+fn test_run_cap_keeps_errors_over_warnings_in_file_order() {
+    let mut findings = vec![
+        finding("todo-comments", 1, "warning"),
+        finding("todo-comments", 2, "warning"),
+        finding("no-debugger", 3, "error"),
+        finding("todo-comments", 4, "warning"),
+    ];
+    let limits = FindingLimits {
+        max_findings: Some(2),
+        ..FindingLimits::default()
+    };
+
+    let truncation = truncate_findings(&mut findings, &limits).unwrap();
+
+    assert_eq!(
+        kept(&findings),
+        vec![("todo-comments", 1), ("no-debugger", 3)]
+    );
+    assert_eq!(truncation.max_findings, Some(2));
+    assert_eq!(truncation.omitted, 2);
+}
+
+#[test]
+fn test_findings_within_the_caps_are_not_truncated() {
+    let mut findings = vec![finding("no-debugger", 1, "error")];
+
+    assert!(truncate_findings(&mut findings, &FindingLimits::default()).is_none());
+    let limits = FindingLimits {
+        max_findings: Some(1),
+        ..FindingLimits::default()
+    };
+    assert!(truncate_findings(&mut findings, &limits).is_none());
+    assert_eq!(findings.len(), 1);
+}
+
+#[test]
+fn test_reports_record_the_truncation_and_count_every_finding() {
+    let dir = tempfile::tempdir().unwrap();
+    let config = Config {
+        output_dir: Some(dir.path().to_string_lossy().into_owned()),
+        max_findings_per_rule: Some(1),
+        ..Config::default()
+    };
+    let analysis = Sentinel::new(config.clone())
+        .with_args(vec![
+            "scoper".to_string(),
+            "--rules".to_string(),
+            "no-debugger".to_string(),
+        ])
+        .with_sources(vec![(
+            "src/app.ts".to_string(),
+            "debugger;\ndebugger;\ndebugger;\n".to_string(),
+        )])
+        .run()
+        .expect("analysis failed");
+    analysis.export(&config, DebugLevel::Error);
+
+    let export: FindingsExport =
+        serde_json::from_str(&std::fs::read_to_string(dir.path().join("findings.json")).unwrap())
+            .unwrap();
+    assert_eq!(kept(&export.findings), vec![("no-debugger", 1)]);
+    assert_eq!(export.summary.total_findings, 3);
+    let truncation = export.truncation.unwrap();
+    assert_eq!(truncation.max_findings_per_rule, Some(1));
+    assert_eq!(truncation.omitted, 2);
+}
@@ -0,0 +1,12 @@
+// Rule cache tests
+mod cache_test;
+// Finding cap tests
+mod limits_test;
+// Rule selector tests
+mod selectors_test;
+// Pipeline tests on in-memory sources
+mod sentinel_test;
+// Source decoding and column tests
+mod source_test;
+// Suppression tests
+mod suppressions_test;
@@ -0,0 +1,71 @@
+use scoper::rules_registry::create_default_registry;
+use scoper::utilities::config::get_rule_selectors;
+
+// Test utilities
+fn args(args: &[&str]) -> Vec<String> {
+    args.iter().map(|arg| arg.to_string()).collect()
+}
+
+#[test]
+fn test_rules_include_selects_categories_and_exclude_wins() {
+    let mut registry = create_default_registry();
+    let (include, exclude) = get_rule_selectors(&args(&[
+        "scoper",
+        "--rules-include=security,rxjs",
+        "--rules-exclude",
+        "experimental",
+    ]));
+    assert_eq!(include, vec!["security", "rxjs"]);
+    assert_eq!(exclude, vec!["experimental"]);
+
+    registry.apply_rule_selectors(&include, &exclude);
+
+    let enabled = registry.get_enabled_rules();
+    assert!(enabled.contains(&"security-inner-html".to_string()));
+    for rule_name in [
+        "security-taint-flow",
+        "security-http-url-concatenation",
+        "rxjs-subscription-leak",
+        "typescript-no-any",
+        "no-debugger",
+    ] {
+        assert!(!registry.is_rule_enabled(rule_name), "{}", rule_name);
+    }
+    for rule_name in &enabled {
+        let category = registry.get_rule_category(rule_name).unwrap();
+        assert!(
+            ["security", "rxjs"].contains(&category.as_str()),
+            "{} is in {}",
+            rule_name,
+            category.as_str()
+        );
+        assert!(!registry.rule_matches_selector(rule_name, "experimental"));
+    }
+}
+
+#[test]
+fn test_selectors_match_names_parent_categories_and_tags() {
+    let registry = create_default_registry();
+
+    assert!(registry.rule_matches_selector("no-debugger", "no-debugger"));
+    assert!(registry.rule_matches_selector("angular-standalone-candidate", "migration"));
+    assert!(registry.rule_matches_selector("angular-standalone-candidate", "Migration/Standalone"));
+    assert!(!registry.rule_matches_selector("angular-standalone-candidate", "migr"));
+    assert!(registry.rule_matches_selector("security-taint-flow", "expensive"));
+    assert!(!registry.rule_matches_selector("security-inner-html", "expensive"));
+    assert!(!registry.rule_matches_selector("unknown-rule", "security"));
+}
+
+#[test]
+fn test_exclude_alone_only_disables_matching_rules() {
+    let mut registry = create_default_registry();
+    registry.enable_rule("no-debugger");
+    registry.enable_rule("security-taint-flow");
+    registry.enable_rule("security-inner-html");
+
+    registry.apply_rule_selectors(&[], &["experimental".to_string()]);
+
+    let mut enabled = registry.get_enabled_rules();
+    enabled.sort();
+    assert_eq!(enabled, vec!["no-debugger", "security-inner-html"]);
+}
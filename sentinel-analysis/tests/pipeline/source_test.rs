@@ -0,0 +1,125 @@
+use scoper::Sentinel;
+use scoper::utilities::config::Config;
+use scoper::utilities::source::{
+    ColumnUnit, decode_source, offset_of_position, position_of_offset,
+};
+use std::path::Path;
+
+// Test utilities
+fn utf16(text: &str, bom: [u8; 2], to_bytes: fn(u16) -> [u8; 2]) -> Vec<u8> {
+    let mut bytes = bom.to_vec();
+    bytes.extend(text.encode_utf16().flat_map(to_bytes));
+    bytes
+}
+
+#[test]
+fn test_utf8_byte_order_mark_is_stripped() {
+    let mut bytes = vec![0xEF, 0xBB, 0xBF];
+    bytes.extend("const café = 1;\n".as_bytes());
+
+    let decoded = decode_source(bytes).unwrap();
+    assert_eq!(decoded.content, "const café = 1;\n");
+    assert_eq!(decoded.encoding, "utf-8-bom");
+}
+
+#[test]
+fn test_utf16_files_are_transcoded() {
+    let text = "const café = '😀';\n";
+
+    let little_endian = decode_source(utf16(text, [0xFF, 0xFE], u16::to_le_bytes)).unwrap();
+    assert_eq!(little_endian.content, text);
+    assert_eq!(little_endian.encoding, "utf-16le");
+
+    let big_endian = decode_source(utf16(text, [0xFE, 0xFF], u16::to_be_bytes)).unwrap();
+    assert_eq!(big_endian.content, text);
+    assert_eq!(big_endian.encoding, "utf-16be");
+}
+
+#[test]
+fn test_invalid_utf8_is_read_as_latin1() {
+    let decoded = decode_source(b"const caf\xe9 = 'na\xefve';\n".to_vec()).unwrap();
+    assert_eq!(decoded.content, "const café = 'naïve';\n");
+    assert_eq!(decoded.encoding, "latin-1");
+
+    let plain = decode_source(b"const id = 1;\n".to_vec()).unwrap();
+    assert_eq!(plain.encoding, "utf-8");
+}
+
+#[test]
+fn test_binary_content_is_not_decoded() {
+    assert!(decode_source(vec![0x89, b'P', b'N', b'G', 0x00, 0x01]).is_err());
+}
+
+#[test]
+fn test_columns_after_a_non_bmp_character_depend_on_the_unit() {
+    let source = "const id = 1;\nconst s = '😀'; debugger;\n";
+    let offset = source.find("debugger").unwrap();
+
+    // The emoji is 4 bytes, 1 character and 2 UTF-16 code units
+    assert_eq!(
+        position_of_offset(source, offset, ColumnUnit::Byte),
+        (2, 19)
+    );
+    assert_eq!(
+        position_of_offset(source, offset, ColumnUnit::Char),
+        (2, 16)
+    );
+    assert_eq!(
+        position_of_offset(source, offset, ColumnUnit::Utf16),
+        (2, 17)
+    );
+
+    for (unit, column) in [
+        (ColumnUnit::Byte, 19),
+        (ColumnUnit::Char, 16),
+        (ColumnUnit::Utf16, 17),
+    ] {
+        assert_eq!(offset_of_position(source, 2, column, unit), Some(offset));
+    }
+}
+
+#[test]
+fn test_findings_in_decoded_files_have_their_positions() {
+    let dir = tempfile::tempdir().unwrap();
+    std::fs::write(
+        dir.path().join("utf16.ts"),
+        utf16("// ☕\ndebugger;\n", [0xFF, 0xFE], u16::to_le_bytes),
+    )
+    .unwrap();
+    std::fs::write(dir.path().join("latin1.ts"), b"// caf\xe9\ndebugger;\n").unwrap();
+    std::fs::write(dir.path().join("emoji.ts"), "const s = '😀'; debugger;\n").unwrap();
+
+    let analysis = Sentinel::new(Config::default())
+        .with_args(vec![
+            "scoper".to_string(),
+            "--rules".to_string(),
+            "no-debugger".to_string(),
+        ])
+        .with_target(dir.path().to_str().unwrap())
+        .run()
+        .expect("analysis failed");
+
+    let mut findings: Vec<(String, usize, usize)> = analysis
+        .results
+        .iter()
+        .flat_map(|result| {
+            result.diagnostics.iter().map(|diagnostic| {
+                let file_name = Path::new(&result.file_path).file_name().unwrap();
+                (
+                    file_name.to_string_lossy().into_owned(),
+                    diagnostic.line_number,
+                    diagnostic.column_number,
+                )
+            })
+        })
+        .collect();
+    findings.sort();
+    assert_eq!(
+        findings,
+        vec![
+            ("emoji.ts".to_string(), 1, 17),
+            ("latin1.ts".to_string(), 2, 1),
+            ("utf16.ts".to_string(), 2, 1),
+        ]
+    );
+}
@@ -0,0 +1,100 @@
+use chrono::NaiveDate;
+use scoper::suppressions::{Baseline, BaselineEntry, apply_suppressions};
+use scoper::utilities::config::Config;
+use scoper::{Analysis, DebugLevel, Sentinel};
+
+// Test utilities
+fn analyze(code: &str, suppressions: &str) -> Analysis {
+    Sentinel::new(Config {
+        suppressions: Some(suppressions.to_string()),
+        ..Config::default()
+    })
+    .with_args(vec![
+        "scoper".to_string(),
+        "--rules".to_string(),
+        "no-debugger".to_string(),
+    ])
+    .with_sources(vec![("src/app.ts".to_string(), code.to_string())])
+    .run()
+    .expect("analysis failed")
+}
+
+fn lines(analysis: &Analysis) -> Vec<usize> {
+    analysis
+        .results
+        .iter()
+        .flat_map(|result| &result.diagnostics)
+        .map(|diagnostic| diagnostic.line_number)
+        .collect()
+}
+
+#[test]
+fn test_expired_inline_suppressions_report_their_findings_again() {
+    let dir = tempfile::tempdir().unwrap();
+    let baseline = dir.path().join("none.json");
+    let code = "\
+// sentinel-disable-next-line no-debugger expires=2020-01-31 owner=@web-team -- old
+debugger;
+// sentinel-disable-next-line no-debugger expires=2999-12-31
+debugger;
+// sentinel-disable-next-line no-debugger
+debugger;
+debugger; // sentinel-disable-line no-debugger expires=soon
+";
+
+    let analysis = analyze(code, baseline.to_str().unwrap());
+
+    // Invalid dates count as expired, so a typo does not suppress a finding forever
+    assert_eq!(lines(&analysis), vec![2, 7]);
+}
+
+#[test]
+fn test_expired_baseline_entries_report_their_findings_again() {
+    let dir = tempfile::tempdir().unwrap();
+    let baseline = dir.path().join("sentinel-suppressions.json");
+    let entry = |expires: &str| BaselineEntry {
+        rule: "no-debugger".to_string(),
+        file: "src/app.ts".to_string(),
+        expires: Some(expires.to_string()),
+        ..BaselineEntry::default()
+    };
+    let write = |entries: Vec<BaselineEntry>| {
+        let baseline_file = Baseline {
+            suppressions: entries,
+        };
+        std::fs::write(&baseline, serde_json::to_string(&baseline_file).unwrap()).unwrap();
+    };
+
+    write(vec![entry("2999-12-31")]);
+    assert!(lines(&analyze("debugger;\n", baseline.to_str().unwrap())).is_empty());
+
+    write(vec![entry("2020-01-31")]);
+    assert_eq!(
+        lines(&analyze("debugger;\n", baseline.to_str().unwrap())),
+        vec![1]
+    );
+
+    // One active suppression is enough, even next to an expired one
+    write(vec![entry("2020-01-31"), entry("2999-12-31")]);
+    assert!(lines(&analyze("debugger;\n", baseline.to_str().unwrap())).is_empty());
+}
+
+#[test]
+fn test_suppressions_expire_after_their_last_day() {
+    let dir = tempfile::tempdir().unwrap();
+    let baseline = dir.path().join("none.json");
+    let code = "// sentinel-disable-next-line no-debugger expires=2026-06-30\ndebugger;\n";
+    let run = |today: &str| {
+        let mut results = analyze(code, baseline.to_str().unwrap()).results;
+        let outcome = apply_suppressions(
+            &mut results,
+            &Baseline::default(),
+            NaiveDate::parse_from_str(today, "%Y-%m-%d").unwrap(),
+            DebugLevel::Error,
+        );
+        (outcome.suppressed, outcome.expired)
+    };
+
+    assert_eq!(run("2026-06-30"), (1, 0));
+    assert_eq!(run("2026-07-01"), (0, 1));
+}
@@ -0,0 +1,147 @@
+use oxc_allocator::Allocator;
+use oxc_parser::Parser;
+use oxc_semantic::SemanticBuilder;
+use oxc_span::SourceType;
+use serde_json::{Value, json};
+
+use scoper::rules::{AngularComponentStylesRule, Rule};
+use scoper::{RuleDiagnostic, RulesRegistry};
+
+// Test utilities
+fn check_styles(code: &str, file_path: &str, config: Value) -> Vec<RuleDiagnostic> {
+    let allocator = Allocator::default();
+    let source_type = SourceType::default().with_typescript(true);
+    let parser_return = Parser::new(&allocator, code, source_type).parse();
+    let semantic_result = SemanticBuilder::new().build(&parser_return.program);
+
+    let mut rule = AngularComponentStylesRule::new();
+    rule.set_config(config);
+    let mut registry = RulesRegistry::new();
+    registry.register_rule(Box::new(rule));
+    registry.enable_rule("angular-component-styles");
+
+    let (diagnostics, _) = registry.run_rules_with_metrics(&semantic_result, file_path, code);
+    diagnostics
+}
+
+fn messages(diagnostics: &[RuleDiagnostic]) -> Vec<String> {
+    diagnostics
+        .iter()
+        .map(|d| d.diagnostic.message.to_string())
+        .collect()
+}
+
+#[test]
+fn test_ng_deep_is_reported() {
+    let code = r#"
+        @Component({
+          selector: 'app-card',
+          styles: [`
+            :host {
+              display: block;
+            }
+            :host ::ng-deep .mat-card {
+              padding: 0;
+            }
+          `],
+        })
+        export class CardComponent {}
+    "#;
+
+    let diagnostics = check_styles(code, "test.ts", json!({}));
+    assert_eq!(
+        messages(&diagnostics),
+        vec!["::ng-deep used in inline styles, line 5"]
+    );
+}
+
+#[test]
+fn test_nested_scss_selectors_are_resolved_for_depth() {
+    let code = r#"
+        @Component({
+          selector: 'app-list',
+          styles: `
+            .list {
+              .item {
+                > .header {
+                  .title { font-weight: bold; }
+                  &:hover { color: red; }
+                }
+              }
+            }
+            @media (max-width: 600px) {
+              .list .item { padding: 0; }
+            }
+          `,
+        })
+        export class ListComponent {}
+    "#;
+
+    let diagnostics = check_styles(code, "test.ts", json!({}));
+    assert!(diagnostics.is_empty());
+
+    let diagnostics = check_styles(code, "test.ts", json!({ "maxSelectorDepth": 3 }));
+    assert_eq!(
+        messages(&diagnostics),
+        vec!["Selector nested 4 levels deep in inline styles, line 5"]
+    );
+}
+
+#[test]
+fn test_allow_ng_deep() {
+    let code = r#"
+        @Component({
+          selector: 'app-card',
+          styles: [':host ::ng-deep .mat-card { padding: 0; }'],
+        })
+        export class CardComponent {}
+    "#;
+
+    let diagnostics = check_styles(code, "test.ts", json!({ "allowNgDeep": true }));
+    assert!(diagnostics.is_empty());
+}
+
+#[test]
+fn test_style_files_are_checked() {
+    let dir = tempfile::tempdir().unwrap();
+    std::fs::write(dir.path().join("card.component.scss"), "// TODO\n").unwrap();
+    std::fs::write(
+        dir.path().join("theme.scss"),
+        ".card {\n  ::ng-deep .title { color: red; }\n}\n",
+    )
+    .unwrap();
+    let file_path = dir.path().join("card.component.ts");
+
+    let code = r#"
+        @Component({
+          selector: 'app-card',
+          styleUrls: ['./card.component.scss', './theme.scss', './missing.scss'],
+        })
+        export class CardComponent {}
+    "#;
+
+    let diagnostics = check_styles(code, file_path.to_str().unwrap(), json!({}));
+    assert_eq!(
+        messages(&diagnostics),
+        vec![
+            "Style file ./card.component.scss contains no styles",
+            "::ng-deep used in ./theme.scss:2",
+            "Style file ./missing.scss does not exist",
+        ]
+    );
+}
+
+#[test]
+fn test_non_component_classes_are_ignored() {
+    let code = r#"
+        @Directive({
+          selector: '[appCard]',
+        })
+        export class CardDirective {
+          styles = [':host ::ng-deep .mat-card { padding: 0; }'];
+        }
+    "#;
+
+    let diagnostics = check_styles(code, "test.ts", json!({}));
+    assert!(diagnostics.is_empty());
+}
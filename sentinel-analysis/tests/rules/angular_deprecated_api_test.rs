@@ -0,0 +1,50 @@
+use oxc_allocator::Allocator;
+use oxc_parser::Parser;
+use oxc_semantic::SemanticBuilder;
+use oxc_span::SourceType;
+use serde_json::json;
+
+use scoper::RulesRegistry;
+use scoper::rules::{AngularDeprecatedApiRule, Rule};
+
+// Test utilities
+fn find_deprecated(code: &str, angular_version: &str) -> Vec<String> {
+    let allocator = Allocator::default();
+    let source_type = SourceType::default().with_typescript(true);
+    let parser_return = Parser::new(&allocator, code, source_type).parse();
+    let semantic_result = SemanticBuilder::new().build(&parser_return.program);
+
+    let mut rule = AngularDeprecatedApiRule::new();
+    rule.set_config(json!({ "versions": { "@angular/core": angular_version } }));
+    let mut registry = RulesRegistry::new();
+    registry.register_rule(Box::new(rule));
+    registry.enable_rule("angular-deprecated-api");
+
+    let (diagnostics, _) = registry.run_rules_with_metrics(&semantic_result, "test.ts", code);
+    diagnostics
+        .iter()
+        .map(|diagnostic| diagnostic.diagnostic.message.to_string())
+        .collect()
+}
+
+#[test]
+fn test_only_deprecations_up_to_the_project_version_are_reported() {
+    let code = r#"
+        import { ComponentFactoryResolver, APP_INITIALIZER } from '@angular/core';
+        import { HttpClientModule } from '@angular/common/http';
+    "#;
+
+    assert_eq!(find_deprecated(code, "^17.3.0").len(), 1);
+    assert_eq!(find_deprecated(code, "~18.2.1").len(), 2);
+    assert_eq!(find_deprecated(code, "19.0.0").len(), 3);
+}
+
+#[test]
+fn test_deprecation_message_names_the_version() {
+    let code = "import { HttpClientModule } from '@angular/common/http';";
+
+    assert_eq!(
+        find_deprecated(code, "18.0.0"),
+        vec!["'HttpClientModule' from '@angular/common/http' is deprecated since @angular/common 18.0.0".to_string()]
+    );
+}
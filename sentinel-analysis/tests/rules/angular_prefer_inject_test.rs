@@ -0,0 +1,74 @@
+use oxc_allocator::Allocator;
+use oxc_parser::Parser;
+use oxc_semantic::SemanticBuilder;
+use oxc_span::SourceType;
+use serde_json::json;
+
+use scoper::rules::AngularPreferInjectRule;
+use scoper::{RuleDiagnostic, RulesRegistry};
+
+// Test utilities
+fn find_constructor_injection(code: &str) -> Vec<RuleDiagnostic> {
+    let allocator = Allocator::default();
+    let source_type = SourceType::default().with_typescript(true);
+    let parser_return = Parser::new(&allocator, code, source_type).parse();
+    let semantic_result = SemanticBuilder::new().build(&parser_return.program);
+
+    let mut registry = RulesRegistry::new();
+    registry.register_rule(Box::new(AngularPreferInjectRule::new()));
+    registry.enable_rule("angular-prefer-inject");
+
+    let (diagnostics, _) = registry.run_rules_with_metrics(&semantic_result, "test.ts", code);
+    diagnostics
+}
+
+#[test]
+fn test_simple_constructor_gets_a_suggestion() {
+    let code = r#"
+@Injectable({ providedIn: 'root' })
+export class UserService {
+  constructor(private readonly http: HttpClient, @Optional() private logger: Logger) {}
+}
+"#;
+
+    let diagnostics = find_constructor_injection(code);
+    assert_eq!(diagnostics.len(), 1);
+    assert_eq!(
+        diagnostics[0].metadata["tokens"],
+        json!(["HttpClient", "Logger"])
+    );
+    assert_eq!(
+        diagnostics[0].suggestion.as_deref(),
+        Some(
+            "private readonly http = inject(HttpClient);\n  private logger = inject(Logger, { optional: true });"
+        )
+    );
+}
+
+#[test]
+fn test_constructor_with_body_has_no_suggestion() {
+    let code = r#"
+@Component({ selector: 'app-user' })
+export class UserComponent {
+  constructor(@Inject(USER_ID) id: string) {
+    this.load(id);
+  }
+}
+"#;
+
+    let diagnostics = find_constructor_injection(code);
+    assert_eq!(diagnostics.len(), 1);
+    assert_eq!(diagnostics[0].metadata["tokens"], json!(["USER_ID"]));
+    assert!(diagnostics[0].suggestion.is_none());
+}
+
+#[test]
+fn test_plain_classes_are_not_reported() {
+    let code = r#"
+export class Point {
+  constructor(private x: number, private y: number) {}
+}
+"#;
+
+    assert!(find_constructor_injection(code).is_empty());
+}
@@ -0,0 +1,67 @@
+use oxc_allocator::Allocator;
+use oxc_parser::Parser;
+use oxc_semantic::SemanticBuilder;
+use oxc_span::SourceType;
+use serde_json::json;
+
+use scoper::rules::I18nUntranslatedTextRule;
+use scoper::{RuleDiagnostic, RulesRegistry};
+
+// Test utilities
+fn find_untranslated(code: &str) -> Vec<RuleDiagnostic> {
+    let allocator = Allocator::default();
+    let source_type = SourceType::default().with_typescript(true);
+    let parser_return = Parser::new(&allocator, code, source_type).parse();
+    let semantic_result = SemanticBuilder::new().build(&parser_return.program);
+
+    let mut registry = RulesRegistry::new();
+    registry.register_rule(Box::new(I18nUntranslatedTextRule::new()));
+    registry.enable_rule("i18n-untranslated-text");
+
+    let (diagnostics, _) = registry.run_rules_with_metrics(&semantic_result, "test.ts", code);
+    diagnostics
+}
+
+#[test]
+fn test_unmarked_template_text_is_reported() {
+    let code = r#"
+        @Component({
+          selector: 'app-search',
+          template: `
+            <h1 i18n>Search <b>products</b></h1>
+            <input placeholder="Search products" i18n-placeholder>
+            <button title="Start the search">Search {{ count }} items</button>
+          `,
+        })
+        export class SearchComponent {}
+    "#;
+
+    let diagnostics = find_untranslated(code);
+    let texts: Vec<_> = diagnostics
+        .iter()
+        .map(|diagnostic| diagnostic.metadata["text"].clone())
+        .collect();
+    assert_eq!(
+        texts,
+        vec![json!("Start the search"), json!("Search items")]
+    );
+    assert_eq!(diagnostics[0].metadata["source"], json!("template:title"));
+    assert_eq!(diagnostics[1].metadata["source"], json!("template"));
+}
+
+#[test]
+fn test_component_strings_without_localize_are_reported() {
+    let code = r#"
+        @Component({ selector: 'app-save', template: '<button i18n>Save</button>' })
+        export class SaveComponent {
+          label = 'Unsaved changes';
+          title = $localize`Save`;
+          mode = 'edit';
+        }
+    "#;
+
+    let diagnostics = find_untranslated(code);
+    assert_eq!(diagnostics.len(), 1);
+    assert_eq!(diagnostics[0].metadata["text"], json!("Unsaved changes"));
+    assert_eq!(diagnostics[0].category.as_str(), "i18n");
+}
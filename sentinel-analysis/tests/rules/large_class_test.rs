@@ -0,0 +1,65 @@
+use oxc_allocator::Allocator;
+use oxc_parser::Parser;
+use oxc_semantic::SemanticBuilder;
+use oxc_span::SourceType;
+use serde_json::{Value, json};
+
+use scoper::rules::{LargeClassRule, Rule};
+use scoper::{RuleDiagnostic, RulesRegistry};
+
+// Test utilities
+fn find_large_classes(code: &str, config: Value) -> Vec<RuleDiagnostic> {
+    let allocator = Allocator::default();
+    let source_type = SourceType::default().with_typescript(true);
+    let parser_return = Parser::new(&allocator, code, source_type).parse();
+    let semantic_result = SemanticBuilder::new().build(&parser_return.program);
+
+    let mut rule = LargeClassRule::new();
+    rule.set_config(config);
+    let mut registry = RulesRegistry::new();
+    registry.register_rule(Box::new(rule));
+    registry.enable_rule("large-class");
+
+    let (diagnostics, _) = registry.run_rules_with_metrics(&semantic_result, "test.ts", code);
+    diagnostics
+}
+
+#[test]
+fn test_class_over_threshold_reports_measures() {
+    let code = r#"
+        @Component({
+          selector: 'app-checkout',
+          template: '<input [(ngModel)]="coupon" (blur)="applyCoupon()">{{ total }}',
+        })
+        export class CheckoutComponent {
+          private readonly cart = inject(CartFacade);
+
+          constructor(private http: HttpClient, private router: Router) {}
+
+          loadCart() {}
+          applyCoupon() {}
+          get total() { return 0; }
+        }
+    "#;
+
+    let diagnostics = find_large_classes(code, json!({ "maxDependencies": 2 }));
+    assert_eq!(diagnostics.len(), 1);
+
+    let metadata = &diagnostics[0].metadata;
+    assert_eq!(metadata["methods"], json!(2));
+    assert_eq!(metadata["dependencies"], json!(3));
+    assert_eq!(metadata["templateBindings"], json!(3));
+    assert_eq!(metadata["exceeded"], json!(["dependencies"]));
+}
+
+#[test]
+fn test_class_within_thresholds_is_not_reported() {
+    let code = r#"
+        export class CartService {
+          add() {}
+          remove() {}
+        }
+    "#;
+
+    assert!(find_large_classes(code, json!({})).is_empty());
+}
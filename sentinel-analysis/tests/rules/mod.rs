@@ -1,2 +1,13 @@
 // Angular module tests
+mod angular_component_styles_test;
 mod angular_decorator_test;
+mod angular_deprecated_api_test;
+mod angular_prefer_inject_test;
+// Best practice rule tests
+mod large_class_test;
+// i18n rule tests
+mod i18n_untranslated_text_test;
+// RxJS rule tests
+mod rxjs_subscription_leak_test;
+// TypeScript rule tests
+mod typescript_no_any_test;
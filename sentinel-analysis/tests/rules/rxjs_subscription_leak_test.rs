@@ -0,0 +1,108 @@
+use oxc_allocator::Allocator;
+use oxc_parser::Parser;
+use oxc_semantic::SemanticBuilder;
+use oxc_span::SourceType;
+
+use scoper::RulesRegistry;
+use scoper::rules::RxjsSubscriptionLeakRule;
+
+// Test utilities
+fn count_leaks(code: &str) -> usize {
+    let allocator = Allocator::default();
+    let source_type = SourceType::default().with_typescript(true);
+    let parser_return = Parser::new(&allocator, code, source_type).parse();
+    let semantic_result = SemanticBuilder::new().build(&parser_return.program);
+
+    let mut registry = RulesRegistry::new();
+    registry.register_rule(Box::new(RxjsSubscriptionLeakRule::new()));
+    registry.enable_rule("rxjs-subscription-leak");
+
+    let (diagnostics, _) = registry.run_rules_with_metrics(&semantic_result, "test.ts", code);
+    diagnostics.len()
+}
+
+#[test]
+fn test_unmanaged_subscription_is_reported() {
+    let code = r#"
+        @Component({ selector: 'app-user' })
+        export class UserComponent {
+          ngOnInit() {
+            this.store.user$.subscribe(user => this.user = user);
+          }
+        }
+    "#;
+
+    assert_eq!(count_leaks(code), 1);
+}
+
+#[test]
+fn test_take_until_destroyed_is_not_reported() {
+    let code = r#"
+        @Component({ selector: 'app-user' })
+        export class UserComponent {
+          ngOnInit() {
+            this.store.user$.pipe(takeUntilDestroyed(this.destroyRef)).subscribe();
+          }
+        }
+    "#;
+
+    assert_eq!(count_leaks(code), 0);
+}
+
+#[test]
+fn test_stored_subscription_requires_unsubscribe() {
+    let leaking = r#"
+        @Component({ selector: 'app-user' })
+        export class UserComponent {
+          ngOnInit() {
+            this.sub = this.store.user$.subscribe();
+          }
+        }
+    "#;
+    let managed = r#"
+        @Component({ selector: 'app-user' })
+        export class UserComponent {
+          ngOnInit() {
+            this.sub = this.store.user$.subscribe();
+          }
+          ngOnDestroy() {
+            this.sub.unsubscribe();
+          }
+        }
+    "#;
+
+    assert_eq!(count_leaks(leaking), 1);
+    assert_eq!(count_leaks(managed), 0);
+}
+
+#[test]
+fn test_take_until_requires_notifier_in_ng_on_destroy() {
+    let code = r#"
+        @Component({ selector: 'app-user' })
+        export class UserComponent {
+          ngOnInit() {
+            this.store.user$.pipe(takeUntil(this.destroy$)).subscribe();
+          }
+          ngOnDestroy() {
+            this.destroy$.next();
+            this.destroy$.complete();
+          }
+        }
+    "#;
+
+    assert_eq!(count_leaks(code), 0);
+}
+
+#[test]
+fn test_services_are_not_checked() {
+    let code = r#"
+        @Injectable({ providedIn: 'root' })
+        export class UserService {
+          constructor() {
+            this.http.get('/users').subscribe();
+          }
+        }
+    "#;
+
+    assert_eq!(count_leaks(code), 0);
+}
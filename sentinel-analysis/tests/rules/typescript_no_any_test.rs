@@ -0,0 +1,99 @@
+use oxc_allocator::Allocator;
+use oxc_parser::Parser;
+use oxc_semantic::SemanticBuilder;
+use oxc_span::SourceType;
+use serde_json::{Value, json};
+
+use scoper::RulesRegistry;
+use scoper::rules::{Rule, TypeScriptNoAnyRule};
+
+// Test utilities
+fn find_any(code: &str, file_path: &str, config: Option<Value>) -> Vec<String> {
+    let allocator = Allocator::default();
+    let source_type = SourceType::default().with_typescript(true);
+    let parser_return = Parser::new(&allocator, code, source_type).parse();
+    let semantic_result = SemanticBuilder::new().build(&parser_return.program);
+
+    let mut rule = TypeScriptNoAnyRule::new();
+    if let Some(config) = config {
+        rule.set_config(config);
+    }
+    let mut registry = RulesRegistry::new();
+    registry.register_rule(Box::new(rule));
+    registry.enable_rule("typescript-no-any");
+
+    let (diagnostics, _) = registry.run_rules_with_metrics(&semantic_result, file_path, code);
+    diagnostics
+        .iter()
+        .map(|diagnostic| diagnostic.diagnostic.message.to_string())
+        .collect()
+}
+
+#[test]
+fn test_explicit_any_and_casts_are_reported() {
+    let code = r#"
+        let payload: any;
+        const items: Array<any> = [];
+        const user = response as any;
+    "#;
+
+    let messages = find_any(code, "src/app/user.ts", None);
+    assert_eq!(messages.len(), 3);
+    assert_eq!(
+        messages
+            .iter()
+            .filter(|m| m.as_str() == "Cast to 'any'")
+            .count(),
+        1
+    );
+}
+
+#[test]
+fn test_untyped_parameters_are_reported() {
+    let code = r#"
+        function format(value, prefix = '') {
+          return prefix + String(value);
+        }
+        class UserService {
+          load(id) {}
+          set name(value) {}
+        }
+        const handler = (event) => event;
+    "#;
+
+    assert_eq!(find_any(code, "src/app/user.ts", None).len(), 3);
+}
+
+#[test]
+fn test_contextually_typed_callbacks_are_not_reported() {
+    let code = r#"
+        users.map(user => user.name);
+        const handler: (event: Event) => void = (event) => event.preventDefault();
+        function format(value: number) {
+          return String(value);
+        }
+    "#;
+
+    assert!(find_any(code, "src/app/user.ts", None).is_empty());
+}
+
+#[test]
+fn test_allowlisted_paths_are_not_reported() {
+    let code = r#"
+        let payload: any;
+        function format(value) {}
+    "#;
+    let config = json!({
+        "allow": [
+            "**/*.spec.ts",
+            { "path": "src/app/legacy/**", "kinds": ["parameter"] }
+        ]
+    });
+
+    assert!(find_any(code, "src/app/user.spec.ts", Some(config.clone())).is_empty());
+    assert_eq!(
+        find_any(code, "src/app/legacy/user.ts", Some(config.clone())),
+        vec!["Explicit 'any' type".to_string()]
+    );
+    assert_eq!(find_any(code, "src/app/user.ts", Some(config)).len(), 2);
+}
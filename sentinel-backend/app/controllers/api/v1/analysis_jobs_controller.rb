@@ -62,6 +62,8 @@ module Api
         end
 
         if @job.save
+          audit('analysis.started', @job, project_id: @project.id)
+
           begin
             # Initialize the analysis service
             service = AnalysisService.new(@job.id)
@@ -149,6 +151,7 @@ module Api
           
           # Process the results
           if service.process_results(@job)
+            audit('analysis.results_processed', @job, project_id: @job.project_id)
             render json: { message: 'Analysis results processing has been scheduled' }, status: :ok
           else
             render json: { error: 'Failed to process analysis results' }, status: :unprocessable_entity
@@ -32,6 +32,8 @@ module Api
         analysis_job.save!
 
         if analysis_job.persisted?
+          audit('analysis.uploaded', analysis_job, project_id: project.id, bytes: findings_data.bytesize)
+
           # Process the findings. This might be better off in a background job
           # For simplicity, calling a service method directly here.
           # We'll need to adapt AnalysisService or create a new one.
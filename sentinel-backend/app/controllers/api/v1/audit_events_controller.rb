@@ -0,0 +1,32 @@
+module Api
+  module V1
+    class AuditEventsController < ApplicationController
+      before_action :require_signed_in_user
+
+      # GET /api/v1/audit
+      def index
+        @events = AuditEvent.recent
+        @events = @events.by_action(params[:event]) if params[:event].present?
+        @events = @events.by_actor(params[:actor]) if params[:actor].present?
+        @events = @events.by_subject(params[:subject_type], params[:subject_id]) if params[:subject_type].present?
+
+        if params[:since].present?
+          since = parse_time(params[:since])
+          return render json: { error: "Invalid since parameter: #{params[:since]}" }, status: :bad_request unless since
+
+          @events = @events.since(since)
+        end
+
+        render_serialized @events, each_serializer: AuditEventSerializer
+      end
+
+      private
+
+      def parse_time(value)
+        Time.zone.parse(value)
+      rescue ArgumentError
+        nil
+      end
+    end
+  end
+end
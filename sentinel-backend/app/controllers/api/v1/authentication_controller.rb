@@ -85,7 +85,8 @@ module Api
           # Update sign count
           credential.update_sign_count(webauthn_credential.sign_count)
 
-          # You might want to generate a JWT token here for subsequent API calls
+          # The session identifies the user to later requests, e.g. as the actor of audit events
+          sign_in(user)
           render json: {
             status: "ok",
             user: {
@@ -30,6 +30,7 @@ module Api
         )
 
         if @project_rule.update(project_rule_params)
+          audit('project_rule.updated', @project_rule, project_id: @project.id, rule_id: @rule.id, enabled: @project_rule.enabled)
           render json: @project_rule
         else
           render json: { errors: @project_rule.errors }, status: :unprocessable_entity
@@ -46,6 +47,7 @@ module Api
         @project_rule.enabled = !@project_rule.enabled
 
         if @project_rule.save
+          audit('project_rule.updated', @project_rule, project_id: @project.id, rule_id: @rule.id, enabled: @project_rule.enabled)
           render json: @project_rule
         else
           render json: { errors: @project_rule.errors }, status: :unprocessable_entity
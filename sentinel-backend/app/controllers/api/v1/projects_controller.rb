@@ -19,6 +19,8 @@ module Api
         @project = Project.new(project_params)
 
         if @project.save
+          audit('project.created', @project, name: @project.name, repository_url: @project.repository_url)
+
           # Clone repository if URL is provided
           if @project.repository_url.present?
             begin
@@ -28,6 +30,7 @@ module Api
               render_serialized @project, status: :created
             rescue GitService::GitError => e
               @project.destroy # Rollback project creation if clone fails
+              audit('project.deleted', @project, name: @project.name, reason: e.message)
               render json: { error: e.message }, status: :unprocessable_entity
             ensure
               Thread.current[:github_token] = nil
@@ -16,6 +16,7 @@ module Api
         @rule_group = RuleGroup.new(rule_group_params)
 
         if @rule_group.save
+          audit('rule_group.created', @rule_group, name: @rule_group.name)
           render json: @rule_group, serializer: RuleGroupSerializer, status: :created
         else
           render json: { errors: @rule_group.errors }, status: :unprocessable_entity
@@ -24,6 +25,7 @@ module Api
 
       def update
         if @rule_group.update(rule_group_params)
+          audit('rule_group.updated', @rule_group, changes: @rule_group.saved_changes.except('updated_at'))
           render json: @rule_group, serializer: RuleGroupSerializer
         else
           render json: { errors: @rule_group.errors }, status: :unprocessable_entity
@@ -32,6 +34,7 @@ module Api
 
       def destroy
         @rule_group.destroy
+        audit('rule_group.deleted', @rule_group, name: @rule_group.name)
         head :no_content
       end
 
@@ -44,6 +47,7 @@ module Api
           end
         end
 
+        audit('rule_group.rules_added', @rule_group, rule_ids: params[:rule_ids])
         render json: @rule_group, serializer: RuleGroupSerializer
       rescue ActiveRecord::RecordInvalid => e
         render json: { errors: e.message }, status: :unprocessable_entity
@@ -52,6 +56,7 @@ module Api
       def remove_rule
         rule = @rule_group.rules.find(params[:rule_id])
         @rule_group.rules.delete(rule)
+        audit('rule_group.rule_removed', @rule_group, rule_id: rule.id)
         render json: @rule_group, serializer: RuleGroupSerializer
       rescue ActiveRecord::RecordNotFound
         render json: { error: 'Rule not found' }, status: :not_found
@@ -19,6 +19,7 @@ module Api
         @rule = Rule.new(rule_params)
 
         if @rule.save
+        audit('rule.created', @rule, name: @rule.name)
         render json: @rule, status: :created
         else
         render json: { errors: @rule.errors }, status: :unprocessable_entity
@@ -28,6 +29,7 @@ module Api
     # PATCH/PUT /rules/:id
     def update
         if @rule.update(rule_params)
+        audit('rule.updated', @rule, changes: @rule.saved_changes.except('updated_at'))
         render json: @rule
         else
         render json: { errors: @rule.errors }, status: :unprocessable_entity
@@ -37,6 +39,7 @@ module Api
     # DELETE /rules/:id
     def destroy
         @rule.destroy
+        audit('rule.deleted', @rule, name: @rule.name)
         head :no_content
     end
 
@@ -2,6 +2,7 @@ class ApplicationController < ActionController::API
   # Include necessary modules for sessions and cookies
   include ActionController::Cookies
   include ActionController::RequestForgeryProtection
+  include Auditable
   
   rescue_from ActiveRecord::RecordNotFound, with: :not_found
   rescue_from ActiveRecord::RecordInvalid, with: :unprocessable_entity
@@ -9,6 +10,11 @@ class ApplicationController < ActionController::API
 
   private
 
+  # Refuse requests without a signed in user
+  def require_signed_in_user
+    render json: { error: 'Sign in required' }, status: :unauthorized unless current_user
+  end
+
   # Handle JSON parse errors
   def bad_request(exception)
     Rails.logger.error("Parameter parsing error: #{exception.message}")
@@ -0,0 +1,29 @@
+# Records server actions to the audit log
+module Auditable
+  extend ActiveSupport::Concern
+
+  private
+
+  # Record an action, e.g. audit('rule.deleted', @rule, name: @rule.name)
+  #
+  # A failure to write the event is logged and does not fail the request.
+  def audit(action, subject = nil, details = {})
+    AuditEvent.create!(
+      action: action,
+      actor: audit_actor,
+      claimed_actor: request.headers['X-Sentinel-User'].presence,
+      subject: subject,
+      details: details.presence,
+      remote_ip: request.remote_ip,
+      request_id: request.request_id
+    )
+  rescue ActiveRecord::ActiveRecordError => e
+    Rails.logger.error("Failed to record audit event #{action}: #{e.message}")
+  end
+
+  # The user signed in with WebAuthn. The user named by the client in X-Sentinel-User is
+  # not verified, so it is only recorded as the claimed actor.
+  def audit_actor
+    current_user&.email.presence
+  end
+end
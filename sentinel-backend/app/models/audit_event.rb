@@ -0,0 +1,40 @@
+# Append-only record of a server action: who did what to which record, and when.
+# Events are never updated or destroyed once written.
+class AuditEvent < ActiveRecord::Base
+  # Bulk changes skip readonly? and go straight to the database, so they are refused too
+  module AppendOnly
+    def update_all(*)
+      raise ActiveRecord::ReadOnlyRecord, "#{klass.name} is append-only"
+    end
+
+    def delete_all(*)
+      raise ActiveRecord::ReadOnlyRecord, "#{klass.name} is append-only"
+    end
+
+    def upsert_all(*, **)
+      raise ActiveRecord::ReadOnlyRecord, "#{klass.name} is append-only"
+    end
+  end
+  relation_delegate_class(ActiveRecord::Relation).prepend(AppendOnly)
+
+  validates :action, presence: true
+
+  scope :recent, -> { order(created_at: :desc, id: :desc) }
+  scope :by_action, ->(action) { where(action: action) }
+  scope :by_actor, ->(actor) { where(actor: actor) }
+  scope :by_subject, ->(type, id = nil) { id ? where(subject_type: type, subject_id: id) : where(subject_type: type) }
+  scope :since, ->(time) { where('created_at >= ?', time) }
+
+  def self.upsert_all(*, **)
+    raise ActiveRecord::ReadOnlyRecord, "#{name} is append-only"
+  end
+
+  def readonly?
+    persisted? || super
+  end
+
+  def subject=(record)
+    self.subject_type = record&.class&.name
+    self.subject_id = record&.id
+  end
+end
@@ -0,0 +1,3 @@
+class AuditEventSerializer < ActiveModel::Serializer
+  attributes :id, :action, :actor, :claimed_actor, :subject_type, :subject_id, :details, :remote_ip, :request_id, :created_at
+end
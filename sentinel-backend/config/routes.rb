@@ -52,6 +52,9 @@ Rails.application.routes.draw do
 
       get 'files_with_violations', to: 'files_with_violations#index'
 
+      # Append-only log of uploads, analyses, rule changes and deletions
+      get 'audit', to: 'audit_events#index'
+
       # GitHub integration routes
       post 'auth/github/callback', to: 'github#callback'
       get 'github/repositories', to: 'github#repositories'
@@ -0,0 +1,18 @@
+class CreateAuditEvents < ActiveRecord::Migration[8.0]
+  def change
+    create_table :audit_events do |t|
+      t.string :action, null: false
+      t.string :actor
+      t.string :subject_type
+      t.bigint :subject_id
+      t.json :details
+      t.string :remote_ip
+      t.string :request_id
+      t.datetime :created_at, null: false
+    end
+
+    add_index :audit_events, :action
+    add_index :audit_events, [:subject_type, :subject_id]
+    add_index :audit_events, :created_at
+  end
+end
@@ -0,0 +1,5 @@
+class AddClaimedActorToAuditEvents < ActiveRecord::Migration[8.0]
+  def change
+    add_column :audit_events, :claimed_actor, :string
+  end
+end
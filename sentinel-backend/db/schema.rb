@@ -10,7 +10,7 @@
 #
 # It's strongly recommended that you check this file into your version control system.
 
-ActiveRecord::Schema[8.0].define(version: 2025_05_15_155949) do
+ActiveRecord::Schema[8.0].define(version: 2025_06_01_100000) do
   create_table "analysis_jobs", charset: "utf8mb4", collation: "utf8mb4_unicode_ci", force: :cascade do |t|
     t.bigint "project_id", null: false
     t.string "status", default: "pending", null: false
@@ -34,6 +34,21 @@ ActiveRecord::Schema[8.0].define(version: 2025_05_15_155949) do
     t.index ["status"], name: "index_analysis_jobs_on_status"
   end
 
+  create_table "audit_events", charset: "utf8mb4", collation: "utf8mb4_unicode_ci", force: :cascade do |t|
+    t.string "action", null: false
+    t.string "actor"
+    t.string "subject_type"
+    t.bigint "subject_id"
+    t.json "details"
+    t.string "remote_ip"
+    t.string "request_id"
+    t.datetime "created_at", null: false
+    t.string "claimed_actor"
+    t.index ["action"], name: "index_audit_events_on_action"
+    t.index ["created_at"], name: "index_audit_events_on_created_at"
+    t.index ["subject_type", "subject_id"], name: "index_audit_events_on_subject_type_and_subject_id"
+  end
+
   create_table "build_metrics", charset: "utf8mb4", collation: "utf8mb4_unicode_ci", force: :cascade do |t|
     t.datetime "timestamp", null: false
     t.integer "duration_ms", null: false
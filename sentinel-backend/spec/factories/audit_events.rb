@@ -0,0 +1,10 @@
+FactoryBot.define do
+  factory :audit_event do
+    action { "rule.updated" }
+    actor { "ada@example.com" }
+    subject_type { "Rule" }
+    sequence(:subject_id)
+    details { { changes: { name: ["Old", "New"] } } }
+    remote_ip { "127.0.0.1" }
+  end
+end
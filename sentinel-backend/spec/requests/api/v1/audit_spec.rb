@@ -0,0 +1,129 @@
+require 'swagger_helper'
+
+RSpec.describe 'Api::V1::Audit', type: :request do
+  let(:user) { create(:user) }
+
+  path '/api/v1/audit' do
+    get 'Lists the audit log, newest first' do
+      tags 'Audit'
+      produces 'application/json'
+      parameter name: :event, in: :query, type: :string, required: false, description: 'Action, e.g. rule.deleted'
+      parameter name: :actor, in: :query, type: :string, required: false
+      parameter name: :subject_type, in: :query, type: :string, required: false
+      parameter name: :subject_id, in: :query, type: :integer, required: false
+      parameter name: :since, in: :query, type: :string, format: 'date-time', required: false
+      parameter name: :page, in: :query, type: :integer, required: false
+      parameter name: :per_page, in: :query, type: :integer, required: false
+
+      response '200', 'audit events found' do
+        before { sign_in user }
+
+        schema type: 'object',
+          properties: {
+            data: {
+              type: 'array',
+              items: {
+                type: 'object',
+                properties: {
+                  id: { type: 'integer' },
+                  action: { type: 'string' },
+                  actor: { type: 'string', nullable: true },
+                  claimed_actor: { type: 'string', nullable: true },
+                  subject_type: { type: 'string', nullable: true },
+                  subject_id: { type: 'integer', nullable: true },
+                  details: { type: 'object', nullable: true },
+                  remote_ip: { type: 'string', nullable: true },
+                  request_id: { type: 'string', nullable: true },
+                  created_at: { type: 'string', format: 'date-time' }
+                },
+                required: %w[id action created_at]
+              }
+            },
+            meta: {
+              type: 'object',
+              properties: {
+                total_count: { type: 'integer' },
+                page: { type: 'integer' },
+                per_page: { type: 'integer' }
+              }
+            }
+          },
+          required: ['data', 'meta']
+
+        let!(:old_event) { create(:audit_event, action: 'rule.created', created_at: 2.days.ago) }
+        let!(:new_event) { create(:audit_event, action: 'rule.deleted') }
+
+        run_test! do |response|
+          data = JSON.parse(response.body)
+          expect(data['data'].map { |event| event['id'] }).to eq([new_event.id, old_event.id])
+          expect(data['meta']['total_count']).to eq(2)
+        end
+      end
+
+      response '200', 'audit events filtered by action' do
+        before { sign_in user }
+
+        let!(:events) { [create(:audit_event, action: 'rule.created'), create(:audit_event, action: 'rule.deleted')] }
+        let(:event) { 'rule.deleted' }
+
+        run_test! do |response|
+          data = JSON.parse(response.body)
+          expect(data['data'].map { |event| event['action'] }).to eq(['rule.deleted'])
+        end
+      end
+
+      response '401', 'not signed in' do
+        run_test!
+      end
+
+      response '400', 'invalid since' do
+        before { sign_in user }
+
+        let(:since) { 'not a date' }
+        run_test!
+      end
+    end
+  end
+
+  describe 'recorded actions' do
+    let!(:rule_group) { create(:rule_group) }
+
+    it 'records deletions with the signed in user as the actor' do
+      sign_in user
+      delete "/api/v1/rule_groups/#{rule_group.id}", headers: { 'X-Sentinel-User' => 'eve@example.com' }
+
+      event = AuditEvent.last
+      expect(event.action).to eq('rule_group.deleted')
+      expect(event.actor).to eq(user.email)
+      expect(event.claimed_actor).to eq('eve@example.com')
+      expect(event.subject_type).to eq('RuleGroup')
+      expect(event.subject_id).to eq(rule_group.id)
+      expect(event.details).to eq('name' => rule_group.name)
+    end
+
+    it 'records no actor without a signed in user' do
+      delete "/api/v1/rule_groups/#{rule_group.id}", headers: { 'X-Sentinel-User' => 'ada@example.com' }
+
+      event = AuditEvent.last
+      expect(event.actor).to be_nil
+      expect(event.claimed_actor).to eq('ada@example.com')
+    end
+
+    it 'keeps events append-only' do
+      event = create(:audit_event)
+
+      expect { event.update!(action: 'rule.created') }.to raise_error(ActiveRecord::ReadOnlyRecord)
+      expect { event.destroy }.to raise_error(ActiveRecord::ReadOnlyRecord)
+    end
+
+    it 'refuses bulk changes' do
+      event = create(:audit_event)
+
+      expect { AuditEvent.where(id: event.id).update_all(action: 'rule.created') }.to raise_error(ActiveRecord::ReadOnlyRecord)
+      expect { AuditEvent.delete_all }.to raise_error(ActiveRecord::ReadOnlyRecord)
+      expect { AuditEvent.recent.delete_all }.to raise_error(ActiveRecord::ReadOnlyRecord)
+      expect { AuditEvent.upsert_all([{ id: event.id, action: 'rule.created' }]) }.to raise_error(ActiveRecord::ReadOnlyRecord)
+      expect(event.reload.action).to eq('rule.updated')
+    end
+  end
+end